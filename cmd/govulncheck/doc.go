@@ -12,7 +12,64 @@ https://vuln.go.dev. Requests to the vulnerability database contain only module
 paths with vulnerabilities already known to the database, not code or other
 properties of your program. See https://vuln.go.dev/privacy.html for more.
 Use the -db flag to specify a different database, which must implement the
-specification at https://go.dev/security/vuln/database.
+specification at https://go.dev/security/vuln/database. The database may also
+be a single zip archive of that layout, given as a file or http(s) URL ending
+in ".zip"; append "#sha256=<hex digest>" to the URL to verify the archive.
+A local database may also be given as an absolute path, such as C:\vulndb or
+\\server\share\vulndb.zip on Windows, instead of a file URL.
+Use the -db-key flag to require that the database index files and each entry
+read carry detached Ed25519 signatures by a trusted key, in a ".sig" file next
+to each of them, so that a mirror of the database cannot alter its contents.
+The cache of the vulnerabilities of each module, described below, is then not
+used, as it may hold unverified entries. With -db-key-warn, verification
+failures are reported as warnings.
+
+The -db flag, or the GOVULNDB environment variable when the flag is not set,
+may also list several databases separated by commas, such as a private database
+of vulnerabilities in internal modules along with the public one. Their
+vulnerabilities are merged. Each database may be followed by options of the
+form ";key=value": ";timeout=<duration>" limits the duration of each request to
+the database, and ";header=<name>:<value>" adds a header to each request to an
+http(s) database. Option values may refer to environment variables as $NAME,
+so that credentials need not appear in the list:
+
+	GOVULNDB='https://vuln.go.dev,https://vulndb.example.com;header=Authorization:Bearer $VULNDB_TOKEN'
+
+A database of the list may itself be a chain of databases separated by "|",
+such as a mirror followed by the database it mirrors, which are read like
+GOPROXY: when a database does not have what is requested, as an http(s)
+database answering "404 Not Found" or "410 Gone", the next one is read instead,
+but other errors are fatal. With -show verbose, govulncheck reports which
+databases of each chain answered:
+
+	$ govulncheck -db 'https://mirror.example.com|https://vuln.go.dev' -show verbose ./...
+
+Private databases served behind authentication are supported with these
+options as well: ";token=<token>" sends a bearer token, ";ca=<file>" trusts
+the certificate authorities of a PEM bundle in addition to those of the system,
+and ";cert=<file>;key=<file>" present a client certificate, for mutual TLS:
+
+	GOVULNDB='https://vulndb.example.com;ca=/etc/ssl/corp.pem;cert=client.pem;key=client.key'
+
+The vulnerabilities of each module are cached in the user's cache directory
+(or the directory named by the GOVULNCHECK_CACHE environment variable) for as
+long as the database is not modified, so that repeated scans do not fetch and
+process them again. The responses of HTTP databases are cached there too, and
+revalidated with conditional requests, so that only the files that changed are
+downloaded again. Set GOVULNCHECK_CACHE=off to disable the cache.
+
+In query mode, which looks up the vulnerabilities of modules given as
+module@version, govulncheck warns when the database was last modified more
+than 30 days ago, as a mirrored database may no longer be updated. Use the
+-db-max-age flag to change the number of days, and the -require-fresh flag to
+fail instead of warning.
+
+A query may also name several versions of a module, as a comma-separated list
+such as module@v1.1.0,v1.2.0 or as a range of the versions the go command knows
+of, such as "module@>=v1.0.0 <v1.5.0". Each vulnerability of such a query is
+then followed by a module-level finding for every version it affects, which
+gives a matrix of vulnerabilities by version, for instance to maintain several
+release branches.
 
 Govulncheck looks for vulnerabilities in Go programs using a specific build
 configuration. For analyzing source code, that configuration is the Go version
@@ -39,10 +96,163 @@ To control which files are processed, use the -tags flag to provide a
 comma-separated list of build tags, and the -test flag to indicate that test
 files should be included.
 
+In source mode, govulncheck loads packages with the go command, in the
+environment it is run in. GOFLAGS, GOOS, GOARCH, GOWORK, and the other
+variables of the go command apply as they do to 'go build', and the Go
+version and effective GOFLAGS are reported in the output. Flags given to
+govulncheck take precedence over GOFLAGS: -tags replaces the build tags
+of GOFLAGS, and each -buildflags flag passes one more flag to the go
+command, for instance '-buildflags=-mod=vendor -buildflags=-buildvcs=false'.
+Only flags that affect which files and module versions are built, such as
+-mod, -modfile, -overlay, -gcflags, and -buildvcs, are accepted. When
+packages fail to load with the build configuration, govulncheck fails
+rather than reporting on the packages that did load.
+
+With the -allow-load-errors flag, govulncheck instead scans the packages
+that load, leaving out those that fail to and the packages that import them.
+The load errors are reported as diagnostics, in a section of the text output
+and as "diagnostic" messages of the JSON output, and govulncheck exits with
+code 4 to tell that the results are partial.
+
+Use the -scan flag to choose how precisely vulnerabilities are matched. The
+default, '-scan symbol', analyzes the call graph of the program to find the
+vulnerable functions it calls. '-scan package' reports the vulnerabilities in
+imported packages, and '-scan module' those in required modules; these scans
+only load the import graph and do not type check any code, so they are much
+faster on large code bases.
+
+With '-scan symbol', the -deep flag refines the call graph of a source scan
+before vulnerable calls are looked up in it. Calls in code ruled out by
+constant conditions, such as 'if debug' with a false constant debug, are
+removed, and so are calls through interfaces to methods of types that the
+program never converts to an interface. This reports fewer vulnerable calls
+that cannot happen, at the risk of missing calls made through reflection.
+Calls through interfaces are only refined when all the scanned packages are
+main packages, since the callers of other packages may convert any of their
+types.
+
+A call of a method through an interface may call the method of any type whose
+values are converted to the interface, so a vulnerable symbol found only through
+such calls is less certain to be called. Govulncheck labels the traces of these
+findings as low-confidence paths, and tells which call of the trace goes through
+an interface: in the scanned code, or in a package such as fmt or encoding/json
+that calls the methods of the values passed to it, which it inspects through
+reflection. JSON findings describe the call in their "dispatch" field. With the
+-exclude-low-confidence flag, these vulnerabilities are reported as imported
+rather than called.
+
 To include more detailed stack traces, pass '-show traces', this will cause it to
 print the full call stack for each entry.
 
+Traces that reach a vulnerable symbol through the same calls into its
+module are folded into the shortest of them, noting how many more call sites
+there are. Pass '-show all-traces' to list every trace.
+
+In source mode, when a vulnerable module is not a direct requirement of the
+main module, govulncheck also shows the chain of requirements through which
+it is required, starting from the main module, such as
+
+	Required through: example.com/m → golang.org/x/a@v1.2.0 → golang.org/x/b@v0.1.0
+
+so that you can tell which direct dependency to upgrade. The chain is taken
+from 'go mod graph'; requirements marked as indirect in go.mod are not
+followed.
+
+By default, the entry points of the analysis are the main and init functions of
+main packages, and the exported functions and methods of other packages. The
+-entry flag restricts them to a comma-separated list of packages, such as
+example.com/m/api or example.com/m/api/..., and functions, such as
+example.com/m/api.Client.Get, example.com/m/cmd/tool.main, or
+example.com/m/api.New*, whose names may use the wildcards of path.Match. For
+instance, library authors can check whether a vulnerability is reachable from
+their public API with '-entry example.com/m/...'.
+
+Library authors can pass the -library flag to analyze the scanned packages as a
+library rather than as programs: the entry points are then the exported
+functions and methods, and the init functions, of the public packages, leaving
+out main and internal packages, so that reported symbols are those reachable
+through the exported API. With '-format openvex', vulnerabilities that are not
+reachable that way are stated as not affecting the library.
+
+Functions in generated or vendored source directories can be left out of the
+entry points of the analysis with the -exclude-dirs flag, which takes a
+comma-separated list of directories relative to the module root, such as
+'-exclude-dirs gen,third_party'. A directory name without a slash matches at any
+depth, and elements may use the wildcards of path.Match. Vulnerable symbols that
+are reachable only from the excluded directories are reported among the
+imported packages, with the precision excluded-reachable.
+
+Calls from C code into Go, such as calls of functions exported with //export in
+packages that use cgo, are not part of the call graph. For each package that
+uses cgo, outside the standard library, govulncheck reports a "cgo" diagnostic,
+and reports the vulnerabilities of the packages it imports, directly or not,
+that the scanned code does not call among the imported packages, with the
+precision cgo-imported, rather than as not called. With '-format openvex', their
+status is under_investigation.
+
+To route findings to the teams that own the affected code, pass a CODEOWNERS
+file, or any file in the same syntax, with the -owners flag. Each finding is
+attributed the owners of the file of the scanned code that is closest to the
+vulnerable symbol in its trace, using the last matching rule as GitHub does.
+Patterns are relative to the directory of the file, or to its parent for a file
+in a .github, .gitlab, or docs directory.
+
+Source scans only look up vulnerabilities in the modules of the scanned packages
+and of the packages they import. To also report vulnerabilities in modules that
+go.mod requires but that no scanned package imports, which are often left over
+and worth removing with 'go mod tidy', pass -unused-modules. Their findings are
+marked as required but unused, with precision module-unused, and the text
+output lists them even without '-show verbose'.
+
+To help judge the effort of fixing vulnerabilities, the -upgrades flag looks
+up the latest version of each vulnerable module with 'go list -m', which queries
+the module proxy, and reports whether the upgrade to the fixed version changes
+the patch, minor, or major version of the module. As for semantic versioning, a
+change of minor version at major version 0 counts as major. Findings then have
+an "upgrade" field in JSON with the "latest" version and the "difficulty".
+
 To include progress messages and more details on findings, pass '-show verbose'.
+The -v flag is shorthand for '-show traces,verbose'. The -q flag prints only the
+one-line summary of findings.
+
+The text output lists findings by vulnerability. Pass '-group-by module' to list
+each vulnerable module once instead, with the worst severity of its
+vulnerabilities, their advisories, and the single version to upgrade to, which
+fixes all of them that have a fix. '-group-by package' does the same for each
+vulnerable package.
+
+When the code is affected, the text output ends with the next steps: a
+'go get' command upgrading each affected module to the lowest version that
+fixes all of its vulnerabilities, the Go release that fixes the standard
+library, links to the advisories, and the number of informational
+vulnerabilities that need no action. Pass -no-advice to leave them out, for
+instance to keep CI logs short.
+
+Pass '-show coverage' to print, after the results of a source or binary scan,
+how many of the vulnerabilities of the scanned modules were checked at symbol,
+package, and module precision, and why some were checked less precisely than
+the scan level allows: for instance, because their advisory lists no vulnerable
+symbols, or because the binary has no symbol table. A clean scan only rules out
+the vulnerabilities at the precision they were checked at.
+
+Warnings about the reliability of the results are reported as diagnostics,
+whatever is shown: packages that failed to load, binaries without a symbol
+table or with incomplete build information, stale or unverified databases,
+and version ranges of vulnerabilities that govulncheck cannot evaluate. Text
+output lists them in a section after the results, JSON output has a
+"diagnostic" message for each, SARIF output has them as tool execution
+notifications, Markdown and HTML reports have a section for them, and OpenVEX
+output notes their number in the status notes of each statement.
+
+Text output is colored when it is written to a terminal, unless the NO_COLOR
+environment variable is set or TERM is "dumb". Pass '-show color' to color
+output regardless.
+
+Text output is printed in the language of the user's locale, as given by the
+LC_ALL, LC_MESSAGES, or LANG environment variables, when govulncheck has a
+translation for it, and in English otherwise. Use the -lang flag to choose
+the language explicitly. Currently English ("en") and Spanish ("es") are
+supported.
 
 To run govulncheck on a compiled binary, pass it the path to the binary file
 with the '-mode binary' flag:
@@ -55,29 +265,245 @@ also the main module of the binary. The latter functions are checked for only
 when the precise version of the binary module is known. Govulncheck output on
 binaries omits call stacks, which require source code analysis.
 
+Besides executables, binary mode accepts Go plugins and shared libraries built
+with -buildmode=plugin or -buildmode=c-shared, and static libraries built with
+-buildmode=c-archive. Static libraries are only supported for ELF platforms,
+such as Linux.
+
+WebAssembly modules built with GOARCH=wasm, for both GOOS=js and GOOS=wasip1,
+are supported as well, as are Android archives (AAR files) built with gomobile.
+For an Android archive, the library for the first available ABI of arm64-v8a,
+armeabi-v7a, x86_64 and x86 is analyzed. iOS frameworks are not supported.
+
+Binary mode also accepts Debian packages, RPM packages, and tar archives. Each
+Go binary found in the package is analyzed and the results are reported per
+binary, named by its path in the package; in JSON output, findings and SBOMs
+have an "artifact" field with that path. Packages and archives compressed with
+gzip or bzip2 are supported, but not those compressed with xz or zstd.
+
 Govulncheck also supports '-mode extract' on a Go binary for extraction of minimal
 information needed to analyze the binary. This will produce a blob, typically much
 smaller than the binary, that can also be passed to govulncheck as an argument with
 '-mode binary'. The users should not rely on the contents or representation of the blob.
 
+With the -history flag, govulncheck records the findings of a source or binary
+scan in a history file in the user's cache directory (or the file named by the
+GOVULNCHECK_HISTORY environment variable). '-mode history' then reports when
+each recorded vulnerability first appeared and when it was resolved, for the
+current module directory or for the binary given as an argument:
+
+	$ govulncheck -history ./...
+	$ govulncheck -mode history
+
+'-mode info' prints the details of the vulnerabilities given as arguments,
+which may be Go vulnerability IDs or aliases such as CVE and GHSA IDs: their
+summary, aliases, severity, affected versions, packages and symbols, and
+references. Use '-format json' to get the OSV entries instead:
+
+	$ govulncheck -mode info GO-2021-0265 CVE-2020-36067
+
+'-mode audit' lists every known vulnerability of the modules given as
+arguments, whatever their versions, with the versions each one affects. This
+helps to evaluate a dependency before adopting it. A module may be followed by
+@version or @latest, in which case the vulnerabilities that affect that version
+are marked and govulncheck exits with status 3 if there are any:
+
+	$ govulncheck -mode audit github.com/tidwall/gjson@latest
+
+'-mode snapshot' writes to standard output a zip database holding the
+vulnerabilities, known at the time, of the modules in the go.sum file of the
+current module, or in the go.sum file or directory given as an argument, and of
+the standard library. Saved next to a binary at build time, it lets later
+binary scans report the vulnerabilities known when the binary was built, along
+with those published since, by listing it before the live database in -db:
+
+	$ govulncheck -mode snapshot > app.vulndb.zip
+	$ govulncheck -mode binary -db file:///path/to/app.vulndb.zip,https://vuln.go.dev app
+
+With the -watch flag, govulncheck keeps running after the first source scan and
+rescans whenever go.mod, go.sum, go.work, or go.work.sum change, printing only
+the vulnerabilities that appeared or disappeared since the previous scan. Use
+-watch=src to also rescan when Go source files change. A burst of changes, such
+as an editor saving many files, causes a single rescan once the files have
+stayed unchanged for a second, and a rescan is canceled and restarted if files
+change while it runs.
+
+With the -recursive flag, govulncheck scans every module in the directory tree,
+skipping vendor and testdata directories, and reports the results of each
+module separately, followed by a summary of how many modules are affected. Each
+module is analyzed with the Go version the go command selects for it. A module
+that fails to load does not stop the others from being scanned; the failures
+are reported after the results. In JSON output, findings and SBOMs have an
+"artifact" field with the module directory.
+
+	$ govulncheck -recursive
+
+On very large code bases, source analysis can use a lot of memory. The
+-max-memory flag sets a soft limit, such as 4GiB, on the memory govulncheck
+uses: garbage is collected more often as the limit approaches, fewer packages
+are analyzed in parallel, and SSA is built one package at a time. This makes
+the scan slower. The GOMEMLIMIT environment variable has the same effect on
+SSA construction and garbage collection. As the memory limit applies to the
+whole process, scans run with golang.org/x/vuln/scan ignore -max-memory, and
+the programs running them can set GOMEMLIMIT instead.
+
+To help diagnose slow scans, the -debug flag prints to standard error how long
+each phase of the scan took: loading packages, fetching vulnerabilities,
+building SSA, building the call graph, matching vulnerabilities, and writing
+output. Some phases run concurrently. The -cpuprofile, -memprofile, and -trace
+flags write a CPU profile, an allocation profile, and an execution trace, in
+which the phases are marked as regions, to the named files. Please attach them
+when reporting performance issues.
+
+The -policy flag names a file with a policy that decides, for each finding,
+whether to report it, suppress it, or fail the scan on it. A policy is an
+expression in a subset of the Common Expression Language (CEL) that evaluates
+to "report", "suppress", or "fail", using the variables id, module,
+fixed_version, severity (a CVSS rating such as "HIGH", or "" if unknown),
+reachable, fix_available, module_depth (the number of modules crossed by the
+call stack), age_days (days since the vulnerability was published), and
+fix_available_days (days since the fixed version was released):
+
+	// Fail on reachable high severity vulnerabilities, and on
+	// reachable vulnerabilities with a fix older than 30 days,
+	// and suppress those that cannot be fixed yet.
+	severity in ["CRITICAL", "HIGH"] && reachable ? "fail" :
+	reachable && fix_available_days > 30 ? "fail" :
+	!fix_available ? "suppress" :
+	"report"
+
 # Integrations
 
 Govulncheck supports streaming JSON. For more details, please see [golang.org/x/vuln/internal/govulncheck].
+Programs that consume it can use [golang.org/x/vuln/scan/protocol], which
+decodes and encodes streams, rejects streams of incompatible protocol versions,
+and documents how the protocol may change within a major version.
+
+The config message that starts JSON output, which is also the "properties" of
+the SARIF tool driver, records how the scan was run, so that its results can
+be reproduced and audited: the scanner version and the Go version it was built
+with, the flags it was run with, the database and when it was last modified,
+the Go version and effective GOFLAGS used to load packages, and the scan level
+and mode.
+
+Each finding states the precision of the analysis behind it: symbol-reachable,
+package-imported, module-required, binary-imprecise for symbols found in a
+binary, which are present but not necessarily reachable, excluded-reachable
+for symbols reachable only from directories excluded with -exclude-dirs, or
+cgo-imported for packages imported by packages that use cgo, or module-unused
+for modules required but unused, reported with -unused-modules. JSON findings
+have a "precision" field, SARIF results a "precision" property, OpenVEX
+statements status notes, and text output shows the precision with
+'-show verbose'.
+
+Findings also carry the disclosure of their vulnerability: when it was
+published and last modified in the database, and for how many days a fix has
+been available. The database does not record when fixes are released, so they
+are looked up in the module proxy, which is only done with -policy, except in
+convert and query modes or when GOPROXY is off. Fix release times are unknown
+otherwise, for the standard library, and when the proxy cannot be reached.
+JSON findings and SARIF results have a "disclosure" field, OpenVEX status notes
+include the publication date, and text output shows the disclosure with '-show
+verbose'.
+
+In source mode, findings in modules that are only required indirectly have a
+"module_chain" field in JSON and a "module_chain" property in SARIF results,
+listing the chain of requirements described above. The chains come from 'go mod
+graph', which is only run once a finding outside the standard library is
+reported; when it fails, findings have no chains, and '-show verbose' tells
+why. With -owners, findings have an "owners" field in JSON, and SARIF results
+an "owners" property.
+
+For tools that identify packages by Package URL (purl), JSON findings have a
+"purl" field with the purl of their vulnerable module at the version found,
+such as pkg:golang/golang.org%2Fx%2Ftext@v0.3.7, and the affected modules of
+OSV entries have a "purl" field without a version, as in the OSV schema.
+OpenVEX statements use the same purls as product identifiers.
+
+The output of a scan is ordered deterministically, whatever the format:
+vulnerabilities are ordered by ID, and their findings by the module,
+package, and symbol they were found in, so that the output of two scans
+can be compared with diff. In streaming JSON, findings are therefore
+emitted only once a scan is complete.
+
+Go tests can run govulncheck on their module with the Check function of
+[golang.org/x/vuln/scan/scantest], which fails the test when vulnerable
+functions are called, or according to a policy, so that plain go test gates
+vulnerabilities in continuous integration.
 
 Govulncheck also supports Static Analysis Results Interchange Format (SARIF) output
 format, following the specification at https://www.oasis-open.org/committees/tc_home.php?wg_abbrev=sarif.
 For more details, please see [golang.org/x/vuln/internal/sarif].
 
+With '-upload', the results are also posted to a service once the scan is
+complete, in any output format, so that a single step of a workflow scans and
+reports. Each service is configured with environment variables, so that tokens
+are kept out of the command line.
+
+'-upload github' uploads the results as SARIF to GitHub code scanning. The
+repository and the token come from GITHUB_REPOSITORY and GITHUB_TOKEN, which
+needs the security-events write permission, and the results are for the commit
+and ref in GITHUB_SHA and GITHUB_REF, as set by GitHub Actions, or else for the
+commit and branch checked out. GITHUB_API_URL selects a GitHub Enterprise
+Server:
+
+	$ govulncheck -format sarif -upload github ./... > govulncheck.sarif
+
+'-upload defectdojo' imports the results into the DefectDojo engagement
+DEFECTDOJO_ENGAGEMENT of the instance at DEFECTDOJO_URL, with the API token in
+DEFECTDOJO_TOKEN, as a Generic Findings Import scan. There is a finding for
+each vulnerability of each module, with its severity, the fixed version, and
+the position in the scanned code closest to the vulnerable symbol, if any. The
+unique ID of a finding is a fingerprint of the vulnerability and the module, so
+that DefectDojo tracks it across scans and versions.
+
+'-upload webhook' posts the same findings, along with the configuration of the
+scan, as a JSON object to GOVULNCHECK_WEBHOOK_URL, with the bearer token in
+GOVULNCHECK_WEBHOOK_TOKEN, if set, for other services to consume.
+
+With '-attest file', govulncheck also writes an in-toto attestation of the
+scan to file, so that supply-chain policies can verify its results. The
+statement has the vulnerability predicate of the in-toto attestation framework:
+the version of govulncheck, the database and when it was last modified, and each
+vulnerability found, with its severity and, for each affected module, its
+version, fixed version and how precisely it was found. Its subject is the
+binary in binary mode, and the main modules in source mode, identified by the
+digests of their go.mod files and of the commit checked out, if any.
+
+With '-attest-key', the statement is signed in a DSSE envelope with the
+PEM-encoded ECDSA, Ed25519 or RSA private key in the given file. Otherwise, it
+is written unsigned, for example to be signed by cosign with Sigstore keyless
+signing, which needs an OIDC identity:
+
+	$ govulncheck -mode binary -attest scan.intoto.json -attest-key key.pem ./app
+
 Govulncheck supports the Vulnerability EXchange (VEX) output format, following
 the specification at https://github.com/openvex/spec.
 For more details, please see [golang.org/x/vuln/internal/openvex].
 
+With '-format markdown' or '-format html', govulncheck writes a report of the
+findings for people to read, such as in a pull request comment or on a CI
+dashboard. '-mode convert' renders the JSON output of a previous scan, read
+from the file given as argument or from standard input, in any other format
+without scanning again:
+
+	$ govulncheck -format json ./... > scan.json
+	$ govulncheck -mode convert -format html scan.json > report.html
+
 # Exit codes
 
 Govulncheck exits successfully (exit code 0) if there are no vulnerabilities,
 and exits unsuccessfully if there are. It also exits successfully if the
-'format -json' ('-json'), '-format sarif', or '-format openvex' is provided,
-regardless of the number of detected vulnerabilities.
+'format -json' ('-json'), '-format sarif', '-format openvex', '-format markdown',
+or '-format html' is provided, regardless of the number of detected
+vulnerabilities.
+
+With the -policy flag, the policy decides instead: govulncheck exits with code 3
+if the policy fails on any finding, whatever the output format, and successfully
+otherwise.
+
+With the -allow-load-errors flag, govulncheck exits with code 4 if some packages
+failed to load, whatever the output format, unless it exits with code 3.
 
 # Limitations
 
@@ -92,7 +518,8 @@ Govulncheck has these limitations:
   - Because Go binaries do not contain detailed call information, govulncheck
     cannot show the call graphs for detected vulnerabilities. It may also
     report false positives for code that is in the binary but unreachable.
-  - There is no support for silencing vulnerability findings. See https://go.dev/issue/61211 for
+  - There is no support for silencing vulnerability findings other than with
+    a -policy. See https://go.dev/issue/61211 for
     updates.
   - Govulncheck reports only standard library vulnerabilities for binaries
     built with Go versions prior to Go 1.18.
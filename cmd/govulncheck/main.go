@@ -10,6 +10,7 @@ import (
 	"os"
 
 	"golang.org/x/telemetry"
+	iscan "golang.org/x/vuln/internal/scan"
 	"golang.org/x/vuln/scan"
 )
 
@@ -18,6 +19,10 @@ func main() {
 
 	ctx := context.Background()
 
+	// Only the command changes the settings of the runtime,
+	// which scans run as a library share with their programs.
+	iscan.LimitMemory(os.Args[1:])
+
 	cmd := scan.Command(ctx, os.Args[1:]...)
 	err := cmd.Start()
 	if err == nil {
@@ -92,7 +92,11 @@ func runTestCase(t *testing.T, tcName, testDir string) {
 	}
 
 	os.Setenv("moddir", modulesDir)
+	// Text output is localized, so use the default language.
+	os.Setenv("LC_ALL", "C")
 	os.Setenv("testdir", testfilesDir)
+	// Keep the cache of vulnerabilities out of the user's cache directory.
+	os.Setenv("GOVULNCHECK_CACHE", t.TempDir())
 	runTestSuite(t, testfilesDir, govulndbURI.String(), cfg, *update)
 }
 
@@ -0,0 +1,7 @@
+package app
+
+import "golang.org/broken/lib"
+
+func Run() int {
+	return lib.Answer()
+}
@@ -0,0 +1,3 @@
+module golang.org/broken
+
+go 1.18
@@ -0,0 +1,7 @@
+// Package lib does not compile, to test scans of the
+// packages that load when others fail to.
+package lib
+
+func Answer() int {
+	return missing
+}
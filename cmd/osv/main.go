@@ -0,0 +1,48 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Command osv helps to maintain a private vulnerability database that
+// govulncheck can read with its -db flag.
+//
+// Usage:
+//
+//	osv new [flags]
+//
+// The new command scaffolds an OSV entry for a private advisory from its
+// flags, asking for the missing ones when the standard input is a
+// terminal, validates it, and prints it as JSON. For example:
+//
+//	$ osv new -id PRIVATE-2024-0001 -module example.com/m -fixed v1.2.3 \
+//		-symbols example.com/m/api.Parse,example.com/m/api.T.Decode \
+//		-summary "Panic on malformed input in example.com/m/api" \
+//		-fix https://example.com/m/commit/1234 > PRIVATE-2024-0001.json
+//
+// With -commit, osv new lists the functions and methods changed by the
+// fix commit in the repository of the module given by -repo, and uses
+// them as the vulnerable symbols unless -symbols is set. Review them: a
+// fix may change functions that are not vulnerable, or fix a function
+// by changing its callees.
+//
+// Problems that make the entry invalid are fatal; problems that are
+// merely discouraged, such as a missing fix reference, are printed as
+// warnings on standard error.
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+func main() {
+	if len(os.Args) < 2 || os.Args[1] != "new" {
+		fmt.Fprintln(os.Stderr, "usage: osv new [flags]")
+		os.Exit(2)
+	}
+	if err := runNew(os.Args[2:], os.Stdin, os.Stdout, os.Stderr); err != nil {
+		if err != errUsage {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		os.Exit(exitCode(err))
+	}
+}
@@ -0,0 +1,257 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/vuln/internal/osv"
+)
+
+var errUsage = errors.New("invalid usage")
+
+// exitCode returns the exit status for err: 2 for usage
+// errors and 1 for the others.
+func exitCode(err error) int {
+	if err == errUsage {
+		return 2
+	}
+	return 1
+}
+
+// newOptions are the fields of an entry scaffolded by osv new.
+type newOptions struct {
+	id         string
+	module     string
+	introduced string
+	fixed      string
+	packages   string // comma-separated import paths
+	symbols    string // comma-separated qualified symbols
+	summary    string
+	details    string
+	aliases    string // comma-separated IDs
+	fix        string // URL of the fix commit
+	repo       string // directory of the git repository of the module
+	commit     string // fix commit in repo
+	advisory   string // URL of the advisory
+	output     string
+}
+
+// runNew implements osv new: it scaffolds an OSV entry from args,
+// asking on in and out for the missing fields if in is a terminal,
+// and writes it to out or to the -o file. Warnings go to stderr.
+func runNew(args []string, in io.Reader, out, stderr io.Writer) error {
+	var o newOptions
+	flags := flag.NewFlagSet("osv new", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.StringVar(&o.id, "id", "", "`ID` of the entry, such as PRIVATE-2024-0001")
+	flags.StringVar(&o.module, "module", "", "`path` of the affected module")
+	flags.StringVar(&o.introduced, "introduced", "", "first affected `version` (default all versions before -fixed)")
+	flags.StringVar(&o.fixed, "fixed", "", "first fixed `version` (default none)")
+	flags.StringVar(&o.packages, "packages", "", "comma-separated `list` of affected packages of the module (default those of -symbols)")
+	flags.StringVar(&o.symbols, "symbols", "", "comma-separated `list` of vulnerable symbols, such as example.com/m/api.Parse or example.com/m/api.T.Decode")
+	flags.StringVar(&o.summary, "summary", "", "one-line `summary` of the vulnerability")
+	flags.StringVar(&o.details, "details", "", "`description` of the vulnerability")
+	flags.StringVar(&o.aliases, "aliases", "", "comma-separated `list` of aliases, such as CVE or GHSA IDs")
+	flags.StringVar(&o.fix, "fix", "", "`url` of the fix commit")
+	flags.StringVar(&o.repo, "repo", ".", "`dir` of the git repository of the module, whose root is the module root, for -commit")
+	flags.StringVar(&o.commit, "commit", "", "fix `commit` in -repo; the functions it changes are suggested as the vulnerable symbols, and used if -symbols is not set")
+	flags.StringVar(&o.advisory, "advisory", "", "`url` of the advisory")
+	flags.StringVar(&o.output, "o", "", "write the entry to `file` instead of standard output")
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return errUsage
+	}
+	if flags.NArg() > 0 {
+		fmt.Fprintln(stderr, "osv new takes no arguments")
+		return errUsage
+	}
+	if isTerminal(in) {
+		if err := ask(bufio.NewReader(in), stderr, &o); err != nil {
+			return err
+		}
+	}
+
+	if o.commit != "" && o.module != "" {
+		syms, err := fixSymbols(o.repo, o.commit, o.module)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(stderr, "functions changed by %s:\n", o.commit)
+		for _, s := range syms {
+			fmt.Fprintf(stderr, "\t%s\n", s)
+		}
+		if o.symbols == "" {
+			o.symbols = strings.Join(syms, ",")
+		}
+	}
+	e, err := newEntry(&o, time.Now())
+	if err != nil {
+		return err
+	}
+	if err := osv.Validate(e); err != nil {
+		return fmt.Errorf("invalid entry:\n%v", err)
+	}
+	for _, err := range osv.Lint(e) {
+		fmt.Fprintf(stderr, "warning: %v\n", err)
+	}
+	b, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+	if o.output != "" {
+		return os.WriteFile(o.output, b, 0o644)
+	}
+	_, err = out.Write(b)
+	return err
+}
+
+// isTerminal reports whether r is a terminal.
+func isTerminal(r io.Reader) bool {
+	f, ok := r.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// ask asks on w for the fields of o that are not set, and reads the
+// answers from r. Empty answers leave the fields unset.
+func ask(r *bufio.Reader, w io.Writer, o *newOptions) error {
+	for _, q := range []struct {
+		prompt string
+		field  *string
+	}{
+		{"ID", &o.id},
+		{"Module path", &o.module},
+		{"Introduced version (empty for all versions)", &o.introduced},
+		{"Fixed version (empty if not fixed)", &o.fixed},
+		{"Vulnerable symbols", &o.symbols},
+		{"Summary", &o.summary},
+		{"Fix commit URL", &o.fix},
+	} {
+		if *q.field != "" || (q.field == &o.symbols && o.commit != "") {
+			// With -commit, the symbols are discovered.
+			continue
+		}
+		fmt.Fprintf(w, "%s: ", q.prompt)
+		line, err := r.ReadString('\n')
+		*q.field = strings.TrimSpace(line)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newEntry returns the entry described by o, published and modified
+// at now.
+func newEntry(o *newOptions, now time.Time) (*osv.Entry, error) {
+	if o.id == "" || o.module == "" {
+		return nil, errors.New("the ID and the module of the entry are required")
+	}
+	now = now.UTC().Truncate(time.Second)
+	introduced := strings.TrimPrefix(o.introduced, "v")
+	if introduced == "" {
+		introduced = "0"
+	}
+	events := []osv.RangeEvent{{Introduced: introduced}}
+	if o.fixed != "" {
+		events = append(events, osv.RangeEvent{Fixed: strings.TrimPrefix(o.fixed, "v")})
+	}
+	pkgs, err := packages(split(o.packages), split(o.symbols))
+	if err != nil {
+		return nil, err
+	}
+	e := &osv.Entry{
+		ID:        o.id,
+		Modified:  now,
+		Published: now,
+		Aliases:   split(o.aliases),
+		Summary:   o.summary,
+		Details:   o.details,
+		Affected: []osv.Affected{{
+			Module: osv.Module{Path: o.module, Ecosystem: osv.GoEcosystem, Purl: osv.PackageURL(o.module, "")},
+			Ranges: []osv.Range{{Type: osv.RangeTypeSemver, Events: events}},
+			EcosystemSpecific: osv.EcosystemSpecific{
+				Packages: pkgs,
+			},
+		}},
+	}
+	if o.advisory != "" {
+		e.References = append(e.References, osv.Reference{Type: osv.ReferenceTypeAdvisory, URL: o.advisory})
+	}
+	if o.fix != "" {
+		e.References = append(e.References, osv.Reference{Type: osv.ReferenceTypeFix, URL: o.fix})
+	}
+	return e, nil
+}
+
+// packages returns the affected packages with the given paths and
+// qualified symbols, in the order they first appear.
+func packages(paths, symbols []string) ([]osv.Package, error) {
+	var pkgs []osv.Package
+	index := make(map[string]int)
+	add := func(path string) int {
+		i, ok := index[path]
+		if !ok {
+			i = len(pkgs)
+			index[path] = i
+			pkgs = append(pkgs, osv.Package{Path: path})
+		}
+		return i
+	}
+	for _, p := range paths {
+		add(p)
+	}
+	for _, s := range symbols {
+		path, sym, ok := splitSymbol(s)
+		if !ok {
+			return nil, fmt.Errorf("symbol %q is not of the form package.Symbol", s)
+		}
+		i := add(path)
+		pkgs[i].Symbols = append(pkgs[i].Symbols, sym)
+	}
+	return pkgs, nil
+}
+
+// splitSymbol splits a qualified symbol, such as example.com/m/api.T.M,
+// into its package path and its name within the package.
+func splitSymbol(s string) (path, sym string, ok bool) {
+	slash := strings.LastIndex(s, "/")
+	dot := strings.Index(s[slash+1:], ".")
+	if dot < 0 {
+		return "", "", false
+	}
+	dot += slash + 1
+	path, sym = s[:dot], s[dot+1:]
+	return path, sym, path != "" && sym != ""
+}
+
+// split returns the non-empty elements of the comma-separated list s.
+func split(s string) []string {
+	var elems []string
+	for _, e := range strings.Split(s, ",") {
+		if e = strings.TrimSpace(e); e != "" {
+			elems = append(elems, e)
+		}
+	}
+	return elems
+}
@@ -0,0 +1,113 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/vuln/internal/osv"
+)
+
+func TestNewEntry(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+	got, err := newEntry(&newOptions{
+		id:       "PRIVATE-2024-0001",
+		module:   "example.com/m",
+		fixed:    "v1.2.3",
+		packages: "example.com/m/internal",
+		symbols:  "example.com/m/api.Parse, example.com/m/api.T.Decode,example.com/m.New",
+		summary:  "Panic in example.com/m",
+		aliases:  "CVE-2024-1234",
+		fix:      "https://example.com/m/commit/1234",
+	}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &osv.Entry{
+		ID:        "PRIVATE-2024-0001",
+		Modified:  now,
+		Published: now,
+		Aliases:   []string{"CVE-2024-1234"},
+		Summary:   "Panic in example.com/m",
+		Affected: []osv.Affected{{
+			Module: osv.Module{Path: "example.com/m", Ecosystem: osv.GoEcosystem, Purl: "pkg:golang/example.com%2Fm"},
+			Ranges: []osv.Range{{
+				Type:   osv.RangeTypeSemver,
+				Events: []osv.RangeEvent{{Introduced: "0"}, {Fixed: "1.2.3"}},
+			}},
+			EcosystemSpecific: osv.EcosystemSpecific{Packages: []osv.Package{
+				{Path: "example.com/m/internal"},
+				{Path: "example.com/m/api", Symbols: []string{"Parse", "T.Decode"}},
+				{Path: "example.com/m", Symbols: []string{"New"}},
+			}},
+		}},
+		References: []osv.Reference{{Type: osv.ReferenceTypeFix, URL: "https://example.com/m/commit/1234"}},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("newEntry() mismatch (-want +got):\n%s", diff)
+	}
+	if err := osv.Validate(got); err != nil {
+		t.Errorf("newEntry() is invalid: %v", err)
+	}
+
+	if _, err := newEntry(&newOptions{id: "PRIVATE-2024-0001", module: "example.com/m", symbols: "Parse"}, now); err == nil {
+		t.Error("newEntry() with an unqualified symbol succeeded unexpectedly")
+	}
+}
+
+func TestAsk(t *testing.T) {
+	o := &newOptions{id: "PRIVATE-2024-0001"}
+	var prompts bytes.Buffer
+	in := "example.com/m\n\n1.0.1\nexample.com/m.F\nA summary"
+	if err := ask(bufio.NewReader(strings.NewReader(in)), &prompts, o); err != nil {
+		t.Fatal(err)
+	}
+	want := &newOptions{
+		id:      "PRIVATE-2024-0001",
+		module:  "example.com/m",
+		fixed:   "1.0.1",
+		symbols: "example.com/m.F",
+		summary: "A summary",
+	}
+	if *o != *want {
+		t.Errorf("ask() = %+v, want %+v", *o, *want)
+	}
+	if strings.Contains(prompts.String(), "ID:") {
+		t.Errorf("ask() asked for the ID, which was set: %q", prompts.String())
+	}
+}
+
+func TestRunNew(t *testing.T) {
+	var out, stderr bytes.Buffer
+	err := runNew([]string{"-id", "PRIVATE-2024-0001", "-module", "example.com/m", "-fixed", "1.0.1"}, strings.NewReader(""), &out, &stderr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var e osv.Entry
+	if err := json.Unmarshal(out.Bytes(), &e); err != nil {
+		t.Fatal(err)
+	}
+	if e.ID != "PRIVATE-2024-0001" {
+		t.Errorf("got entry %s, want PRIVATE-2024-0001", e.ID)
+	}
+	// There is no summary, and no fix reference.
+	if got := strings.Count(stderr.String(), "warning: "); got < 2 {
+		t.Errorf("got %d warnings, want at least 2:\n%s", got, stderr.String())
+	}
+
+	err = runNew([]string{"-id", "PRIVATE-2024-0001", "-module", "example.com/m", "-fixed", "x.y"}, strings.NewReader(""), &out, &stderr)
+	if err == nil || !strings.Contains(err.Error(), "not a valid SEMVER version") {
+		t.Errorf("runNew() with an invalid version = %v, want an invalid version error", err)
+	}
+	if err := runNew([]string{"-module", "example.com/m"}, strings.NewReader(""), &out, &stderr); err == nil {
+		t.Error("runNew() without an ID succeeded unexpectedly")
+	}
+}
@@ -0,0 +1,186 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os/exec"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// fixSymbols returns the qualified symbols, such as example.com/m/api.T.M,
+// of the functions and methods of module that commit changes in the git
+// repository in dir, whose root is the root of the module. The symbols
+// are those of the parent of commit, the last vulnerable version, and
+// test files are left out.
+func fixSymbols(dir, commit, module string) ([]string, error) {
+	diff, err := git(dir, "diff", "--unified=0", "--no-renames", "--no-color", commit+"^", commit, "--", "*.go")
+	if err != nil {
+		return nil, err
+	}
+	changes, err := parseDiff(diff)
+	if err != nil {
+		return nil, err
+	}
+	var syms []string
+	for _, c := range changes {
+		if !strings.HasSuffix(c.file, ".go") || strings.HasSuffix(c.file, "_test.go") {
+			continue
+		}
+		src, err := git(dir, "show", commit+"^:"+c.file)
+		if err != nil {
+			return nil, err
+		}
+		funcs, err := changedFuncs(c.file, src, c.lines)
+		if err != nil {
+			return nil, err
+		}
+		pkg := module
+		if d := path.Dir(c.file); d != "." {
+			pkg += "/" + d
+		}
+		for _, f := range funcs {
+			syms = append(syms, pkg+"."+f)
+		}
+	}
+	sort.Strings(syms)
+	return syms, nil
+}
+
+// git runs git with args in dir and returns its output.
+func git(dir string, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	out, err := cmd.Output()
+	if err != nil {
+		var ee *exec.ExitError
+		if errors.As(err, &ee) && len(ee.Stderr) > 0 {
+			return nil, fmt.Errorf("git %s: %s", args[0], bytes.TrimSpace(ee.Stderr))
+		}
+		return nil, fmt.Errorf("git %s: %v", args[0], err)
+	}
+	return out, nil
+}
+
+// A fileChange is a file changed by a diff, with the
+// ranges of lines of its old version that are changed.
+type fileChange struct {
+	file  string
+	lines [][2]int // inclusive ranges
+}
+
+// parseDiff parses the changes to existing files of a unified diff.
+// Lines that are only added are attributed to the line before them.
+func parseDiff(diff []byte) ([]fileChange, error) {
+	var changes []fileChange
+	var cur *fileChange
+	header := false // whether in the header of a file, before its hunks
+	s := bufio.NewScanner(bytes.NewReader(diff))
+	for s.Scan() {
+		line := s.Text()
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			header, cur = true, nil
+		case header && strings.HasPrefix(line, "--- "):
+			if name, ok := strings.CutPrefix(line, "--- a/"); ok {
+				changes = append(changes, fileChange{file: name})
+				cur = &changes[len(changes)-1]
+			}
+		case strings.HasPrefix(line, "@@ "):
+			header = false
+			if cur == nil {
+				continue
+			}
+			// @@ -start[,count] +start[,count] @@
+			fields := strings.Fields(line)
+			if len(fields) < 3 || !strings.HasPrefix(fields[1], "-") {
+				return nil, fmt.Errorf("invalid hunk header %q", line)
+			}
+			start, count, err := parseRange(fields[1][1:])
+			if err != nil {
+				return nil, fmt.Errorf("invalid hunk header %q: %v", line, err)
+			}
+			end := start + count - 1
+			if count == 0 {
+				end = start
+			}
+			cur.lines = append(cur.lines, [2]int{start, end})
+		}
+	}
+	return changes, s.Err()
+}
+
+// parseRange parses the start[,count] range of a hunk header.
+func parseRange(s string) (start, count int, err error) {
+	count = 1
+	if i := strings.Index(s, ","); i >= 0 {
+		if count, err = strconv.Atoi(s[i+1:]); err != nil {
+			return 0, 0, err
+		}
+		s = s[:i]
+	}
+	start, err = strconv.Atoi(s)
+	return start, count, err
+}
+
+// changedFuncs returns the names of the functions, and methods as
+// Type.Method, of the Go source src of file that overlap lines.
+func changedFuncs(file string, src []byte, lines [][2]int) ([]string, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, file, src, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+	var funcs []string
+	for _, d := range f.Decls {
+		fd, ok := d.(*ast.FuncDecl)
+		if !ok {
+			continue
+		}
+		start, end := fset.Position(fd.Pos()).Line, fset.Position(fd.End()).Line
+		for _, l := range lines {
+			if l[0] <= end && start <= l[1] {
+				funcs = append(funcs, funcName(fd))
+				break
+			}
+		}
+	}
+	return funcs, nil
+}
+
+// funcName returns the name of fd, as Type.Method for methods.
+func funcName(fd *ast.FuncDecl) string {
+	if fd.Recv == nil || len(fd.Recv.List) == 0 {
+		return fd.Name.Name
+	}
+	t := fd.Recv.List[0].Type
+	for {
+		switch x := t.(type) {
+		case *ast.StarExpr:
+			t = x.X
+			continue
+		case *ast.IndexExpr:
+			t = x.X
+			continue
+		case *ast.IndexListExpr:
+			t = x.X
+			continue
+		case *ast.ParenExpr:
+			t = x.X
+			continue
+		case *ast.Ident:
+			return x.Name + "." + fd.Name.Name
+		}
+		return fd.Name.Name
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/vuln/internal/testenv"
+)
+
+func TestParseDiff(t *testing.T) {
+	const diff = `diff --git a/api/parse.go b/api/parse.go
+index 1111111..2222222 100644
+--- a/api/parse.go
++++ b/api/parse.go
+@@ -10,2 +10,3 @@ func Parse(s string) error {
+-	x := 1
+-	y := 2
++	x := 0
+@@ -20 +21,0 @@ func helper() {
+@@ -30,0 +31,2 @@ func (t *T) Decode() {
+diff --git a/new.go b/new.go
+new file mode 100644
+index 0000000..3333333
+--- /dev/null
++++ b/new.go
+@@ -0,0 +1,3 @@
++package m
+`
+	got, err := parseDiff([]byte(diff))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []fileChange{{file: "api/parse.go", lines: [][2]int{{10, 11}, {20, 20}, {30, 30}}}}
+	if diff := cmp.Diff(want, got, cmp.AllowUnexported(fileChange{})); diff != "" {
+		t.Errorf("parseDiff() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+const parseSrc = `package api
+
+type T[P any] struct{}
+
+func Parse(s string) error {
+	return nil
+}
+
+func (t *T[P]) Decode() {
+}
+
+func Helper() {
+}
+`
+
+func TestChangedFuncs(t *testing.T) {
+	got, err := changedFuncs("parse.go", []byte(parseSrc), [][2]int{{6, 6}, {9, 9}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"Parse", "T.Decode"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("changedFuncs() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestFixSymbols(t *testing.T) {
+	testenv.NeedsExec(t)
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available")
+	}
+	dir := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	write := func(name, content string) {
+		t.Helper()
+		name = filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	run("init", "-q")
+	write("api/parse.go", parseSrc)
+	write("api/parse_test.go", "package api\n\nfunc TestParse() {\n}\n")
+	run("add", ".")
+	run("commit", "-q", "-m", "vulnerable")
+	write("api/parse.go", parseSrc[:len(parseSrc)-len("}\n")]+"\tprintln()\n}\n")
+	write("api/parse_test.go", "package api\n\nfunc TestParse() {\n\tprintln()\n}\n")
+	run("commit", "-q", "-a", "-m", "fix")
+
+	got, err := fixSymbols(dir, "HEAD", "example.com/m")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"example.com/m/api.Helper"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("fixSymbols() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -8,6 +8,7 @@ require (
 	golang.org/x/mod v0.22.0
 	golang.org/x/sync v0.10.0
 	golang.org/x/telemetry v0.0.0-20240522233618-39ace7a40ae7
+	golang.org/x/text v0.21.0
 	golang.org/x/tools v0.29.0
 )
 
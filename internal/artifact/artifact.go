@@ -0,0 +1,218 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package artifact finds the Go binaries in software packages, so that
+// govulncheck can scan what is actually shipped to users.
+//
+// Debian packages, RPM packages, and tar archives, optionally compressed
+// with gzip or bzip2, are supported.
+package artifact
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"debug/buildinfo"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Format is a supported package format.
+type Format string
+
+const (
+	FormatDeb = Format("deb")
+	FormatRPM = Format("rpm")
+	FormatTar = Format("tar")
+)
+
+// A Binary is a Go binary found in a package.
+type Binary struct {
+	// Name is the slash-separated path of the binary in the package.
+	Name string
+	// File is the path of the binary extracted from the package.
+	File string
+}
+
+const (
+	debMagic = "!<arch>\ndebian-binary"
+	rpmMagic = "\xed\xab\xee\xdb"
+	tarMagic = "ustar"
+	// tarMagicOffset is the offset of the magic in the tar header.
+	tarMagicOffset = 257
+)
+
+// DetectFormat returns the format of the package in the file at path,
+// or "" if it is not a supported package.
+func DetectFormat(path string) (Format, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	r := bufio.NewReader(f)
+	b, err := r.Peek(tarMagicOffset + len(tarMagic))
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	switch {
+	case bytes.HasPrefix(b, []byte(debMagic)):
+		return FormatDeb, nil
+	case bytes.HasPrefix(b, []byte(rpmMagic)):
+		return FormatRPM, nil
+	case len(b) == tarMagicOffset+len(tarMagic) && string(b[tarMagicOffset:]) == tarMagic:
+		return FormatTar, nil
+	}
+	if c, err := compression(b); c != nil || err != nil {
+		// Only tar archives are compressed as a whole.
+		if err != nil {
+			return "", err
+		}
+		zr, err := c(r)
+		if err != nil {
+			return "", nil
+		}
+		b := make([]byte, tarMagicOffset+len(tarMagic))
+		if _, err := io.ReadFull(zr, b); err == nil && string(b[tarMagicOffset:]) == tarMagic {
+			return FormatTar, nil
+		}
+	}
+	return "", nil
+}
+
+// decompressor returns a reader of the decompressed contents of r.
+type decompressor func(r io.Reader) (io.Reader, error)
+
+// compression returns the decompressor for data starting with b, or
+// nil if b is not compressed. It returns an error for compression
+// formats that are recognized but not supported.
+func compression(b []byte) (decompressor, error) {
+	switch {
+	case bytes.HasPrefix(b, []byte("\x1f\x8b")):
+		return func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) }, nil
+	case bytes.HasPrefix(b, []byte("BZh")):
+		return func(r io.Reader) (io.Reader, error) { return bzip2.NewReader(r), nil }, nil
+	case bytes.HasPrefix(b, []byte("\xfd7zXZ\x00")):
+		return nil, errors.New("xz compression is not supported")
+	case bytes.HasPrefix(b, []byte("\x28\xb5\x2f\xfd")):
+		return nil, errors.New("zstd compression is not supported")
+	}
+	return nil, nil
+}
+
+// decompress returns a reader of the contents
+// of r, decompressed if r is compressed.
+func decompress(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	b, err := br.Peek(8)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	c, err := compression(b)
+	if err != nil || c == nil {
+		return br, err
+	}
+	return c(br)
+}
+
+// Extract writes the Go binaries in the package at path to the
+// directory dir and returns them, sorted by name.
+func Extract(path, dir string) ([]Binary, error) {
+	format, err := DetectFormat(path)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r := bufio.NewReader(f)
+	x := &extractor{dir: dir}
+	switch format {
+	case FormatDeb:
+		err = x.deb(r)
+	case FormatRPM:
+		err = x.rpm(r)
+	case FormatTar:
+		var dr io.Reader
+		if dr, err = decompress(r); err == nil {
+			err = x.tar(dr)
+		}
+	default:
+		err = errors.New("not a supported package")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	sort.Slice(x.bins, func(i, j int) bool { return x.bins[i].Name < x.bins[j].Name })
+	return x.bins, nil
+}
+
+// An extractor writes the Go binaries of a package to dir.
+type extractor struct {
+	dir  string
+	bins []Binary
+}
+
+// file extracts the file with the given name and contents
+// if it is a Go binary.
+func (x *extractor) file(name string, r io.Reader) error {
+	br := bufio.NewReader(r)
+	b, err := br.Peek(8)
+	if err != nil && err != io.EOF {
+		return err
+	}
+	if !isExecutable(b) {
+		return nil
+	}
+	out := filepath.Join(x.dir, strconv.Itoa(len(x.bins)))
+	f, err := os.Create(out)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(f, br)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := buildinfo.ReadFile(out); err != nil {
+		// Not a Go binary.
+		return os.Remove(out)
+	}
+	name = path.Clean("/" + strings.TrimPrefix(name, "./"))[1:]
+	x.bins = append(x.bins, Binary{Name: name, File: out})
+	return nil
+}
+
+// isExecutable reports whether a file starting with b is an
+// executable or shared library in a format used by Go.
+func isExecutable(b []byte) bool {
+	for _, magic := range []string{
+		"\x7fELF",          // ELF
+		"MZ",               // PE
+		"\xfe\xed\xfa\xce", // Mach-O, 32-bit big-endian
+		"\xfe\xed\xfa\xcf", // Mach-O, 64-bit big-endian
+		"\xce\xfa\xed\xfe", // Mach-O, 32-bit little-endian
+		"\xcf\xfa\xed\xfe", // Mach-O, 64-bit little-endian
+		"\xca\xfe\xba\xbe", // Mach-O, universal
+		"\x01\xdf",         // XCOFF, 32-bit
+		"\x01\xf7",         // XCOFF, 64-bit
+	} {
+		if bytes.HasPrefix(b, []byte(magic)) {
+			return true
+		}
+	}
+	return false
+}
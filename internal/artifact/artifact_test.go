@@ -0,0 +1,221 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package artifact
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/vuln/internal/test"
+)
+
+// file is a file in a test package.
+type file struct {
+	name string
+	data []byte
+}
+
+// testFiles returns the files of a test package: two Go binaries,
+// a file that looks like an executable but is not, and a text file.
+func testFiles(t *testing.T) []file {
+	binary, done := test.GoBuild(t, "testdata/src", "", false)
+	t.Cleanup(done)
+	data, err := os.ReadFile(binary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return []file{
+		{"./usr/bin/hello", data},
+		{"./usr/share/doc/hello/README", []byte("hello")},
+		{"./usr/lib/hello/fake", []byte("\x7fELF not really")},
+		{"./usr/lib/hello/hello-helper", data},
+	}
+}
+
+var wantBinaries = []string{"usr/bin/hello", "usr/lib/hello/hello-helper"}
+
+func TestExtract(t *testing.T) {
+	files := testFiles(t)
+	for _, tc := range []struct {
+		name   string
+		format Format
+		data   []byte
+	}{
+		{"tar", FormatTar, tarData(t, files)},
+		{"tar.gz", FormatTar, gzipData(t, tarData(t, files))},
+		{"deb", FormatDeb, debData(t, "data.tar.gz", gzipData(t, tarData(t, files)))},
+		{"deb-uncompressed", FormatDeb, debData(t, "data.tar", tarData(t, files))},
+		{"rpm", FormatRPM, rpmData(t, gzipData(t, cpioData(files)))},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "pkg")
+			if err := os.WriteFile(path, tc.data, 0666); err != nil {
+				t.Fatal(err)
+			}
+			format, err := DetectFormat(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if format != tc.format {
+				t.Errorf("got format %q, want %q", format, tc.format)
+			}
+			bins, err := Extract(path, t.TempDir())
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, b := range bins {
+				got = append(got, b.Name)
+				if _, err := os.Stat(b.File); err != nil {
+					t.Error(err)
+				}
+			}
+			if diff := cmp.Diff(wantBinaries, got); diff != "" {
+				t.Errorf("(-want,+got):%s", diff)
+			}
+		})
+	}
+}
+
+func TestDetectFormatOther(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		data    []byte
+		wantErr string
+	}{
+		{"text", []byte("hello"), ""},
+		{"gzip", gzipData(t, []byte("hello")), ""},
+		{"xz", []byte("\xfd7zXZ\x00\x00"), "xz compression is not supported"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "file")
+			if err := os.WriteFile(path, tc.data, 0666); err != nil {
+				t.Fatal(err)
+			}
+			format, err := DetectFormat(path)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("got error %v, want %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil || format != "" {
+				t.Errorf("got %q, %v; want no format", format, err)
+			}
+		})
+	}
+}
+
+func TestExtractUnsupportedCompression(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pkg.deb")
+	if err := os.WriteFile(path, debData(t, "data.tar.zst", []byte("\x28\xb5\x2f\xfd")), 0666); err != nil {
+		t.Fatal(err)
+	}
+	_, err := Extract(path, t.TempDir())
+	if want := "zstd compression is not supported"; err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("got error %v, want %q", err, want)
+	}
+}
+
+func tarData(t *testing.T, files []file) []byte {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, f := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: f.name, Mode: 0755, Size: int64(len(f.data)), Format: tar.FormatUSTAR}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(f.data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func gzipData(t *testing.T, data []byte) []byte {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// debData returns a Debian package with the data archive
+// of the given name and contents.
+func debData(t *testing.T, dataName string, data []byte) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("!<arch>\n")
+	for _, m := range []file{
+		{"debian-binary", []byte("2.0\n")},
+		{"control.tar.gz", gzipData(t, tarData(t, nil))},
+		{dataName, data},
+	} {
+		fmt.Fprintf(&buf, "%-16s%-12d%-6d%-6d%-8o%-10d`\n", m.name, 0, 0, 0, 0644, len(m.data))
+		buf.Write(m.data)
+		if len(m.data)%2 == 1 {
+			buf.WriteByte('\n')
+		}
+	}
+	return buf.Bytes()
+}
+
+// rpmData returns an RPM package with the given payload
+// and empty headers.
+func rpmData(t *testing.T, payload []byte) []byte {
+	var buf bytes.Buffer
+	lead := make([]byte, 96)
+	copy(lead, rpmMagic)
+	buf.Write(lead)
+	header := func(size int) {
+		buf.WriteString("\x8e\xad\xe8\x01\x00\x00\x00\x00")
+		binary.Write(&buf, binary.BigEndian, uint32(1))    // entries
+		binary.Write(&buf, binary.BigEndian, uint32(size)) // data size
+		buf.Write(make([]byte, 16+size))
+	}
+	header(5) // signature, padded to 8 bytes
+	buf.Write(make([]byte, 3))
+	header(12)
+	buf.Write(payload)
+	return buf.Bytes()
+}
+
+// cpioData returns a cpio archive of files in the "new ASCII" format.
+func cpioData(files []file) []byte {
+	var buf bytes.Buffer
+	pad := func() {
+		for buf.Len()%4 != 0 {
+			buf.WriteByte(0)
+		}
+	}
+	entry := func(name string, mode int, data []byte) {
+		fmt.Fprintf(&buf, "070701%08x%08x%08x%08x%08x%08x%08x%08x%08x%08x%08x%08x%08x",
+			0, mode, 0, 0, 1, 0, len(data), 0, 0, 0, 0, len(name)+1, 0)
+		buf.WriteString(name + "\x00")
+		pad()
+		buf.Write(data)
+		pad()
+	}
+	entry("./usr", 040755, nil)
+	for _, f := range files {
+		entry(f.name, 0100755, f.data)
+	}
+	entry("TRAILER!!!", 0, nil)
+	return buf.Bytes()
+}
@@ -0,0 +1,177 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package artifact
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// tar extracts the Go binaries of the tar archive r.
+func (x *extractor) tar(r io.Reader) error {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		if err := x.file(hdr.Name, tr); err != nil {
+			return err
+		}
+	}
+}
+
+// deb extracts the Go binaries of the Debian package r, which is an ar
+// archive whose data.tar member, compressed or not, holds the files.
+func (x *extractor) deb(r io.Reader) error {
+	const headerLen = 60
+	if _, err := io.CopyN(io.Discard, r, int64(len("!<arch>\n"))); err != nil {
+		return err
+	}
+	hdr := make([]byte, headerLen)
+	for {
+		if _, err := io.ReadFull(r, hdr); err != nil {
+			if err == io.EOF {
+				return errors.New("Debian package has no data archive")
+			}
+			return err
+		}
+		if string(hdr[58:60]) != "`\n" {
+			return errors.New("malformed Debian package")
+		}
+		size, err := strconv.ParseInt(strings.TrimSpace(string(hdr[48:58])), 10, 64)
+		if err != nil || size < 0 {
+			return errors.New("malformed Debian package")
+		}
+		name := strings.TrimSuffix(strings.TrimSpace(string(hdr[0:16])), "/")
+		if strings.HasPrefix(name, "data.tar") {
+			dr, err := decompress(io.LimitReader(r, size))
+			if err != nil {
+				return fmt.Errorf("%s: %v", name, err)
+			}
+			return x.tar(dr)
+		}
+		// Members are padded to an even offset.
+		if _, err := io.CopyN(io.Discard, r, size+size%2); err != nil {
+			return err
+		}
+	}
+}
+
+// rpm extracts the Go binaries of the RPM package r. The package starts
+// with a lead and two headers, the signature and the package header,
+// followed by the payload, a compressed cpio archive.
+func (x *extractor) rpm(r io.Reader) error {
+	const leadLen = 96
+	if _, err := io.CopyN(io.Discard, r, leadLen); err != nil {
+		return err
+	}
+	// The signature header is padded to a multiple of 8 bytes.
+	n, err := skipRPMHeader(r)
+	if err != nil {
+		return err
+	}
+	if _, err := io.CopyN(io.Discard, r, (8-n%8)%8); err != nil {
+		return err
+	}
+	if _, err := skipRPMHeader(r); err != nil {
+		return err
+	}
+	pr, err := decompress(r)
+	if err != nil {
+		return fmt.Errorf("RPM payload: %v", err)
+	}
+	return x.cpio(pr)
+}
+
+// skipRPMHeader skips over the RPM header at the start
+// of r and returns its length.
+func skipRPMHeader(r io.Reader) (int64, error) {
+	const (
+		headerMagic = "\x8e\xad\xe8"
+		introLen    = 16
+		entryLen    = 16
+	)
+	intro := make([]byte, introLen)
+	if _, err := io.ReadFull(r, intro); err != nil {
+		return 0, err
+	}
+	if !bytes.HasPrefix(intro, []byte(headerMagic)) {
+		return 0, errors.New("malformed RPM header")
+	}
+	entries := int64(binary.BigEndian.Uint32(intro[8:]))
+	size := int64(binary.BigEndian.Uint32(intro[12:]))
+	n := entries*entryLen + size
+	if _, err := io.CopyN(io.Discard, r, n); err != nil {
+		return 0, err
+	}
+	return introLen + n, nil
+}
+
+// cpio extracts the Go binaries of the cpio archive r,
+// which must be in the "new ASCII" format used by RPM.
+func (x *extractor) cpio(r io.Reader) error {
+	const (
+		headerLen = 110
+		trailer   = "TRAILER!!!"
+		modeType  = 0170000
+		modeReg   = 0100000
+	)
+	// Headers and file data are padded to a multiple of 4 bytes.
+	pad := func(n int64) int64 { return (4 - n%4) % 4 }
+	hdr := make([]byte, headerLen)
+	for {
+		if _, err := io.ReadFull(r, hdr); err != nil {
+			return err
+		}
+		magic := string(hdr[:6])
+		if magic != "070701" && magic != "070702" {
+			return errors.New("unsupported cpio archive format")
+		}
+		field := func(i int) (int64, error) {
+			// Fields are 8 hex digits, following the magic.
+			return strconv.ParseInt(string(hdr[6+8*i:6+8*(i+1)]), 16, 64)
+		}
+		mode, err1 := field(1)
+		size, err2 := field(6)
+		nameSize, err3 := field(11)
+		if err := errors.Join(err1, err2, err3); err != nil || nameSize < 1 {
+			return errors.New("malformed cpio header")
+		}
+		nameBuf := make([]byte, nameSize+pad(headerLen+nameSize))
+		if _, err := io.ReadFull(r, nameBuf); err != nil {
+			return err
+		}
+		name := string(nameBuf[:nameSize-1]) // drop NUL terminator
+		if name == trailer {
+			return nil
+		}
+		data := io.LimitReader(r, size)
+		if mode&modeType == modeReg {
+			if err := x.file(name, data); err != nil {
+				return err
+			}
+		}
+		// Skip any data not read, and the padding.
+		if _, err := io.Copy(io.Discard, data); err != nil {
+			return err
+		}
+		if _, err := io.CopyN(io.Discard, r, pad(size)); err != nil {
+			return err
+		}
+	}
+}
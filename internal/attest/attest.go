@@ -0,0 +1,91 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package attest defines the in-toto attestation types supported by
+// govulncheck, and signs them in DSSE envelopes.
+//
+// Scans are attested by in-toto statements with the vulnerability
+// predicate of the in-toto attestation framework, so that the results
+// can be verified by supply-chain policies. See
+// https://github.com/in-toto/attestation for the specifications.
+package attest
+
+import "time"
+
+const (
+	// StatementType is the type of in-toto statements.
+	StatementType = "https://in-toto.io/Statement/v1"
+
+	// VulnsPredicateType is the type of the predicate
+	// of statements about vulnerability scans.
+	VulnsPredicateType = "https://in-toto.io/attestation/vulns/v0.1"
+
+	// PayloadType is the type of the payload of DSSE
+	// envelopes that hold in-toto statements.
+	PayloadType = "application/vnd.in-toto+json"
+)
+
+// A Statement binds the artifacts in Subject to the results of a scan
+// in Predicate.
+type Statement struct {
+	Type          string          `json:"_type"`
+	Subject       []*Subject      `json:"subject"`
+	PredicateType string          `json:"predicateType"`
+	Predicate     *VulnsPredicate `json:"predicate"`
+}
+
+// A Subject is an artifact identified by its digests.
+type Subject struct {
+	Name string `json:"name"`
+
+	// Digest maps algorithms, such as "sha256" or "gitCommit",
+	// to the digest of the artifact in hexadecimal.
+	Digest map[string]string `json:"digest"`
+}
+
+// A VulnsPredicate describes the scanner and the
+// vulnerabilities it found in the subjects.
+type VulnsPredicate struct {
+	Scanner  Scanner  `json:"scanner"`
+	Metadata Metadata `json:"metadata"`
+}
+
+// A Scanner describes the tool that scanned the subjects,
+// its vulnerability database, and its results.
+type Scanner struct {
+	// URI is the Package URL of the scanner.
+	URI     string   `json:"uri"`
+	Version string   `json:"version,omitempty"`
+	DB      DB       `json:"db"`
+	Result  []Result `json:"result"`
+}
+
+// A DB describes the vulnerability database of a scan.
+type DB struct {
+	URI        string     `json:"uri,omitempty"`
+	LastUpdate *time.Time `json:"lastUpdate,omitempty"`
+}
+
+// A Result is a vulnerability found in the subjects.
+type Result struct {
+	ID       string     `json:"id"`
+	Severity []Severity `json:"severity,omitempty"`
+
+	// Annotations hold the details of the vulnerability in each
+	// affected module, such as its version and how precisely it
+	// was found.
+	Annotations []map[string]string `json:"annotations,omitempty"`
+}
+
+// A Severity is the score of a vulnerability by a rating method.
+type Severity struct {
+	Method string `json:"method"`
+	Score  string `json:"score"`
+}
+
+// Metadata records when a scan ran.
+type Metadata struct {
+	ScanStartedOn  time.Time `json:"scanStartedOn"`
+	ScanFinishedOn time.Time `json:"scanFinishedOn"`
+}
@@ -0,0 +1,149 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package attest
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+)
+
+// An Envelope is a DSSE envelope, which holds a signed payload.
+// See https://github.com/secure-systems-lab/dsse.
+type Envelope struct {
+	PayloadType string       `json:"payloadType"`
+	Payload     []byte       `json:"payload"` // encoded in base64
+	Signatures  []*Signature `json:"signatures"`
+}
+
+// A Signature is the signature of the payload of an envelope by the
+// key with KeyID, if known.
+type Signature struct {
+	KeyID string `json:"keyid,omitempty"`
+	Sig   []byte `json:"sig"` // encoded in base64
+}
+
+// Sign returns an envelope of s signed with key, which must be an
+// ECDSA, Ed25519 or RSA private key. The ID of the key is the SHA-256
+// digest of its public key in PKIX form.
+func Sign(s *Statement, key crypto.Signer) (*Envelope, error) {
+	payload, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	msg := pae(PayloadType, payload)
+	var sig []byte
+	switch key.(type) {
+	case ed25519.PrivateKey:
+		sig, err = key.Sign(rand.Reader, msg, crypto.Hash(0))
+	case *ecdsa.PrivateKey, *rsa.PrivateKey:
+		digest := sha256.Sum256(msg)
+		sig, err = key.Sign(rand.Reader, digest[:], crypto.SHA256)
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", key)
+	}
+	if err != nil {
+		return nil, err
+	}
+	id, err := keyID(key.Public())
+	if err != nil {
+		return nil, err
+	}
+	return &Envelope{
+		PayloadType: PayloadType,
+		Payload:     payload,
+		Signatures:  []*Signature{{KeyID: id, Sig: sig}},
+	}, nil
+}
+
+// Verify reports an error unless e holds an in-toto statement with a
+// valid signature by the private key of pub, and returns the statement
+// otherwise.
+func Verify(e *Envelope, pub crypto.PublicKey) (*Statement, error) {
+	if e.PayloadType != PayloadType {
+		return nil, fmt.Errorf("unexpected payload type %q", e.PayloadType)
+	}
+	msg := pae(e.PayloadType, e.Payload)
+	digest := sha256.Sum256(msg)
+	verified := false
+	for _, s := range e.Signatures {
+		switch pub := pub.(type) {
+		case ed25519.PublicKey:
+			verified = ed25519.Verify(pub, msg, s.Sig)
+		case *ecdsa.PublicKey:
+			verified = ecdsa.VerifyASN1(pub, digest[:], s.Sig)
+		case *rsa.PublicKey:
+			verified = rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], s.Sig) == nil
+		default:
+			return nil, fmt.Errorf("unsupported key type %T", pub)
+		}
+		if verified {
+			break
+		}
+	}
+	if !verified {
+		return nil, errors.New("no valid signature")
+	}
+	var s Statement
+	if err := json.Unmarshal(e.Payload, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// ParsePrivateKey parses a PEM-encoded private key in PKCS #8, SEC 1
+// (EC) or PKCS #1 (RSA) form, as written by openssl. Encrypted keys
+// are not supported.
+func ParsePrivateKey(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM-encoded key found")
+	}
+	var key any
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported key type %T", key)
+	}
+	return signer, nil
+}
+
+// keyID returns the SHA-256 digest of pub in PKIX form, in hexadecimal.
+func keyID(pub crypto.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(der)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// pae returns the DSSE pre-authentication encoding of
+// payload of type typ, which is what is signed.
+func pae(typ string, payload []byte) []byte {
+	return fmt.Appendf(nil, "DSSEv1 %d %s %d %s", len(typ), typ, len(payload), payload)
+}
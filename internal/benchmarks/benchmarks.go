@@ -0,0 +1,205 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package benchmarks measures how long govulncheck takes to scan a set of
+// fixture modules, phase by phase, and compares the measures with a
+// baseline, so that changes to the scan pipeline can be checked for
+// performance regressions.
+//
+// The benchmarks and the regression test of the package run the fixtures:
+//
+//	$ go test ./internal/benchmarks -run '^$' -bench .
+//	$ go test ./internal/benchmarks -run TestRegression -baseline old.json -update
+//	$ go test ./internal/benchmarks -run TestRegression -baseline old.json
+//
+// The first run of TestRegression records the baseline, typically before
+// a change, and the second one fails when a phase of a scan takes longer
+// than in the baseline beyond the threshold. Baselines are specific to a
+// machine, so they are not checked in.
+package benchmarks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/vuln/internal/scan"
+	"golang.org/x/vuln/internal/timing"
+	"golang.org/x/vuln/internal/web"
+)
+
+// A Fixture is a scan of the packages of a module.
+type Fixture struct {
+	// Name identifies the fixture in results.
+	Name string
+	// Dir is the directory of the module.
+	Dir string
+	// Patterns are the package patterns to scan.
+	Patterns []string
+}
+
+// Fixtures returns the fixtures of the x/vuln repository at root,
+// which are scanned with the vulnerability databases that Database
+// returns. Their dependencies are pinned: one is vendored, and the
+// other is the govulncheck command itself, with the dependencies
+// of the go.sum file of the repository.
+func Fixtures(root string) []Fixture {
+	return []Fixture{
+		{
+			Name:     "vendored",
+			Dir:      filepath.Join(root, "cmd", "govulncheck", "testdata", "common", "modules", "vendored"),
+			Patterns: []string{"./..."},
+		},
+		{
+			Name:     "govulncheck",
+			Dir:      root,
+			Patterns: []string{"./cmd/govulncheck"},
+		},
+	}
+}
+
+// Database returns the URL of the vulnerability databases of the
+// x/vuln repository at root, as a list for the -db flag: those of
+// the tests of the govulncheck command for the standard library
+// and for modules. Both have an entry GO-2022-0969, which is read
+// from the first one, where it affects the standard library.
+func Database(root string) (string, error) {
+	var urls []string
+	for _, name := range []string{"stdlib", "common"} {
+		dir, err := filepath.Abs(filepath.Join(root, "cmd", "govulncheck", "testdata", name, "vulndb-v1"))
+		if err != nil {
+			return "", err
+		}
+		u, err := web.URLFromFilePath(dir)
+		if err != nil {
+			return "", err
+		}
+		urls = append(urls, u.String())
+	}
+	return strings.Join(urls, ","), nil
+}
+
+// Scan scans f with govulncheck, using the vulnerability database
+// at the URL db, and returns the duration of each phase of the scan.
+func Scan(ctx context.Context, f Fixture, db string) (map[string]time.Duration, error) {
+	rec := &timing.Recorder{}
+	ctx = timing.NewContext(ctx, rec)
+	// The cache of vulnerabilities would make the scans after
+	// the first one faster. The standard library is pinned to a
+	// version with vulnerabilities in the database, so that the
+	// call graph of every fixture is analyzed.
+	env := append(os.Environ(), "GOVULNCHECK_CACHE=off", "GOVERSION=go1.18")
+	args := append([]string{"-db", db, "-format", "json", "-C", f.Dir}, f.Patterns...)
+	if err := scan.RunGovulncheck(ctx, env, nil, io.Discard, io.Discard, args); err != nil {
+		return nil, fmt.Errorf("%s: %w", f.Name, err)
+	}
+	phases := make(map[string]time.Duration)
+	for _, p := range rec.Phases() {
+		phases[p.Name] = p.Duration
+	}
+	return phases, nil
+}
+
+// Measure scans f runs times and returns the median
+// duration of each phase of the scans.
+func Measure(ctx context.Context, f Fixture, db string, runs int) (map[string]time.Duration, error) {
+	all := make(map[string][]time.Duration)
+	for range runs {
+		phases, err := Scan(ctx, f, db)
+		if err != nil {
+			return nil, err
+		}
+		for name, d := range phases {
+			all[name] = append(all[name], d)
+		}
+	}
+	medians := make(map[string]time.Duration)
+	for name, ds := range all {
+		slices.Sort(ds)
+		medians[name] = ds[len(ds)/2]
+	}
+	return medians, nil
+}
+
+// Results holds the durations of the phases of the
+// scans of fixtures, by fixture name and phase name.
+type Results map[string]map[string]time.Duration
+
+// ReadResults reads results from the JSON file at path.
+func ReadResults(path string) (Results, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var r Results
+	if err := json.Unmarshal(b, &r); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return r, nil
+}
+
+// WriteResults writes r to the file at path as JSON.
+func WriteResults(path string, r Results) error {
+	b, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(b, '\n'), 0666)
+}
+
+// A Regression is a phase of the scan of a fixture
+// that took longer than in the baseline.
+type Regression struct {
+	Fixture, Phase string
+	Baseline, Got  time.Duration
+}
+
+func (r Regression) String() string {
+	return fmt.Sprintf("%s: %s took %v, %.0f%% more than the baseline %v",
+		r.Fixture, r.Phase, r.Got.Round(time.Millisecond),
+		100*(float64(r.Got)/float64(r.Baseline)-1), r.Baseline.Round(time.Millisecond))
+}
+
+// errNoBaseline is returned by Compare when a
+// fixture of the results has no baseline.
+var errNoBaseline = errors.New("no baseline")
+
+// Compare returns the regressions of got from baseline: the phases
+// that took more than threshold times longer than in the baseline,
+// such as 0.2 for 20% longer, and at least minDelta longer, so that short
+// phases do not fail on noise. Phases that are not in both results are
+// not compared. The regressions are ordered by fixture and phase.
+func Compare(baseline, got Results, threshold float64, minDelta time.Duration) ([]Regression, error) {
+	var regs []Regression
+	for fixture, phases := range got {
+		base, ok := baseline[fixture]
+		if !ok {
+			return nil, fmt.Errorf("%s: %w", fixture, errNoBaseline)
+		}
+		for phase, d := range phases {
+			b, ok := base[phase]
+			if !ok {
+				continue
+			}
+			if d-b >= minDelta && float64(d) > float64(b)*(1+threshold) {
+				regs = append(regs, Regression{Fixture: fixture, Phase: phase, Baseline: b, Got: d})
+			}
+		}
+	}
+	sort.Slice(regs, func(i, j int) bool {
+		if regs[i].Fixture != regs[j].Fixture {
+			return regs[i].Fixture < regs[j].Fixture
+		}
+		return regs[i].Phase < regs[j].Phase
+	})
+	return regs, nil
+}
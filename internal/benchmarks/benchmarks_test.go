@@ -0,0 +1,139 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package benchmarks
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/vuln/internal/test"
+	"golang.org/x/vuln/internal/testenv"
+)
+
+var (
+	baseline  = flag.String("baseline", "", "compare the scans of the fixtures with the baseline in this `file`")
+	update    = flag.Bool("update", false, "write the baseline instead of comparing with it")
+	runs      = flag.Int("runs", 5, "scan each fixture this many times and keep the median durations")
+	threshold = flag.Float64("threshold", 0.2, "fail when a phase takes this much longer than in the baseline, as a fraction")
+	minDelta  = flag.Duration("min", 50*time.Millisecond, "ignore phases that take less than this much longer than in the baseline")
+)
+
+// root is the root of the x/vuln repository.
+var root = filepath.Join("..", "..")
+
+func BenchmarkScan(b *testing.B) {
+	test.NeedsGoEnv(b)
+	testenv.NeedsGoBuild(b)
+	db, err := Database(root)
+	if err != nil {
+		b.Fatal(err)
+	}
+	for _, f := range Fixtures(root) {
+		b.Run(f.Name, func(b *testing.B) {
+			totals := make(map[string]time.Duration)
+			for range b.N {
+				phases, err := Scan(context.Background(), f, db)
+				if err != nil {
+					b.Fatal(err)
+				}
+				for name, d := range phases {
+					totals[name] += d
+				}
+			}
+			for name, d := range totals {
+				unit := strings.ReplaceAll(name, " ", "-") + "-ms/op"
+				b.ReportMetric(float64(d.Milliseconds())/float64(b.N), unit)
+			}
+		})
+	}
+}
+
+// TestRegression compares the scans of the fixtures with the baseline
+// given by the -baseline flag, or writes it with the -update flag.
+func TestRegression(t *testing.T) {
+	if *baseline == "" {
+		t.Skip("no -baseline file")
+	}
+	test.NeedsGoEnv(t)
+	testenv.NeedsGoBuild(t)
+	db, err := Database(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(Results)
+	for _, f := range Fixtures(root) {
+		phases, err := Measure(context.Background(), f, db, *runs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got[f.Name] = phases
+		t.Logf("%s: %v", f.Name, phases)
+	}
+	if *update {
+		if err := WriteResults(*baseline, got); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	base, err := ReadResults(*baseline)
+	if err != nil {
+		t.Fatal(err)
+	}
+	regs, err := Compare(base, got, *threshold, *minDelta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range regs {
+		t.Error(r)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	base := Results{
+		"vendored": {"load": time.Second, "matching": 10 * time.Millisecond},
+	}
+	got := Results{
+		"vendored": {
+			"load":     1300 * time.Millisecond, // 30% longer
+			"matching": 20 * time.Millisecond,   // 100% longer, but by less than the minimum
+			"output":   time.Second,             // not in the baseline
+		},
+	}
+	regs, err := Compare(base, got, 0.2, 50*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "vendored: load took 1.3s, 30% more than the baseline 1s"
+	if len(regs) != 1 || regs[0].String() != want {
+		t.Errorf("Compare() = %v, want [%s]", regs, want)
+	}
+
+	if regs, _ := Compare(base, got, 0.5, 50*time.Millisecond); len(regs) != 0 {
+		t.Errorf("Compare() with a threshold of 50%% = %v, want none", regs)
+	}
+	if _, err := Compare(Results{}, got, 0.2, 0); !errors.Is(err, errNoBaseline) {
+		t.Errorf("Compare() without a baseline: got error %v, want %v", err, errNoBaseline)
+	}
+}
+
+func TestResults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	want := Results{"govulncheck": {"load": 1500 * time.Millisecond, "call graph": 2 * time.Second}}
+	if err := WriteResults(path, want); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadResults(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got["govulncheck"]["call graph"] != 2*time.Second || got["govulncheck"]["load"] != 1500*time.Millisecond {
+		t.Errorf("ReadResults() = %v, want %v", got, want)
+	}
+}
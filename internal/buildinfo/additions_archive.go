@@ -0,0 +1,162 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build go1.18
+// +build go1.18
+
+package buildinfo
+
+// This file adds support for archives produced with -buildmode=c-archive,
+// which contain the Go code of the program in a relocatable object file
+// named go.o.
+
+import (
+	"bytes"
+	"debug/buildinfo"
+	"debug/elf"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"runtime/debug"
+	"strconv"
+	"strings"
+)
+
+const (
+	archiveMagic     = "!<arch>\n"
+	archiveGoObject  = "go.o"
+	archiveHeaderLen = 60
+)
+
+// isArchive reports whether r is an ar archive.
+func isArchive(r io.ReaderAt) bool {
+	data := make([]byte, len(archiveMagic))
+	_, err := r.ReadAt(data, 0)
+	return err == nil && string(data) == archiveMagic
+}
+
+// archiveMember returns the contents of the member of the ar archive
+// r with the given name.
+func archiveMember(r io.ReaderAt, name string) (*io.SectionReader, error) {
+	off := int64(len(archiveMagic))
+	hdr := make([]byte, archiveHeaderLen)
+	for {
+		if _, err := r.ReadAt(hdr, off); err != nil {
+			if err == io.EOF {
+				return nil, fmt.Errorf("archive has no member %s", name)
+			}
+			return nil, err
+		}
+		if string(hdr[58:60]) != "`\n" {
+			return nil, errors.New("malformed archive header")
+		}
+		size, err := strconv.ParseInt(strings.TrimSpace(string(hdr[48:58])), 10, 64)
+		if err != nil || size < 0 {
+			return nil, errors.New("malformed archive member size")
+		}
+		off += archiveHeaderLen
+		start, n := off, size
+		mname := strings.TrimSpace(string(hdr[0:16]))
+		if l, ok := strings.CutPrefix(mname, "#1/"); ok {
+			// BSD long name, stored at the start of the data.
+			nl, err := strconv.ParseInt(l, 10, 64)
+			if err != nil || nl < 0 || nl > size {
+				return nil, errors.New("malformed archive member name")
+			}
+			b := make([]byte, nl)
+			if _, err := r.ReadAt(b, off); err != nil {
+				return nil, err
+			}
+			mname = string(bytes.TrimRight(b, "\x00"))
+			start, n = off+nl, size-nl
+		}
+		if strings.TrimSuffix(mname, "/") == name {
+			return io.NewSectionReader(r, start, n), nil
+		}
+		off += size + size%2 // data is padded to an even offset
+	}
+}
+
+// openArchiveObject returns the Go object file of the c-archive r.
+// Only ELF objects are supported.
+func openArchiveObject(r io.ReaderAt) (*elfExe, error) {
+	m, err := archiveMember(r, archiveGoObject)
+	if err != nil {
+		return nil, err
+	}
+	f, err := elf.NewFile(m)
+	if err != nil {
+		return nil, fmt.Errorf("c-archive: %s is not a supported object file: %v", archiveGoObject, err)
+	}
+	if f.Type != elf.ET_REL {
+		return nil, fmt.Errorf("c-archive: %s is not a relocatable object", archiveGoObject)
+	}
+	return &elfExe{f: f}, nil
+}
+
+// readBuildInfo returns the build information of the Go binary,
+// shared object, or c-archive r.
+func readBuildInfo(r io.ReaderAt) (*debug.BuildInfo, error) {
+	if !isArchive(r) {
+		return buildinfo.Read(r)
+	}
+	x, err := openArchiveObject(r)
+	if err != nil {
+		return nil, err
+	}
+	s := x.f.Section(".go.buildinfo")
+	if s == nil {
+		return nil, errors.New("c-archive: no build information")
+	}
+	data, err := s.Data()
+	if err != nil {
+		return nil, err
+	}
+	return parseBuildInfo(data)
+}
+
+// parseBuildInfo parses the contents of a build info section. Since
+// the section of a relocatable object has not been relocated, only the
+// format of Go 1.18 and later, which stores strings inline, is supported.
+//
+// Derived from debug/buildinfo.readRawBuildInfo.
+func parseBuildInfo(data []byte) (*debug.BuildInfo, error) {
+	const (
+		buildInfoMagic  = "\xff Go buildinf:"
+		buildInfoHeader = 32
+		flagsVersionInl = 0x2
+	)
+	if len(data) < buildInfoHeader || !bytes.HasPrefix(data, []byte(buildInfoMagic)) {
+		return nil, errors.New("not a Go build info section")
+	}
+	if data[15]&flagsVersionInl == 0 {
+		return nil, errors.New("build info of Go versions before 1.18 is not supported in c-archives")
+	}
+	vers, rest := decodeString(data[buildInfoHeader:])
+	mod, _ := decodeString(rest)
+	if vers == "" {
+		return nil, errors.New("not a Go build info section")
+	}
+	// Strip the sentinels around the module information.
+	if len(mod) >= 33 && mod[len(mod)-17] == '\n' {
+		mod = mod[16 : len(mod)-16]
+	} else {
+		mod = ""
+	}
+	bi, err := debug.ParseBuildInfo(mod)
+	if err != nil {
+		return nil, err
+	}
+	bi.GoVersion = vers
+	return bi, nil
+}
+
+func decodeString(data []byte) (s string, rest []byte) {
+	u, n := binary.Uvarint(data)
+	if n <= 0 || u > uint64(len(data)-n) {
+		return "", nil
+	}
+	return string(data[n : uint64(n)+u]), data[uint64(n)+u:]
+}
@@ -0,0 +1,54 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build go1.18
+// +build go1.18
+
+package buildinfo
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+)
+
+func TestArchiveMember(t *testing.T) {
+	var b bytes.Buffer
+	b.WriteString(archiveMagic)
+	add := func(name, data string) {
+		fmt.Fprintf(&b, "%-16s%-12d%-6d%-6d%-8o%-10d`\n", name, 0, 0, 0, 0o644, len(data))
+		b.WriteString(data)
+		if len(data)%2 == 1 {
+			b.WriteByte('\n')
+		}
+	}
+	add("__.SYMDEF/", "odd")
+	add("#1/8", "long.o\x00\x00long data")
+	add("go.o/", "go object")
+	r := bytes.NewReader(b.Bytes())
+
+	if !isArchive(r) {
+		t.Fatal("isArchive() = false, want true")
+	}
+	for name, want := range map[string]string{
+		"go.o":   "go object",
+		"long.o": "long data",
+	} {
+		m, err := archiveMember(r, name)
+		if err != nil {
+			t.Fatalf("archiveMember(%q): %v", name, err)
+		}
+		got, err := io.ReadAll(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != want {
+			t.Errorf("archiveMember(%q) = %q, want %q", name, got, want)
+		}
+	}
+	if _, err := archiveMember(r, "missing.o"); err == nil {
+		t.Error("archiveMember(missing.o) succeeded unexpectedly")
+	}
+}
@@ -32,6 +32,15 @@ func (x *elfExe) SymbolInfo(name string) (uint64, uint64, io.ReaderAt, error) {
 		}
 		return 0, 0, nil, fmt.Errorf("no symbol %q", name)
 	}
+	if x.f.Type == elf.ET_REL {
+		// In relocatable objects, such as the go.o of a c-archive,
+		// symbol values are offsets in their section.
+		sect := x.section(sym)
+		if sect == nil {
+			return 0, 0, nil, fmt.Errorf("no section for %q", name)
+		}
+		return sym.Value, 0, sect, nil
+	}
 	prog := x.progContaining(sym.Value)
 	if prog == nil {
 		return 0, 0, nil, fmt.Errorf("no Prog containing value %d for %q", sym.Value, name)
@@ -58,6 +67,15 @@ func (x *elfExe) lookupSymbol(name string) (*elf.Symbol, error) {
 	return x.symbols[name], nil
 }
 
+// section returns the section of sym, or nil if it has none.
+func (x *elfExe) section(sym *elf.Symbol) *elf.Section {
+	i := int(sym.Section)
+	if i <= 0 || i >= len(x.f.Sections) {
+		return nil
+	}
+	return x.f.Sections[i]
+}
+
 func (x *elfExe) progContaining(addr uint64) *elf.Prog {
 	for _, p := range x.f.Progs {
 		if addr >= p.Vaddr && addr < p.Vaddr+p.Filesz {
@@ -69,6 +87,8 @@ func (x *elfExe) progContaining(addr uint64) *elf.Prog {
 
 const go12magic = 0xfffffffb
 const go116magic = 0xfffffffa
+const go118magic = 0xfffffff0
+const go120magic = 0xfffffff1
 
 // PCLNTab is derived from cmd/internal/objfile/elf.go:pcln.
 func (x *elfExe) PCLNTab() ([]byte, uint64) {
@@ -78,6 +98,14 @@ func (x *elfExe) PCLNTab() ([]byte, uint64) {
 		offset = text.Offset
 	}
 	pclntab := x.f.Section(".gopclntab")
+	if pclntab == nil {
+		// Addition: shared objects, that is plugins and c-shared
+		// libraries, have no .gopclntab section, but the table
+		// can be found using the runtime symbols delimiting it.
+		if b := x.symbolData("runtime.pclntab", "runtime.epclntab"); b != nil {
+			return b, offset
+		}
+	}
 	if pclntab == nil {
 		// Addition: this code is added to support some form of stripping.
 		pclntab = x.f.Section(".data.rel.ro.gopclntab")
@@ -112,6 +140,14 @@ func (x *elfExe) PCLNTab() ([]byte, uint64) {
 					case leMagic == go116magic:
 						fallthrough
 					case beMagic == go116magic:
+						fallthrough
+					case leMagic == go118magic:
+						fallthrough
+					case beMagic == go118magic:
+						fallthrough
+					case leMagic == go120magic:
+						fallthrough
+					case beMagic == go120magic:
 						return b[i:], offset
 					}
 				}
@@ -125,6 +161,39 @@ func (x *elfExe) PCLNTab() ([]byte, uint64) {
 	return b, offset
 }
 
+// symbolData returns the contents of the program between the values
+// of the symbols start and end, or nil if they cannot be found.
+func (x *elfExe) symbolData(start, end string) []byte {
+	s, err := x.lookupSymbol(start)
+	if err != nil || s == nil {
+		return nil
+	}
+	e, err := x.lookupSymbol(end)
+	if err != nil || e == nil || e.Value < s.Value {
+		return nil
+	}
+	var r io.ReaderAt
+	var off uint64
+	if x.f.Type == elf.ET_REL {
+		sect := x.section(s)
+		if sect == nil || e.Section != s.Section || e.Value > sect.Size {
+			return nil
+		}
+		r, off = sect, s.Value
+	} else {
+		prog := x.progContaining(s.Value)
+		if prog == nil || e.Value > prog.Vaddr+prog.Filesz {
+			return nil
+		}
+		r, off = prog, s.Value-prog.Vaddr
+	}
+	b := make([]byte, e.Value-s.Value)
+	if _, err := r.ReadAt(b, int64(off)); err != nil {
+		return nil
+	}
+	return b
+}
+
 // SymbolInfo is derived from cmd/internal/objfile/pe.go:findPESymbol, loadPETable.
 func (x *peExe) SymbolInfo(name string) (uint64, uint64, io.ReaderAt, error) {
 	sym, err := x.lookupSymbol(name)
@@ -11,9 +11,9 @@ package buildinfo
 // and cmd/go/internal/version/exe.go.
 
 import (
-	"debug/buildinfo"
 	"errors"
 	"fmt"
+	"io"
 	"net/url"
 	"os"
 	"runtime/debug"
@@ -49,6 +49,10 @@ type Symbol struct {
 // ExtractPackagesAndSymbols extracts symbols, packages, modules from
 // Go binary file as well as bin's metadata.
 //
+// Besides executables, file can be a shared object, a c-archive, a
+// WebAssembly module, or an Android archive (AAR) containing shared
+// objects.
+//
 // If the symbol table is not available, such as in the case of stripped
 // binaries, returns module and binary info but without the symbol info.
 func ExtractPackagesAndSymbols(file string) ([]*packages.Module, []Symbol, *debug.BuildInfo, error) {
@@ -58,8 +62,31 @@ func ExtractPackagesAndSymbols(file string) ([]*packages.Module, []Symbol, *debu
 	}
 	defer bin.Close()
 
-	bi, err := buildinfo.Read(bin)
+	switch {
+	case isWasm(bin):
+		return extractWasm(bin)
+	case isZip(bin):
+		fi, err := bin.Stat()
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		r, err := androidArchiveLibrary(bin, fi.Size())
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		return extractPackagesAndSymbols(r, "")
+	}
+	return extractPackagesAndSymbols(bin, file)
+}
+
+// extractPackagesAndSymbols is ExtractPackagesAndSymbols for the
+// binary bin read from file, which is empty if bin is not a file.
+func extractPackagesAndSymbols(bin io.ReaderAt, file string) ([]*packages.Module, []Symbol, *debug.BuildInfo, error) {
+	bi, err := readBuildInfo(bin)
 	if err != nil {
+		if isArchive(bin) || file == "" {
+			return nil, nil, nil, err
+		}
 		// It could be that bin is an ancient Go binary.
 		v, err := goversion.ReadExe(file)
 		if err != nil {
@@ -8,10 +8,15 @@
 package buildinfo
 
 import (
+	"archive/zip"
 	"fmt"
+	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
+	"runtime/debug"
 	"sort"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -166,3 +171,177 @@ func Vuln() {
 		})
 	}
 }
+
+func TestExtractBuildModes(t *testing.T) {
+	testenv.NeedsGoBuild(t)
+	if runtime.GOOS != "linux" {
+		t.Skip("shared objects and c-archives are only tested on linux")
+	}
+	cc, err := exec.Command("go", "env", "CC").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := exec.LookPath(strings.TrimSpace(string(cc))); err != nil {
+		t.Skipf("no C compiler: %v", err)
+	}
+
+	e := packagestest.Export(t, packagestest.Modules, []packagestest.Module{
+		{
+			Name: "golang.org/entry",
+			Files: map[string]interface{}{
+				"main.go": `
+			package main
+
+			import "C"
+
+			import "golang.org/bmod/bvuln"
+
+			//export Entry
+			func Entry() {
+				bvuln.Vuln()
+			}
+
+			func main() {}
+			`,
+			}},
+		{
+			Name: "golang.org/bmod@v0.5.0",
+			Files: map[string]interface{}{"bvuln/bvuln.go": `
+			package bvuln
+
+			var debug = true
+
+			func Vuln() {
+				if debug {
+					return
+				}
+				print("vuln")
+			}`},
+		},
+	})
+	defer e.Cleanup()
+
+	for _, mode := range []string{"plugin", "c-shared", "c-archive"} {
+		t.Run(mode, func(t *testing.T) {
+			out := filepath.Join(t.TempDir(), "entry."+mode)
+			cmd := exec.Command("go", "build", "-buildmode="+mode, "-o", out)
+			cmd.Dir = e.Config.Dir
+			cmd.Env = append(e.Config.Env, "CGO_ENABLED=1")
+			if b, err := cmd.CombinedOutput(); err != nil {
+				t.Fatalf("failed to build the %s: %v %s", mode, err, b)
+			}
+
+			mods, syms, bi, err := ExtractPackagesAndSymbols(out)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if bi.Path != "golang.org/entry" {
+				t.Errorf("got path %q, want golang.org/entry", bi.Path)
+			}
+			if len(mods) != 1 || mods[0].Path != "golang.org/bmod" {
+				t.Errorf("got modules %v, want golang.org/bmod", mods)
+			}
+			got := sortedSymbols("golang.org/bmod/bvuln", syms)
+			want := []Symbol{{"golang.org/bmod/bvuln", "Vuln"}}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("(-want,+got):%s", diff)
+			}
+		})
+	}
+}
+
+func TestExtractWasm(t *testing.T) {
+	for _, goos := range []string{"js", "wasip1"} {
+		t.Run(goos, func(t *testing.T) {
+			binary, done := test.GoBuild(t, "testdata/src", "", false, "GOOS", goos, "GOARCH", "wasm")
+			defer done()
+
+			_, syms, bi, err := ExtractPackagesAndSymbols(binary)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if bi.GoVersion != runtime.Version() {
+				t.Errorf("got Go version %q, want %q", bi.GoVersion, runtime.Version())
+			}
+			if got := buildSetting(bi, "GOOS"); got != goos {
+				t.Errorf("got GOOS %q, want %q", got, goos)
+			}
+
+			got := sortedSymbols("main", syms)
+			want := []Symbol{
+				{"main", "f"},
+				{"main", "g"},
+				{"main", "main"},
+			}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("(-want,+got):%s", diff)
+			}
+		})
+	}
+}
+
+func TestExtractAndroidArchive(t *testing.T) {
+	binary, done := test.GoBuild(t, "testdata/src", "", false, "GOOS", "android", "GOARCH", "arm64", "CGO_ENABLED", "0")
+	defer done()
+	lib, err := os.ReadFile(binary)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The library of the preferred ABI is used,
+	// regardless of the order in the archive.
+	aar := filepath.Join(t.TempDir(), "lib.aar")
+	f, err := os.Create(aar)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	for _, m := range []struct {
+		name string
+		data []byte
+	}{
+		{"AndroidManifest.xml", []byte("<manifest/>")},
+		{"jni/x86/libgojni.so", []byte("not a Go library")},
+		{"jni/arm64-v8a/libgojni.so", lib},
+	} {
+		w, err := zw.Create(m.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write(m.data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	_, syms, bi, err := ExtractPackagesAndSymbols(aar)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := buildSetting(bi, "GOOS"); got != "android" {
+		t.Errorf("got GOOS %q, want android", got)
+	}
+	got := sortedSymbols("main", syms)
+	want := []Symbol{
+		{"main", "f"},
+		{"main", "g"},
+		{"main", "main"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("(-want,+got):%s", diff)
+	}
+}
+
+func buildSetting(bi *debug.BuildInfo, key string) string {
+	for _, s := range bi.Settings {
+		if s.Key == key {
+			return s.Value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,442 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build go1.18
+// +build go1.18
+
+package buildinfo
+
+// This file adds support for WebAssembly modules built with GOARCH=wasm
+// and for Android archives built by gomobile.
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"runtime/debug"
+	"sort"
+
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/vuln/internal/gosym"
+)
+
+const wasmMagic = "\x00asm\x01\x00\x00\x00"
+
+// isWasm reports whether r is a WebAssembly module.
+func isWasm(r io.ReaderAt) bool {
+	data := make([]byte, len(wasmMagic))
+	_, err := r.ReadAt(data, 0)
+	return err == nil && string(data) == wasmMagic
+}
+
+// wasmModule is the content of a WebAssembly module
+// needed to extract Go build information and symbols.
+type wasmModule struct {
+	// goVersion is the Go version from the producers section.
+	goVersion string
+	// memory is the initial linear memory, as given
+	// by the active data segments.
+	memory []byte
+}
+
+const (
+	wasmSectionCustom = 0
+	wasmSectionData   = 11
+
+	// wasmMaxMemory limits the linear memory
+	// reconstructed from data segments.
+	wasmMaxMemory = 1 << 30
+)
+
+// parseWasm parses the WebAssembly module data.
+func parseWasm(data []byte) (*wasmModule, error) {
+	m := &wasmModule{}
+	r := &wasmReader{data: data[len(wasmMagic):]}
+	for len(r.data) > 0 && r.err == nil {
+		id := r.byte()
+		sect := &wasmReader{data: r.bytes()}
+		switch id {
+		case wasmSectionCustom:
+			if sect.name() == "producers" {
+				m.goVersion = sect.producersGoVersion()
+			}
+		case wasmSectionData:
+			m.memory = sect.dataSegments()
+		}
+		if sect.err != nil {
+			return nil, sect.err
+		}
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	if m.goVersion == "" {
+		return nil, errors.New("not a Go WebAssembly module")
+	}
+	return m, nil
+}
+
+// modinfo sentinels, from cmd/go/internal/modload/build.go.
+var (
+	modinfoStart = []byte("\x30\x77\xaf\x0c\x92\x74\x08\x02\x41\xe1\xc1\x07\xe6\xd6\x18\xe6")
+	modinfoEnd   = []byte("\xf9\x32\x43\x31\x86\x18\x20\x72\x00\x82\x42\x10\x41\x16\xd8\xf2")
+)
+
+// buildInfo returns the build information of m. WebAssembly modules
+// have no build info header, so the module information is found
+// using the sentinels around it.
+func (m *wasmModule) buildInfo() (*debug.BuildInfo, error) {
+	var mod string
+	if i := bytes.Index(m.memory, modinfoStart); i >= 0 {
+		rest := m.memory[i+len(modinfoStart):]
+		if j := bytes.Index(rest, modinfoEnd); j >= 0 {
+			mod = string(rest[:j])
+		}
+	}
+	bi, err := debug.ParseBuildInfo(mod)
+	if err != nil {
+		return nil, err
+	}
+	bi.GoVersion = m.goVersion
+	return bi, nil
+}
+
+// lineTable returns the PCLN table of m and its address, or nil if
+// it cannot be found. The function names in the WebAssembly name
+// section are mangled, so the names in the table are used instead.
+func (m *wasmModule) lineTable() (*gosym.LineTable, *gosym.Table, uint64) {
+	const (
+		go118magic = 0xfffffff0
+		go120magic = 0xfffffff1
+		quantum    = 1
+		ptrSize    = 8
+	)
+	for i := 0; i+8 <= len(m.memory); i += ptrSize {
+		b := m.memory[i:]
+		if magic := binary.LittleEndian.Uint32(b); magic != go118magic && magic != go120magic {
+			continue
+		}
+		if b[4] != 0 || b[5] != 0 || b[6] != quantum || b[7] != ptrSize {
+			continue
+		}
+		lt := gosym.NewLineTable(b, 0)
+		tab, err := gosym.NewTable(nil, lt)
+		if err == nil && len(tab.Funcs) > 0 {
+			return lt, tab, uint64(i)
+		}
+	}
+	return nil, nil, 0
+}
+
+// goFunc returns the address of the go:func.* symbol, which inline
+// trees are relative to, or false if it cannot be found.
+//
+// The address is recorded in the runtime's module data, which starts
+// with a pointer to the PCLN table at address pclntab. The layout of
+// the module data differs between Go versions, so rather than relying
+// on the offset of the field, each word of the module data is tried
+// and the one yielding the most plausible inline trees is used.
+func (m *wasmModule) goFunc(lt *gosym.LineTable, tab *gosym.Table, pclntab uint64) (uint64, bool) {
+	const (
+		ptrSize         = 8
+		moduleDataWords = 64
+	)
+	names := make(map[string]bool)
+	for _, f := range tab.Funcs {
+		names[f.Name] = true
+	}
+	r := bytes.NewReader(m.memory)
+	var best uint64
+	bestScore := 0
+	for md := 0; md+ptrSize*moduleDataWords <= len(m.memory); md += ptrSize {
+		if binary.LittleEndian.Uint64(m.memory[md:]) != pclntab {
+			continue
+		}
+		for w := 1; w < moduleDataWords; w++ {
+			v := binary.LittleEndian.Uint64(m.memory[md+w*ptrSize:])
+			if v == 0 || v >= uint64(len(m.memory)) {
+				continue
+			}
+			if score := inlineTreesScore(lt, tab, v, r, names); score > bestScore {
+				best, bestScore = v, score
+			}
+		}
+	}
+	return best, bestScore > 0
+}
+
+// inlineTreesScore returns how plausible the inline trees of tab are
+// when read relative to goFunc: the number of distinct functions that
+// are inlined and also in tab, or 0 if any tree is malformed. Trees
+// read from the wrong place mostly name the same few functions.
+func inlineTreesScore(lt *gosym.LineTable, tab *gosym.Table, goFunc uint64, r io.ReaderAt, names map[string]bool) (score int) {
+	// The line table does not check the name offsets of inlined
+	// calls, which are out of range when goFunc is wrong.
+	defer func() {
+		if recover() != nil {
+			score = 0
+		}
+	}()
+	seen := make(map[string]bool)
+	for i := range tab.Funcs {
+		f := &tab.Funcs[i]
+		it, err := lt.InlineTree(f, goFunc, 0, r)
+		if err != nil {
+			return 0
+		}
+		for _, ic := range it {
+			if ic.Name == "" || ic.ParentPC < 0 {
+				return 0
+			}
+			if names[ic.Name] && !seen[ic.Name] {
+				seen[ic.Name] = true
+				score++
+			}
+		}
+	}
+	return score
+}
+
+// extractWasm is ExtractPackagesAndSymbols for a WebAssembly module.
+func extractWasm(r io.Reader) ([]*packages.Module, []Symbol, *debug.BuildInfo, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	m, err := parseWasm(data)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	bi, err := m.buildInfo()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	lt, tab, pclntab := m.lineTable()
+	if tab == nil {
+		// As for stripped binaries, return just module info and metadata.
+		return debugModulesToPackagesModules(bi.Deps), nil, bi, nil
+	}
+	goFunc, inlined := m.goFunc(lt, tab, pclntab)
+
+	pkgSyms := make(map[Symbol]bool)
+	for _, f := range tab.Funcs {
+		if f.Func == nil {
+			continue
+		}
+		pkgName, symName, err := parseName(f.Func.Sym)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		pkgSyms[Symbol{pkgName, symName}] = true
+
+		if !inlined {
+			continue
+		}
+		// Collect symbols that were inlined in f.
+		it, err := lt.InlineTree(&f, goFunc, 0, bytes.NewReader(m.memory))
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("InlineTree: %v", err)
+		}
+		for _, ic := range it {
+			pkgName, symName, err := parseName(&gosym.Sym{Name: ic.Name})
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			pkgSyms[Symbol{pkgName, symName}] = true
+		}
+	}
+	var syms []Symbol
+	for ps := range pkgSyms {
+		syms = append(syms, ps)
+	}
+	return debugModulesToPackagesModules(bi.Deps), syms, bi, nil
+}
+
+// wasmReader decodes the contents of a WebAssembly module.
+// The first error encountered is kept in err, after which
+// all methods return zero values.
+type wasmReader struct {
+	data []byte
+	err  error
+}
+
+func (r *wasmReader) fail() {
+	if r.err == nil {
+		r.err = errors.New("malformed WebAssembly module")
+	}
+	r.data = nil
+}
+
+func (r *wasmReader) byte() byte {
+	if len(r.data) < 1 {
+		r.fail()
+		return 0
+	}
+	b := r.data[0]
+	r.data = r.data[1:]
+	return b
+}
+
+func (r *wasmReader) uleb() uint64 {
+	v, n := binary.Uvarint(r.data)
+	if n <= 0 {
+		r.fail()
+		return 0
+	}
+	r.data = r.data[n:]
+	return v
+}
+
+func (r *wasmReader) sleb() int64 {
+	var v int64
+	var shift uint
+	for i, c := range r.data {
+		if shift >= 64 {
+			break
+		}
+		v |= int64(c&0x7f) << shift
+		shift += 7
+		if c&0x80 == 0 {
+			if shift < 64 && c&0x40 != 0 {
+				v |= -1 << shift // sign extend
+			}
+			r.data = r.data[i+1:]
+			return v
+		}
+	}
+	r.fail()
+	return 0
+}
+
+func (r *wasmReader) bytes() []byte {
+	n := r.uleb()
+	if n > uint64(len(r.data)) {
+		r.fail()
+		return nil
+	}
+	b := r.data[:n]
+	r.data = r.data[n:]
+	return b
+}
+
+func (r *wasmReader) name() string { return string(r.bytes()) }
+
+// producersGoVersion returns the Go version
+// recorded in a producers section.
+func (r *wasmReader) producersGoVersion() string {
+	for fields := r.uleb(); fields > 0 && r.err == nil; fields-- {
+		field := r.name()
+		for values := r.uleb(); values > 0 && r.err == nil; values-- {
+			name, version := r.name(), r.name()
+			if field == "language" && name == "Go" {
+				return version
+			}
+		}
+	}
+	return ""
+}
+
+// dataSegments returns the linear memory initialized
+// by the active segments of a data section.
+func (r *wasmReader) dataSegments() []byte {
+	type segment struct {
+		offset int64
+		data   []byte
+	}
+	var segs []segment
+	var size int64
+	for n := r.uleb(); n > 0 && r.err == nil; n-- {
+		flags := r.uleb()
+		if flags == 1 {
+			r.bytes() // passive segment
+			continue
+		}
+		if flags == 2 {
+			r.uleb() // memory index
+		}
+		if r.byte() != 0x41 { // i32.const
+			r.fail()
+			break
+		}
+		offset := r.sleb()
+		if r.byte() != 0x0b { // end
+			r.fail()
+			break
+		}
+		data := r.bytes()
+		if offset < 0 || offset+int64(len(data)) > wasmMaxMemory {
+			r.err = fmt.Errorf("WebAssembly data segment at %#x is out of range", offset)
+			break
+		}
+		segs = append(segs, segment{offset, data})
+		size = max(size, offset+int64(len(data)))
+	}
+	if r.err != nil {
+		return nil
+	}
+	memory := make([]byte, size)
+	for _, s := range segs {
+		copy(memory[s.offset:], s.data)
+	}
+	return memory
+}
+
+// isZip reports whether r is a zip archive.
+func isZip(r io.ReaderAt) bool {
+	data := make([]byte, 4)
+	_, err := r.ReadAt(data, 0)
+	return err == nil && string(data) == "PK\x03\x04"
+}
+
+// androidABIs are the Android ABIs, in order of preference.
+var androidABIs = []string{"arm64-v8a", "armeabi-v7a", "x86_64", "x86"}
+
+// androidArchiveLibrary returns the contents of the Go shared library
+// in the Android archive (AAR) r of the given size. Archives contain
+// a library for each supported ABI, all built from the same code, so
+// only the library for the preferred ABI is returned.
+func androidArchiveLibrary(r io.ReaderAt, size int64) (*bytes.Reader, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+	var libs []*zip.File
+	for _, f := range zr.File {
+		if dir, _ := path.Split(f.Name); path.Dir(path.Clean(dir)) == "jni" && path.Ext(f.Name) == ".so" {
+			libs = append(libs, f)
+		}
+	}
+	if len(libs) == 0 {
+		return nil, errors.New("zip archive is not an Android archive with Go libraries")
+	}
+	rank := func(f *zip.File) int {
+		abi := path.Base(path.Dir(f.Name))
+		for i, a := range androidABIs {
+			if a == abi {
+				return i
+			}
+		}
+		return len(androidABIs)
+	}
+	sort.SliceStable(libs, func(i, j int) bool {
+		if ri, rj := rank(libs[i]), rank(libs[j]); ri != rj {
+			return ri < rj
+		}
+		return libs[i].Name < libs[j].Name
+	})
+	rc, err := libs[0].Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	b, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(b), nil
+}
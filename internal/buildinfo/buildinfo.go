@@ -28,6 +28,10 @@ func openExe(r io.ReaderAt) (exe, error) {
 	if _, err := r.ReadAt(data, 0); err != nil {
 		return nil, err
 	}
+	// Addition: c-archives contain the Go code in an object file.
+	if bytes.HasPrefix(data, []byte(archiveMagic)) {
+		return openArchiveObject(r)
+	}
 	if bytes.HasPrefix(data, []byte("\x7FELF")) {
 		e, err := elf.NewFile(r)
 		if err != nil {
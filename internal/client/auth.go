@@ -0,0 +1,65 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+// tlsConfig returns the TLS configuration to connect to the database
+// of s with, or nil if s has no TLS options. Its root CAs are those
+// of the system along with the bundle in s.CAFile, and it presents
+// the client certificate in s.CertFile and s.KeyFile.
+func (s *Source) tlsConfig() (*tls.Config, error) {
+	if s.CAFile == "" && s.CertFile == "" {
+		return nil, nil
+	}
+	cfg := &tls.Config{}
+	if s.CAFile != "" {
+		pem, err := os.ReadFile(s.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("%s: no PEM certificates found", s.CAFile)
+		}
+		cfg.RootCAs = pool
+	}
+	if s.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(s.CertFile, s.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
+
+// withTLSConfig returns a copy of c whose connections use cfg. The
+// transport of c must be an *http.Transport, or nil for the default
+// transport.
+func withTLSConfig(c *http.Client, cfg *tls.Config) (*http.Client, error) {
+	var t *http.Transport
+	switch ct := c.Transport.(type) {
+	case nil:
+		t = http.DefaultTransport.(*http.Transport).Clone()
+	case *http.Transport:
+		t = ct.Clone()
+	default:
+		return nil, fmt.Errorf("cannot configure TLS for an HTTP client with a %T transport", ct)
+	}
+	t.TLSClientConfig = cfg
+	cc := *c
+	cc.Transport = t
+	return &cc, nil
+}
@@ -0,0 +1,141 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeClientCert writes a self-signed client certificate and its
+// key to PEM files in dir, and returns the files and the certificate.
+func writeClientCert(t *testing.T, dir string) (certFile, keyFile string, cert *x509.Certificate) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "govulncheck"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cert, err = x509.ParseCertificate(der); err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile = filepath.Join(dir, "client.pem")
+	keyFile = filepath.Join(dir, "client.key")
+	writePEM(t, certFile, "CERTIFICATE", der)
+	writePEM(t, keyFile, "EC PRIVATE KEY", keyDER)
+	return certFile, keyFile, cert
+}
+
+func writePEM(t *testing.T, file, typ string, der []byte) {
+	t.Helper()
+	if err := os.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile, cert := writeClientCert(t, dir)
+
+	// A database that is served with a certificate of its own
+	// authority, to clients with a certificate it trusts.
+	srv := httptest.NewUnstartedServer(http.FileServer(http.Dir(testVulndb)))
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(cert)
+	srv.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+	caFile := filepath.Join(dir, "ca.pem")
+	writePEM(t, caFile, "CERTIFICATE", srv.Certificate().Raw)
+
+	for _, tc := range []struct {
+		name    string
+		options string
+		wantErr bool
+	}{
+		{name: "ca and cert", options: ";ca=" + caFile + ";cert=" + certFile + ";key=" + keyFile},
+		{name: "no cert", options: ";ca=" + caFile, wantErr: true},
+		{name: "no ca", options: ";cert=" + certFile + ";key=" + keyFile, wantErr: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			sources, err := ParseSources(srv.URL+tc.options, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			c, err := NewMultiClient(sources, nil)
+			if err != nil {
+				if !tc.wantErr {
+					t.Fatal(err)
+				}
+				return
+			}
+			_, err = c.LastModifiedTime(context.Background())
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Errorf("LastModifiedTime() error = %v, want error: %t", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestBearerToken(t *testing.T) {
+	fs := http.FileServer(http.Dir(testVulndb))
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		fs.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	opts := &Options{HTTPClient: srv.Client()}
+
+	sources, err := ParseSources(srv.URL+";token=secret", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewMultiClient(sources, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.LastModifiedTime(context.Background()); err != nil {
+		t.Error(err)
+	}
+
+	// Without the token, the database refuses access, which
+	// is not mistaken for a database of an unknown format.
+	_, err = NewClient(srv.URL, opts)
+	if err == nil || errors.Is(err, errUnknownSchema) {
+		t.Errorf("NewClient() without token = %v, want an access error", err)
+	}
+}
@@ -11,9 +11,11 @@ package client
 import (
 	"bytes"
 	"context"
+	"crypto/ed25519"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"os"
@@ -32,18 +34,81 @@ import (
 // A Client for reading vulnerability databases.
 type Client struct {
 	source
+	// index, if set, caches the entries of modules between runs.
+	index *indexCache
 }
 
 type Options struct {
 	HTTPClient *http.Client
+	// CacheDir, if set, is a directory in which responses from
+	// HTTP databases are cached between runs, separately for each
+	// database. Cached responses are revalidated with conditional
+	// requests, so the server only sends data that has changed.
+	CacheDir string
+	// IndexCacheDir, if set, is a directory in which the entries
+	// of each module requested with ByModules are cached between
+	// runs, for as long as the database is not modified, so that
+	// repeated scans skip fetching and decoding them again. It is
+	// not used with VerifyKey, as cached entries may not have been
+	// verified.
+	IndexCacheDir string
+	// VerifyKey, if set, is an Ed25519 public key that must have
+	// signed the database's index files and each of its entries.
+	// See VerifyWarn.
+	VerifyKey ed25519.PublicKey
+	// VerifyWarn, if set, is called when verification against
+	// VerifyKey fails, instead of NewClient returning an error.
+	VerifyWarn func(error)
+	// Timeout, if positive, limits the duration of each
+	// request to an HTTP database.
+	Timeout time.Duration
+	// Header holds headers to send with each request to
+	// an HTTP database, such as Authorization.
+	Header http.Header
 }
 
 // NewClient returns a client that reads the vulnerability database
-// in source (an "http" or "file" prefixed URL).
+// in source (an "http" or "file" prefixed URL, or an absolute path).
+//
+// If source ends in ".zip", it is read as a zip archive of the
+// database, optionally verified against a "#sha256=<hex>" fragment.
+//
+// If opts.VerifyKey is set, the index files of the database must
+// have detached signatures by that key, in endpoints named after
+// the index with a ".sig" suffix (for example, "index/db.sig").
 //
 // It supports databases following the API described
 // in https://go.dev/security/vuln/database#api.
-func NewClient(source string, opts *Options) (_ *Client, err error) {
+func NewClient(source string, opts *Options) (*Client, error) {
+	c, err := newClient(source, opts)
+	if err != nil {
+		return nil, err
+	}
+	if opts != nil && opts.VerifyKey != nil {
+		vs, err := newVerifiedSource(context.Background(), c.source, opts.VerifyKey, opts.VerifyWarn)
+		if err != nil {
+			c.Close()
+			return nil, err
+		}
+		c.source = vs
+	}
+	if opts != nil && opts.IndexCacheDir != "" && opts.VerifyKey == nil {
+		c.index = newIndexCache(opts.IndexCacheDir, source)
+	}
+	return c, nil
+}
+
+func newClient(source string, opts *Options) (*Client, error) {
+	if path, frag, _ := strings.Cut(source, "#"); filepath.IsAbs(path) {
+		// A local path, such as C:\vulndb or \\server\share\vulndb
+		// on Windows, rather than a URL.
+		u, err := web.URLFromFilePath(path)
+		if err != nil {
+			return nil, err
+		}
+		u.Fragment = frag
+		source = u.String()
+	}
 	source = strings.TrimRight(source, "/")
 	uri, err := url.Parse(source)
 	if err != nil {
@@ -51,8 +116,14 @@ func NewClient(source string, opts *Options) (_ *Client, err error) {
 	}
 	switch uri.Scheme {
 	case "http", "https":
+		if isZipURL(uri) {
+			return newZipClient(uri, opts)
+		}
 		return newHTTPClient(uri, opts)
 	case "file":
+		if isZipURL(uri) {
+			return newZipClient(uri, opts)
+		}
 		return newLocalClient(uri)
 	default:
 		return nil, fmt.Errorf("source %q has unsupported scheme", uri)
@@ -62,24 +133,47 @@ func NewClient(source string, opts *Options) (_ *Client, err error) {
 var errUnknownSchema = errors.New("unrecognized vulndb format; see https://go.dev/security/vuln/database#api for accepted schema")
 
 func newHTTPClient(uri *url.URL, opts *Options) (*Client, error) {
-	source := uri.String()
+	hs := newHTTPSource(uri.String(), opts)
 
 	// v1 returns true if the source likely follows the V1 schema.
-	v1 := func() bool {
-		return source == "https://vuln.go.dev" ||
-			endpointExistsHTTP(source, "index/modules.json.gz")
+	v1 := func() (bool, error) {
+		if hs.url == "https://vuln.go.dev" {
+			return true, nil
+		}
+		return endpointExistsHTTP(hs, "index/modules.json.gz")
 	}
 
-	if v1() {
-		return &Client{source: newHTTPSource(uri.String(), opts)}, nil
+	ok, err := v1()
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		return &Client{source: hs}, nil
 	}
 
 	return nil, errUnknownSchema
 }
 
-func endpointExistsHTTP(source, endpoint string) bool {
-	r, err := http.Head(source + "/" + endpoint)
-	return err == nil && r.StatusCode == http.StatusOK
+// endpointExistsHTTP reports whether endpoint exists in the database
+// of hs, which is requested as any other endpoint, such as with the
+// headers of hs. It returns an error if the database could not be
+// reached or refused access, rather than not having the endpoint.
+func endpointExistsHTTP(hs *httpSource, endpoint string) (bool, error) {
+	req, err := http.NewRequest(http.MethodHead, hs.url+"/"+endpoint, nil)
+	if err != nil {
+		return false, err
+	}
+	hs.addHeader(req)
+	r, err := hs.c.Do(req)
+	if err != nil {
+		return false, err
+	}
+	r.Body.Close()
+	switch r.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return false, unexpectedStatus(req.Method, req.URL.String(), r)
+	}
+	return r.StatusCode == http.StatusOK, nil
 }
 
 func newLocalClient(uri *url.URL) (*Client, error) {
@@ -133,6 +227,20 @@ func NewInMemoryClient(entries []*osv.Entry) (*Client, error) {
 	return &Client{source: s}, nil
 }
 
+// Close releases the resources of the client, such as
+// the files of local zip databases.
+func (c *Client) Close() error {
+	return closeSource(c.source)
+}
+
+// closeSource closes s if it holds resources.
+func closeSource(s source) error {
+	if c, ok := s.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
 func (c *Client) LastModifiedTime(ctx context.Context) (_ time.Time, err error) {
 	derrors.Wrap(&err, "LastModifiedTime()")
 
@@ -156,6 +264,9 @@ type ModuleRequest struct {
 	// (Optional) If set, only return vulnerabilities affected
 	// at this version.
 	Version string
+	// (Optional) Resolver maps the versions of non-SEMVER ranges
+	// to module versions when checking whether Version is affected.
+	Resolver isem.Resolver
 }
 
 type ModuleResponse struct {
@@ -173,7 +284,16 @@ type ModuleResponse struct {
 func (c *Client) ByModules(ctx context.Context, reqs []*ModuleRequest) (_ []*ModuleResponse, err error) {
 	derrors.Wrap(&err, "ByModules(%v)", reqs)
 
-	metas, err := c.moduleMetas(ctx, reqs)
+	var (
+		metas []*moduleMeta
+		all   [][]*osv.Entry
+	)
+	if c.index != nil {
+		metas, all, err = c.cachedModules(ctx, reqs)
+	} else {
+		metas, err = c.moduleMetas(ctx, reqs)
+		all = make([][]*osv.Entry, len(reqs))
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -184,7 +304,7 @@ func (c *Client) ByModules(ctx context.Context, reqs []*ModuleRequest) (_ []*Mod
 	for i, req := range reqs {
 		i, req := i, req
 		g.Go(func() error {
-			entries, err := c.byModule(gctx, req, metas[i])
+			entries, err := c.byModule(gctx, req, metas[i], all[i])
 			if err != nil {
 				return err
 			}
@@ -203,6 +323,52 @@ func (c *Client) ByModules(ctx context.Context, reqs []*ModuleRequest) (_ []*Mod
 	return resps, nil
 }
 
+// ByIDs returns the OSV entries with the given IDs, in order. An ID may
+// also be an alias of entries, such as a CVE or GHSA ID, in which case
+// all the entries with that alias are returned, sorted by ID.
+//
+// It returns an error if an ID is neither an entry nor an alias of one.
+func (c *Client) ByIDs(ctx context.Context, ids []string) (_ []*osv.Entry, err error) {
+	defer derrors.Wrap(&err, "ByIDs(%v)", ids)
+
+	b, err := c.source.get(ctx, vulnsEndpoint)
+	if err != nil {
+		return nil, err
+	}
+	dec, err := newStreamDecoder(b)
+	if err != nil {
+		return nil, err
+	}
+	byAlias := make(map[string][]string)
+	for dec.More() {
+		var v vulnMeta
+		if err := dec.Decode(&v); err != nil {
+			return nil, err
+		}
+		byAlias[v.ID] = append(byAlias[v.ID], v.ID)
+		for _, a := range v.Aliases {
+			byAlias[a] = append(byAlias[a], v.ID)
+		}
+	}
+
+	var resolved []string
+	seen := make(map[string]bool)
+	for _, id := range ids {
+		vids := byAlias[id]
+		if len(vids) == 0 {
+			return nil, fmt.Errorf("%s is not in the vulnerability database", id)
+		}
+		sort.Strings(vids)
+		for _, vid := range vids {
+			if !seen[vid] {
+				seen[vid] = true
+				resolved = append(resolved, vid)
+			}
+		}
+	}
+	return c.byIDs(ctx, resolved)
+}
+
 func (c *Client) moduleMetas(ctx context.Context, reqs []*ModuleRequest) (_ []*moduleMeta, err error) {
 	b, err := c.source.get(ctx, modulesEndpoint)
 	if err != nil {
@@ -232,8 +398,9 @@ func (c *Client) moduleMetas(ctx context.Context, reqs []*ModuleRequest) (_ []*m
 }
 
 // byModule returns the OSV entries matching the ModuleRequest,
-// or (nil, nil) if there are none.
-func (c *Client) byModule(ctx context.Context, req *ModuleRequest, m *moduleMeta) (_ []*osv.Entry, err error) {
+// or (nil, nil) if there are none. If all is set, it holds all
+// the entries of the module, so that they are not fetched.
+func (c *Client) byModule(ctx context.Context, req *ModuleRequest, m *moduleMeta, all []*osv.Entry) (_ []*osv.Entry, err error) {
 	// This module isn't in the database.
 	if m == nil {
 		return nil, nil
@@ -258,8 +425,10 @@ func (c *Client) byModule(ctx context.Context, req *ModuleRequest, m *moduleMeta
 		return nil, nil
 	}
 
-	entries, err := c.byIDs(ctx, ids)
-	if err != nil {
+	var entries []*osv.Entry
+	if all != nil {
+		entries = selectEntries(all, ids)
+	} else if entries, err = c.byIDs(ctx, ids); err != nil {
 		return nil, err
 	}
 
@@ -267,7 +436,7 @@ func (c *Client) byModule(ctx context.Context, req *ModuleRequest, m *moduleMeta
 	if req.Version != "" {
 		affected := func(e *osv.Entry) bool {
 			for _, a := range e.Affected {
-				if a.Module.Path == req.Path && isem.Affects(a.Ranges, req.Version) {
+				if a.Module.Path == req.Path && isem.AffectsResolved(a.Ranges, req.Version, req.Resolver) {
 					return true
 				}
 			}
@@ -314,6 +483,21 @@ func (c *Client) byIDs(ctx context.Context, ids []string) (_ []*osv.Entry, err e
 	return entries, nil
 }
 
+// selectEntries returns the entries with the given IDs, in order.
+func selectEntries(entries []*osv.Entry, ids []string) []*osv.Entry {
+	byID := make(map[string]*osv.Entry, len(entries))
+	for _, e := range entries {
+		byID[e.ID] = e
+	}
+	selected := make([]*osv.Entry, 0, len(ids))
+	for _, id := range ids {
+		if e, ok := byID[id]; ok {
+			selected = append(selected, e)
+		}
+	}
+	return selected
+}
+
 // byID returns the OSV entry with the given ID,
 // or an error if it does not exist / cannot be unmarshaled.
 func (c *Client) byID(ctx context.Context, id string) (_ *osv.Entry, err error) {
@@ -137,6 +137,20 @@ func TestNewClient(t *testing.T) {
 		}
 	})
 
+	t.Run("local/path", func(t *testing.T) {
+		src, err := filepath.Abs(testVulndb)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c, err := NewClient(src, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.ByIDs(context.Background(), []string{"GO-2021-0068"}); err != nil {
+			t.Errorf("ByIDs() from %s: %v", src, err)
+		}
+	})
+
 	t.Run("local/legacy", func(t *testing.T) {
 		src := testLegacyVulndbFileURL
 		_, err := NewClient(src, nil)
@@ -294,6 +308,27 @@ func TestByModules(t *testing.T) {
 	})
 }
 
+func TestByIDs(t *testing.T) {
+	test := func(t *testing.T, c *Client) {
+		got, err := c.ByIDs(context.Background(), []string{"GO-2022-0463", "CVE-2020-7919", "GHSA-qx32-f6g6-fcfr"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := entries([]string{"GO-2022-0463", "GO-2022-0229"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("ByIDs() mismatch (-want +got):\n%s", diff)
+		}
+
+		if _, err := c.ByIDs(context.Background(), []string{"GO-1999-0001"}); err == nil {
+			t.Error("ByIDs(GO-1999-0001) succeeded, want error")
+		}
+	}
+	testAllClientTypes(t, test)
+}
+
 // testAllClientTypes runs a given test for all client types.
 func testAllClientTypes(t *testing.T, test func(t *testing.T, c *Client)) {
 	t.Run("http", func(t *testing.T) {
@@ -0,0 +1,85 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"net/http"
+	"sync/atomic"
+)
+
+// fallbackSource reads a chain of databases, with the semantics of
+// GOPROXY: an endpoint is read from the first database of the chain,
+// then from the next ones for as long as they do not have it. Other
+// errors are returned as is.
+type fallbackSource struct {
+	urls    []string
+	sources []source
+	// answers counts the requests answered by each source.
+	answers []atomic.Int64
+}
+
+func (f *fallbackSource) Close() error { return closeSources(f.sources) }
+
+func (f *fallbackSource) get(ctx context.Context, endpoint string) ([]byte, error) {
+	var err error
+	for i, s := range f.sources {
+		var b []byte
+		b, err = s.get(ctx, endpoint)
+		if err == nil {
+			f.answers[i].Add(1)
+			return b, nil
+		}
+		if !isNotFound(err) {
+			return nil, err
+		}
+	}
+	return nil, err
+}
+
+// isNotFound reports whether err is the error of a database that does
+// not have an endpoint, which for an HTTP database is a response with
+// status "404 Not Found" or "410 Gone".
+func isNotFound(err error) bool {
+	var se *statusError
+	if errors.As(err, &se) {
+		return se.code == http.StatusNotFound || se.code == http.StatusGone
+	}
+	return errors.Is(err, fs.ErrNotExist)
+}
+
+// An Answer counts the requests that a database of a chain answered.
+type Answer struct {
+	URL      string
+	Requests int64
+}
+
+// Answers returns the answers of each database of c that has
+// fallbacks, as given to NewMultiClient: for every database of
+// such a chain, the number of requests it answered so far.
+func (c *Client) Answers() [][]Answer {
+	var chains []*fallbackSource
+	switch s := c.source.(type) {
+	case *fallbackSource:
+		chains = append(chains, s)
+	case *mergedSource:
+		for _, ms := range s.sources {
+			if f, ok := ms.(*fallbackSource); ok {
+				chains = append(chains, f)
+			}
+		}
+	}
+	var answers [][]Answer
+	for _, f := range chains {
+		chain := make([]Answer, len(f.sources))
+		for i := range f.sources {
+			chain[i] = Answer{URL: f.urls[i], Requests: f.answers[i].Load()}
+		}
+		answers = append(answers, chain)
+	}
+	return answers
+}
@@ -0,0 +1,65 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFallback(t *testing.T) {
+	// A mirror that lacks an entry, and fails on another.
+	fs := http.FileServer(http.Dir(testVulndb))
+	mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ID/GO-2022-0463.json.gz":
+			http.Error(w, "gone", http.StatusGone)
+		case "/ID/GO-2022-0569.json.gz":
+			http.Error(w, "oops", http.StatusBadRequest)
+		default:
+			fs.ServeHTTP(w, r)
+		}
+	}))
+	t.Cleanup(mirror.Close)
+	// A location that has no database at all.
+	empty := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(empty.Close)
+
+	sources, err := ParseSources(empty.URL+"|"+mirror.URL+"|"+testVulndbFileURL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := SourceURLs(sources), empty.URL+"|"+mirror.URL+"|"+testVulndbFileURL; got != want {
+		t.Errorf("SourceURLs() = %s, want %s", got, want)
+	}
+	c, err := NewMultiClient(sources, &Options{HTTPClient: mirror.Client()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	entries, err := c.ByIDs(ctx, []string{"GO-2022-0463", "GO-2021-0159"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("got %d entries, want 2", len(entries))
+	}
+	// The vulns index and GO-2021-0159 are read from the
+	// mirror, and GO-2022-0463 from the local database.
+	want := [][]Answer{{{URL: mirror.URL, Requests: 2}, {URL: testVulndbFileURL, Requests: 1}}}
+	if diff := cmp.Diff(want, c.Answers()); diff != "" {
+		t.Errorf("Answers() mismatch (-want +got):\n%s", diff)
+	}
+
+	// Errors other than missing endpoints are fatal.
+	if _, err := c.ByIDs(ctx, []string{"GO-2022-0569"}); err == nil {
+		t.Error("ByIDs(GO-2022-0569) succeeded, want error")
+	}
+}
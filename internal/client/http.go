@@ -0,0 +1,276 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Defaults for retrying transient HTTP failures.
+const (
+	// defaultMaxAttempts is the maximum number of attempts
+	// made for a single request.
+	defaultMaxAttempts = 4
+	// defaultRetryBudget is the total number of retries a
+	// single source may make, across all requests. It keeps
+	// a struggling server from being hit by every request.
+	defaultRetryBudget = 16
+	// defaultBaseDelay is the delay before the first retry.
+	// It doubles with each subsequent retry.
+	defaultBaseDelay = 500 * time.Millisecond
+	// defaultMaxDelay caps the delay between attempts,
+	// including delays requested by a Retry-After header.
+	defaultMaxDelay = 30 * time.Second
+)
+
+// retryPolicy controls how an httpSource retries transient failures.
+type retryPolicy struct {
+	maxAttempts int
+	baseDelay   time.Duration
+	maxDelay    time.Duration
+
+	mu     sync.Mutex
+	budget int // remaining retries
+}
+
+func newRetryPolicy() *retryPolicy {
+	return &retryPolicy{
+		maxAttempts: defaultMaxAttempts,
+		baseDelay:   defaultBaseDelay,
+		maxDelay:    defaultMaxDelay,
+		budget:      defaultRetryBudget,
+	}
+}
+
+// take reports whether there is retry budget left, consuming
+// one retry if so.
+func (p *retryPolicy) take() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.budget <= 0 {
+		return false
+	}
+	p.budget--
+	return true
+}
+
+// delay returns how long to wait before retrying after the given
+// (zero-based) attempt failed with err.
+func (p *retryPolicy) delay(attempt int, err *transientError) time.Duration {
+	if err.retryAfter > 0 {
+		return min(err.retryAfter, p.maxDelay)
+	}
+	d := p.baseDelay << attempt
+	if d <= 0 || d > p.maxDelay {
+		d = p.maxDelay
+	}
+	// Add jitter so that many clients failing at the
+	// same time do not retry in lockstep.
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// transientError is an error that may go away if the request is retried.
+type transientError struct {
+	err error
+	// retryAfter is the delay requested by the server, if any.
+	retryAfter time.Duration
+}
+
+func (e *transientError) Error() string { return e.err.Error() }
+func (e *transientError) Unwrap() error { return e.err }
+
+func asTransient(err error) (*transientError, bool) {
+	var te *transientError
+	ok := errors.As(err, &te)
+	return te, ok
+}
+
+// isTransientStatus reports whether an HTTP response with the given
+// status code is worth retrying.
+func isTransientStatus(code int) bool {
+	switch code {
+	case http.StatusRequestTimeout,
+		http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// isTransientError reports whether an error returned by an HTTP
+// client for a request is worth retrying. Only network failures
+// are: canceled requests, certificate and TLS protocol failures,
+// and requests the client cannot make, such as for a URL with an
+// unsupported scheme, fail the same way on every attempt.
+func isTransientError(err error) bool {
+	var (
+		verifyErr    *tls.CertificateVerificationError
+		recordErr    tls.RecordHeaderError
+		authorityErr x509.UnknownAuthorityError
+		hostnameErr  x509.HostnameError
+		invalidErr   x509.CertificateInvalidError
+	)
+	switch {
+	case errors.Is(err, context.Canceled),
+		errors.As(err, &verifyErr),
+		errors.As(err, &recordErr),
+		errors.As(err, &authorityErr),
+		errors.As(err, &hostnameErr),
+		errors.As(err, &invalidErr):
+		return false
+	}
+	// A *url.Error is itself a net.Error, so classify its cause.
+	var ue *url.Error
+	if errors.As(err, &ue) {
+		err = ue.Err
+	}
+	var ne net.Error
+	return errors.As(err, &ne) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// parseRetryAfter parses the value of a Retry-After header, which
+// is either a number of seconds or an HTTP date. It returns 0 if
+// the value is missing or invalid.
+func parseRetryAfter(v string, now time.Time) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0
+		}
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
+
+// cachedResponse is the last successful response for an endpoint,
+// along with the validators needed to make a conditional request
+// for it.
+type cachedResponse struct {
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+	// Data is the uncompressed response body.
+	Data []byte `json:"data"`
+}
+
+// responseCache stores responses by endpoint. Caches are best-effort:
+// failures to load or store are treated as cache misses.
+type responseCache interface {
+	load(endpoint string) (*cachedResponse, bool)
+	store(endpoint string, r *cachedResponse)
+}
+
+// newResponseCache returns the cache of the responses of the database
+// at url. Persistent caches are in a directory of their own for each
+// database, as all the sources of a client share its options.
+func newResponseCache(url string, opts *Options) responseCache {
+	if opts != nil && opts.CacheDir != "" {
+		return &dirCache{dir: filepath.Join(opts.CacheDir, hashName(url))}
+	}
+	return &memCache{m: make(map[string]*cachedResponse)}
+}
+
+// memCache is a responseCache that lives as long as its source.
+type memCache struct {
+	mu sync.Mutex
+	m  map[string]*cachedResponse
+}
+
+func (c *memCache) load(endpoint string) (*cachedResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	r, ok := c.m[endpoint]
+	return r, ok
+}
+
+func (c *memCache) store(endpoint string, r *cachedResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.m[endpoint] = r
+}
+
+// dirCache is a responseCache that persists responses as JSON
+// files in a directory, so they survive across runs.
+type dirCache struct {
+	dir string
+}
+
+func (c *dirCache) path(endpoint string) string {
+	return filepath.Join(c.dir, filepath.FromSlash(endpoint)+".json")
+}
+
+func (c *dirCache) load(endpoint string) (*cachedResponse, bool) {
+	b, err := os.ReadFile(c.path(endpoint))
+	if err != nil {
+		return nil, false
+	}
+	var r cachedResponse
+	if err := json.Unmarshal(b, &r); err != nil {
+		return nil, false
+	}
+	return &r, true
+}
+
+func (c *dirCache) store(endpoint string, r *cachedResponse) {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return
+	}
+	p := c.path(endpoint)
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return
+	}
+	// Write to a temporary file first so that concurrent
+	// runs never observe a partially written entry.
+	tmp, err := os.CreateTemp(filepath.Dir(p), filepath.Base(p)+".tmp*")
+	if err != nil {
+		return
+	}
+	_, werr := tmp.Write(b)
+	cerr := tmp.Close()
+	if werr != nil || cerr != nil {
+		os.Remove(tmp.Name())
+		return
+	}
+	if err := os.Rename(tmp.Name(), p); err != nil {
+		os.Remove(tmp.Name())
+	}
+}
+
+func unexpectedStatus(method, url string, resp *http.Response) error {
+	return &statusError{method: method, url: url, status: resp.Status, code: resp.StatusCode}
+}
+
+// statusError is the error for an HTTP response with an unexpected status.
+type statusError struct {
+	method, url, status string
+	code                int
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("HTTP %s %s returned unexpected status: %s", e.method, e.url, e.status)
+}
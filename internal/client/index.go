@@ -10,6 +10,7 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"sort"
 
 	"golang.org/x/vuln/internal/osv"
 	isem "golang.org/x/vuln/internal/semver"
@@ -69,6 +70,7 @@ func indexFromEntries(entries []*osv.Entry) (map[string][]byte, error) {
 type index struct {
 	db      *dbMeta
 	modules modulesIndex
+	vulns   []*vulnMeta
 }
 
 func newIndex() *index {
@@ -83,6 +85,12 @@ func (i *index) add(entry *osv.Entry) {
 	if entry.Modified.After(i.db.Modified) {
 		i.db.Modified = entry.Modified
 	}
+	// Add to vulns index.
+	i.vulns = append(i.vulns, &vulnMeta{
+		ID:       entry.ID,
+		Modified: entry.Modified,
+		Aliases:  entry.Aliases,
+	})
 	// Add to modules index.
 	for _, affected := range entry.Affected {
 		modulePath := affected.Module.Path
@@ -116,5 +124,14 @@ func (i *index) raw() (map[string][]byte, error) {
 	}
 	data[modulesEndpoint] = b
 
+	sort.SliceStable(i.vulns, func(a, b int) bool {
+		return i.vulns[a].ID < i.vulns[b].ID
+	})
+	b, err = json.Marshal(i.vulns)
+	if err != nil {
+		return nil, err
+	}
+	data[vulnsEndpoint] = b
+
 	return data, nil
 }
@@ -0,0 +1,178 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"golang.org/x/vuln/internal/osv"
+)
+
+// moduleIndex is what a scan needs to know about a module from
+// the database: its metadata in the modules index, if it has any
+// vulnerabilities, and all of its entries.
+type moduleIndex struct {
+	Meta    *moduleMeta  `json:"meta,omitempty"`
+	Entries []*osv.Entry `json:"entries,omitempty"`
+}
+
+// indexCache stores the moduleIndex of modules in a directory, per
+// snapshot of a database. Snapshots are identified by the last
+// modified time of the database, so a cache is never stale. Snapshots
+// older than the one stored to are removed once they have not been
+// written to for snapshotGracePeriod, so that concurrent runs that
+// still read an older snapshot of the database are not disturbed.
+//
+// Like responseCache, indexCache is best-effort: failures to load
+// or store are treated as cache misses.
+type indexCache struct {
+	// dir is the directory of the snapshots of a database.
+	dir string
+	// pruned is done on the first store.
+	pruned sync.Once
+}
+
+// snapshotGracePeriod is how long outdated snapshots are kept
+// after they were last written to.
+const snapshotGracePeriod = time.Hour
+
+// newIndexCache returns a cache in dir for the database at source.
+func newIndexCache(dir, source string) *indexCache {
+	return &indexCache{dir: filepath.Join(dir, hashName(source))}
+}
+
+func (c *indexCache) snapshot(modified time.Time) string {
+	return filepath.Join(c.dir, modified.UTC().Format("20060102T150405.000000000Z"))
+}
+
+// path returns the file of the module modPath in the snapshot.
+// Files are named after a hash of the module path, which, unlike
+// the path itself, is safe on case-insensitive file systems.
+func (c *indexCache) path(modified time.Time, modPath string) string {
+	return filepath.Join(c.snapshot(modified), hashName(modPath)+".json")
+}
+
+func hashName(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:8])
+}
+
+func (c *indexCache) load(modified time.Time, modPath string) (*moduleIndex, bool) {
+	b, err := os.ReadFile(c.path(modified, modPath))
+	if err != nil {
+		return nil, false
+	}
+	var mi moduleIndex
+	if err := json.Unmarshal(b, &mi); err != nil {
+		return nil, false
+	}
+	return &mi, true
+}
+
+func (c *indexCache) store(modified time.Time, modPath string, mi *moduleIndex) {
+	p := c.path(modified, modPath)
+	b, err := json.Marshal(mi)
+	if err != nil {
+		return
+	}
+	c.pruned.Do(func() { c.prune(modified, time.Now()) })
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return
+	}
+	// As in dirCache, write to a temporary file first so that
+	// concurrent runs never observe a partially written entry.
+	tmp, err := os.CreateTemp(filepath.Dir(p), filepath.Base(p)+".tmp*")
+	if err != nil {
+		return
+	}
+	_, werr := tmp.Write(b)
+	cerr := tmp.Close()
+	if werr != nil || cerr != nil {
+		os.Remove(tmp.Name())
+		return
+	}
+	if err := os.Rename(tmp.Name(), p); err != nil {
+		os.Remove(tmp.Name())
+	}
+}
+
+// prune removes the snapshots of the cache that are older than the
+// snapshot of modified and were last written to before now minus
+// snapshotGracePeriod.
+func (c *indexCache) prune(modified, now time.Time) {
+	des, err := os.ReadDir(c.dir)
+	if err != nil {
+		return
+	}
+	// Snapshot names sort by time.
+	current := filepath.Base(c.snapshot(modified))
+	for _, de := range des {
+		if !de.IsDir() || de.Name() >= current {
+			continue
+		}
+		info, err := de.Info()
+		if err != nil || now.Sub(info.ModTime()) < snapshotGracePeriod {
+			continue
+		}
+		os.RemoveAll(filepath.Join(c.dir, de.Name()))
+	}
+}
+
+// cachedModules returns the metadata of the modules of reqs, as
+// moduleMetas does, along with all the entries of each module. The
+// modules are loaded from the index cache of c when the database
+// has not changed since they were stored, and are fetched and stored
+// otherwise. Without a last modified time for the database, nothing
+// is cached.
+func (c *Client) cachedModules(ctx context.Context, reqs []*ModuleRequest) (_ []*moduleMeta, _ [][]*osv.Entry, err error) {
+	entries := make([][]*osv.Entry, len(reqs))
+	modified, err := c.LastModifiedTime(ctx)
+	if err != nil || modified.IsZero() {
+		metas, err := c.moduleMetas(ctx, reqs)
+		return metas, entries, err
+	}
+
+	metas := make([]*moduleMeta, len(reqs))
+	var missed []*ModuleRequest
+	var missedIdx []int
+	for i, req := range reqs {
+		if mi, ok := c.index.load(modified, req.Path); ok {
+			metas[i], entries[i] = mi.Meta, mi.Entries
+			continue
+		}
+		missed = append(missed, req)
+		missedIdx = append(missedIdx, i)
+	}
+	if len(missed) == 0 {
+		return metas, entries, nil
+	}
+
+	missedMetas, err := c.moduleMetas(ctx, missed)
+	if err != nil {
+		return nil, nil, err
+	}
+	for j, m := range missedMetas {
+		mi := &moduleIndex{Meta: m}
+		if m != nil {
+			ids := make([]string, len(m.Vulns))
+			for k, v := range m.Vulns {
+				ids[k] = v.ID
+			}
+			if mi.Entries, err = c.byIDs(ctx, ids); err != nil {
+				return nil, nil, err
+			}
+		}
+		c.index.store(modified, missed[j].Path, mi)
+		metas[missedIdx[j]], entries[missedIdx[j]] = mi.Meta, mi.Entries
+	}
+	return metas, entries, nil
+}
@@ -0,0 +1,96 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestByModulesIndexCache(t *testing.T) {
+	var idRequests atomic.Int32
+	fs := http.FileServer(http.Dir(testVulndb))
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/"+idDir+"/") {
+			idRequests.Add(1)
+		}
+		fs.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	reqs := []*ModuleRequest{
+		{Path: "github.com/beego/beego", Version: "1.12.10"},
+		{Path: "stdlib", Version: "go1.17"},
+		{Path: "golang.org/x/crypto"},
+		{Path: "does.not/exist"},
+	}
+	uncached, err := NewClient(srv.URL, &Options{HTTPClient: srv.Client()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := uncached.ByModules(context.Background(), reqs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	for i, wantRequests := range []bool{true, false} {
+		// A new client for each run, as for separate runs of govulncheck.
+		c, err := NewClient(srv.URL, &Options{HTTPClient: srv.Client(), IndexCacheDir: dir})
+		if err != nil {
+			t.Fatal(err)
+		}
+		idRequests.Store(0)
+		got, err := c.ByModules(context.Background(), reqs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("run %d: ByModules() mismatch (-want +got):\n%s", i, diff)
+		}
+		if n := idRequests.Load(); (n > 0) != wantRequests {
+			t.Errorf("run %d: got %d requests for entries, want requests: %t", i, n, wantRequests)
+		}
+	}
+
+	// Storing a module of a newer snapshot keeps the older snapshot
+	// for runs that still read it, until its grace period is over.
+	modified := time.Date(2023, 4, 3, 15, 57, 51, 0, time.UTC)
+	newer := modified.Add(time.Hour)
+	c := newIndexCache(dir, srv.URL)
+	c.store(newer, "stdlib", &moduleIndex{})
+	if _, ok := c.load(modified, "stdlib"); !ok {
+		t.Error("stdlib of the older snapshot was removed during its grace period")
+	}
+	old := time.Now().Add(-2 * snapshotGracePeriod)
+	if err := os.Chtimes(c.snapshot(newer), old, old); err != nil {
+		t.Fatal(err)
+	}
+	// Storing to an older snapshot does not remove newer ones.
+	c = newIndexCache(dir, srv.URL)
+	c.store(modified, "golang.org/x/crypto", &moduleIndex{})
+	if _, ok := c.load(newer, "stdlib"); !ok {
+		t.Error("stdlib of the newer snapshot was removed")
+	}
+	if err := os.Chtimes(c.snapshot(modified), old, old); err != nil {
+		t.Fatal(err)
+	}
+	c = newIndexCache(dir, srv.URL)
+	c.store(newer, "golang.org/x/crypto", &moduleIndex{})
+	if _, ok := c.load(modified, "stdlib"); ok {
+		t.Error("stdlib of the older snapshot is still in the cache")
+	}
+	if des, err := os.ReadDir(c.dir); err != nil || len(des) != 1 {
+		t.Errorf("got %d snapshots (err %v), want 1", len(des), err)
+	}
+}
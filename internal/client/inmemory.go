@@ -0,0 +1,179 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/vuln/internal/osv"
+)
+
+// InMemoryDB is a mutable, in-memory vulnerability database intended
+// for tests. Entries can be changed while clients are reading from it,
+// and failures and latencies can be injected for endpoints.
+//
+// All methods are safe for concurrent use.
+type InMemoryDB struct {
+	mu       sync.Mutex
+	entries  map[string]*osv.Entry
+	src      *inMemorySource
+	failures map[string]error // endpoint prefix -> error
+	latency  time.Duration
+	requests map[string]int // endpoint -> count
+}
+
+// NewInMemoryDB returns a database holding entries.
+func NewInMemoryDB(entries []*osv.Entry) (*InMemoryDB, error) {
+	db := &InMemoryDB{
+		entries:  make(map[string]*osv.Entry),
+		failures: make(map[string]error),
+		requests: make(map[string]int),
+	}
+	if err := db.Add(entries...); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+// Client returns a client that reads from db.
+func (db *InMemoryDB) Client() *Client {
+	return &Client{source: db}
+}
+
+// Add adds entries to db, replacing any existing entries with
+// the same IDs.
+func (db *InMemoryDB) Add(entries ...*osv.Entry) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	for _, e := range entries {
+		db.entries[e.ID] = e
+	}
+	return db.rebuild()
+}
+
+// Remove removes the entries with the given IDs from db.
+// It is an error if any of them does not exist.
+func (db *InMemoryDB) Remove(ids ...string) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	for _, id := range ids {
+		if _, ok := db.entries[id]; !ok {
+			return fmt.Errorf("no entry with ID %s", id)
+		}
+	}
+	for _, id := range ids {
+		delete(db.entries, id)
+	}
+	return db.rebuild()
+}
+
+// Modify calls f with the entry with the given ID and stores
+// the result, updating the indexes of db accordingly.
+func (db *InMemoryDB) Modify(id string, f func(*osv.Entry)) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	e, ok := db.entries[id]
+	if !ok {
+		return fmt.Errorf("no entry with ID %s", id)
+	}
+	f(e)
+	if e.ID != id {
+		delete(db.entries, id)
+		db.entries[e.ID] = e
+	}
+	return db.rebuild()
+}
+
+// Fail makes requests for endpoints starting with prefix
+// (for example "index/" or "ID/GO-2023-") fail with err.
+// A nil err removes the failure for prefix.
+func (db *InMemoryDB) Fail(prefix string, err error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if err == nil {
+		delete(db.failures, prefix)
+		return
+	}
+	db.failures[prefix] = err
+}
+
+// SetLatency makes every request take at least d, or until
+// its context is done.
+func (db *InMemoryDB) SetLatency(d time.Duration) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.latency = d
+}
+
+// Requests returns the number of requests made for endpoints
+// starting with prefix. An empty prefix counts all requests.
+func (db *InMemoryDB) Requests(prefix string) int {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	n := 0
+	for endpoint, c := range db.requests {
+		if strings.HasPrefix(endpoint, prefix) {
+			n += c
+		}
+	}
+	return n
+}
+
+// ResetRequests sets all request counts to zero.
+func (db *InMemoryDB) ResetRequests() {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	clear(db.requests)
+}
+
+// rebuild regenerates the endpoints of db from its entries.
+// db.mu must be held.
+func (db *InMemoryDB) rebuild() error {
+	entries := make([]*osv.Entry, 0, len(db.entries))
+	for _, e := range db.entries {
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].ID < entries[j].ID })
+	src, err := newInMemorySource(entries)
+	if err != nil {
+		return err
+	}
+	db.src = src
+	return nil
+}
+
+func (db *InMemoryDB) get(ctx context.Context, endpoint string) ([]byte, error) {
+	db.mu.Lock()
+	db.requests[endpoint]++
+	latency, src := db.latency, db.src
+	// The failure for the longest matching prefix wins.
+	var failure error
+	longest := -1
+	for prefix, err := range db.failures {
+		if strings.HasPrefix(endpoint, prefix) && len(prefix) > longest {
+			failure, longest = err, len(prefix)
+		}
+	}
+	db.mu.Unlock()
+
+	if latency > 0 {
+		t := time.NewTimer(latency)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return nil, ctx.Err()
+		case <-t.C:
+		}
+	}
+	if failure != nil {
+		return nil, failure
+	}
+	return src.get(ctx, endpoint)
+}
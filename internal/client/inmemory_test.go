@@ -0,0 +1,132 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"golang.org/x/vuln/internal/osv"
+)
+
+func testEntry(id, mod, fixed string) *osv.Entry {
+	return &osv.Entry{
+		ID:       id,
+		Modified: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Affected: []osv.Affected{{
+			Module: osv.Module{Path: mod, Ecosystem: osv.GoEcosystem},
+			Ranges: []osv.Range{{
+				Type:   osv.RangeTypeSemver,
+				Events: []osv.RangeEvent{{Introduced: "0"}, {Fixed: fixed}},
+			}},
+		}},
+	}
+}
+
+// byModule returns the IDs of the entries affecting mod@version.
+func byModule(t *testing.T, c *Client, mod, version string) []string {
+	t.Helper()
+	resps, err := c.ByModules(context.Background(), []*ModuleRequest{{Path: mod, Version: version}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, e := range resps[0].Entries {
+		ids = append(ids, e.ID)
+	}
+	return ids
+}
+
+func TestInMemoryDBMutation(t *testing.T) {
+	db, err := NewInMemoryDB([]*osv.Entry{testEntry("GO-2024-0001", "example.com/a", "1.1.0")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := db.Client()
+	if got := byModule(t, c, "example.com/a", "v1.0.0"); len(got) != 1 {
+		t.Fatalf("got %v, want GO-2024-0001", got)
+	}
+
+	if err := db.Add(testEntry("GO-2024-0002", "example.com/a", "1.2.0")); err != nil {
+		t.Fatal(err)
+	}
+	if got := byModule(t, c, "example.com/a", "v1.1.0"); len(got) != 1 || got[0] != "GO-2024-0002" {
+		t.Errorf("after Add: got %v, want [GO-2024-0002]", got)
+	}
+
+	if err := db.Modify("GO-2024-0002", func(e *osv.Entry) {
+		e.Affected[0].Ranges[0].Events[1].Fixed = "1.1.0"
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if got := byModule(t, c, "example.com/a", "v1.1.0"); len(got) != 0 {
+		t.Errorf("after Modify: got %v, want none", got)
+	}
+
+	if err := db.Remove("GO-2024-0001"); err != nil {
+		t.Fatal(err)
+	}
+	if got := byModule(t, c, "example.com/a", "v1.0.0"); len(got) != 1 || got[0] != "GO-2024-0002" {
+		t.Errorf("after Remove: got %v, want [GO-2024-0002]", got)
+	}
+	if err := db.Remove("GO-2024-0001"); err == nil {
+		t.Error("Remove of missing entry succeeded unexpectedly")
+	}
+}
+
+func TestInMemoryDBFailures(t *testing.T) {
+	ctx := context.Background()
+	db, err := NewInMemoryDB([]*osv.Entry{testEntry("GO-2024-0001", "example.com/a", "1.1.0")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := db.Client()
+
+	errFail := errors.New("injected failure")
+	db.Fail(idDir+"/", errFail)
+	if _, err := c.ByModules(ctx, []*ModuleRequest{{Path: "example.com/a"}}); !errors.Is(err, errFail) {
+		t.Errorf("ByModules() = %v, want %v", err, errFail)
+	}
+	if _, err := c.LastModifiedTime(ctx); err != nil {
+		t.Errorf("LastModifiedTime() = %v, want nil", err)
+	}
+	db.Fail(idDir+"/", nil)
+	if _, err := c.ByModules(ctx, []*ModuleRequest{{Path: "example.com/a"}}); err != nil {
+		t.Errorf("ByModules() after clearing failure = %v, want nil", err)
+	}
+
+	db.SetLatency(time.Hour)
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if _, err := c.LastModifiedTime(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("LastModifiedTime() = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestInMemoryDBRequests(t *testing.T) {
+	db, err := NewInMemoryDB([]*osv.Entry{
+		testEntry("GO-2024-0001", "example.com/a", "1.1.0"),
+		testEntry("GO-2024-0002", "example.com/b", "1.1.0"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	byModule(t, db.Client(), "example.com/a", "v1.0.0")
+	if got, want := db.Requests(modulesEndpoint), 1; got != want {
+		t.Errorf("Requests(%s) = %d, want %d", modulesEndpoint, got, want)
+	}
+	if got, want := db.Requests(idDir+"/"), 1; got != want {
+		t.Errorf("Requests(%s/) = %d, want %d", idDir, got, want)
+	}
+	if got, want := db.Requests(""), 2; got != want {
+		t.Errorf(`Requests("") = %d, want %d`, got, want)
+	}
+	db.ResetRequests()
+	if got := db.Requests(""); got != 0 {
+		t.Errorf("Requests after reset = %d, want 0", got)
+	}
+}
@@ -0,0 +1,385 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/textproto"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/vuln/internal/derrors"
+)
+
+// A Source is a vulnerability database, along with the options
+// to read it with that are specific to it.
+type Source struct {
+	// URL is the location of the database, as accepted by NewClient.
+	URL string
+	// Timeout, if positive, overrides the Timeout of the Options.
+	Timeout time.Duration
+	// Header holds headers to send with each request to the
+	// database, in addition to the Header of the Options.
+	Header http.Header
+	// CAFile, if set, is a file of PEM certificates of authorities
+	// to trust for the database, in addition to those of the system.
+	CAFile string
+	// CertFile and KeyFile, if set, are the PEM files of a client
+	// certificate and its key to present to the database.
+	CertFile, KeyFile string
+	// Fallback, if set, is the database to read instead when
+	// this one does not have what is requested.
+	Fallback *Source
+}
+
+// ParseSources parses a comma-separated list of databases, as given
+// to the -db flag or in the GOVULNDB environment variable. Each
+// database is a URL followed by options of the form ";key=value":
+//
+//   - timeout=<duration> limits the duration of each request to the
+//     database, as parsed by [time.ParseDuration].
+//   - header=<name>:<value> adds a header to each request to the
+//     database, which must be an http(s) URL. It may be repeated.
+//   - token=<token> authenticates requests to the database with the
+//     bearer token, like header=Authorization:Bearer <token>.
+//   - ca=<file> trusts the certificate authorities in the PEM file,
+//     in addition to those of the system, for an https database.
+//   - cert=<file> and key=<file> authenticate with the client
+//     certificate and key in the PEM files to an https database.
+//
+// A database may also be a chain of databases separated by "|", each with
+// its own options, which are read with the semantics of GOPROXY: when
+// a database does not have what is requested (it answers "404 Not Found"
+// or "410 Gone"), the next one is read instead, but other errors are
+// fatal.
+//
+// Option values may refer to environment variables as $NAME or ${NAME},
+// which are looked up with lookupEnv, so that credentials such as
+// tokens need not be part of the list itself. It is an error for
+// them to be unset.
+//
+// For example, "https://vuln.go.dev,https://vulndb.example.com;header=Authorization:Bearer $TOKEN".
+func ParseSources(spec string, lookupEnv func(string) (string, bool)) ([]Source, error) {
+	var sources []Source
+	for _, chain := range strings.Split(spec, ",") {
+		var src *Source
+		dbs := strings.Split(chain, "|")
+		// Link the chain from its end.
+		for i := len(dbs) - 1; i >= 0; i-- {
+			s, err := parseSource(dbs[i], lookupEnv)
+			if err != nil {
+				return nil, err
+			}
+			if s.URL == "" {
+				return nil, fmt.Errorf("database list %q has an empty database", spec)
+			}
+			s.Fallback = src
+			src = s
+		}
+		sources = append(sources, *src)
+	}
+	return sources, nil
+}
+
+// parseSource parses a database of a list, without fallbacks.
+func parseSource(db string, lookupEnv func(string) (string, bool)) (*Source, error) {
+	url, options, _ := strings.Cut(strings.TrimSpace(db), ";")
+	src := &Source{URL: url}
+	if options != "" {
+		for _, opt := range strings.Split(options, ";") {
+			if err := src.setOption(opt, lookupEnv); err != nil {
+				return nil, fmt.Errorf("database %s: %v", url, err)
+			}
+		}
+	}
+	if (src.CertFile == "") != (src.KeyFile == "") {
+		return nil, fmt.Errorf("database %s: the cert and key options must be set together", url)
+	}
+	return src, nil
+}
+
+func (s *Source) setOption(opt string, lookupEnv func(string) (string, bool)) error {
+	key, value, ok := strings.Cut(opt, "=")
+	if !ok {
+		return fmt.Errorf("option %q is not of the form key=value", opt)
+	}
+	var unset []string
+	value = os.Expand(value, func(name string) string {
+		if lookupEnv != nil {
+			if v, ok := lookupEnv(name); ok {
+				return v
+			}
+		}
+		unset = append(unset, name)
+		return ""
+	})
+	if len(unset) > 0 {
+		return fmt.Errorf("option %s refers to unset environment variable %s", key, unset[0])
+	}
+	switch key {
+	case "timeout":
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("timeout %q is not a positive duration", value)
+		}
+		s.Timeout = d
+	case "header", "token":
+		if !strings.HasPrefix(s.URL, "http://") && !strings.HasPrefix(s.URL, "https://") {
+			return fmt.Errorf("the %s option is only supported for http(s) databases", key)
+		}
+		name, v, ok := "Authorization", "Bearer "+value, value != ""
+		if key == "header" {
+			name, v, ok = strings.Cut(value, ":")
+			name = strings.TrimSpace(name)
+		}
+		if !ok || name == "" {
+			return fmt.Errorf("%s option %q is invalid", key, value)
+		}
+		if s.Header == nil {
+			s.Header = make(http.Header)
+		}
+		s.Header.Add(textproto.CanonicalMIMEHeaderKey(name), strings.TrimSpace(v))
+	case "ca", "cert", "key":
+		if !strings.HasPrefix(s.URL, "https://") {
+			return fmt.Errorf("the %s option is only supported for https databases", key)
+		}
+		if value == "" {
+			return fmt.Errorf("the %s option must name a file", key)
+		}
+		switch key {
+		case "ca":
+			s.CAFile = value
+		case "cert":
+			s.CertFile = value
+		case "key":
+			s.KeyFile = value
+		}
+	default:
+		return fmt.Errorf("unknown option %q", key)
+	}
+	return nil
+}
+
+// SourceURLs returns the URLs of sources, as a list in the syntax of
+// ParseSources, but without their options, which may hold credentials.
+func SourceURLs(sources []Source) string {
+	urls := make([]string, len(sources))
+	for i, s := range sources {
+		urls[i] = s.chainURL()
+	}
+	return strings.Join(urls, ",")
+}
+
+// chainURL returns the URLs of s and its fallbacks, separated by "|".
+func (s *Source) chainURL() string {
+	if s.Fallback == nil {
+		return s.URL
+	}
+	return s.URL + "|" + s.Fallback.chainURL()
+}
+
+// NewMultiClient returns a client that reads the union of the
+// databases in sources, each read with opts and its own options.
+//
+// The indexes of the databases are merged, and an entry is read from
+// the first database that has it. With a single source,
+// NewMultiClient is like NewClient.
+func NewMultiClient(sources []Source, opts *Options) (*Client, error) {
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("no vulnerability database")
+	}
+	ms := &mergedSource{}
+	for _, src := range sources {
+		s, err := src.newChain(opts)
+		if err != nil {
+			ms.Close()
+			return nil, err
+		}
+		ms.sources = append(ms.sources, s)
+	}
+	c := &Client{source: ms}
+	if len(ms.sources) == 1 {
+		c.source = ms.sources[0]
+	}
+	if opts != nil && opts.IndexCacheDir != "" && opts.VerifyKey == nil {
+		c.index = newIndexCache(opts.IndexCacheDir, SourceURLs(sources))
+	}
+	return c, nil
+}
+
+// newChain returns the source of s, read with opts and the options
+// of s, which falls back to the sources of its fallbacks.
+func (s *Source) newChain(opts *Options) (source, error) {
+	f := &fallbackSource{}
+	for src := s; src != nil; src = src.Fallback {
+		ss, err := src.newSource(opts)
+		if errors.Is(err, errUnknownSchema) && src.Fallback != nil {
+			// Like a database without an endpoint, a
+			// location without a database is skipped.
+			continue
+		}
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		f.urls = append(f.urls, src.URL)
+		f.sources = append(f.sources, ss)
+	}
+	if s.Fallback == nil {
+		return f.sources[0], nil
+	}
+	f.answers = make([]atomic.Int64, len(f.sources))
+	return f, nil
+}
+
+// newSource returns the source of s, without its fallbacks.
+func (s *Source) newSource(opts *Options) (source, error) {
+	var o Options
+	if opts != nil {
+		o = *opts
+	}
+	// The index is cached for the merged databases.
+	o.IndexCacheDir = ""
+	if s.Timeout > 0 {
+		o.Timeout = s.Timeout
+	}
+	if len(s.Header) > 0 {
+		o.Header = o.Header.Clone()
+		if o.Header == nil {
+			o.Header = make(http.Header)
+		}
+		for name, values := range s.Header {
+			o.Header[name] = append(o.Header[name], values...)
+		}
+	}
+	tlsCfg, err := s.tlsConfig()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", s.URL, err)
+	}
+	if tlsCfg != nil {
+		hc := http.DefaultClient
+		if o.HTTPClient != nil {
+			hc = o.HTTPClient
+		}
+		if o.HTTPClient, err = withTLSConfig(hc, tlsCfg); err != nil {
+			return nil, fmt.Errorf("%s: %w", s.URL, err)
+		}
+	}
+	c, err := NewClient(s.URL, &o)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", s.URL, err)
+	}
+	return c.source, nil
+}
+
+// mergedSource reads the union of several databases. Its indexes
+// are merged from theirs, and other endpoints, such as entries, are
+// read from the first database that has them.
+type mergedSource struct {
+	sources []source
+}
+
+func (ms *mergedSource) Close() error { return closeSources(ms.sources) }
+
+// closeSources closes all of sources.
+func closeSources(sources []source) error {
+	var errs []error
+	for _, s := range sources {
+		errs = append(errs, closeSource(s))
+	}
+	return errors.Join(errs...)
+}
+
+func (ms *mergedSource) get(ctx context.Context, endpoint string) (_ []byte, err error) {
+	defer derrors.Wrap(&err, "get(%s)", endpoint)
+
+	switch endpoint {
+	case dbEndpoint, modulesEndpoint, vulnsEndpoint:
+		idx := newIndex()
+		for _, s := range ms.sources {
+			b, err := s.get(ctx, endpoint)
+			if err != nil {
+				return nil, err
+			}
+			if err := idx.merge(endpoint, b); err != nil {
+				return nil, err
+			}
+		}
+		data, err := idx.raw()
+		if err != nil {
+			return nil, err
+		}
+		return data[endpoint], nil
+	}
+
+	var firstErr error
+	for _, s := range ms.sources {
+		b, err := s.get(ctx, endpoint)
+		if err == nil {
+			return b, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return nil, firstErr
+}
+
+// merge adds the contents b of the endpoint of a database to i.
+// Vulnerabilities already in i take precedence.
+func (i *index) merge(endpoint string, b []byte) error {
+	switch endpoint {
+	case dbEndpoint:
+		var db dbMeta
+		if err := json.Unmarshal(b, &db); err != nil {
+			return err
+		}
+		if db.Modified.After(i.db.Modified) {
+			i.db.Modified = db.Modified
+		}
+	case modulesEndpoint:
+		var modules []*moduleMeta
+		if err := json.Unmarshal(b, &modules); err != nil {
+			return err
+		}
+		for _, m := range modules {
+			mm, ok := i.modules[m.Path]
+			if !ok {
+				i.modules[m.Path] = m
+				continue
+			}
+			seen := make(map[string]bool)
+			for _, v := range mm.Vulns {
+				seen[v.ID] = true
+			}
+			for _, v := range m.Vulns {
+				if !seen[v.ID] {
+					mm.Vulns = append(mm.Vulns, v)
+				}
+			}
+		}
+	case vulnsEndpoint:
+		var vulns []*vulnMeta
+		if err := json.Unmarshal(b, &vulns); err != nil {
+			return err
+		}
+		seen := make(map[string]bool)
+		for _, v := range i.vulns {
+			seen[v.ID] = true
+		}
+		for _, v := range vulns {
+			if !seen[v.ID] {
+				i.vulns = append(i.vulns, v)
+			}
+		}
+	}
+	return nil
+}
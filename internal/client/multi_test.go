@@ -0,0 +1,195 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/vuln/internal/osv"
+)
+
+func TestParseSources(t *testing.T) {
+	env := map[string]string{"TOKEN": "secret"}
+	lookupEnv := func(name string) (string, bool) {
+		v, ok := env[name]
+		return v, ok
+	}
+	for _, tc := range []struct {
+		spec    string
+		want    []Source
+		wantErr bool
+	}{
+		{
+			spec: "https://vuln.go.dev",
+			want: []Source{{URL: "https://vuln.go.dev"}},
+		},
+		{
+			spec: "https://vuln.go.dev, file:///db",
+			want: []Source{{URL: "https://vuln.go.dev"}, {URL: "file:///db"}},
+		},
+		{
+			spec: "https://db.example.com;timeout=30s;header=authorization: Bearer ${TOKEN};header=X-Team:sec",
+			want: []Source{{
+				URL:     "https://db.example.com",
+				Timeout: 30 * time.Second,
+				Header: http.Header{
+					"Authorization": {"Bearer secret"},
+					"X-Team":        {"sec"},
+				},
+			}},
+		},
+		{
+			spec: "https://db.example.com;token=$TOKEN;ca=ca.pem;cert=client.pem;key=client.key",
+			want: []Source{{
+				URL:      "https://db.example.com",
+				Header:   http.Header{"Authorization": {"Bearer secret"}},
+				CAFile:   "ca.pem",
+				CertFile: "client.pem",
+				KeyFile:  "client.key",
+			}},
+		},
+		{
+			spec: "https://mirror.example.com;timeout=5s|https://vuln.go.dev",
+			want: []Source{{
+				URL:      "https://mirror.example.com",
+				Timeout:  5 * time.Second,
+				Fallback: &Source{URL: "https://vuln.go.dev"},
+			}},
+		},
+		{spec: "", wantErr: true},
+		{spec: "https://mirror.example.com|", wantErr: true},
+		{spec: "https://vuln.go.dev,", wantErr: true},
+		{spec: "https://db.example.com;timeout", wantErr: true},
+		{spec: "https://db.example.com;timeout=-1s", wantErr: true},
+		{spec: "https://db.example.com;retries=3", wantErr: true},
+		{spec: "https://db.example.com;header=Bearer", wantErr: true},
+		{spec: "https://db.example.com;header=Authorization:$UNSET", wantErr: true},
+		{spec: "file:///db;header=Authorization:x", wantErr: true},
+		{spec: "https://db.example.com;token=", wantErr: true},
+		{spec: "http://db.example.com;ca=ca.pem", wantErr: true},
+		{spec: "https://db.example.com;cert=client.pem", wantErr: true},
+	} {
+		got, err := ParseSources(tc.spec, lookupEnv)
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("ParseSources(%q) succeeded, want error", tc.spec)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseSources(%q): %v", tc.spec, err)
+			continue
+		}
+		if diff := cmp.Diff(tc.want, got); diff != "" {
+			t.Errorf("ParseSources(%q) mismatch (-want +got):\n%s", tc.spec, diff)
+		}
+	}
+}
+
+func TestMultiClient(t *testing.T) {
+	// A private database, served with authentication.
+	fs := http.FileServer(http.Dir(testVulndb))
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		fs.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	// A local database, as a directory of entries.
+	dir := t.TempDir()
+	for _, e := range []*osv.Entry{
+		testEntry("GO-2024-0001", "example.com/private", "1.1.0"),
+		testEntry("GO-2024-0002", "stdlib", "1.99.0"),
+	} {
+		b, err := json.Marshal(e)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, e.ID+".json"), b, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sources, err := ParseSources(srv.URL+";header=Authorization:Bearer $TOKEN,"+localURL(dir), func(name string) (string, bool) {
+		return "secret", name == "TOKEN"
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewMultiClient(sources, &Options{HTTPClient: srv.Client()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	modified, err := c.LastModifiedTime(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC); !modified.Equal(want) {
+		t.Errorf("LastModifiedTime() = %s, want %s", modified, want)
+	}
+
+	resps, err := c.ByModules(ctx, []*ModuleRequest{{Path: "stdlib"}, {Path: "example.com/private"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range [][]string{
+		{"GO-2021-0159", "GO-2021-0240", "GO-2021-0264", "GO-2022-0229", "GO-2022-0273", "GO-2024-0002"},
+		{"GO-2024-0001"},
+	} {
+		var got []string
+		for _, e := range resps[i].Entries {
+			got = append(got, e.ID)
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("ByModules(%s) = %v, want %v", resps[i].Path, got, want)
+		}
+	}
+
+	entries, err := c.ByIDs(ctx, []string{"GO-2024-0001", "GO-2022-0463"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].ID != "GO-2024-0001" || entries[1].ID != "GO-2022-0463" {
+		t.Errorf("ByIDs() returned %d unexpected entries", len(entries))
+	}
+}
+
+func TestMergedSourceErrors(t *testing.T) {
+	var ms mergedSource
+	for _, e := range []*osv.Entry{
+		testEntry("GO-2024-0001", "example.com/a", "1.0.0"),
+		testEntry("GO-2024-0002", "example.com/b", "1.0.0"),
+	} {
+		s, err := newInMemorySource([]*osv.Entry{e})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ms.sources = append(ms.sources, s)
+	}
+	ctx := context.Background()
+	endpoint := entryEndpoint("GO-2024-0003")
+	if _, err := ms.get(ctx, endpoint); err == nil || !strings.HasPrefix(err.Error(), "get("+endpoint+")") {
+		t.Errorf("get(%s): got error %v, want it to name the endpoint", endpoint, err)
+	}
+	c := &Client{source: &ms}
+	if _, err := c.ByIDs(ctx, []string{"GO-2024-0003"}); err == nil || !strings.HasPrefix(err.Error(), "ByIDs([GO-2024-0003])") {
+		t.Errorf("ByIDs(GO-2024-0003): got error %v, want it to name the IDs", err)
+	}
+}
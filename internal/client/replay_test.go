@@ -0,0 +1,100 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/vuln/internal/test"
+)
+
+var record = flag.Bool("record", false, "record the responses of https://vuln.go.dev for TestRecordedVulnDB")
+
+// recordedVulnDB holds the responses of https://vuln.go.dev
+// to the requests of TestRecordedVulnDB.
+var recordedVulnDB = filepath.Join("testdata", "replay", "vuln.go.dev.json")
+
+func TestReplayClient(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "replay.json")
+	srv := newTestServer(testVulndb)
+	reqs := []*ModuleRequest{{Path: "stdlib", Version: "go1.18"}, {Path: "golang.org/x/crypto"}}
+
+	var want []*ModuleResponse
+	t.Run("record", func(t *testing.T) {
+		c, err := NewClient(srv.URL, &Options{HTTPClient: test.ReplayClient(t, file, true)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want, err = c.ByModules(context.Background(), reqs); err != nil {
+			t.Fatal(err)
+		}
+	})
+	// Replaying makes no request to the database.
+	srv.Close()
+	t.Run("replay", func(t *testing.T) {
+		hc := test.ReplayClient(t, file, false)
+		c, err := NewClient(srv.URL, &Options{HTTPClient: hc})
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := c.ByModules(context.Background(), reqs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("ByModules() mismatch (-want +got):\n%s", diff)
+		}
+		// Requests that were not recorded fail.
+		if resp, err := hc.Get(srv.URL + "/ID/GO-2022-0463.json.gz"); err == nil {
+			resp.Body.Close()
+			t.Error("request that was not recorded succeeded, want error")
+		}
+	})
+}
+
+// TestRecordedVulnDB checks the client against the recorded responses
+// of https://vuln.go.dev. Record them again with the -record flag when
+// the format of the database changes.
+func TestRecordedVulnDB(t *testing.T) {
+	if _, err := os.Stat(recordedVulnDB); errors.Is(err, fs.ErrNotExist) && !*record {
+		t.Skipf("%s has not been recorded, run with -record", recordedVulnDB)
+	}
+	c, err := NewClient("https://vuln.go.dev", &Options{HTTPClient: test.ReplayClient(t, recordedVulnDB, *record)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	resps, err := c.ByModules(ctx, []*ModuleRequest{
+		{Path: "golang.org/x/text", Version: "v0.3.0"},
+		{Path: "github.com/tidwall/gjson", Version: "v1.6.5"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The database only grows, so these vulnerabilities
+	// remain in new recordings.
+	for i, want := range [][]string{
+		{"GO-2020-0015", "GO-2021-0113"},
+		{"GO-2021-0054", "GO-2021-0265"},
+	} {
+		var got []string
+		for _, e := range resps[i].Entries {
+			got = append(got, e.ID)
+		}
+		for _, id := range want {
+			if !slices.Contains(got, id) {
+				t.Errorf("ByModules(%s) = %v, want %s among them", resps[i].Path, got, id)
+			}
+		}
+	}
+}
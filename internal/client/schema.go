@@ -19,6 +19,7 @@ const (
 var (
 	dbEndpoint      = path.Join(indexDir, "db")
 	modulesEndpoint = path.Join(indexDir, "modules")
+	vulnsEndpoint   = path.Join(indexDir, "vulns")
 )
 
 func entryEndpoint(id string) string {
@@ -57,6 +58,19 @@ type moduleVuln struct {
 	Fixed string `json:"fixed,omitempty"`
 }
 
+// vulnMeta contains metadata about a vulnerability in the database.
+//
+// Found in the "index/vulns" endpoint of the vulnerability database.
+type vulnMeta struct {
+	// ID is a unique identifier for the vulnerability.
+	ID string `json:"id"`
+	// Modified is the time the vuln was last modified.
+	Modified time.Time `json:"modified"`
+	// Aliases is a list of IDs for the same vulnerability in other
+	// databases.
+	Aliases []string `json:"aliases,omitempty"`
+}
+
 // modulesIndex represents an in-memory modules index.
 type modulesIndex map[string]*moduleMeta
 
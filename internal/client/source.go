@@ -14,6 +14,7 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"time"
 
 	"golang.org/x/vuln/internal/derrors"
 	"golang.org/x/vuln/internal/osv"
@@ -33,31 +34,96 @@ func newHTTPSource(url string, opts *Options) *httpSource {
 	if opts != nil && opts.HTTPClient != nil {
 		c = opts.HTTPClient
 	}
-	return &httpSource{url: url, c: c}
+	hs := &httpSource{
+		url:   url,
+		c:     c,
+		cache: newResponseCache(url, opts),
+		retry: newRetryPolicy(),
+	}
+	if opts != nil {
+		hs.timeout = opts.Timeout
+		hs.header = opts.Header
+	}
+	return hs
 }
 
 // httpSource reads a vulnerability database from an http(s) source.
+//
+// Requests are made conditional on the validators (ETag and
+// Last-Modified) of previously cached responses, and transient
+// failures are retried with exponential backoff.
 type httpSource struct {
-	url string
-	c   *http.Client
+	url     string
+	c       *http.Client
+	cache   responseCache
+	retry   *retryPolicy
+	timeout time.Duration // of each request, if positive
+	header  http.Header   // sent with each request
 }
 
 func (hs *httpSource) get(ctx context.Context, endpoint string) (_ []byte, err error) {
 	derrors.Wrap(&err, "get(%s)", endpoint)
 
+	for attempt := 0; ; attempt++ {
+		b, err := hs.fetch(ctx, endpoint)
+		te, ok := asTransient(err)
+		if !ok || attempt+1 >= hs.retry.maxAttempts || !hs.retry.take() {
+			return b, err
+		}
+		t := time.NewTimer(hs.retry.delay(attempt, te))
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return nil, ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// fetch makes a single request for endpoint. Errors that may
+// go away on retry are reported as a *transientError.
+func (hs *httpSource) fetch(ctx context.Context, endpoint string) ([]byte, error) {
 	method := http.MethodGet
 	reqURL := fmt.Sprintf("%s/%s", hs.url, endpoint+".json.gz")
-	req, err := http.NewRequestWithContext(ctx, method, reqURL, nil)
+	reqCtx := ctx
+	if hs.timeout > 0 {
+		var cancel context.CancelFunc
+		reqCtx, cancel = context.WithTimeout(ctx, hs.timeout)
+		defer cancel()
+	}
+	req, err := http.NewRequestWithContext(reqCtx, method, reqURL, nil)
 	if err != nil {
 		return nil, err
 	}
+	hs.addHeader(req)
+	cached, ok := hs.cache.load(endpoint)
+	if ok {
+		if cached.ETag != "" {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
 	resp, err := hs.c.Do(req)
 	if err != nil {
-		return nil, err
+		if ctx.Err() != nil || !isTransientError(err) {
+			return nil, err
+		}
+		return nil, &transientError{err: err}
 	}
 	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("HTTP %s %s returned unexpected status: %s", method, reqURL, resp.Status)
+	switch {
+	case resp.StatusCode == http.StatusNotModified && ok:
+		return cached.Data, nil
+	case resp.StatusCode == http.StatusOK:
+	case isTransientStatus(resp.StatusCode):
+		return nil, &transientError{
+			err:        unexpectedStatus(method, reqURL, resp),
+			retryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+		}
+	default:
+		return nil, unexpectedStatus(method, reqURL, resp)
 	}
 
 	// Uncompress the result.
@@ -67,7 +133,24 @@ func (hs *httpSource) get(ctx context.Context, endpoint string) (_ []byte, err e
 	}
 	defer r.Close()
 
-	return io.ReadAll(r)
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	etag, lastModified := resp.Header.Get("ETag"), resp.Header.Get("Last-Modified")
+	if etag != "" || lastModified != "" {
+		hs.cache.store(endpoint, &cachedResponse{ETag: etag, LastModified: lastModified, Data: b})
+	}
+	return b, nil
+}
+
+// addHeader adds the headers of hs to req.
+func (hs *httpSource) addHeader(req *http.Request) {
+	for name, values := range hs.header {
+		for _, v := range values {
+			req.Header.Add(name, v)
+		}
+	}
 }
 
 func newLocalSource(dir string) *localSource {
@@ -5,9 +5,17 @@
 package client
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestGet(t *testing.T) {
@@ -77,3 +85,267 @@ func testAllSourceTypes(t *testing.T, test func(t *testing.T, s source)) {
 		test(t, hs)
 	})
 }
+
+// gzipped returns the gzip compression of s.
+func gzipped(t *testing.T, s string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write([]byte(s)); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func newTestHTTPSource(t *testing.T, h http.HandlerFunc, opts *Options) *httpSource {
+	t.Helper()
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+	if opts == nil {
+		opts = &Options{}
+	}
+	opts.HTTPClient = srv.Client()
+	hs := newHTTPSource(srv.URL, opts)
+	hs.retry.baseDelay = time.Millisecond
+	hs.retry.maxDelay = 10 * time.Millisecond
+	return hs
+}
+
+func TestHTTPSourceRetry(t *testing.T) {
+	const want = `{"modified":"2023-01-01T00:00:00Z"}`
+	for _, tc := range []struct {
+		name     string
+		failures int
+		status   int
+		wantErr  bool
+		wantReqs int
+	}{
+		{name: "ok", failures: 0, status: http.StatusServiceUnavailable, wantReqs: 1},
+		{name: "transient", failures: 2, status: http.StatusServiceUnavailable, wantReqs: 3},
+		{name: "too many", failures: 10, status: http.StatusBadGateway, wantErr: true, wantReqs: defaultMaxAttempts},
+		{name: "permanent", failures: 1, status: http.StatusNotFound, wantErr: true, wantReqs: 1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var reqs int
+			hs := newTestHTTPSource(t, func(w http.ResponseWriter, r *http.Request) {
+				reqs++
+				if reqs <= tc.failures {
+					w.Header().Set("Retry-After", "0")
+					w.WriteHeader(tc.status)
+					return
+				}
+				w.Write(gzipped(t, want))
+			}, nil)
+			got, err := hs.get(context.Background(), "index/db")
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("get() error = %v, want error %t", err, tc.wantErr)
+			}
+			if !tc.wantErr && string(got) != want {
+				t.Errorf("get() = %s, want %s", got, want)
+			}
+			if reqs != tc.wantReqs {
+				t.Errorf("got %d requests, want %d", reqs, tc.wantReqs)
+			}
+		})
+	}
+}
+
+// countingTransport counts the requests it makes.
+type countingTransport struct {
+	base http.RoundTripper
+	n    int
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.n++
+	return t.base.RoundTrip(req)
+}
+
+func TestHTTPSourceRetryErrors(t *testing.T) {
+	ok := func(w http.ResponseWriter, r *http.Request) {}
+	for _, tc := range []struct {
+		name string
+		// source returns the URL of the database and the
+		// client to request it with.
+		source   func(t *testing.T) (string, *http.Client)
+		canceled bool
+		wantReqs int
+	}{
+		{
+			name: "canceled",
+			source: func(t *testing.T) (string, *http.Client) {
+				srv := httptest.NewServer(http.HandlerFunc(ok))
+				t.Cleanup(srv.Close)
+				return srv.URL, srv.Client()
+			},
+			canceled: true,
+			wantReqs: 1,
+		},
+		{
+			name: "unknown authority",
+			source: func(t *testing.T) (string, *http.Client) {
+				srv := httptest.NewTLSServer(http.HandlerFunc(ok))
+				t.Cleanup(srv.Close)
+				srv.Config.ErrorLog = log.New(io.Discard, "", 0)
+				return srv.URL, &http.Client{Transport: &http.Transport{}}
+			},
+			wantReqs: 1,
+		},
+		{
+			name: "not tls",
+			source: func(t *testing.T) (string, *http.Client) {
+				srv := httptest.NewServer(http.HandlerFunc(ok))
+				t.Cleanup(srv.Close)
+				return strings.Replace(srv.URL, "http:", "https:", 1), srv.Client()
+			},
+			wantReqs: 1,
+		},
+		{
+			name: "unsupported scheme",
+			source: func(t *testing.T) (string, *http.Client) {
+				return "ftp://vuln.go.dev", &http.Client{Transport: &http.Transport{}}
+			},
+			wantReqs: 1,
+		},
+		{
+			name: "no host",
+			source: func(t *testing.T) (string, *http.Client) {
+				return "https://", &http.Client{Transport: &http.Transport{}}
+			},
+			wantReqs: 1,
+		},
+		{
+			// Transient, for comparison.
+			name: "refused",
+			source: func(t *testing.T) (string, *http.Client) {
+				srv := httptest.NewServer(http.HandlerFunc(ok))
+				srv.Close()
+				return srv.URL, srv.Client()
+			},
+			wantReqs: defaultMaxAttempts,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			u, c := tc.source(t)
+			tr := &countingTransport{base: c.Transport}
+			c.Transport = tr
+			hs := newHTTPSource(u, &Options{HTTPClient: c})
+			hs.retry.baseDelay = time.Millisecond
+			hs.retry.maxDelay = 10 * time.Millisecond
+			ctx := context.Background()
+			if tc.canceled {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithCancel(ctx)
+				cancel()
+			}
+			if _, err := hs.get(ctx, "index/db"); err == nil {
+				t.Fatal("get() succeeded unexpectedly")
+			}
+			if tr.n != tc.wantReqs {
+				t.Errorf("got %d requests, want %d", tr.n, tc.wantReqs)
+			}
+		})
+	}
+}
+
+func TestHTTPSourceRetryBudget(t *testing.T) {
+	var reqs int
+	hs := newTestHTTPSource(t, func(w http.ResponseWriter, r *http.Request) {
+		reqs++
+		w.WriteHeader(http.StatusTooManyRequests)
+	}, nil)
+	hs.retry.budget = 1
+	for i := 0; i < 2; i++ {
+		if _, err := hs.get(context.Background(), "index/db"); err == nil {
+			t.Fatal("get() succeeded unexpectedly")
+		}
+	}
+	// One retry for the first request, none for the second.
+	if want := 3; reqs != want {
+		t.Errorf("got %d requests, want %d", reqs, want)
+	}
+}
+
+func TestHTTPSourceConditional(t *testing.T) {
+	const (
+		etag = `"v1"`
+		want = `{"modified":"2023-01-01T00:00:00Z"}`
+	)
+	for _, tc := range []struct {
+		name string
+		opts *Options
+	}{
+		{name: "memory"},
+		{name: "dir", opts: &Options{CacheDir: t.TempDir()}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var full, notModified int
+			h := func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("If-None-Match") == etag {
+					notModified++
+					w.WriteHeader(http.StatusNotModified)
+					return
+				}
+				full++
+				w.Header().Set("ETag", etag)
+				w.Write(gzipped(t, want))
+			}
+			hs := newTestHTTPSource(t, h, tc.opts)
+			for i := 0; i < 2; i++ {
+				got, err := hs.get(context.Background(), "index/db")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(got) != want {
+					t.Errorf("get() = %s, want %s", got, want)
+				}
+			}
+			if full != 1 || notModified != 1 {
+				t.Errorf("got %d full and %d not modified responses, want 1 and 1", full, notModified)
+			}
+			if tc.opts == nil {
+				return
+			}
+			// A fresh source of the same database sharing the
+			// cache directory revalidates instead of refetching.
+			hs = newHTTPSource(hs.url, &Options{HTTPClient: hs.c, CacheDir: tc.opts.CacheDir})
+			if _, err := hs.get(context.Background(), "index/db"); err != nil {
+				t.Fatal(err)
+			}
+			if full != 1 || notModified != 2 {
+				t.Errorf("got %d full and %d not modified responses, want 1 and 2", full, notModified)
+			}
+			// A source of another database does not
+			// use the responses of the first one.
+			hs = newTestHTTPSource(t, h, &Options{CacheDir: tc.opts.CacheDir})
+			if _, err := hs.get(context.Background(), "index/db"); err != nil {
+				t.Fatal(err)
+			}
+			if full != 2 || notModified != 2 {
+				t.Errorf("got %d full and %d not modified responses, want 2 and 2", full, notModified)
+			}
+		})
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		in   string
+		want time.Duration
+	}{
+		{"", 0},
+		{"5", 5 * time.Second},
+		{"-1", 0},
+		{"soon", 0},
+		{now.Add(time.Minute).Format(http.TimeFormat), time.Minute},
+		{now.Add(-time.Minute).Format(http.TimeFormat), 0},
+	} {
+		if got := parseRetryAfter(tc.in, now); got != tc.want {
+			t.Errorf("parseRetryAfter(%q) = %v, want %v", tc.in, got, tc.want)
+		}
+	}
+}
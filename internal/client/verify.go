@@ -0,0 +1,170 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/vuln/internal/derrors"
+)
+
+// signedEndpoints are the index endpoints whose contents are covered
+// by a detached signature when verification is enabled. Each entry
+// in the ID directory is also covered by its own signature, which is
+// checked when the entry is read.
+var signedEndpoints = []string{dbEndpoint, modulesEndpoint}
+
+// signatureEndpoint returns the endpoint of the detached
+// signature for endpoint. Like every other endpoint, it
+// is a JSON document (see signatureFile).
+func signatureEndpoint(endpoint string) string {
+	return endpoint + ".sig"
+}
+
+// signatureFile is the contents of a signature endpoint.
+type signatureFile struct {
+	Signatures []signature `json:"signatures"`
+}
+
+// signature is an Ed25519 signature of the uncompressed
+// contents of an endpoint.
+type signature struct {
+	// KeyID is the hex-encoded SHA-256 digest of the
+	// public key. Signatures from other keys are ignored.
+	KeyID string `json:"keyid"`
+	// Sig is the base64-encoded signature.
+	Sig string `json:"sig"`
+}
+
+// ErrVerification is returned when the signature of a
+// database index or entry is missing or invalid.
+var ErrVerification = errors.New("database signature verification failed")
+
+// KeyID returns the identifier used for key in signature files.
+func KeyID(key ed25519.PublicKey) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:])
+}
+
+// ParsePublicKey parses an Ed25519 public key, which is either
+// PEM-encoded in PKIX form or the base64 encoding of the raw key.
+func ParsePublicKey(b []byte) (ed25519.PublicKey, error) {
+	if block, _ := pem.Decode(b); block != nil {
+		k, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		key, ok := k.(ed25519.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("public key is a %T, want Ed25519", k)
+		}
+		return key, nil
+	}
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(b)))
+	if err != nil {
+		return nil, fmt.Errorf("public key is neither PEM nor base64: %v", err)
+	}
+	if len(raw) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("public key has %d bytes, want %d", len(raw), ed25519.PublicKeySize)
+	}
+	return ed25519.PublicKey(raw), nil
+}
+
+// newVerifiedSource returns a source that serves the signed endpoints
+// and the entries of s only after checking their signatures against
+// key. The verified contents of the signed endpoints are kept so that
+// later reads cannot observe different data.
+//
+// If warn is nil, a verification failure is an error. Otherwise warn
+// is called with the failure and the unverified contents are used.
+// As entries may be read concurrently, so may warn be called.
+func newVerifiedSource(ctx context.Context, s source, key ed25519.PublicKey, warn func(error)) (*verifiedSource, error) {
+	vs := &verifiedSource{source: s, key: key, warn: warn, verified: make(map[string][]byte)}
+	for _, endpoint := range signedEndpoints {
+		b, err := s.get(ctx, endpoint)
+		if err != nil {
+			return nil, err
+		}
+		if err := verifyEndpoint(ctx, s, endpoint, b, key); err != nil {
+			if warn == nil {
+				return nil, err
+			}
+			warn(err)
+		}
+		vs.verified[endpoint] = b
+	}
+	return vs, nil
+}
+
+// verifiedSource is a source whose signed endpoints have been
+// verified, and whose entries are verified when read.
+type verifiedSource struct {
+	source
+	key  ed25519.PublicKey
+	warn func(error)
+	// verified holds the contents of signed endpoints.
+	// It is not modified after construction.
+	verified map[string][]byte
+}
+
+func (vs *verifiedSource) Close() error { return closeSource(vs.source) }
+
+func (vs *verifiedSource) get(ctx context.Context, endpoint string) ([]byte, error) {
+	if b, ok := vs.verified[endpoint]; ok {
+		return b, nil
+	}
+	b, err := vs.source.get(ctx, endpoint)
+	if err != nil || !strings.HasPrefix(endpoint, idDir+"/") {
+		return b, err
+	}
+	if err := verifyEndpoint(ctx, vs.source, endpoint, b, vs.key); err != nil {
+		if vs.warn == nil {
+			return nil, err
+		}
+		vs.warn(err)
+	}
+	return b, nil
+}
+
+// verifyEndpoint checks that data, the contents of endpoint,
+// has a valid signature by key in s.
+func verifyEndpoint(ctx context.Context, s source, endpoint string, data []byte, key ed25519.PublicKey) (err error) {
+	defer derrors.Wrap(&err, "verifying %s", endpoint)
+
+	b, err := s.get(ctx, signatureEndpoint(endpoint))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrVerification, err)
+	}
+	var sf signatureFile
+	if err := json.Unmarshal(b, &sf); err != nil {
+		return fmt.Errorf("%w: %v", ErrVerification, err)
+	}
+	id := KeyID(key)
+	found := false
+	for _, sig := range sf.Signatures {
+		if sig.KeyID != id {
+			continue
+		}
+		found = true
+		raw, err := base64.StdEncoding.DecodeString(sig.Sig)
+		if err == nil && ed25519.Verify(key, data, raw) {
+			return nil
+		}
+	}
+	if found {
+		return fmt.Errorf("%w: invalid signature by key %s", ErrVerification, id)
+	}
+	return fmt.Errorf("%w: no signature by key %s", ErrVerification, id)
+}
@@ -0,0 +1,214 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func signatureJSON(t *testing.T, priv ed25519.PrivateKey, data []byte) []byte {
+	t.Helper()
+	pub := priv.Public().(ed25519.PublicKey)
+	b, err := json.Marshal(signatureFile{Signatures: []signature{{
+		KeyID: KeyID(pub),
+		Sig:   base64.StdEncoding.EncodeToString(ed25519.Sign(priv, data)),
+	}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// testEntryEndpoint is the endpoint of an entry of the v1 test database.
+var testEntryEndpoint = entryEndpoint("GO-2021-0068")
+
+// signedSource returns an in-memory source with the v1 test indexes,
+// one of its entries, and their signatures by priv.
+func signedSource(t *testing.T, priv ed25519.PrivateKey) *inMemorySource {
+	t.Helper()
+	data := make(map[string][]byte)
+	for _, endpoint := range append(signedEndpoints, testEntryEndpoint) {
+		b, err := os.ReadFile(filepath.Join(testVulndb, filepath.FromSlash(endpoint)+".json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		data[endpoint] = b
+		data[signatureEndpoint(endpoint)] = signatureJSON(t, priv, b)
+	}
+	return &inMemorySource{data: data}
+}
+
+func TestVerifiedSource(t *testing.T) {
+	ctx := context.Background()
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, otherPriv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		s := signedSource(t, priv)
+		vs, err := newVerifiedSource(ctx, s, pub, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Later changes to the underlying source are not observed.
+		want := string(s.data[dbEndpoint])
+		s.data[dbEndpoint] = []byte(`{"modified":"2000-01-01T00:00:00Z"}`)
+		got, err := vs.get(ctx, dbEndpoint)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != want {
+			t.Errorf("get(%s) = %s, want %s", dbEndpoint, got, want)
+		}
+	})
+
+	for _, tc := range []struct {
+		name   string
+		modify func(s *inMemorySource)
+	}{
+		{
+			name: "tampered index",
+			modify: func(s *inMemorySource) {
+				s.data[modulesEndpoint] = append(s.data[modulesEndpoint], ' ')
+			},
+		},
+		{
+			name: "missing signature",
+			modify: func(s *inMemorySource) {
+				delete(s.data, signatureEndpoint(dbEndpoint))
+			},
+		},
+		{
+			name: "wrong key",
+			modify: func(s *inMemorySource) {
+				s.data[signatureEndpoint(dbEndpoint)] = signatureJSON(t, otherPriv, s.data[dbEndpoint])
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s := signedSource(t, priv)
+			tc.modify(s)
+			if _, err := newVerifiedSource(ctx, s, pub, nil); !errors.Is(err, ErrVerification) {
+				t.Errorf("newVerifiedSource() = %v, want %v", err, ErrVerification)
+			}
+
+			var warnings []error
+			warn := func(err error) { warnings = append(warnings, err) }
+			if _, err := newVerifiedSource(ctx, s, pub, warn); err != nil {
+				t.Fatalf("newVerifiedSource() in warn mode = %v, want nil", err)
+			}
+			if len(warnings) != 1 || !errors.Is(warnings[0], ErrVerification) {
+				t.Errorf("got warnings %v, want one %v", warnings, ErrVerification)
+			}
+		})
+	}
+}
+
+func TestVerifiedSourceEntries(t *testing.T) {
+	ctx := context.Background()
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := signedSource(t, priv)
+	vs, err := newVerifiedSource(ctx, s, pub, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := vs.get(ctx, testEntryEndpoint); err != nil {
+		t.Fatalf("get(%s) = %v", testEntryEndpoint, err)
+	}
+
+	// A mirror that alters an entry is caught, even though
+	// the indexes are intact.
+	want := string(s.data[testEntryEndpoint])
+	s.data[testEntryEndpoint] = []byte(`{"id":"GO-2021-0068","withdrawn":"2021-01-01T00:00:00Z"}`)
+	if _, err := vs.get(ctx, testEntryEndpoint); !errors.Is(err, ErrVerification) {
+		t.Errorf("get(altered entry) = %v, want %v", err, ErrVerification)
+	}
+
+	var warnings []error
+	vs, err = newVerifiedSource(ctx, s, pub, func(err error) { warnings = append(warnings, err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := vs.get(ctx, testEntryEndpoint)
+	if err != nil {
+		t.Fatalf("get(altered entry) in warn mode = %v, want nil", err)
+	}
+	if string(got) == want || len(warnings) != 1 || !errors.Is(warnings[0], ErrVerification) {
+		t.Errorf("got warnings %v, want one %v with the altered entry", warnings, ErrVerification)
+	}
+}
+
+func TestNewClientVerify(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	for _, d := range []string{indexDir, idDir} {
+		if err := os.Mkdir(filepath.Join(dir, d), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for endpoint, b := range signedSource(t, priv).data {
+		if err := os.WriteFile(filepath.Join(dir, filepath.FromSlash(endpoint)+".json"), b, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	c, err := NewClient(localURL(dir), &Options{VerifyKey: pub})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.LastModifiedTime(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// The test database has no signatures.
+	if _, err := NewClient(testVulndbFileURL, &Options{VerifyKey: pub}); !errors.Is(err, ErrVerification) {
+		t.Errorf("NewClient() = %v, want %v", err, ErrVerification)
+	}
+}
+
+func TestParsePublicKey(t *testing.T) {
+	pub, _, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, in := range map[string][]byte{
+		"pem":    pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}),
+		"base64": []byte(base64.StdEncoding.EncodeToString(pub) + "\n"),
+	} {
+		got, err := ParsePublicKey(in)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !got.Equal(pub) {
+			t.Errorf("%s: got key %x, want %x", name, got, pub)
+		}
+	}
+	if _, err := ParsePublicKey([]byte("bm90IGEga2V5")); err == nil {
+		t.Error("ParsePublicKey(short key) succeeded unexpectedly")
+	}
+}
@@ -0,0 +1,227 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+
+	"golang.org/x/vuln/internal/derrors"
+	"golang.org/x/vuln/internal/osv"
+	"golang.org/x/vuln/internal/web"
+)
+
+// isZipURL reports whether uri refers to a zip archive
+// of a vulnerability database. The extension is matched
+// regardless of case, as file names are on Windows.
+func isZipURL(uri *url.URL) bool {
+	return strings.HasSuffix(strings.ToLower(uri.Path), ".zip")
+}
+
+// newZipClient returns a client that reads a database following
+// the v1 schema from the zip archive at uri, which may be a local
+// file or an http(s) URL.
+//
+// Remote archives are downloaded in full; entries of the archive are
+// only decompressed when requested. Each entry's checksum is verified
+// when it is read. If uri has a fragment of the form "sha256=<hex>",
+// the SHA-256 digest of the whole archive must also match it.
+func newZipClient(uri *url.URL, opts *Options) (_ *Client, err error) {
+	defer derrors.Wrap(&err, "zip database %s", uri.Redacted())
+
+	want, err := zipDigest(uri)
+	if err != nil {
+		return nil, err
+	}
+	u := *uri
+	u.Fragment = ""
+
+	var (
+		r    io.ReaderAt
+		size int64
+		f    *os.File
+	)
+	switch u.Scheme {
+	case "http", "https":
+		b, err := downloadZip(&u, opts)
+		if err != nil {
+			return nil, err
+		}
+		r, size = bytes.NewReader(b), int64(len(b))
+	case "file":
+		name, err := web.URLToFilePath(&u)
+		if err != nil {
+			return nil, err
+		}
+		// The file stays open until the client is closed
+		// so that entries can be read lazily.
+		if f, err = os.Open(name); err != nil {
+			return nil, err
+		}
+		defer func() {
+			if err != nil {
+				f.Close()
+			}
+		}()
+		fi, err := f.Stat()
+		if err != nil {
+			return nil, err
+		}
+		r, size = f, fi.Size()
+	default:
+		return nil, fmt.Errorf("unsupported scheme %q", u.Scheme)
+	}
+
+	if want != "" {
+		h := sha256.New()
+		if _, err := io.Copy(h, io.NewSectionReader(r, 0, size)); err != nil {
+			return nil, err
+		}
+		if got := hex.EncodeToString(h.Sum(nil)); got != want {
+			return nil, fmt.Errorf("sha256 digest is %s, want %s", got, want)
+		}
+	}
+
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+	fsys, err := zipRoot(zr)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{source: &zipSource{localSource: localSource{fs: fsys}, file: f}}, nil
+}
+
+// zipSource reads a vulnerability database from a zip archive.
+type zipSource struct {
+	localSource
+	// file is the local archive, if any, which is
+	// read until the source is closed.
+	file *os.File
+}
+
+func (zs *zipSource) Close() error {
+	if zs.file == nil {
+		return nil
+	}
+	return zs.file.Close()
+}
+
+// zipDigest returns the expected SHA-256 digest of the archive
+// encoded in the fragment of uri, or "" if there is none.
+func zipDigest(uri *url.URL) (string, error) {
+	if uri.Fragment == "" {
+		return "", nil
+	}
+	d, ok := strings.CutPrefix(uri.Fragment, "sha256=")
+	if b, err := hex.DecodeString(d); !ok || err != nil || len(b) != sha256.Size {
+		return "", fmt.Errorf("invalid fragment %q: want sha256=<hex digest>", uri.Fragment)
+	}
+	return strings.ToLower(d), nil
+}
+
+func downloadZip(uri *url.URL, opts *Options) ([]byte, error) {
+	c := http.DefaultClient
+	if opts != nil && opts.HTTPClient != nil {
+		c = opts.HTTPClient
+	}
+	resp, err := c.Get(uri.String())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, unexpectedStatus(http.MethodGet, uri.Redacted(), resp)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// zipRoot returns the directory of zr that contains the database,
+// which is either the root of the archive or a single top-level
+// directory. It checks that the database follows the v1 schema.
+func zipRoot(zr *zip.Reader) (fs.FS, error) {
+	modules := modulesEndpoint + ".json"
+	root := ""
+	for _, f := range zr.File {
+		if f.Name == modules {
+			root = "."
+			break
+		}
+		if dir, ok := strings.CutSuffix(f.Name, "/"+modules); ok && !strings.Contains(dir, "/") {
+			root = dir
+			break
+		}
+	}
+	if root == "" {
+		return nil, errUnknownSchema
+	}
+	fsys, err := fs.Sub(zr, root)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := fs.Stat(fsys, dbEndpoint+".json"); err != nil {
+		return nil, fmt.Errorf("missing %s: %w", dbEndpoint, errUnknownSchema)
+	}
+	return fsys, nil
+}
+
+// WriteZip writes a database following the v1 schema, holding
+// entries, to w as a zip archive that can be read back with -db.
+//
+// The archive only depends on entries: its files are sorted by name
+// and have the modification time of the database, so that the archive
+// of the same entries can be compared byte for byte.
+func WriteZip(w io.Writer, entries []*osv.Entry) (err error) {
+	defer derrors.Wrap(&err, "writing zip database")
+
+	data, err := indexFromEntries(entries)
+	if err != nil {
+		return err
+	}
+	var db dbMeta
+	if err := json.Unmarshal(data[dbEndpoint], &db); err != nil {
+		return err
+	}
+	for _, e := range entries {
+		b, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		data[entryEndpoint(e.ID)] = b
+	}
+	endpoints := make([]string, 0, len(data))
+	for endpoint := range data {
+		endpoints = append(endpoints, endpoint)
+	}
+	sort.Strings(endpoints)
+
+	zw := zip.NewWriter(w)
+	for _, endpoint := range endpoints {
+		f, err := zw.CreateHeader(&zip.FileHeader{
+			Name:     endpoint + ".json",
+			Method:   zip.Deflate,
+			Modified: db.Modified,
+		})
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(data[endpoint]); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
@@ -0,0 +1,254 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/vuln/internal/osv"
+)
+
+// writeTestZip writes the database in dir to a zip archive, with all
+// names under prefix, and returns the archive's path.
+func writeTestZip(t *testing.T, dir, prefix string) string {
+	t.Helper()
+	name := filepath.Join(t.TempDir(), "db.zip")
+	f, err := os.Create(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := zip.NewWriter(f)
+	err = fs.WalkDir(os.DirFS(dir), ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(p, ".json") {
+			return err
+		}
+		b, err := os.ReadFile(filepath.Join(dir, p))
+		if err != nil {
+			return err
+		}
+		zf, err := w.Create(path.Join(prefix, p))
+		if err != nil {
+			return err
+		}
+		_, err = zf.Write(b)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return name
+}
+
+func fileDigest(t *testing.T, name string) string {
+	t.Helper()
+	b, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+func TestZipClient(t *testing.T) {
+	ctx := context.Background()
+	check := func(t *testing.T, c *Client) {
+		t.Helper()
+		got, err := c.get(ctx, entryEndpoint("GO-2021-0068"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := os.ReadFile(filepath.Join(testVulndb, idDir, "GO-2021-0068.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != string(want) {
+			t.Errorf("get() = %s, want %s", got, want)
+		}
+	}
+
+	for _, prefix := range []string{"", "vulndb"} {
+		name := writeTestZip(t, testVulndb, prefix)
+		src := localURL(name)
+
+		t.Run("local/"+prefix, func(t *testing.T) {
+			c, err := NewClient(src, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			check(t, c)
+		})
+
+		t.Run("http/"+prefix, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				http.ServeFile(w, r, name)
+			}))
+			t.Cleanup(srv.Close)
+			c, err := NewClient(srv.URL+"/db.zip", &Options{HTTPClient: srv.Client()})
+			if err != nil {
+				t.Fatal(err)
+			}
+			check(t, c)
+		})
+	}
+
+	t.Run("digest", func(t *testing.T) {
+		name := writeTestZip(t, testVulndb, "")
+		src := localURL(name)
+		c, err := NewClient(src+"#sha256="+fileDigest(t, name), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		check(t, c)
+
+		bad := strings.Repeat("0", 2*sha256.Size)
+		if _, err := NewClient(src+"#sha256="+bad, nil); err == nil || !strings.Contains(err.Error(), "sha256 digest") {
+			t.Errorf("NewClient() with wrong digest = %v, want digest mismatch", err)
+		}
+		if _, err := NewClient(src+"#md5=abc", nil); err == nil {
+			t.Error("NewClient() with invalid fragment succeeded unexpectedly")
+		}
+	})
+
+	t.Run("path", func(t *testing.T) {
+		// Local archives may be given as paths, and with an
+		// upper case extension, as is common on Windows.
+		name := writeTestZip(t, testVulndb, "")
+		upper := filepath.Join(filepath.Dir(name), "DB.ZIP")
+		if err := os.Rename(name, upper); err != nil {
+			t.Fatal(err)
+		}
+		c, err := NewClient(upper+"#sha256="+fileDigest(t, upper), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		check(t, c)
+	})
+
+	t.Run("legacy", func(t *testing.T) {
+		src := localURL(writeTestZip(t, testLegacyVulndb, ""))
+		_, err := NewClient(src, nil)
+		if !errors.Is(err, errUnknownSchema) || !strings.Contains(err.Error(), "zip database") {
+			t.Errorf("NewClient() = %v, want error %s of the zip database", err, errUnknownSchema)
+		}
+	})
+
+	t.Run("close", func(t *testing.T) {
+		c, err := NewClient(localURL(writeTestZip(t, testVulndb, "")), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		check(t, c)
+		if err := c.Close(); err != nil {
+			t.Fatal(err)
+		}
+		// The archive is read lazily, from the closed file.
+		if _, err := c.get(ctx, entryEndpoint("GO-2021-0068")); !errors.Is(err, os.ErrClosed) {
+			t.Errorf("get() after Close = %v, want %v", err, os.ErrClosed)
+		}
+	})
+
+	t.Run("corrupt", func(t *testing.T) {
+		name := writeTestZip(t, testVulndb, "")
+		f, err := os.Open(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fi, _ := f.Stat()
+		zr, err := zip.NewReader(f, fi.Size())
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Flip a byte in the compressed data of an entry so
+		// that it no longer matches its recorded checksum.
+		var off int64
+		for _, zf := range zr.File {
+			if zf.Name == entryEndpoint("GO-2021-0068")+".json" {
+				off, err = zf.DataOffset()
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+		f.Close()
+		b, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b[off] ^= 0xff
+		if err := os.WriteFile(name, b, 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		c, err := NewClient(localURL(name), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.get(ctx, entryEndpoint("GO-2021-0068")); err == nil {
+			t.Error("get() of corrupted entry succeeded unexpectedly")
+		}
+	})
+}
+
+func TestWriteZip(t *testing.T) {
+	entries := []*osv.Entry{
+		testEntry("GO-2024-0001", "example.com/a", "1.1.0"),
+		testEntry("GO-2024-0002", "example.com/b", "2.0.1"),
+	}
+	write := func() []byte {
+		t.Helper()
+		var buf bytes.Buffer
+		if err := WriteZip(&buf, entries); err != nil {
+			t.Fatal(err)
+		}
+		return buf.Bytes()
+	}
+	b := write()
+	if !bytes.Equal(b, write()) {
+		t.Error("WriteZip() of the same entries is not deterministic")
+	}
+
+	name := filepath.Join(t.TempDir(), "db.zip")
+	if err := os.WriteFile(name, b, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewClient(localURL(name), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := byModule(t, c, "example.com/a", "1.0.0"), []string{"GO-2024-0001"}; !slices.Equal(got, want) {
+		t.Errorf("entries of example.com/a = %v, want %v", got, want)
+	}
+	if got := byModule(t, c, "example.com/b", "2.0.1"); len(got) != 0 {
+		t.Errorf("entries of fixed example.com/b = %v, want none", got)
+	}
+	got, err := c.ByIDs(context.Background(), []string{"GO-2024-0002"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(entries[1:], got); diff != "" {
+		t.Errorf("ByIDs() mismatch (-want, +got):\n%s", diff)
+	}
+}
@@ -10,7 +10,8 @@
 // large projects where govulncheck execution might take some time.
 //
 // govulncheck JSON emits configuration used to perform the analysis,
-// a user-friendly message about what is being analyzed, and the
+// a user-friendly message about what is being analyzed, diagnostics
+// about problems that make the results incomplete, and the
 // vulnerability findings. Findings for the same vulnerability can
 // can be emitted several times. For instance, govulncheck JSON will
 // emit a finding when it sees that a vulnerable module is required
@@ -18,9 +19,16 @@
 // Please see documentation on Message and related types for precise
 // details on the stream encoding.
 //
-// There are no guarantees on the order of messages. The pattern of emitted
+// OSV messages are emitted ordered by ID, and findings ordered by OSV
+// ID and then by the module, package, and symbol of their first frame,
+// after all of the OSV messages of a scan. Otherwise there are no
+// guarantees on the order of messages. The pattern of emitted
 // messages can change in the future. Clients can follow code in handler.go
 // for consuming the streaming JSON programmatically.
+//
+// The types of this package are exported, along with the compatibility
+// guarantees of the protocol, by golang.org/x/vuln/scan/protocol. Changes
+// to them must keep streams compatible within a major ProtocolVersion.
 package govulncheck
 
 import (
@@ -31,7 +39,7 @@ import (
 
 const (
 	// ProtocolVersion is the current protocol version this file implements
-	ProtocolVersion = "v1.0.0"
+	ProtocolVersion = "v1.6.0"
 )
 
 // Message is an entry in the output stream. It will always have exactly one
@@ -45,8 +53,9 @@ type Message struct {
 	// module is being used at a vulnerable version, the corresponding
 	// OSV will be referenced in Findings depending on the type of usage
 	// and the desired scan level.
-	OSV     *osv.Entry `json:"osv,omitempty"`
-	Finding *Finding   `json:"finding,omitempty"`
+	OSV        *osv.Entry  `json:"osv,omitempty"`
+	Finding    *Finding    `json:"finding,omitempty"`
+	Diagnostic *Diagnostic `json:"diagnostic,omitempty"`
 }
 
 // Config must occur as the first message of a stream and informs the client
@@ -65,6 +74,14 @@ type Config struct {
 	// ScannerVersion is the version of the tool.
 	ScannerVersion string `json:"scanner_version,omitempty"`
 
+	// ScannerGoVersion is the version of Go the tool was built with.
+	ScannerGoVersion string `json:"scanner_go_version,omitempty"`
+
+	// Flags are the command-line flags the tool was run with, in
+	// the form -name=value and sorted by name. They do not include
+	// the package patterns or binary that were scanned.
+	Flags []string `json:"flags,omitempty"`
+
 	// DB is the database used by the tool, for example,
 	// vuln.go.dev.
 	DB string `json:"db,omitempty"`
@@ -76,6 +93,35 @@ type Config struct {
 	// vulnerabilities.
 	GoVersion string `json:"go_version,omitempty"`
 
+	// GoFlags is the effective value of GOFLAGS for the go command
+	// used to load packages in source mode.
+	GoFlags string `json:"go_flags,omitempty"`
+
+	// ExcludeDirs are the directory patterns, relative to the root of
+	// a module, whose functions are not entry points of a source scan,
+	// such as directories of generated code.
+	ExcludeDirs []string `json:"exclude_dirs,omitempty"`
+
+	// Library reports whether a source scan analyzed the scanned
+	// packages as a library, with the exported API of its public
+	// packages as entry points, rather than as programs.
+	Library bool `json:"library,omitempty"`
+
+	// Entries are the patterns of the packages and functions that are
+	// the only entry points of a source scan, if any.
+	Entries []string `json:"entries,omitempty"`
+
+	// Deep reports whether a source scan refined its call graph,
+	// leaving out calls ruled out by constant conditions and calls
+	// through interfaces to types never converted to interfaces.
+	Deep bool `json:"deep,omitempty"`
+
+	// ExcludeLowConfidence reports whether a source scan left out the
+	// symbol findings whose every path goes through a dispatch, as
+	// described by Finding.Dispatch. Their vulnerabilities are then
+	// only reported as imported.
+	ExcludeLowConfidence bool `json:"exclude_low_confidence,omitempty"`
+
 	// ScanLevel instructs govulncheck to analyze at a specific level of detail.
 	// Valid values include module, package and symbol.
 	ScanLevel ScanLevel `json:"scan_level,omitempty"`
@@ -99,6 +145,11 @@ type SBOM struct {
 	// For binaries, this will be the main package.
 	// For source code, this will be the packages matching the provided package patterns.
 	Roots []string `json:"roots,omitempty"`
+
+	// Artifact identifies the part of the scan described, like
+	// Finding.Artifact. When set, there is an SBOM message for each
+	// part of the scan.
+	Artifact string `json:"artifact,omitempty"`
 }
 
 type Module struct {
@@ -163,8 +214,89 @@ type Finding struct {
 	// findings, the trace will contain a single-frame with no symbol or position
 	// information.
 	Trace []*Frame `json:"trace,omitempty"`
+
+	// PURL is the Package URL of the module of the first frame of Trace
+	// at its version, such as pkg:golang/golang.org%2Fx%2Ftext@v0.3.7,
+	// for tools that identify packages by purl.
+	PURL string `json:"purl,omitempty"`
+
+	// Precision describes how precisely govulncheck determined that the
+	// vulnerability affects the code, which is otherwise implied by the
+	// fields of the first frame of Trace and the scan mode.
+	//
+	// It is empty in streams produced by older versions of govulncheck;
+	// use PrecisionOf to handle those as well.
+	Precision Precision `json:"precision,omitempty"`
+
+	// Disclosure describes when the vulnerability was disclosed, and for
+	// how long a fix has been available, as of the scan. It is nil if
+	// the publication date of the vulnerability is unknown.
+	Disclosure *Disclosure `json:"disclosure,omitempty"`
+
+	// ModuleChain is the shortest chain of module requirements through
+	// which the main module requires the module of the first frame of
+	// Trace, starting with the main module path and followed by
+	// module@version strings, such as ["example.com/main",
+	// "example.com/direct@v1.0.0", "example.com/vulnerable@v0.1.0"].
+	// It is only set in source mode, for modules that are not required
+	// directly.
+	ModuleChain []string `json:"module_chain,omitempty"`
+
+	// Dispatch is set when govulncheck found no path to the vulnerable
+	// symbol of Trace but through calls resolved broadly, such as calls
+	// of methods through interfaces, which may call any method of that
+	// name of the types converted to the interface. The trace is then a
+	// low-confidence path. It is only set in source mode.
+	Dispatch *Dispatch `json:"dispatch,omitempty"`
+
+	// Owners are the owners, as listed in the CODEOWNERS file given
+	// with the -owners flag, of the file of the scanned code that is
+	// closest to the vulnerable symbol in Trace. It is only set in
+	// source mode.
+	Owners []string `json:"owners,omitempty"`
+
+	// Upgrade describes the upgrade of the module of Trace that fixes
+	// the vulnerability, and the latest version of that module known to
+	// the module proxy. It is only set with the -upgrades flag.
+	Upgrade *Upgrade `json:"upgrade,omitempty"`
+
+	// Artifact identifies the part of the scan the finding is for. In
+	// binary mode, when scanning Debian packages, RPM packages, and tar
+	// archives, it is the path of a binary within the package. In source
+	// mode with -recursive, it is the slash-separated directory of a
+	// module, relative to the scanned directory. Otherwise it is empty.
+	Artifact string `json:"artifact,omitempty"`
 }
 
+// Upgrade describes how to upgrade a vulnerable module.
+type Upgrade struct {
+	// Latest is the latest version of the module, as listed by the
+	// module proxy, or empty if it is unknown.
+	Latest string `json:"latest,omitempty"`
+
+	// Difficulty is how large the upgrade from the version of the
+	// module found by the scan to the fixed version is. It is empty
+	// if there is no fixed version.
+	Difficulty UpgradeDifficulty `json:"difficulty,omitempty"`
+}
+
+// UpgradeDifficulty is the size of an upgrade according to semantic
+// versioning.
+type UpgradeDifficulty string
+
+const (
+	// UpgradePatch changes only the patch version.
+	UpgradePatch = "patch"
+
+	// UpgradeMinor changes the minor version of a module at major
+	// version 1 or higher, which keeps its API compatible.
+	UpgradeMinor = "minor"
+
+	// UpgradeMajor changes the major version, or the minor version of
+	// a module at major version 0, which may break its API.
+	UpgradeMajor = "major"
+)
+
 // Frame represents an entry in a finding trace.
 type Frame struct {
 	// Module is the module path of the module containing this symbol.
@@ -179,14 +311,29 @@ type Frame struct {
 	Package string `json:"package,omitempty"`
 
 	// Function is the function name.
+	//
+	// For instantiations of generic functions, the type arguments
+	// are not part of the name, see TypeArgs.
 	Function string `json:"function,omitempty"`
 
 	// Receiver is the receiver type if the called symbol is a method.
 	//
 	// The client can create the final symbol name by
 	// prepending Receiver to FuncName.
+	//
+	// For methods of generic types, the type arguments are
+	// not part of the receiver, see TypeArgs.
 	Receiver string `json:"receiver,omitempty"`
 
+	// TypeArgs are the type arguments of the instantiation of a generic
+	// function or of the receiver type of a method, if known. Types are
+	// qualified by their full package path.
+	TypeArgs []string `json:"type_args,omitempty"`
+
+	// Origin describes whether the symbol is in the scanned module,
+	// a dependency, or the standard library.
+	Origin Origin `json:"origin,omitempty"`
+
 	// Position describes an arbitrary source position
 	// including the file, line, and column location.
 	// A Position is valid if the line number is > 0.
@@ -197,6 +344,29 @@ type Frame struct {
 	Position *Position `json:"position,omitempty"`
 }
 
+// Dispatch describes the call of a trace through which the
+// vulnerable symbol of a low-confidence path is reached.
+type Dispatch struct {
+	// Kind is the kind of the call.
+	Kind DispatchKind `json:"kind"`
+
+	// Frame is the index in the trace of the frame whose
+	// position is that of the call.
+	Frame int `json:"frame"`
+}
+
+// DispatchKind describes how the callee of a Dispatch is resolved.
+type DispatchKind string
+
+const (
+	// A call of a method through an interface.
+	DispatchInterface = "interface"
+	// A call of a method through an interface by a package that
+	// inspects values with reflection, such as fmt or encoding/json,
+	// which calls the method on the values passed to it.
+	DispatchReflection = "reflection"
+)
+
 // Position represents arbitrary source position.
 type Position struct {
 	Filename string `json:"filename,omitempty"` // filename, if any
@@ -205,6 +375,140 @@ type Position struct {
 	Column   int    `json:"column"`             // column number, starting at 1 (byte count)
 }
 
+// Diagnostic reports a problem that did not stop the scan, but that
+// makes its results incomplete or less precise, such as a package that
+// failed to load. Diagnostics are warnings meant for the user, unlike
+// progress messages.
+type Diagnostic struct {
+	// Kind is the kind of problem.
+	Kind DiagnosticKind `json:"kind"`
+
+	// OSV is the ID of the vulnerability that the problem is about,
+	// if any.
+	OSV string `json:"osv,omitempty"`
+
+	// Module is the path of the module that the problem is about,
+	// if any.
+	Module string `json:"module,omitempty"`
+
+	// Package is the import path of the package that the problem is
+	// about, if any.
+	Package string `json:"package,omitempty"`
+
+	// Position is the position of the problem in source code, if known.
+	Position *Position `json:"position,omitempty"`
+
+	// Message describes the problem.
+	Message string `json:"message"`
+}
+
+// DiagnosticKind describes the kind of problem that a Diagnostic reports.
+type DiagnosticKind string
+
+const (
+	// A package failed to load, and was left out of the scan
+	// along with the scanned packages that import it.
+	DiagnosticLoadError = "load-error"
+	// The scanned binary has no symbol table, so the vulnerable
+	// symbols of the packages it contains are all reported.
+	DiagnosticStrippedBinary = "stripped-binary"
+	// The scanned binary lacks build information, such as its
+	// modules or platform, so some vulnerabilities are not checked.
+	DiagnosticBinaryBuildInfo = "binary-build-info"
+	// The vulnerability database is older than allowed.
+	DiagnosticStaleDB = "stale-db"
+	// The vulnerability database failed verification.
+	DiagnosticUnverifiedDB = "unverified-db"
+	// The OSV entry of a vulnerability has version ranges of a type
+	// that cannot be evaluated, or GIT ranges whose commits cannot
+	// be resolved to module versions. Such ranges are ignored, or,
+	// when no range can be evaluated, mean that every version is
+	// affected.
+	DiagnosticUnsupportedRange = "unsupported-range"
+	// A scanned package uses cgo. Calls from its C code are not in
+	// the call graph, so the vulnerabilities of the packages it imports
+	// that the scanned code does not call are reported as imported by
+	// a cgo package.
+	DiagnosticCgo = "cgo"
+)
+
+// Origin describes where the code of a frame comes from.
+type Origin string
+
+const (
+	OriginMain       = "main"       // the main module, which is being scanned
+	OriginDependency = "dependency" // a module required by the main module
+	OriginStdlib     = "stdlib"     // the Go standard library
+)
+
+// Precision describes the depth of the analysis behind a finding.
+type Precision string
+
+const (
+	// The vulnerable symbol is reachable from the scanned code.
+	PrecisionSymbolReachable = "symbol-reachable"
+	// The vulnerable package is imported, directly or indirectly.
+	PrecisionPackageImported = "package-imported"
+	// The vulnerable symbol is reachable, but only from code in
+	// directories excluded from the entry points of the analysis.
+	PrecisionExcludedReachable = "excluded-reachable"
+	// The vulnerable package is imported, directly or not, by a package
+	// that uses cgo, and the vulnerable symbol is not reachable from the
+	// scanned code. Calls from C code are not analyzed, so it may still
+	// be called.
+	PrecisionCgoImported = "cgo-imported"
+	// The vulnerable module is required at an affected version.
+	PrecisionModuleRequired = "module-required"
+	// The vulnerable module is required at an affected version by the
+	// go.mod file of the scanned module, but none of its packages is
+	// imported by the scanned code.
+	PrecisionModuleUnused = "module-unused"
+	// The vulnerable symbol is present in the scanned binary, which does
+	// not mean it is reachable: binaries have no call graph to check.
+	PrecisionBinaryImprecise = "binary-imprecise"
+)
+
+// PrecisionOf returns the precision of f, a finding of a scan in the
+// given mode. If f has no precision, it is inferred from its trace.
+func PrecisionOf(f *Finding, mode ScanMode) Precision {
+	switch {
+	case f.Precision != "":
+		return f.Precision
+	case len(f.Trace) == 0:
+		return ""
+	case f.Trace[0].Function != "":
+		if mode == ScanModeBinary {
+			return PrecisionBinaryImprecise
+		}
+		return PrecisionSymbolReachable
+	case f.Trace[0].Package != "":
+		return PrecisionPackageImported
+	default:
+		return PrecisionModuleRequired
+	}
+}
+
+// MostPrecise returns the most precise of the precisions of findings,
+// which are findings of a scan in the given mode.
+func MostPrecise(findings []*Finding, mode ScanMode) Precision {
+	rank := map[Precision]int{
+		PrecisionModuleUnused:      1,
+		PrecisionModuleRequired:    1,
+		PrecisionPackageImported:   2,
+		PrecisionExcludedReachable: 3,
+		PrecisionCgoImported:       3,
+		PrecisionBinaryImprecise:   4,
+		PrecisionSymbolReachable:   4,
+	}
+	var best Precision
+	for _, f := range findings {
+		if p := PrecisionOf(f, mode); rank[p] > rank[best] {
+			best = p
+		}
+	}
+	return best
+}
+
 // ScanLevel represents the detail level at which a scan occurred.
 // This can be necessary to correctly interpret the findings, for instance if
 // a scan is at symbol level and a finding does not have a symbol it means the
@@ -233,9 +537,60 @@ func (l ScanLevel) WantPackages() bool { return l == ScanLevelPackage || l == Sc
 type ScanMode string
 
 const (
-	ScanModeSource  = "source"
-	ScanModeBinary  = "binary"
-	ScanModeConvert = "convert"
-	ScanModeQuery   = "query"
-	ScanModeExtract = "extract" // currently, only binary extraction is supported
+	ScanModeSource   = "source"
+	ScanModeBinary   = "binary"
+	ScanModeConvert  = "convert"
+	ScanModeQuery    = "query"
+	ScanModeExtract  = "extract"  // currently, only binary extraction is supported
+	ScanModeHistory  = "history"  // reports findings recorded by earlier scans
+	ScanModeInfo     = "info"     // reports the details of vulnerabilities
+	ScanModeAudit    = "audit"    // reports all the vulnerabilities of modules
+	ScanModeSnapshot = "snapshot" // saves the vulnerabilities of the modules of a go.sum
 )
+
+// Disclosure describes when a vulnerability was disclosed.
+type Disclosure struct {
+	// Published is when the vulnerability was published
+	// in the vulnerability database.
+	Published time.Time `json:"published"`
+
+	// Modified is when the database entry of the
+	// vulnerability was last modified.
+	Modified time.Time `json:"modified"`
+
+	// FixAvailableDays is the number of full days, as of the scan,
+	// since the fixed version of the vulnerable module was released,
+	// according to the module proxy. It is nil if there is no fix or
+	// if its release time is unknown, as for the standard library.
+	FixAvailableDays *int `json:"fix_available_days,omitempty"`
+}
+
+// NewDisclosure returns the disclosure of the vulnerability e as of
+// now, for a finding whose fixed version was released at fixed, which
+// is zero if there is no fix or if its release time is unknown. It
+// returns nil if the publication date of e is unknown.
+func NewDisclosure(e *osv.Entry, fixed, now time.Time) *Disclosure {
+	if e.Published.IsZero() {
+		return nil
+	}
+	d := &Disclosure{Published: e.Published, Modified: e.Modified}
+	if !fixed.IsZero() {
+		days := 0
+		if now.After(fixed) {
+			days = int(now.Sub(fixed).Hours() / 24)
+		}
+		d.FixAvailableDays = &days
+	}
+	return d
+}
+
+// FirstDisclosure returns the disclosure of the first of findings
+// that has one, or nil if none has.
+func FirstDisclosure(findings []*Finding) *Disclosure {
+	for _, f := range findings {
+		if f.Disclosure != nil {
+			return f.Disclosure
+		}
+	}
+	return nil
+}
@@ -7,6 +7,7 @@ package govulncheck_test
 import (
 	"testing"
 
+	"golang.org/x/vuln/internal/govulncheck"
 	"golang.org/x/vuln/internal/test"
 )
 
@@ -15,3 +16,28 @@ func TestImports(t *testing.T) {
 		"golang.org/x/vuln/internal/osv", // allowed to pull in the osv json entries
 	)
 }
+
+func TestPrecisionOf(t *testing.T) {
+	module := &govulncheck.Frame{Module: "golang.org/vmod", Version: "v0.0.1"}
+	pkg := &govulncheck.Frame{Module: "golang.org/vmod", Version: "v0.0.1", Package: "golang.org/vmod"}
+	symbol := &govulncheck.Frame{Module: "golang.org/vmod", Version: "v0.0.1", Package: "golang.org/vmod", Function: "Vuln"}
+	for _, tc := range []struct {
+		name    string
+		finding *govulncheck.Finding
+		mode    govulncheck.ScanMode
+		want    govulncheck.Precision
+	}{
+		{"set", &govulncheck.Finding{Trace: []*govulncheck.Frame{symbol}, Precision: govulncheck.PrecisionBinaryImprecise}, govulncheck.ScanModeSource, govulncheck.PrecisionBinaryImprecise},
+		{"module", &govulncheck.Finding{Trace: []*govulncheck.Frame{module}}, govulncheck.ScanModeSource, govulncheck.PrecisionModuleRequired},
+		{"package", &govulncheck.Finding{Trace: []*govulncheck.Frame{pkg}}, govulncheck.ScanModeBinary, govulncheck.PrecisionPackageImported},
+		{"source symbol", &govulncheck.Finding{Trace: []*govulncheck.Frame{symbol}}, govulncheck.ScanModeSource, govulncheck.PrecisionSymbolReachable},
+		{"binary symbol", &govulncheck.Finding{Trace: []*govulncheck.Frame{symbol}}, govulncheck.ScanModeBinary, govulncheck.PrecisionBinaryImprecise},
+		{"no trace", &govulncheck.Finding{}, govulncheck.ScanModeSource, ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := govulncheck.PrecisionOf(tc.finding, tc.mode); got != tc.want {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
@@ -28,6 +28,10 @@ type Handler interface {
 
 	// Finding is called for each vulnerability finding in the stream.
 	Finding(finding *Finding) error
+
+	// Diagnostic is called for each problem that makes the results
+	// of the scan incomplete.
+	Diagnostic(diagnostic *Diagnostic) error
 }
 
 // HandleJSON reads the json from the supplied stream and hands the decoded
@@ -57,6 +61,9 @@ func HandleJSON(from io.Reader, to Handler) error {
 		if msg.Finding != nil {
 			err = to.Finding(msg.Finding)
 		}
+		if msg.Diagnostic != nil {
+			err = to.Diagnostic(msg.Diagnostic)
+		}
 		if err != nil {
 			return err
 		}
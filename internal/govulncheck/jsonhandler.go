@@ -47,3 +47,8 @@ func (h *jsonHandler) OSV(entry *osv.Entry) error {
 func (h *jsonHandler) Finding(finding *Finding) error {
 	return h.enc.Encode(Message{Finding: finding})
 }
+
+// Diagnostic writes a diagnostic in JSON to the underlying writer.
+func (h *jsonHandler) Diagnostic(diagnostic *Diagnostic) error {
+	return h.enc.Encode(Message{Diagnostic: diagnostic})
+}
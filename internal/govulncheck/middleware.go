@@ -0,0 +1,289 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package govulncheck
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/vuln/internal/osv"
+)
+
+// Middleware wraps a Handler to change the messages it receives.
+//
+// Output features that apply to every format, such as filtering or
+// suppressing findings, are written as middleware and combined with
+// Chain instead of being implemented by each format's handler.
+type Middleware func(next Handler) Handler
+
+// Chain returns h wrapped by mws. The first middleware sees
+// messages first, and passes them on to the next one, and so on,
+// until they reach h.
+func Chain(h Handler, mws ...Middleware) Handler {
+	for i := len(mws) - 1; i >= 0; i-- {
+		h = mws[i](h)
+	}
+	return h
+}
+
+// Flush calls the Flush method of h, if it has one.
+// Handlers that buffer output implement Flush, and
+// its error may determine the exit code of govulncheck.
+func Flush(h Handler) error {
+	if f, ok := h.(interface{ Flush() error }); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+// Wrapper is a Handler that passes every message on to Next.
+// Middleware handlers embed it and override the methods for
+// the messages they change.
+type Wrapper struct {
+	Next Handler
+}
+
+func (w *Wrapper) Config(c *Config) error         { return w.Next.Config(c) }
+func (w *Wrapper) SBOM(s *SBOM) error             { return w.Next.SBOM(s) }
+func (w *Wrapper) Progress(p *Progress) error     { return w.Next.Progress(p) }
+func (w *Wrapper) OSV(e *osv.Entry) error         { return w.Next.OSV(e) }
+func (w *Wrapper) Finding(f *Finding) error       { return w.Next.Finding(f) }
+func (w *Wrapper) Diagnostic(d *Diagnostic) error { return w.Next.Diagnostic(d) }
+
+// Flush flushes Next.
+func (w *Wrapper) Flush() error { return Flush(w.Next) }
+
+// Filter returns middleware that drops the findings for which
+// keep returns false.
+func Filter(keep func(*Finding) bool) Middleware {
+	return func(next Handler) Handler {
+		return &filterHandler{Wrapper{next}, keep}
+	}
+}
+
+type filterHandler struct {
+	Wrapper
+	keep func(*Finding) bool
+}
+
+func (h *filterHandler) Finding(f *Finding) error {
+	if !h.keep(f) {
+		return nil
+	}
+	return h.Next.Finding(f)
+}
+
+// findingKey returns a string identifying the contents of f.
+func findingKey(f *Finding) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s %s", f.OSV, f.FixedVersion, f.Artifact)
+	for _, fr := range f.Trace {
+		fmt.Fprintf(&b, "|%s@%s %s.%s.%s%v", fr.Module, fr.Version, fr.Package, fr.Receiver, fr.Function, fr.TypeArgs)
+		if p := fr.Position; p != nil {
+			fmt.Fprintf(&b, " %s:%d:%d", p.Filename, p.Line, p.Column)
+		}
+	}
+	return b.String()
+}
+
+// Sort returns middleware that holds back findings until the
+// handler is flushed, and then passes them on in the order given
+// by less. Other messages are passed on immediately.
+func Sort(less func(a, b *Finding) bool) Middleware {
+	return func(next Handler) Handler {
+		return &sortHandler{Wrapper: Wrapper{next}, less: less}
+	}
+}
+
+type sortHandler struct {
+	Wrapper
+	less     func(a, b *Finding) bool
+	findings []*Finding
+}
+
+func (h *sortHandler) Finding(f *Finding) error {
+	h.findings = append(h.findings, f)
+	return nil
+}
+
+func (h *sortHandler) Flush() error {
+	sort.SliceStable(h.findings, func(i, j int) bool {
+		return h.less(h.findings[i], h.findings[j])
+	})
+	for _, f := range h.findings {
+		if err := h.Next.Finding(f); err != nil {
+			return err
+		}
+	}
+	h.findings = nil
+	return Flush(h.Next)
+}
+
+// ByOSV orders findings by OSV ID, for use with Sort.
+func ByOSV(a, b *Finding) bool { return a.OSV < b.OSV }
+
+// ByContents orders findings by OSV ID, and then by the module,
+// package, and symbol of their first frame, for use with Sort.
+// Findings that are equal in these are ordered by the rest of
+// their contents, so that the order is total.
+func ByContents(a, b *Finding) bool {
+	if a.OSV != b.OSV {
+		return a.OSV < b.OSV
+	}
+	var af, bf Frame
+	if len(a.Trace) > 0 {
+		af = *a.Trace[0]
+	}
+	if len(b.Trace) > 0 {
+		bf = *b.Trace[0]
+	}
+	switch {
+	case af.Module != bf.Module:
+		return af.Module < bf.Module
+	case af.Package != bf.Package:
+		return af.Package < bf.Package
+	case af.Receiver != bf.Receiver:
+		return af.Receiver < bf.Receiver
+	case af.Function != bf.Function:
+		return af.Function < bf.Function
+	}
+	return findingKey(a) < findingKey(b)
+}
+
+// Order returns middleware that makes the order of OSV entries and
+// findings deterministic. It holds them back until the handler is
+// flushed, and then passes on the entries ordered by ID, followed by
+// the findings ordered by ByContents. Other messages are passed on
+// immediately.
+func Order() Middleware {
+	return func(next Handler) Handler {
+		return &orderHandler{sortHandler: sortHandler{Wrapper: Wrapper{next}, less: ByContents}}
+	}
+}
+
+type orderHandler struct {
+	sortHandler
+	osvs []*osv.Entry
+}
+
+func (h *orderHandler) OSV(e *osv.Entry) error {
+	h.osvs = append(h.osvs, e)
+	return nil
+}
+
+func (h *orderHandler) Flush() error {
+	sort.SliceStable(h.osvs, func(i, j int) bool {
+		return h.osvs[i].ID < h.osvs[j].ID
+	})
+	for _, e := range h.osvs {
+		if err := h.Next.OSV(e); err != nil {
+			return err
+		}
+	}
+	h.osvs = nil
+	return h.sortHandler.Flush()
+}
+
+// PackageURLs returns middleware that adds the Package URL of their
+// module to the affected modules of OSV entries and to findings that
+// have none. Entries and findings are copied before they are changed.
+func PackageURLs() Middleware {
+	return func(next Handler) Handler {
+		return &purlHandler{Wrapper{next}}
+	}
+}
+
+type purlHandler struct {
+	Wrapper
+}
+
+func (h *purlHandler) OSV(e *osv.Entry) error {
+	var affected []osv.Affected
+	for i, a := range e.Affected {
+		if a.Module.Purl != "" {
+			continue
+		}
+		if affected == nil {
+			affected = slices.Clone(e.Affected)
+		}
+		affected[i].Module.Purl = osv.PackageURL(a.Module.Path, "")
+	}
+	if affected != nil {
+		c := *e
+		c.Affected = affected
+		e = &c
+	}
+	return h.Next.OSV(e)
+}
+
+func (h *purlHandler) Finding(f *Finding) error {
+	if f.PURL == "" && len(f.Trace) > 0 {
+		c := *f
+		c.PURL = osv.PackageURL(f.Trace[0].Module, f.Trace[0].Version)
+		f = &c
+	}
+	return h.Next.Finding(f)
+}
+
+// Disclose returns middleware that adds their Disclosure as of now to
+// findings that have none. The release time of the fixed version of a
+// finding is returned by released, which is called once per module
+// version and returns zero if the time is unknown. If released is nil,
+// the release times are all unknown. It relies on the
+// OSV entry of a finding being passed on before the finding, as
+// govulncheck does. Findings are copied before they are changed.
+func Disclose(now time.Time, released func(path, version string) time.Time) Middleware {
+	return func(next Handler) Handler {
+		return &discloseHandler{
+			Wrapper:  Wrapper{next},
+			now:      now,
+			released: released,
+			osvs:     make(map[string]*osv.Entry),
+			times:    make(map[string]time.Time),
+		}
+	}
+}
+
+type discloseHandler struct {
+	Wrapper
+	now      time.Time
+	released func(path, version string) time.Time
+	osvs     map[string]*osv.Entry
+	times    map[string]time.Time // release times by module@version
+}
+
+func (h *discloseHandler) OSV(e *osv.Entry) error {
+	h.osvs[e.ID] = e
+	return h.Next.OSV(e)
+}
+
+func (h *discloseHandler) Finding(f *Finding) error {
+	if e := h.osvs[f.OSV]; e != nil && f.Disclosure == nil {
+		if d := NewDisclosure(e, h.fixReleased(f), h.now); d != nil {
+			c := *f
+			c.Disclosure = d
+			f = &c
+		}
+	}
+	return h.Next.Finding(f)
+}
+
+// fixReleased returns the release time of the fixed version of f,
+// or zero if f has no fix or the time is unknown.
+func (h *discloseHandler) fixReleased(f *Finding) time.Time {
+	if h.released == nil || f.FixedVersion == "" || len(f.Trace) == 0 {
+		return time.Time{}
+	}
+	mv := f.Trace[0].Module + "@" + f.FixedVersion
+	t, ok := h.times[mv]
+	if !ok {
+		t = h.released(f.Trace[0].Module, f.FixedVersion)
+		h.times[mv] = t
+	}
+	return t
+}
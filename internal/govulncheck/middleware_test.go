@@ -0,0 +1,207 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package govulncheck_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/vuln/internal/govulncheck"
+	"golang.org/x/vuln/internal/osv"
+	"golang.org/x/vuln/internal/test"
+)
+
+func finding(id, fn string) *govulncheck.Finding {
+	return &govulncheck.Finding{OSV: id, Trace: []*govulncheck.Frame{{Module: "m", Function: fn}}}
+}
+
+// ids returns the OSV IDs of fs.
+func ids(fs []*govulncheck.Finding) []string {
+	var out []string
+	for _, f := range fs {
+		out = append(out, f.OSV+"/"+f.Trace[0].Function)
+	}
+	return out
+}
+
+func TestChain(t *testing.T) {
+	mock := test.NewMockHandler()
+	h := govulncheck.Chain(mock,
+		govulncheck.Filter(func(f *govulncheck.Finding) bool { return f.OSV != "GO-0000-0002" }),
+		govulncheck.Sort(govulncheck.ByOSV),
+	)
+	for _, f := range []*govulncheck.Finding{
+		finding("GO-0000-0003", "F"),
+		finding("GO-0000-0002", "F"),
+		finding("GO-0000-0001", "F"),
+		finding("GO-0000-0001", "G"),
+	} {
+		if err := h.Finding(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Sorted findings are only passed on when flushed.
+	if len(mock.FindingMessages) != 0 {
+		t.Fatalf("got %d findings before Flush, want 0", len(mock.FindingMessages))
+	}
+	if err := govulncheck.Flush(h); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"GO-0000-0001/F", "GO-0000-0001/G", "GO-0000-0003/F"}
+	if diff := cmp.Diff(want, ids(mock.FindingMessages)); diff != "" {
+		t.Errorf("findings mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestOrder(t *testing.T) {
+	mock := test.NewMockHandler()
+	h := govulncheck.Chain(mock, govulncheck.Order())
+	for _, id := range []string{"GO-0000-0002", "GO-0000-0001"} {
+		if err := h.OSV(&osv.Entry{ID: id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	frame := func(mod, pkg, fn string) *govulncheck.Frame {
+		return &govulncheck.Frame{Module: mod, Package: pkg, Function: fn}
+	}
+	for _, f := range []*govulncheck.Finding{
+		{OSV: "GO-0000-0002", Trace: []*govulncheck.Frame{frame("m", "m/p", "F")}},
+		{OSV: "GO-0000-0001", Trace: []*govulncheck.Frame{frame("m", "m/p", "G"), frame("main", "main", "b")}},
+		{OSV: "GO-0000-0001", Trace: []*govulncheck.Frame{frame("m", "m/p", "G"), frame("main", "main", "a")}},
+		{OSV: "GO-0000-0001", Trace: []*govulncheck.Frame{frame("m", "m/p", "")}},
+		{OSV: "GO-0000-0001", Trace: []*govulncheck.Frame{frame("m", "", "")}},
+		{OSV: "GO-0000-0001", Trace: []*govulncheck.Frame{frame("m", "m/p", "F")}},
+	} {
+		if err := h.Finding(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(mock.OSVMessages) != 0 || len(mock.FindingMessages) != 0 {
+		t.Fatalf("got messages before Flush, want none")
+	}
+	if err := govulncheck.Flush(h); err != nil {
+		t.Fatal(err)
+	}
+	var gotOSVs []string
+	for _, e := range mock.OSVMessages {
+		gotOSVs = append(gotOSVs, e.ID)
+	}
+	if diff := cmp.Diff([]string{"GO-0000-0001", "GO-0000-0002"}, gotOSVs); diff != "" {
+		t.Errorf("OSV entries mismatch (-want +got):\n%s", diff)
+	}
+	var got []string
+	for _, f := range mock.FindingMessages {
+		s := f.OSV
+		for _, fr := range f.Trace {
+			s += " " + fr.Module + ":" + fr.Package + ":" + fr.Function
+		}
+		got = append(got, s)
+	}
+	want := []string{
+		"GO-0000-0001 m::",
+		"GO-0000-0001 m:m/p:",
+		"GO-0000-0001 m:m/p:F",
+		"GO-0000-0001 m:m/p:G main:main:a",
+		"GO-0000-0001 m:m/p:G main:main:b",
+		"GO-0000-0002 m:m/p:F",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("findings mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestDisclose(t *testing.T) {
+	published := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	modified := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	released := time.Date(2024, 2, 20, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	var lookups []string
+	mock := test.NewMockHandler()
+	h := govulncheck.Chain(mock, govulncheck.Disclose(now, func(path, version string) time.Time {
+		lookups = append(lookups, path+"@"+version)
+		if version == "v1.0.1" {
+			return released
+		}
+		return time.Time{} // unknown
+	}))
+	for _, e := range []*osv.Entry{
+		{ID: "GO-0000-0001", Published: published, Modified: modified},
+		{ID: "GO-0000-0002"},
+	} {
+		if err := h.OSV(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	fixed := finding("GO-0000-0001", "F")
+	fixed.FixedVersion = "v1.0.1"
+	fixedAgain := finding("GO-0000-0001", "H")
+	fixedAgain.FixedVersion = "v1.0.1"
+	unreleased := finding("GO-0000-0001", "I")
+	unreleased.FixedVersion = "v1.0.2"
+	findings := []*govulncheck.Finding{
+		fixed,
+		fixedAgain,
+		unreleased,
+		finding("GO-0000-0001", "G"),
+		finding("GO-0000-0002", "F"), // publication date unknown
+	}
+	for _, f := range findings {
+		if err := h.Finding(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+	days := 10 // since the fix was released, not since publication
+	want := []*govulncheck.Disclosure{
+		{Published: published, Modified: modified, FixAvailableDays: &days},
+		{Published: published, Modified: modified, FixAvailableDays: &days},
+		{Published: published, Modified: modified},
+		{Published: published, Modified: modified},
+		nil,
+	}
+	var got []*govulncheck.Disclosure
+	for _, f := range mock.FindingMessages {
+		got = append(got, f.Disclosure)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("disclosure mismatch (-want +got):\n%s", diff)
+	}
+	if fixed.Disclosure != nil {
+		t.Error("Disclose middleware modified its input")
+	}
+	if diff := cmp.Diff([]string{"m@v1.0.1", "m@v1.0.2"}, lookups); diff != "" {
+		t.Errorf("release time lookups mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestPackageURLs(t *testing.T) {
+	mock := test.NewMockHandler()
+	h := govulncheck.Chain(mock, govulncheck.PackageURLs())
+	e := &osv.Entry{ID: "GO-0000-0001", Affected: []osv.Affected{
+		{Module: osv.Module{Path: "example.com/m/v2"}},
+		{Module: osv.Module{Path: "stdlib", Purl: "pkg:golang/std"}},
+	}}
+	if err := h.OSV(e); err != nil {
+		t.Fatal(err)
+	}
+	f := &govulncheck.Finding{OSV: "GO-0000-0001", Trace: []*govulncheck.Frame{{Module: "example.com/m/v2", Version: "v2.1.0"}}}
+	if err := h.Finding(f); err != nil {
+		t.Fatal(err)
+	}
+	var purls []string
+	for _, a := range mock.OSVMessages[0].Affected {
+		purls = append(purls, a.Module.Purl)
+	}
+	want := []string{"pkg:golang/example.com%2Fm%2Fv2", "pkg:golang/std"}
+	if diff := cmp.Diff(want, purls); diff != "" {
+		t.Errorf("entry purls mismatch (-want +got):\n%s", diff)
+	}
+	if got, want := mock.FindingMessages[0].PURL, "pkg:golang/example.com%2Fm%2Fv2@v2.1.0"; got != want {
+		t.Errorf("finding purl = %q, want %q", got, want)
+	}
+	if e.Affected[0].Module.Purl != "" || f.PURL != "" {
+		t.Error("PackageURLs middleware modified its input")
+	}
+}
@@ -0,0 +1,180 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package history records the findings of govulncheck scans so that
+// changes in findings can be reported over time.
+//
+// The history is stored as a file of JSON lines, one Scan per line,
+// which can be appended to cheaply and inspected with ordinary tools.
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Finding identifies a vulnerability affecting a module version.
+type Finding struct {
+	OSV     string `json:"osv"`
+	Module  string `json:"module"`
+	Version string `json:"version,omitempty"`
+}
+
+// Scan is the record of one govulncheck run.
+type Scan struct {
+	Time time.Time `json:"time"`
+	// Target is the absolute path of the scanned
+	// module directory or binary.
+	Target    string    `json:"target"`
+	ScanMode  string    `json:"scan_mode,omitempty"`
+	ScanLevel string    `json:"scan_level,omitempty"`
+	DB        string    `json:"db,omitempty"`
+	Findings  []Finding `json:"findings"`
+}
+
+// Store is a history of scans kept in a file.
+type Store struct {
+	path string
+}
+
+// DefaultPath returns the location of the history file in the
+// user's cache directory.
+func DefaultPath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "govulncheck", "history.jsonl"), nil
+}
+
+// NewStore returns a store that keeps its history in the file at path.
+// The file is created when the first scan is added.
+func NewStore(path string) *Store {
+	return &Store{path: path}
+}
+
+// Add appends sc to the history.
+func (s *Store) Add(sc *Scan) error {
+	b, err := json.Marshal(sc)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(s.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	// A single write keeps lines from concurrent runs intact.
+	_, err = f.Write(append(b, '\n'))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// foldTargets is whether targets are compared regardless of case, as
+// paths are on Windows file systems.
+var foldTargets = runtime.GOOS == "windows"
+
+// sameTarget reports whether the targets a and b are the same path.
+func sameTarget(a, b string) bool {
+	if foldTargets {
+		return strings.EqualFold(a, b)
+	}
+	return a == b
+}
+
+// Scans returns the recorded scans of target, oldest first.
+func (s *Store) Scans(target string) ([]*Scan, error) {
+	f, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var scans []*Scan
+	lines := bufio.NewScanner(f)
+	lines.Buffer(nil, 16<<20)
+	for n := 1; lines.Scan(); n++ {
+		var sc Scan
+		if err := json.Unmarshal(lines.Bytes(), &sc); err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", f.Name(), n, err)
+		}
+		if sameTarget(sc.Target, target) {
+			scans = append(scans, &sc)
+		}
+	}
+	if err := lines.Err(); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(scans, func(i, j int) bool { return scans[i].Time.Before(scans[j].Time) })
+	return scans, nil
+}
+
+// Event describes a period during which a vulnerability affected
+// a module, regardless of which of its versions were in use.
+type Event struct {
+	// Finding is the finding as last seen.
+	Finding
+	// FirstSeen is the time of the first scan reporting the finding.
+	FirstSeen time.Time
+	// LastSeen is the time of the last scan reporting the finding.
+	LastSeen time.Time
+	// Resolved is the time of the first scan after LastSeen that
+	// did not report the finding, or zero if it is still present.
+	Resolved time.Time
+}
+
+// Timeline returns the events of scans, which must be sorted by time,
+// ordered by when they were first seen. A finding that disappears and
+// later reappears produces one event for each period it was present.
+func Timeline(scans []*Scan) []*Event {
+	type key struct{ osv, module string }
+	var events []*Event
+	open := make(map[key]*Event)
+	for _, s := range scans {
+		present := make(map[key]bool)
+		for _, f := range s.Findings {
+			k := key{f.OSV, f.Module}
+			present[k] = true
+			if e, ok := open[k]; ok {
+				e.Finding = f
+				e.LastSeen = s.Time
+				continue
+			}
+			e := &Event{Finding: f, FirstSeen: s.Time, LastSeen: s.Time}
+			open[k] = e
+			events = append(events, e)
+		}
+		for k, e := range open {
+			if !present[k] {
+				e.Resolved = s.Time
+				delete(open, k)
+			}
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		ei, ej := events[i], events[j]
+		if !ei.FirstSeen.Equal(ej.FirstSeen) {
+			return ei.FirstSeen.Before(ej.FirstSeen)
+		}
+		if ei.OSV != ej.OSV {
+			return ei.OSV < ej.OSV
+		}
+		return ei.Module < ej.Module
+	})
+	return events
+}
@@ -0,0 +1,84 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package history
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func day(d int) time.Time {
+	return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestStore(t *testing.T) {
+	s := NewStore(filepath.Join(t.TempDir(), "sub", "history.jsonl"))
+	if got, err := s.Scans("/a"); err != nil || got != nil {
+		t.Fatalf("Scans() on missing file = %v, %v; want nil, nil", got, err)
+	}
+	scans := []*Scan{
+		{Time: day(2), Target: "/a", Findings: []Finding{{OSV: "GO-2024-0001", Module: "m", Version: "v1.0.0"}}},
+		{Time: day(1), Target: "/a", Findings: []Finding{}},
+		{Time: day(1), Target: "/b", Findings: []Finding{}},
+	}
+	for _, sc := range scans {
+		if err := s.Add(sc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, err := s.Scans("/a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*Scan{scans[1], scans[0]}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Scans() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestStoreFoldTargets(t *testing.T) {
+	defer func(fold bool) { foldTargets = fold }(foldTargets)
+	s := NewStore(filepath.Join(t.TempDir(), "history.jsonl"))
+	if err := s.Add(&Scan{Time: day(1), Target: `C:\Work\m`, Findings: []Finding{}}); err != nil {
+		t.Fatal(err)
+	}
+	for _, fold := range []bool{false, true} {
+		foldTargets = fold
+		got, err := s.Scans(`c:\work\M`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := 0
+		if fold {
+			want = 1
+		}
+		if len(got) != want {
+			t.Errorf("foldTargets=%t: got %d scans, want %d", fold, len(got), want)
+		}
+	}
+}
+
+func TestTimeline(t *testing.T) {
+	f1 := Finding{OSV: "GO-2024-0001", Module: "m", Version: "v1.0.0"}
+	f1up := Finding{OSV: "GO-2024-0001", Module: "m", Version: "v1.0.1"}
+	f2 := Finding{OSV: "GO-2024-0002", Module: "n", Version: "v0.1.0"}
+	scans := []*Scan{
+		{Time: day(1), Findings: []Finding{f2, f1}},
+		{Time: day(2), Findings: []Finding{f1up}}, // f2 resolved, m upgraded
+		{Time: day(3), Findings: []Finding{}},     // f1 resolved
+		{Time: day(4), Findings: []Finding{f2}},   // f2 reintroduced
+	}
+	want := []*Event{
+		{Finding: f1up, FirstSeen: day(1), LastSeen: day(2), Resolved: day(3)},
+		{Finding: f2, FirstSeen: day(1), LastSeen: day(1), Resolved: day(2)},
+		{Finding: f2, FirstSeen: day(4), LastSeen: day(4)},
+	}
+	if diff := cmp.Diff(want, Timeline(scans)); diff != "" {
+		t.Errorf("Timeline() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"io"
 	"slices"
+	"strings"
 	"time"
 
 	"golang.org/x/vuln/internal/govulncheck"
@@ -36,6 +37,8 @@ type handler struct {
 	// an osv is indeed called, then all findings for
 	// the osv will have call stack info.
 	findings map[string][]*govulncheck.Finding
+	// diags counts the diagnostics of each kind.
+	diags map[govulncheck.DiagnosticKind]int
 }
 
 func NewHandler(w io.Writer) *handler {
@@ -43,6 +46,7 @@ func NewHandler(w io.Writer) *handler {
 		w:        w,
 		osvs:     make(map[string]*osv.Entry),
 		findings: make(map[string][]*govulncheck.Finding),
+		diags:    make(map[govulncheck.DiagnosticKind]int),
 	}
 }
 
@@ -55,6 +59,11 @@ func (h *handler) Progress(progress *govulncheck.Progress) error {
 	return nil
 }
 
+func (h *handler) Diagnostic(d *govulncheck.Diagnostic) error {
+	h.diags[d.Kind]++
+	return nil
+}
+
 func (h *handler) SBOM(s *govulncheck.SBOM) error {
 	h.sbom = s
 	return nil
@@ -214,11 +223,27 @@ func statements(h *handler) []Statement {
 
 		// Findings are guaranteed to be at the same level, so we can just check the first element
 		fLevel := foundAtLevel(h.findings[id][0])
+		precision := govulncheck.MostPrecise(h.findings[id], h.cfg.ScanMode)
+		s.StatusNotes = precisionNote + string(precision)
+		if d := govulncheck.FirstDisclosure(h.findings[id]); d != nil {
+			// The number of days a fix has been available is left
+			// out, so that the document ID only changes with the findings.
+			s.StatusNotes += fmt.Sprintf(publishedNote, d.Published.Format(time.DateOnly))
+		}
+		if len(h.diags) > 0 {
+			s.StatusNotes += fmt.Sprintf(diagnosticsNote, h.diagnosticCounts())
+		}
 		if fLevel >= scanLevel {
 			s.Status = StatusAffected
+		} else if precision == govulncheck.PrecisionCgoImported {
+			// The call graph does not rule out calls from C code.
+			s.Status = StatusUnderInvestigation
 		} else {
 			s.Status = StatusNotAffected
 			s.ImpactStatement = Impact
+			if h.cfg.Library {
+				s.ImpactStatement = ImpactLibrary
+			}
 			s.Justification = JustificationNotPresent
 			// We only reach this case if running in symbol mode
 			if fLevel == imported {
@@ -242,6 +267,21 @@ func statements(h *handler) []Statement {
 	return statements
 }
 
+// diagnosticCounts returns the number of diagnostics of each
+// kind, such as "1 load-error, 2 stale-db", ordered by kind.
+func (h *handler) diagnosticCounts() string {
+	var counts []string
+	for k, n := range h.diags {
+		counts = append(counts, fmt.Sprintf("%d %s", n, k))
+	}
+	slices.SortFunc(counts, func(a, b string) int {
+		_, ka, _ := strings.Cut(a, " ")
+		_, kb, _ := strings.Cut(b, " ")
+		return strings.Compare(ka, kb)
+	})
+	return strings.Join(counts, ", ")
+}
+
 func hashVex(doc Document) string {
 	// json.Marshal should never error here (because of the structure of Document).
 	// If an error does occur, it won't be a jsonerror, but instead a panic
@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"golang.org/x/vuln/internal/govulncheck"
+	"golang.org/x/vuln/internal/osv"
 )
 
 func TestSubcomponentSet(t *testing.T) {
@@ -181,3 +182,45 @@ func TestMoreSpecific(t *testing.T) {
 		})
 	}
 }
+
+func TestDiagnosticCounts(t *testing.T) {
+	h := NewHandler(nil)
+	for _, k := range []govulncheck.DiagnosticKind{
+		govulncheck.DiagnosticStaleDB,
+		govulncheck.DiagnosticLoadError,
+		govulncheck.DiagnosticLoadError,
+	} {
+		if err := h.Diagnostic(&govulncheck.Diagnostic{Kind: k}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, want := h.diagnosticCounts(), "2 load-error, 1 stale-db"; got != want {
+		t.Errorf("diagnosticCounts() = %q, want %q", got, want)
+	}
+}
+
+func TestCgoStatus(t *testing.T) {
+	h := NewHandler(nil)
+	if err := h.Config(&govulncheck.Config{ScanLevel: govulncheck.ScanLevelSymbol}); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.OSV(&osv.Entry{ID: "GO-2021-0265"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []govulncheck.Precision{govulncheck.PrecisionPackageImported, govulncheck.PrecisionCgoImported} {
+		if err := h.Finding(&govulncheck.Finding{
+			OSV:       "GO-2021-0265",
+			Trace:     []*govulncheck.Frame{{Module: "github.com/tidwall/gjson", Version: "v1.6.5", Package: "github.com/tidwall/gjson"}},
+			Precision: p,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s := statements(h)
+	if len(s) != 1 {
+		t.Fatalf("got %d statements, want 1", len(s))
+	}
+	if s[0].Status != StatusUnderInvestigation || s[0].Justification != "" {
+		t.Errorf("got status %q with justification %q, want %q without one", s[0].Status, s[0].Justification, StatusUnderInvestigation)
+	}
+}
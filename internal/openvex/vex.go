@@ -18,13 +18,26 @@ const (
 	ContextURI = "https://openvex.dev/ns/v0.2.0"
 	Tooling    = "https://pkg.go.dev/golang.org/x/vuln/cmd/govulncheck"
 	Impact     = "Govulncheck determined that the vulnerable code isn't called"
+	// ImpactLibrary replaces Impact for scans of a library.
+	ImpactLibrary = "Govulncheck determined that the vulnerable code isn't reachable from the exported API"
+
+	// precisionNote precedes the precision in StatusNotes.
+	precisionNote = "Govulncheck precision: "
+	// publishedNote follows the precision in StatusNotes when the
+	// publication date of the vulnerability is known.
+	publishedNote = "; published %s"
+	// diagnosticsNote ends StatusNotes when the scan reported
+	// diagnostics, with their number for each kind, such as
+	// "1 load-error". The status may then be less certain.
+	diagnosticsNote = "; scan diagnostics: %s"
 
 	DefaultAuthor = "Unknown Author"
 	DefaultPID    = "Unknown Product"
 
 	// The following are defined by the VEX standard.
-	StatusAffected    = "affected"
-	StatusNotAffected = "not_affected"
+	StatusAffected           = "affected"
+	StatusNotAffected        = "not_affected"
+	StatusUnderInvestigation = "under_investigation"
 
 	// The following are defined by the VEX standard.
 	JustificationNotExecuted = "vulnerable_code_not_in_execute_path"
@@ -69,16 +82,23 @@ type Statement struct {
 	// Products are the products associated with the given vulnerability in the statement.
 	Products []Product `json:"products,omitempty"`
 
-	// The status of the vulnerability. Will be either not_affected or affected for govulncheck.
+	// The status of the vulnerability. Will be either not_affected or affected for govulncheck,
+	// or under_investigation when calls from C code through cgo may reach the vulnerable code.
 	Status string `json:"status,omitempty"`
 
 	// If the status is not_affected, this must be filled. The official VEX justification that
 	// best matches govulncheck's vuln filtering is "vulnerable_code_not_in_execute_path"
 	Justification string `json:"justification,omitempty"`
 
-	// If the status is not_affected, this must be filled. For govulncheck, this will always be:
-	// "Govulncheck determined that the vulnerable code isn't called"
+	// If the status is not_affected, this must be filled. For govulncheck, this will be
+	// Impact, or ImpactLibrary for scans of a library.
 	ImpactStatement string `json:"impact_statement,omitempty"`
+
+	// StatusNotes convey how precisely govulncheck determined the status,
+	// as precisionNote followed by a govulncheck.Precision, and then
+	// when the vulnerability was published, as publishedNote, and the
+	// diagnostics of the scan, as diagnosticsNote.
+	StatusNotes string `json:"status_notes,omitempty"`
 }
 
 // Vulnerability captures a vulnerability and its identifiers/aliases.
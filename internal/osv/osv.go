@@ -8,26 +8,36 @@
 // (https://ossf.github.io/osv-schema), with database and
 // ecosystem-specific meanings and fields.
 //
-// As this package is intended for use with the Go vulnerability
-// database, only the subset of features which are used by that
-// database are implemented (for instance, only the SEMVER affected
-// range type is implemented).
+// The package covers the fields of OSV schema version 1.6. Fields that
+// the Go vulnerability database does not publish, such as severity and
+// related identifiers, are still decoded so that entries from other
+// databases can be displayed without loss of information.
 package osv
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 // RangeType specifies the type of version range being recorded and
 // defines the interpretation of the RangeEvent object's Introduced
 // and Fixed fields.
 //
-// In this implementation, only the "SEMVER" type is supported.
-//
 // See https://ossf.github.io/osv-schema/#affectedrangestype-field.
 type RangeType string
 
-// RangeTypeSemver indicates a semantic version as defined by
-// SemVer 2.0.0, with no leading "v" prefix.
-const RangeTypeSemver RangeType = "SEMVER"
+const (
+	// RangeTypeSemver indicates a semantic version as defined by
+	// SemVer 2.0.0, with no leading "v" prefix.
+	RangeTypeSemver RangeType = "SEMVER"
+	// RangeTypeEcosystem indicates a version as defined by the
+	// package ecosystem. For the Go ecosystem, these are module
+	// versions, possibly with a leading "v" prefix.
+	RangeTypeEcosystem RangeType = "ECOSYSTEM"
+	// RangeTypeGit indicates full-length git commit hashes in the
+	// repository given by the range's Repo field.
+	RangeTypeGit RangeType = "GIT"
+)
 
 // Ecosystem identifies the overall library ecosystem.
 // In this implementation, only the "Go" ecosystem is supported.
@@ -59,6 +69,9 @@ type Module struct {
 	// The ecosystem containing the module. Required.
 	// This should always be "Go".
 	Ecosystem Ecosystem `json:"ecosystem"`
+	// The Package URL of the module, regardless of version, as
+	// returned by PackageURL. Optional.
+	Purl string `json:"purl,omitempty"`
 }
 
 // RangeEvent describes a single module version that either
@@ -85,8 +98,10 @@ type RangeEvent struct {
 type Range struct {
 	// Type is the version type that should be used to interpret the
 	// versions in Events. Required.
-	// In this implementation, only the "SEMVER" type is supported.
 	Type RangeType `json:"type"`
+	// Repo is the URL of the repository for ranges of type "GIT".
+	// Required for "GIT" ranges and not used otherwise.
+	Repo string `json:"repo,omitempty"`
 	// Events is a list of versions representing the ranges in which
 	// the module is vulnerable. Required.
 	// The events should be sorted, and MUST represent non-overlapping
@@ -137,10 +152,25 @@ type Affected struct {
 	// The affected Go module. Required.
 	// Note that this field is called "package" in the OSV specification.
 	Module Module `json:"package"`
+	// Severity is the severity of the vulnerability for this
+	// particular module, if it differs from the Entry severity.
+	Severity []Severity `json:"severity,omitempty"`
 	// The module version ranges affected by the vulnerability.
 	Ranges []Range `json:"ranges,omitempty"`
 	// Details on the affected packages and symbols within the module.
 	EcosystemSpecific EcosystemSpecific `json:"ecosystem_specific"`
+	// DatabaseSpecific holds the raw database-specific data for this
+	// module, if any. Use DecodeDatabaseSpecific to read it.
+	DatabaseSpecific json.RawMessage `json:"database_specific,omitempty"`
+}
+
+// DecodeDatabaseSpecific unmarshals the database-specific data of a
+// into v. It does nothing if a has no such data.
+func (a *Affected) DecodeDatabaseSpecific(v any) error {
+	if len(a.DatabaseSpecific) == 0 {
+		return nil
+	}
+	return json.Unmarshal(a.DatabaseSpecific, v)
 }
 
 // Package contains additional information about an affected package.
@@ -197,12 +227,18 @@ type Entry struct {
 	// Aliases is a list of IDs for the same vulnerability in other
 	// databases.
 	Aliases []string `json:"aliases,omitempty"`
+	// Related is a list of IDs for closely related vulnerabilities,
+	// such as the same problem in alternate ecosystems.
+	Related []string `json:"related,omitempty"`
 	// Summary gives a one-line, English textual summary of the vulnerability.
 	// It is recommended that this field be kept short, on the order of no more
 	// than 120 characters.
 	Summary string `json:"summary,omitempty"`
 	// Details contains additional English textual details about the vulnerability.
 	Details string `json:"details"`
+	// Severity contains the severity scores of the vulnerability.
+	// The Go vulnerability database does not populate this field.
+	Severity []Severity `json:"severity,omitempty"`
 	// Affected contains information on the modules and versions
 	// affected by the vulnerability.
 	Affected []Affected `json:"affected"`
@@ -225,6 +261,57 @@ type Credit struct {
 	// Name is the name, label, or other identifier of the individual or
 	// entity being credited. Required.
 	Name string `json:"name"`
+	// Contact is a list of fully qualified URLs (such as mailto:
+	// or https:// links) to contact the credited entity.
+	Contact []string `json:"contact,omitempty"`
+	// Type is the role of the credited entity.
+	Type CreditType `json:"type,omitempty"`
+}
+
+// CreditType is the role of a credited entity.
+//
+// See https://ossf.github.io/osv-schema/#creditstype-field.
+type CreditType string
+
+const (
+	CreditTypeFinder               = CreditType("FINDER")
+	CreditTypeReporter             = CreditType("REPORTER")
+	CreditTypeAnalyst              = CreditType("ANALYST")
+	CreditTypeCoordinator          = CreditType("COORDINATOR")
+	CreditTypeRemediationDeveloper = CreditType("REMEDIATION_DEVELOPER")
+	CreditTypeRemediationReviewer  = CreditType("REMEDIATION_REVIEWER")
+	CreditTypeRemediationVerifier  = CreditType("REMEDIATION_VERIFIER")
+	CreditTypeTool                 = CreditType("TOOL")
+	CreditTypeSponsor              = CreditType("SPONSOR")
+	CreditTypeOther                = CreditType("OTHER")
+)
+
+// SeverityType is the quantitative method used to calculate
+// a severity score.
+//
+// See https://ossf.github.io/osv-schema/#severitytype-field.
+type SeverityType string
+
+const (
+	// SeverityTypeCVSSV2 is a CVSS v2 vector string.
+	SeverityTypeCVSSV2 = SeverityType("CVSS_V2")
+	// SeverityTypeCVSSV3 is a CVSS v3.0 or v3.1 vector string.
+	SeverityTypeCVSSV3 = SeverityType("CVSS_V3")
+	// SeverityTypeCVSSV4 is a CVSS v4.0 vector string.
+	SeverityTypeCVSSV4 = SeverityType("CVSS_V4")
+	// SeverityTypeUbuntu is an Ubuntu priority level.
+	SeverityTypeUbuntu = SeverityType("Ubuntu")
+)
+
+// Severity is a severity score of a vulnerability.
+//
+// See https://ossf.github.io/osv-schema/#severity-field.
+type Severity struct {
+	// Type is the scoring method. Required.
+	Type SeverityType `json:"type"`
+	// Score is the score itself, whose form depends on Type.
+	// For the CVSS types, this is a vector string. Required.
+	Score string `json:"score"`
 }
 
 // DatabaseSpecific contains additional information about the
@@ -238,3 +325,19 @@ type DatabaseSpecific struct {
 	// The review status of this report (UNREVIEWED or REVIEWED).
 	ReviewStatus ReviewStatus `json:"review_status,omitempty"`
 }
+
+// IsWithdrawn reports whether e has been withdrawn as of now.
+func (e *Entry) IsWithdrawn(now time.Time) bool {
+	return e.Withdrawn != nil && !e.Withdrawn.After(now)
+}
+
+// SeverityScore returns the score of e for the severity type t,
+// or the empty string if e has no such score.
+func (e *Entry) SeverityScore(t SeverityType) string {
+	for _, s := range e.Severity {
+		if s.Type == t {
+			return s.Score
+		}
+	}
+	return ""
+}
@@ -5,11 +5,91 @@
 package osv_test
 
 import (
+	"encoding/json"
 	"testing"
+	"time"
 
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/vuln/internal/osv"
 	"golang.org/x/vuln/internal/test"
 )
 
 func TestImports(t *testing.T) {
 	test.VerifyImports(t) // no non stdlib imports allowed
 }
+
+func TestEntrySchemaFields(t *testing.T) {
+	const raw = `{
+		"id": "GHSA-xxxx-yyyy-zzzz",
+		"withdrawn": "2024-01-02T00:00:00Z",
+		"related": ["CVE-2024-0001"],
+		"details": "",
+		"severity": [{"type": "CVSS_V3", "score": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"}],
+		"affected": [{
+			"package": {"name": "example.com/m", "ecosystem": "Go"},
+			"severity": [{"type": "CVSS_V4", "score": "CVSS:4.0/AV:N"}],
+			"ecosystem_specific": {},
+			"database_specific": {"source":"https://example.com/advisory"}
+		}],
+		"credits": [{"name": "Gopher", "contact": ["mailto:gopher@example.com"], "type": "FINDER"}]
+	}`
+	var e osv.Entry
+	if err := json.Unmarshal([]byte(raw), &e); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := e.SeverityScore(osv.SeverityTypeCVSSV3), "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"; got != want {
+		t.Errorf("SeverityScore(CVSS_V3) = %q, want %q", got, want)
+	}
+	if got := e.SeverityScore(osv.SeverityTypeCVSSV2); got != "" {
+		t.Errorf("SeverityScore(CVSS_V2) = %q, want empty", got)
+	}
+	if !e.IsWithdrawn(time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Error("IsWithdrawn after withdrawal = false, want true")
+	}
+	if e.IsWithdrawn(time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Error("IsWithdrawn before withdrawal = true, want false")
+	}
+
+	var dbs struct {
+		Source string `json:"source"`
+	}
+	if err := e.Affected[0].DecodeDatabaseSpecific(&dbs); err != nil {
+		t.Fatal(err)
+	}
+	if want := "https://example.com/advisory"; dbs.Source != want {
+		t.Errorf("database_specific source = %q, want %q", dbs.Source, want)
+	}
+
+	want := []osv.Credit{{Name: "Gopher", Contact: []string{"mailto:gopher@example.com"}, Type: osv.CreditTypeFinder}}
+	if diff := cmp.Diff(want, e.Credits); diff != "" {
+		t.Errorf("credits mismatch (-want, +got):\n%s", diff)
+	}
+
+	// Round trip the entry to make sure no information is lost.
+	b, err := json.Marshal(&e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var e2 osv.Entry
+	if err := json.Unmarshal(b, &e2); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(e, e2); diff != "" {
+		t.Errorf("round trip mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+func TestPackageURL(t *testing.T) {
+	for _, test := range []struct {
+		path, version, want string
+	}{
+		{"github.com/user/module", "v0.5.7", "pkg:golang/github.com%2Fuser%2Fmodule@v0.5.7"},
+		{"github.com/user/module/submodule", "", "pkg:golang/github.com%2Fuser%2Fmodule%2Fsubmodule"},
+		{"stdlib", "v1.21.3", "pkg:golang/stdlib@v1.21.3"},
+	} {
+		if got := osv.PackageURL(test.path, test.version); got != test.want {
+			t.Errorf("PackageURL(%q, %q) = %q, want %q", test.path, test.version, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package osv
+
+import (
+	"net/url"
+	"strings"
+)
+
+// PackageURL returns the Package URL (purl) of the Go module with path
+// at version, or of the module regardless of version if version is
+// empty.
+//
+// The purl is printed as pkg:golang/MODULE_PATH@VERSION. Conceptually
+// there is no namespace and the name is entirely defined by the module
+// path, so its slashes are escaped. See
+// https://github.com/package-url/purl-spec/issues/63 for further
+// discussion.
+func PackageURL(path, version string) string {
+	var b strings.Builder
+	b.WriteString("pkg:golang/")
+	b.WriteString(url.PathEscape(path))
+	if version != "" {
+		b.WriteString("@")
+		b.WriteString(version)
+	}
+	return b.String()
+}
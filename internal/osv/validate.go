@@ -0,0 +1,380 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package osv
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Validate checks that e is a well-formed Go OSV entry. It returns
+// an error describing every problem found, or nil if there are none.
+//
+// Validate checks the range events of each affected module (type,
+// version syntax, ordering, and introduced/fixed pairing), package
+// import paths, alias and related ID formats, references, and
+// severity scores.
+func Validate(e *Entry) error {
+	return errors.Join(check(e, false)...)
+}
+
+// Lint is like Validate, but it additionally reports problems that do
+// not make e invalid but are discouraged for entries in the Go
+// vulnerability database, such as an overly long summary or a missing
+// fix reference.
+func Lint(e *Entry) []error {
+	return check(e, true)
+}
+
+// maxSummaryLength is the recommended maximum summary length.
+const maxSummaryLength = 120
+
+func check(e *Entry, lint bool) []error {
+	var errs []error
+	add := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if e.ID == "" {
+		add("id: required")
+	} else if !idRegex.MatchString(e.ID) {
+		add("id: %q is not of the form <DATABASE>-<ID>", e.ID)
+	}
+	if e.Modified.IsZero() {
+		add("modified: required")
+	}
+	for _, a := range e.Aliases {
+		if err := checkAlias(a); err != nil {
+			add("aliases: %v", err)
+		}
+	}
+	for _, r := range e.Related {
+		if !idRegex.MatchString(r) {
+			add("related: %q is not of the form <DATABASE>-<ID>", r)
+		}
+	}
+	for _, s := range e.Severity {
+		if err := checkSeverity(s); err != nil {
+			add("severity: %v", err)
+		}
+	}
+	if len(e.Affected) == 0 {
+		add("affected: at least one affected module is required")
+	}
+	for i, a := range e.Affected {
+		for _, err := range checkAffected(a) {
+			add("affected[%d]: %v", i, err)
+		}
+	}
+	for _, r := range e.References {
+		if err := checkReference(r); err != nil {
+			add("references: %v", err)
+		}
+	}
+
+	if !lint {
+		return errs
+	}
+	if e.Summary == "" {
+		add("summary: missing")
+	} else if n := utf8.RuneCountInString(e.Summary); n > maxSummaryLength {
+		add("summary: %d characters long, should be at most %d", n, maxSummaryLength)
+	}
+	if e.Details == "" {
+		add("details: missing")
+	}
+	if !hasFixReference(e) && hasFix(e) {
+		add("references: a fixed version is listed but there is no FIX reference")
+	}
+	return errs
+}
+
+var (
+	// idRegex matches OSV identifiers, which have the
+	// form <DATABASE>-<ENTRYID>.
+	idRegex = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9]*(-[A-Za-z0-9._:]+)+$`)
+
+	// Known alias formats.
+	cveRegex  = regexp.MustCompile(`^CVE-\d{4}-\d{4,}$`)
+	ghsaRegex = regexp.MustCompile(`^GHSA-[23456789cfghjmpqrvwx]{4}-[23456789cfghjmpqrvwx]{4}-[23456789cfghjmpqrvwx]{4}$`)
+	goRegex   = regexp.MustCompile(`^GO-\d{4}-\d{4,}$`)
+)
+
+func checkAlias(alias string) error {
+	prefix, _, _ := strings.Cut(alias, "-")
+	var re *regexp.Regexp
+	switch prefix {
+	case "CVE":
+		re = cveRegex
+	case "GHSA":
+		re = ghsaRegex
+	case "GO":
+		re = goRegex
+	default:
+		re = idRegex
+	}
+	if !re.MatchString(alias) {
+		return fmt.Errorf("%q is not a valid %s identifier", alias, prefix)
+	}
+	return nil
+}
+
+func checkSeverity(s Severity) error {
+	if s.Score == "" {
+		return fmt.Errorf("score for %s is required", s.Type)
+	}
+	switch s.Type {
+	case SeverityTypeCVSSV2, SeverityTypeUbuntu:
+	case SeverityTypeCVSSV3:
+		if !strings.HasPrefix(s.Score, "CVSS:3.") {
+			return fmt.Errorf("%q is not a CVSS v3 vector", s.Score)
+		}
+	case SeverityTypeCVSSV4:
+		if !strings.HasPrefix(s.Score, "CVSS:4.0/") {
+			return fmt.Errorf("%q is not a CVSS v4 vector", s.Score)
+		}
+	default:
+		return fmt.Errorf("unknown type %q", s.Type)
+	}
+	return nil
+}
+
+func checkReference(r Reference) error {
+	switch r.Type {
+	case ReferenceTypeAdvisory, ReferenceTypeArticle, ReferenceTypeReport,
+		ReferenceTypeFix, ReferenceTypePackage, ReferenceTypeEvidence, ReferenceTypeWeb:
+	default:
+		return fmt.Errorf("unknown type %q for %s", r.Type, r.URL)
+	}
+	u, err := url.Parse(r.URL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("%q is not an absolute URL", r.URL)
+	}
+	return nil
+}
+
+func checkAffected(a Affected) []error {
+	var errs []error
+	add := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	mod := a.Module.Path
+	if mod == "" {
+		add("package name: required")
+	} else if mod != GoStdModulePath && mod != GoCmdModulePath {
+		if err := checkImportPath(mod); err != nil {
+			add("package name: %v", err)
+		}
+	}
+	if a.Module.Ecosystem != GoEcosystem {
+		add("package ecosystem: %q, want %q", a.Module.Ecosystem, GoEcosystem)
+	}
+	for _, s := range a.Severity {
+		if err := checkSeverity(s); err != nil {
+			add("severity: %v", err)
+		}
+	}
+	for i, r := range a.Ranges {
+		for _, err := range checkRange(r) {
+			add("ranges[%d]: %v", i, err)
+		}
+	}
+	for _, p := range a.EcosystemSpecific.Packages {
+		if p.Path == "" {
+			add("imports: package path required")
+			continue
+		}
+		if err := checkImportPath(p.Path); err != nil {
+			add("imports: %v", err)
+			continue
+		}
+		if mod != GoStdModulePath && mod != GoCmdModulePath && mod != "" &&
+			p.Path != mod && !strings.HasPrefix(p.Path, mod+"/") {
+			add("imports: package %s is not in module %s", p.Path, mod)
+		}
+	}
+	return errs
+}
+
+// checkRange checks the events of a SEMVER range. Ranges of other types
+// are only checked for being non-empty (and, for GIT ranges, having a
+// repository) since their versions are opaque.
+func checkRange(r Range) []error {
+	var errs []error
+	add := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if len(r.Events) == 0 {
+		add("at least one event is required")
+		return errs
+	}
+	switch r.Type {
+	case RangeTypeSemver:
+	case RangeTypeEcosystem:
+		return errs
+	case RangeTypeGit:
+		if r.Repo == "" {
+			add("repo: required for %s ranges", r.Type)
+		}
+		return errs
+	default:
+		add("unknown type %q", r.Type)
+		return errs
+	}
+
+	prev := ""        // version of the previous event
+	affected := false // whether the previous event was an introduction
+	for i, e := range r.Events {
+		if (e.Introduced == "") == (e.Fixed == "") {
+			add("events[%d]: exactly one of introduced and fixed must be set", i)
+			return errs
+		}
+		v := e.Introduced
+		if e.Fixed != "" {
+			v = e.Fixed
+		}
+		if !(e.Introduced == "0" || isSemver(v)) {
+			add("events[%d]: %q is not a valid SEMVER version (no leading \"v\")", i, v)
+			return errs
+		}
+		switch {
+		case e.Introduced != "" && affected:
+			add("events[%d]: introduced %s follows another introduced event", i, v)
+		case e.Fixed != "" && !affected:
+			add("events[%d]: fixed %s does not follow an introduced event", i, v)
+		case e.Introduced == "0" && i != 0:
+			add("events[%d]: introduced 0 must be the first event", i)
+		case prev != "" && e.Introduced != "0" && compareSemver(prev, v) >= 0:
+			add("events[%d]: %s is not greater than the previous version %s", i, v, prev)
+		}
+		affected = e.Introduced != ""
+		if e.Introduced != "0" {
+			prev = v
+		}
+	}
+	return errs
+}
+
+func hasFix(e *Entry) bool {
+	for _, a := range e.Affected {
+		for _, r := range a.Ranges {
+			for _, ev := range r.Events {
+				if ev.Fixed != "" {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func hasFixReference(e *Entry) bool {
+	for _, r := range e.References {
+		if r.Type == ReferenceTypeFix {
+			return true
+		}
+	}
+	return false
+}
+
+// checkImportPath reports whether path is a syntactically valid
+// import path. It is a simplified version of module.CheckImportPath,
+// which is not available here as this package may only depend on
+// the standard library.
+func checkImportPath(path string) error {
+	if !utf8.ValidString(path) {
+		return fmt.Errorf("%q is not valid UTF-8", path)
+	}
+	if path == "" || path[0] == '/' || path[len(path)-1] == '/' {
+		return fmt.Errorf("%q has a leading or trailing slash", path)
+	}
+	for _, elem := range strings.Split(path, "/") {
+		if elem == "" || elem == "." || elem == ".." {
+			return fmt.Errorf("%q has an empty, \".\", or \"..\" path element", path)
+		}
+		if elem[0] == '.' || elem[len(elem)-1] == '.' {
+			return fmt.Errorf("%q has a path element beginning or ending with a dot", path)
+		}
+		for _, r := range elem {
+			if !importPathOK(r) {
+				return fmt.Errorf("%q contains invalid character %q", path, r)
+			}
+		}
+	}
+	return nil
+}
+
+func importPathOK(r rune) bool {
+	return 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9' ||
+		strings.ContainsRune("-._~+", r)
+}
+
+// semverRegex matches SemVer 2.0.0 versions without a "v" prefix.
+// See https://semver.org/#is-there-a-suggested-regular-expression-regex-to-check-a-semver-string.
+var semverRegex = regexp.MustCompile(`^(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)` +
+	`(?:-((?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*)(?:\.(?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*))*))?` +
+	`(?:\+([0-9a-zA-Z-]+(?:\.[0-9a-zA-Z-]+)*))?$`)
+
+func isSemver(v string) bool {
+	return semverRegex.MatchString(v)
+}
+
+// compareSemver compares valid semantic versions v and w by precedence,
+// returning -1, 0, or +1.
+func compareSemver(v, w string) int {
+	vm, wm := semverRegex.FindStringSubmatch(v), semverRegex.FindStringSubmatch(w)
+	for i := 1; i <= 3; i++ {
+		if c := compareNum(vm[i], wm[i]); c != 0 {
+			return c
+		}
+	}
+	vp, wp := vm[4], wm[4]
+	switch {
+	case vp == wp:
+		return 0
+	case vp == "":
+		return 1
+	case wp == "":
+		return -1
+	}
+	vs, ws := strings.Split(vp, "."), strings.Split(wp, ".")
+	for i := 0; i < len(vs) && i < len(ws); i++ {
+		_, verr := strconv.Atoi(vs[i])
+		_, werr := strconv.Atoi(ws[i])
+		switch {
+		case verr == nil && werr == nil:
+			if c := compareNum(vs[i], ws[i]); c != 0 {
+				return c
+			}
+		case verr == nil:
+			return -1 // numeric identifiers have lower precedence
+		case werr == nil:
+			return 1
+		default:
+			if c := strings.Compare(vs[i], ws[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	return compareNum(strconv.Itoa(len(vs)), strconv.Itoa(len(ws)))
+}
+
+// compareNum compares two decimal numbers without leading zeros.
+func compareNum(x, y string) int {
+	if len(x) != len(y) {
+		if len(x) < len(y) {
+			return -1
+		}
+		return 1
+	}
+	return strings.Compare(x, y)
+}
@@ -0,0 +1,175 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package osv
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func validEntry() *Entry {
+	return &Entry{
+		ID:       "GO-2024-0001",
+		Modified: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Aliases:  []string{"CVE-2024-1234", "GHSA-cfgh-jmpq-rvwx"},
+		Summary:  "Infinite loop in example.com/m",
+		Details:  "Parsing a crafted input loops forever.",
+		Affected: []Affected{{
+			Module: Module{Path: "example.com/m", Ecosystem: GoEcosystem},
+			Ranges: []Range{{
+				Type:   RangeTypeSemver,
+				Events: []RangeEvent{{Introduced: "0"}, {Fixed: "1.2.0"}, {Introduced: "1.3.0"}, {Fixed: "1.3.1-rc.1"}},
+			}},
+			EcosystemSpecific: EcosystemSpecific{
+				Packages: []Package{{Path: "example.com/m/parse", Symbols: []string{"Parse"}}},
+			},
+		}},
+		References: []Reference{{Type: ReferenceTypeFix, URL: "https://example.com/m/commit/abc"}},
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		modify  func(*Entry)
+		wantErr string // empty if valid
+	}{
+		{
+			name:   "valid",
+			modify: func(*Entry) {},
+		},
+		{
+			name:    "missing id",
+			modify:  func(e *Entry) { e.ID = "" },
+			wantErr: "id: required",
+		},
+		{
+			name: "bad ghsa alias",
+			// "a", "b", "e", ... are not in the GHSA alphabet.
+			modify:  func(e *Entry) { e.Aliases = []string{"GHSA-aaaa-bbbb-cccc"} },
+			wantErr: "not a valid GHSA identifier",
+		},
+		{
+			name: "v prefix",
+			modify: func(e *Entry) {
+				e.Affected[0].Ranges[0].Events[1].Fixed = "v1.2.0"
+			},
+			wantErr: "not a valid SEMVER version",
+		},
+		{
+			name: "unordered events",
+			modify: func(e *Entry) {
+				e.Affected[0].Ranges[0].Events[2].Introduced = "1.1.0"
+			},
+			wantErr: "not greater than the previous version 1.2.0",
+		},
+		{
+			name: "fixed without introduced",
+			modify: func(e *Entry) {
+				e.Affected[0].Ranges[0].Events = []RangeEvent{{Fixed: "1.0.0"}}
+			},
+			wantErr: "does not follow an introduced event",
+		},
+		{
+			name: "both introduced and fixed",
+			modify: func(e *Entry) {
+				e.Affected[0].Ranges[0].Events[1].Introduced = "1.1.0"
+			},
+			wantErr: "exactly one of introduced and fixed",
+		},
+		{
+			name: "git range without repo",
+			modify: func(e *Entry) {
+				e.Affected[0].Ranges = append(e.Affected[0].Ranges, Range{
+					Type:   RangeTypeGit,
+					Events: []RangeEvent{{Introduced: "0"}, {Fixed: "abc123"}},
+				})
+			},
+			wantErr: "repo: required for GIT ranges",
+		},
+		{
+			name: "package outside module",
+			modify: func(e *Entry) {
+				e.Affected[0].EcosystemSpecific.Packages[0].Path = "example.com/other"
+			},
+			wantErr: "not in module example.com/m",
+		},
+		{
+			name: "invalid import path",
+			modify: func(e *Entry) {
+				e.Affected[0].EcosystemSpecific.Packages[0].Path = "example.com/m/../x"
+			},
+			wantErr: `".." path element`,
+		},
+		{
+			name: "bad severity",
+			modify: func(e *Entry) {
+				e.Severity = []Severity{{Type: SeverityTypeCVSSV3, Score: "AV:N"}}
+			},
+			wantErr: "not a CVSS v3 vector",
+		},
+		{
+			name: "relative reference",
+			modify: func(e *Entry) {
+				e.References[0].URL = "/commit/abc"
+			},
+			wantErr: "not an absolute URL",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			e := validEntry()
+			tc.modify(e)
+			err := Validate(e)
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("Validate() = %v, want error containing %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestLint(t *testing.T) {
+	e := validEntry()
+	if errs := Lint(e); len(errs) != 0 {
+		t.Fatalf("Lint(valid) = %v, want no errors", errs)
+	}
+
+	e.Summary = strings.Repeat("x", maxSummaryLength+1)
+	e.References = nil
+	errs := Lint(e)
+	if len(errs) != 2 {
+		t.Fatalf("Lint() = %v, want 2 errors", errs)
+	}
+	// Lint findings alone do not make an entry invalid.
+	if err := Validate(e); err != nil {
+		t.Errorf("Validate() = %v, want nil", err)
+	}
+}
+
+func TestCompareSemver(t *testing.T) {
+	for _, tc := range []struct {
+		v, w string
+		want int
+	}{
+		{"1.0.0", "1.0.0", 0},
+		{"1.0.0", "1.0.1", -1},
+		{"1.10.0", "1.9.0", 1},
+		{"1.0.0-rc.1", "1.0.0", -1},
+		{"1.0.0-alpha", "1.0.0-alpha.1", -1},
+		{"1.0.0-alpha.1", "1.0.0-alpha.beta", -1},
+		{"1.0.0-rc.2", "1.0.0-rc.10", -1},
+		{"1.0.0+build", "1.0.0", 0},
+	} {
+		if got := compareSemver(tc.v, tc.w); got != tc.want {
+			t.Errorf("compareSemver(%s, %s) = %d, want %d", tc.v, tc.w, got, tc.want)
+		}
+	}
+}
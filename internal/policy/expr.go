@@ -0,0 +1,708 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// This file implements the subset of CEL supported in policies.
+//
+// Values are booleans, 64-bit integers, strings, and lists. The
+// supported operators are, from lowest to highest precedence:
+//
+//	c ? x : y
+//	||
+//	&&
+//	== != < <= > >= in
+//	+ -
+//	! and unary -
+//
+// The functions are size(x), and the string methods
+// s.startsWith(t), s.endsWith(t), and s.contains(t).
+// As in CEL, operands must have the same type; there
+// are no implicit conversions, and integer arithmetic that
+// overflows is an error. String literals are in
+// single or double quotes, with the escapes of CEL.
+//
+// The rest of CEL is left out: raw, triple-quoted, and
+// bytes literals; unsigned, floating-point, and null
+// values; maps, timestamps, and durations; macros such
+// as all and exists; and the other functions.
+
+// An expr is an expression of the policy language.
+type expr interface {
+	eval(vars map[string]any) (any, error)
+}
+
+type (
+	literal struct{ v any }
+	ident   struct{ name string }
+	list    struct{ elems []expr }
+	unary   struct {
+		op string
+		x  expr
+	}
+	binary struct {
+		op   string
+		x, y expr
+	}
+	conditional struct{ cond, then, els expr }
+	call        struct {
+		fn   string
+		recv expr // nil for global functions
+		args []expr
+	}
+)
+
+// token kinds, besides the operators, which are their own kind.
+const (
+	tokEOF    = "EOF"
+	tokIdent  = "identifier"
+	tokInt    = "integer"
+	tokString = "string"
+)
+
+type token struct {
+	kind string
+	text string
+	pos  int
+}
+
+// lex splits src into tokens. CEL line comments are skipped.
+func lex(src string) ([]token, error) {
+	var toks []token
+	i := 0
+	for i < len(src) {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case strings.HasPrefix(src[i:], "//"):
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case c == '_' || isLetter(c):
+			j := i
+			for j < len(src) && (src[j] == '_' || isLetter(src[j]) || isDigit(src[j])) {
+				j++
+			}
+			kind := tokIdent
+			if src[i:j] == "in" {
+				kind = "in"
+			}
+			toks = append(toks, token{kind, src[i:j], i})
+			i = j
+		case isDigit(c):
+			j := i
+			for j < len(src) && isDigit(src[j]) {
+				j++
+			}
+			toks = append(toks, token{tokInt, src[i:j], i})
+			i = j
+		case c == '"' || c == '\'':
+			j := i + 1
+			for j < len(src) && src[j] != c && src[j] != '\n' {
+				if src[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(src) || src[j] != c {
+				return nil, &syntaxError{i, "string literal not terminated"}
+			}
+			toks = append(toks, token{tokString, src[i : j+1], i})
+			i = j + 1
+		default:
+			op := ""
+			for _, o := range []string{"||", "&&", "==", "!=", "<=", ">=", "<", ">", "!", "?", ":", "(", ")", "[", "]", ",", ".", "+", "-"} {
+				if strings.HasPrefix(src[i:], o) {
+					op = o
+					break
+				}
+			}
+			if op == "" {
+				r, _ := utf8.DecodeRuneInString(src[i:])
+				return nil, &syntaxError{i, fmt.Sprintf("unexpected character %q", r)}
+			}
+			toks = append(toks, token{op, op, i})
+			i += len(op)
+		}
+	}
+	return append(toks, token{tokEOF, "", len(src)}), nil
+}
+
+func isLetter(c byte) bool { return c < utf8.RuneSelf && unicode.IsLetter(rune(c)) }
+func isDigit(c byte) bool  { return '0' <= c && c <= '9' }
+
+// A syntaxError is an error at an offset of the source of a policy.
+type syntaxError struct {
+	pos int
+	msg string
+}
+
+func (e *syntaxError) Error() string { return e.msg }
+
+// parser is a recursive descent parser of expressions.
+type parser struct {
+	toks []token
+	vars map[string]bool
+}
+
+func (p *parser) peek() token { return p.toks[0] }
+
+func (p *parser) next() token {
+	t := p.toks[0]
+	if t.kind != tokEOF {
+		p.toks = p.toks[1:]
+	}
+	return t
+}
+
+func (p *parser) expect(kind string) (token, error) {
+	t := p.next()
+	if t.kind != kind {
+		return t, p.unexpected(t)
+	}
+	return t, nil
+}
+
+func (p *parser) unexpected(t token) error {
+	if t.kind == tokEOF {
+		return &syntaxError{t.pos, "unexpected end of policy"}
+	}
+	return &syntaxError{t.pos, fmt.Sprintf("unexpected %s", t.text)}
+}
+
+// parse parses the source of an expression that may
+// refer to the given variables.
+func parse(src string, vars map[string]bool) (expr, error) {
+	toks, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{toks: toks, vars: vars}
+	x, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.next(); t.kind != tokEOF {
+		return nil, p.unexpected(t)
+	}
+	return x, nil
+}
+
+func (p *parser) expr() (expr, error) {
+	c, err := p.binary(0)
+	if err != nil || p.peek().kind != "?" {
+		return c, err
+	}
+	p.next()
+	then, err := p.binary(0)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.expect(":"); err != nil {
+		return nil, err
+	}
+	els, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+	return &conditional{c, then, els}, nil
+}
+
+// precedences lists the binary operators by increasing precedence.
+var precedences = [][]string{
+	{"||"},
+	{"&&"},
+	{"==", "!=", "<", "<=", ">", ">=", "in"},
+	{"+", "-"},
+}
+
+// binary parses a sequence of operands separated by
+// binary operators of precedence prec or higher.
+func (p *parser) binary(prec int) (expr, error) {
+	if prec == len(precedences) {
+		return p.unary()
+	}
+	x, err := p.binary(prec + 1)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := p.peek().kind
+		found := false
+		for _, o := range precedences[prec] {
+			found = found || op == o
+		}
+		if !found {
+			return x, nil
+		}
+		p.next()
+		y, err := p.binary(prec + 1)
+		if err != nil {
+			return nil, err
+		}
+		x = &binary{op, x, y}
+	}
+}
+
+func (p *parser) unary() (expr, error) {
+	if op := p.peek().kind; op == "!" || op == "-" {
+		minus := p.next()
+		if op == "-" && p.peek().kind == tokInt {
+			// A negative literal, so that the least
+			// integer can be written.
+			return intLiteral(minus.pos, "-"+p.next().text)
+		}
+		x, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return &unary{op, x}, nil
+	}
+	return p.member()
+}
+
+func (p *parser) member() (expr, error) {
+	x, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == "." {
+		p.next()
+		t, err := p.expect(tokIdent)
+		if err != nil {
+			return nil, err
+		}
+		if p.peek().kind != "(" {
+			return nil, &syntaxError{t.pos, fmt.Sprintf("undefined field %s", t.text)}
+		}
+		args, err := p.args()
+		if err != nil {
+			return nil, err
+		}
+		x, err = newCall(t, x, args)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return x, nil
+}
+
+// intLiteral returns the literal of the integer text at pos.
+func intLiteral(pos int, text string) (expr, error) {
+	n, err := strconv.ParseInt(text, 10, 64)
+	if errors.Is(err, strconv.ErrRange) {
+		return nil, &syntaxError{pos, fmt.Sprintf("integer %s out of range", text)}
+	}
+	if err != nil {
+		return nil, &syntaxError{pos, fmt.Sprintf("invalid integer %s", text)}
+	}
+	return &literal{n}, nil
+}
+
+func (p *parser) primary() (expr, error) {
+	t := p.next()
+	switch t.kind {
+	case tokInt:
+		return intLiteral(t.pos, t.text)
+	case tokString:
+		s, err := unquote(t.text)
+		if err != nil {
+			return nil, &syntaxError{t.pos, fmt.Sprintf("invalid string literal %s: %v", t.text, err)}
+		}
+		return &literal{s}, nil
+	case tokIdent:
+		switch t.text {
+		case "true", "false":
+			return &literal{t.text == "true"}, nil
+		}
+		if p.peek().kind == "(" {
+			args, err := p.args()
+			if err != nil {
+				return nil, err
+			}
+			return newCall(t, nil, args)
+		}
+		if !p.vars[t.text] {
+			return nil, &syntaxError{t.pos, fmt.Sprintf("undeclared reference to %s", t.text)}
+		}
+		return &ident{t.text}, nil
+	case "(":
+		x, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return x, nil
+	case "[":
+		l := &list{}
+		for p.peek().kind != "]" {
+			x, err := p.expr()
+			if err != nil {
+				return nil, err
+			}
+			l.elems = append(l.elems, x)
+			if p.peek().kind != "," {
+				break
+			}
+			p.next()
+		}
+		if _, err := p.expect("]"); err != nil {
+			return nil, err
+		}
+		return l, nil
+	}
+	return nil, p.unexpected(t)
+}
+
+// args parses the parenthesized arguments of a call.
+func (p *parser) args() ([]expr, error) {
+	if _, err := p.expect("("); err != nil {
+		return nil, err
+	}
+	var args []expr
+	for p.peek().kind != ")" {
+		x, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, x)
+		if p.peek().kind != "," {
+			break
+		}
+		p.next()
+	}
+	if _, err := p.expect(")"); err != nil {
+		return nil, err
+	}
+	return args, nil
+}
+
+// newCall returns a call of the function named by t, checking
+// that it exists and is passed the right number of arguments.
+func newCall(t token, recv expr, args []expr) (expr, error) {
+	want := -1
+	switch {
+	case recv == nil && t.text == "size":
+		want = 1
+	case recv != nil && (t.text == "startsWith" || t.text == "endsWith" || t.text == "contains"):
+		want = 1
+	}
+	if want < 0 {
+		return nil, &syntaxError{t.pos, fmt.Sprintf("undeclared reference to %s", t.text)}
+	}
+	if len(args) != want {
+		return nil, &syntaxError{t.pos, fmt.Sprintf("%s takes %d argument, not %d", t.text, want, len(args))}
+	}
+	return &call{t.text, recv, args}, nil
+}
+
+// unquote returns the value of a CEL string literal in single
+// or double quotes. As in CEL, the escapes \x, \u, \U, and octal
+// ones denote code points, which are written in UTF-8.
+func unquote(s string) (string, error) {
+	body := s[1 : len(s)-1]
+	var b strings.Builder
+	for i := 0; i < len(body); i++ {
+		c := body[i]
+		if c != '\\' {
+			b.WriteByte(c)
+			continue
+		}
+		i++
+		if i == len(body) {
+			return "", errors.New("escape sequence not terminated")
+		}
+		esc, c := i-1, body[i]
+		if r, ok := simpleEscapes[c]; ok {
+			b.WriteByte(r)
+			continue
+		}
+		// The escape is numeric, with n digits in base.
+		n, base := 0, 16
+		switch c {
+		case 'x', 'X':
+			n = 2
+		case 'u':
+			n = 4
+		case 'U':
+			n = 8
+		case '0', '1', '2', '3':
+			// Octal escapes have no prefix.
+			n, base = 3, 8
+			i--
+		default:
+			return "", fmt.Errorf("unknown escape sequence \\%c", c)
+		}
+		digits := body[i+1 : min(i+1+n, len(body))]
+		i += len(digits)
+		v, err := strconv.ParseUint(digits, base, 32)
+		if err != nil || len(digits) != n {
+			return "", fmt.Errorf("invalid escape sequence %s", body[esc:i+1])
+		}
+		if !utf8.ValidRune(rune(v)) {
+			return "", fmt.Errorf("invalid code point %U", v)
+		}
+		b.WriteRune(rune(v))
+	}
+	return b.String(), nil
+}
+
+// simpleEscapes maps the characters of the CEL escapes of
+// one character, such as \n, to the bytes they denote.
+var simpleEscapes = map[byte]byte{
+	'a': '\a', 'b': '\b', 'f': '\f', 'n': '\n', 'r': '\r', 't': '\t', 'v': '\v',
+	'\\': '\\', '?': '?', '"': '"', '\'': '\'', '`': '`',
+}
+
+func (x *literal) eval(map[string]any) (any, error) { return x.v, nil }
+
+func (x *ident) eval(vars map[string]any) (any, error) { return vars[x.name], nil }
+
+func (x *list) eval(vars map[string]any) (any, error) {
+	var vs []any
+	for _, e := range x.elems {
+		v, err := e.eval(vars)
+		if err != nil {
+			return nil, err
+		}
+		vs = append(vs, v)
+	}
+	return vs, nil
+}
+
+func (x *unary) eval(vars map[string]any) (any, error) {
+	v, err := x.x.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+	switch v := v.(type) {
+	case bool:
+		if x.op == "!" {
+			return !v, nil
+		}
+	case int64:
+		if x.op == "-" {
+			if v == math.MinInt64 {
+				return nil, errOverflow
+			}
+			return -v, nil
+		}
+	}
+	return nil, noOverload(x.op, v)
+}
+
+func (x *conditional) eval(vars map[string]any) (any, error) {
+	c, err := evalBool(x.cond, vars, "?")
+	if err != nil {
+		return nil, err
+	}
+	if c {
+		return x.then.eval(vars)
+	}
+	return x.els.eval(vars)
+}
+
+func (x *binary) eval(vars map[string]any) (any, error) {
+	if x.op == "&&" || x.op == "||" {
+		l, err := evalBool(x.x, vars, x.op)
+		if err != nil {
+			return nil, err
+		}
+		if l == (x.op == "||") {
+			return l, nil
+		}
+		return evalBool(x.y, vars, x.op)
+	}
+	l, err := x.x.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+	r, err := x.y.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+	switch x.op {
+	case "in":
+		vs, ok := r.([]any)
+		if !ok {
+			return nil, noOverload(x.op, l, r)
+		}
+		for _, v := range vs {
+			if eq, err := equal(l, v); err != nil {
+				return nil, err
+			} else if eq {
+				return true, nil
+			}
+		}
+		return false, nil
+	case "==", "!=":
+		eq, err := equal(l, r)
+		if err != nil {
+			return nil, err
+		}
+		return eq == (x.op == "=="), nil
+	}
+	switch l := l.(type) {
+	case int64:
+		if r, ok := r.(int64); ok {
+			switch x.op {
+			case "+":
+				if r > 0 && l > math.MaxInt64-r || r < 0 && l < math.MinInt64-r {
+					return nil, errOverflow
+				}
+				return l + r, nil
+			case "-":
+				if r < 0 && l > math.MaxInt64+r || r > 0 && l < math.MinInt64+r {
+					return nil, errOverflow
+				}
+				return l - r, nil
+			}
+			return compare(x.op, cmpInt(l, r)), nil
+		}
+	case string:
+		if r, ok := r.(string); ok && x.op != "-" {
+			if x.op == "+" {
+				return l + r, nil
+			}
+			return compare(x.op, strings.Compare(l, r)), nil
+		}
+	}
+	return nil, noOverload(x.op, l, r)
+}
+
+func (x *call) eval(vars map[string]any) (any, error) {
+	a, err := x.args[0].eval(vars)
+	if err != nil {
+		return nil, err
+	}
+	if x.recv == nil { // size
+		switch a := a.(type) {
+		case string:
+			return int64(utf8.RuneCountInString(a)), nil
+		case []any:
+			return int64(len(a)), nil
+		}
+		return nil, noOverload(x.fn, a)
+	}
+	r, err := x.recv.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+	s, ok1 := r.(string)
+	t, ok2 := a.(string)
+	if !ok1 || !ok2 {
+		return nil, noOverload(x.fn, r, a)
+	}
+	switch x.fn {
+	case "startsWith":
+		return strings.HasPrefix(s, t), nil
+	case "endsWith":
+		return strings.HasSuffix(s, t), nil
+	default: // contains
+		return strings.Contains(s, t), nil
+	}
+}
+
+// evalBool evaluates x, which must be a boolean operand of op.
+func evalBool(x expr, vars map[string]any, op string) (bool, error) {
+	v, err := x.eval(vars)
+	if err != nil {
+		return false, err
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, noOverload(op, v)
+	}
+	return b, nil
+}
+
+// equal reports whether a and b, which must have the same type, are equal.
+func equal(a, b any) (bool, error) {
+	switch a := a.(type) {
+	case []any:
+		b, ok := b.([]any)
+		if !ok {
+			break
+		}
+		if len(a) != len(b) {
+			return false, nil
+		}
+		for i := range a {
+			if eq, err := equal(a[i], b[i]); err != nil || !eq {
+				return false, err
+			}
+		}
+		return true, nil
+	case bool, int64, string:
+		if typeName(a) == typeName(b) {
+			return a == b, nil
+		}
+	}
+	return false, noOverload("==", a, b)
+}
+
+func cmpInt(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// compare returns the result of the comparison op
+// given the sign c of the difference of its operands.
+func compare(op string, c int) bool {
+	switch op {
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	}
+	return c >= 0
+}
+
+// noOverload returns the error for an operator or
+// function applied to operands of the wrong types.
+// errOverflow is the error of integer arithmetic whose
+// result does not fit in 64 bits, which CEL does not wrap.
+var errOverflow = errors.New("integer overflow")
+
+func noOverload(op string, operands ...any) error {
+	var types []string
+	for _, v := range operands {
+		types = append(types, typeName(v))
+	}
+	return fmt.Errorf("no such overload: %s(%s)", op, strings.Join(types, ", "))
+}
+
+func typeName(v any) string {
+	switch v.(type) {
+	case bool:
+		return "bool"
+	case int64:
+		return "int"
+	case string:
+		return "string"
+	case []any:
+		return "list"
+	}
+	return fmt.Sprintf("%T", v)
+}
@@ -0,0 +1,123 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package policy evaluates user policies that decide what
+// govulncheck does with each finding.
+//
+// A policy is an expression in a subset of the Common Expression
+// Language (CEL, https://cel.dev) that evaluates to "report",
+// "suppress", or "fail". For example,
+//
+//	severity in ["CRITICAL", "HIGH"] && reachable ? "fail" :
+//	reachable && fix_available_days > 30 ? "fail" :
+//	!reachable && !fix_available ? "suppress" :
+//	"report"
+//
+// The variables describing the finding are those of Input.
+package policy
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Action is what a policy decides to do with a finding.
+type Action string
+
+const (
+	// Report reports the finding without failing the scan.
+	Report = Action("report")
+	// Suppress omits the finding from the output.
+	Suppress = Action("suppress")
+	// Fail reports the finding and fails the scan.
+	Fail = Action("fail")
+)
+
+// Input describes a finding. The name of the policy
+// variable for each field is given in its comment.
+type Input struct {
+	// ID is the OSV ID of the vulnerability (id).
+	ID string
+	// Module is the path of the vulnerable module (module).
+	Module string
+	// FixedVersion is the version of the module that fixes
+	// the vulnerability, or "" if there is none (fixed_version).
+	FixedVersion string
+	// Severity is the qualitative severity rating of the
+	// vulnerability, as returned by Severity (severity).
+	Severity string
+	// Reachable reports whether a vulnerable symbol is called by
+	// the code in source mode, or present in the binary in binary
+	// mode (reachable).
+	Reachable bool
+	// FixAvailable reports whether FixedVersion is set (fix_available).
+	FixAvailable bool
+	// ModuleDepth is the number of module boundaries crossed by the
+	// call stack from the code to the vulnerable symbol, so it is 1
+	// when the code calls into the vulnerable module directly. It is
+	// 0 if there is no call stack (module_depth).
+	ModuleDepth int
+	// AgeDays is the number of days since the vulnerability
+	// was published (age_days).
+	AgeDays int
+	// FixAvailableDays is the number of days since the fixed version
+	// was released, or 0 if there is none or its release time is
+	// unknown (fix_available_days). See govulncheck.Disclosure.
+	FixAvailableDays int
+}
+
+// vars returns the values of the policy variables for in.
+func (in *Input) vars() map[string]any {
+	return map[string]any{
+		"id":                 in.ID,
+		"module":             in.Module,
+		"fixed_version":      in.FixedVersion,
+		"severity":           in.Severity,
+		"reachable":          in.Reachable,
+		"fix_available":      in.FixAvailable,
+		"module_depth":       int64(in.ModuleDepth),
+		"age_days":           int64(in.AgeDays),
+		"fix_available_days": int64(in.FixAvailableDays),
+	}
+}
+
+// A Policy is a parsed policy.
+type Policy struct {
+	x expr
+}
+
+// Parse parses the source of a policy.
+func Parse(src string) (*Policy, error) {
+	vars := make(map[string]bool)
+	for v := range (&Input{}).vars() {
+		vars[v] = true
+	}
+	x, err := parse(src, vars)
+	if err != nil {
+		if se, ok := err.(*syntaxError); ok {
+			line := 1 + strings.Count(src[:se.pos], "\n")
+			col := 1 + se.pos - (strings.LastIndex(src[:se.pos], "\n") + 1)
+			return nil, fmt.Errorf("%d:%d: %s", line, col, se.msg)
+		}
+		return nil, err
+	}
+	return &Policy{x: x}, nil
+}
+
+// Eval returns the action the policy decides on for the finding in.
+func (p *Policy) Eval(in *Input) (Action, error) {
+	v, err := p.x.eval(in.vars())
+	if err != nil {
+		return "", err
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("policy result must be a string, not %s", typeName(v))
+	}
+	switch a := Action(s); a {
+	case Report, Suppress, Fail:
+		return a, nil
+	}
+	return "", fmt.Errorf("policy result must be %q, %q, or %q, not %q", Report, Suppress, Fail, s)
+}
@@ -0,0 +1,204 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package policy
+
+import (
+	"testing"
+
+	"golang.org/x/vuln/internal/osv"
+)
+
+func TestEval(t *testing.T) {
+	in := &Input{
+		ID:               "GO-2024-0001",
+		Module:           "golang.org/x/text",
+		FixedVersion:     "v0.3.8",
+		Severity:         "HIGH",
+		Reachable:        true,
+		FixAvailable:     true,
+		ModuleDepth:      2,
+		AgeDays:          45,
+		FixAvailableDays: 20,
+	}
+	for _, test := range []struct {
+		policy string
+		want   Action
+	}{
+		{`"report"`, Report},
+		{`'suppress'`, Suppress},
+		{`reachable ? "fail" : "report"`, Fail},
+		{`!reachable ? "fail" : "report"`, Report},
+		{`severity in ["CRITICAL", "HIGH"] && reachable ? "fail" : "report"`, Fail},
+		{`severity in ["CRITICAL"] || !fix_available ? "fail" : "report"`, Report},
+		{`fix_available && age_days > 30 ? "fail" : "report"`, Fail},
+		{`age_days - 30 >= 15 ? "fail" : "report"`, Fail},
+		{`reachable && fix_available_days > 30 ? "fail" : "report"`, Report},
+		{`module_depth == 1 ? "fail" : module_depth < 3 ? "suppress" : "report"`, Suppress},
+		{`module.startsWith("golang.org/x/") && !id.endsWith("0002") ? "suppress" : "report"`, Suppress},
+		{`module.contains("example") ? "suppress" : "report"`, Report},
+		{`size(fixed_version) > 0 && size([1, 2]) == 2 ? "fail" : "report"`, Fail},
+		{`fixed_version < "v0.4.0" ? "fail" : "report"`, Fail},
+		{`"re" + "port"`, Report},
+		{`(reachable || false) && -age_days < 0 ? "fail" : "report"`, Fail},
+		{`age_days > -9223372036854775808 && 9223372036854775807 - age_days > 0 ? "fail" : "report"`, Fail},
+		{`-9223372036854775807 - 1 == -9223372036854775808 ? "fail" : "report"`, Fail},
+		// Short-circuiting skips the type error on the right.
+		{`!reachable && severity ? "fail" : "report"`, Report},
+		{"// A comment.\nreachable // reachable code\n? \"fail\" : \"report\"", Fail},
+	} {
+		p, err := Parse(test.policy)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", test.policy, err)
+		}
+		got, err := p.Eval(in)
+		if err != nil {
+			t.Fatalf("Eval(%q): %v", test.policy, err)
+		}
+		if got != test.want {
+			t.Errorf("Eval(%q) = %q, want %q", test.policy, got, test.want)
+		}
+	}
+}
+
+func TestUnquote(t *testing.T) {
+	for _, test := range []struct {
+		lit  string
+		want string
+	}{
+		{`"report"`, "report"},
+		{`'it\'s'`, "it's"},
+		{`"say \"hi\""`, `say "hi"`},
+		{`'a"b'`, `a"b`},
+		{`"a'b"`, "a'b"},
+		{`'\"'`, `"`},
+		{"\"\\a\\b\\f\\n\\r\\t\\v\\\\\\?\\`\"", "\a\b\f\n\r\t\v\\?`"},
+		{`"\x41\X42"`, "AB"},
+		{`"\xff"`, "\u00ff"},
+		{`"\u00e9"`, "é"},
+		{`"\U0001F600"`, "\U0001F600"},
+		{`"\101\060"`, "A0"},
+	} {
+		got, err := unquote(test.lit)
+		if err != nil {
+			t.Errorf("unquote(%s): %v", test.lit, err)
+		} else if got != test.want {
+			t.Errorf("unquote(%s) = %q, want %q", test.lit, got, test.want)
+		}
+	}
+}
+
+func TestUnquoteError(t *testing.T) {
+	for _, test := range []struct {
+		lit  string
+		want string
+	}{
+		{`"\q"`, `unknown escape sequence \q`},
+		{`"\8"`, `unknown escape sequence \8`},
+		{`"\x4"`, `invalid escape sequence \x4`},
+		{`"\u00g9"`, `invalid escape sequence \u00g9`},
+		{`"\400"`, `unknown escape sequence \4`},
+		{`"\08"`, `invalid escape sequence \08`},
+		{`"\uD800"`, `invalid code point U+D800`},
+		{`"\U00110000"`, `invalid code point U+110000`},
+	} {
+		_, err := unquote(test.lit)
+		if err == nil || err.Error() != test.want {
+			t.Errorf("unquote(%s): got error %v, want %q", test.lit, err, test.want)
+		}
+	}
+}
+
+func TestParseError(t *testing.T) {
+	for _, test := range []struct {
+		policy string
+		want   string
+	}{
+		{``, "1:1: unexpected end of policy"},
+		{`reachable ? "fail"`, "1:19: unexpected end of policy"},
+		{"reachable\n  && unknown", "2:6: undeclared reference to unknown"},
+		{`"fail`, "1:1: string literal not terminated"},
+		{`"fail\q"`, `1:1: invalid string literal "fail\q": unknown escape sequence \q`},
+		{`reachable # "fail"`, "1:11: unexpected character '#'"},
+		{`matches(id, "GO")`, "1:1: undeclared reference to matches"},
+		{`id.size`, "1:4: undefined field size"},
+		{`size(id, id)`, "1:1: size takes 1 argument, not 2"},
+		{`"fail" "report"`, "1:8: unexpected \"report\""},
+		{`age_days > 9223372036854775808`, "1:12: integer 9223372036854775808 out of range"},
+		{`age_days > -9223372036854775809`, "1:12: integer -9223372036854775809 out of range"},
+	} {
+		_, err := Parse(test.policy)
+		if err == nil || err.Error() != test.want {
+			t.Errorf("Parse(%q): got error %v, want %q", test.policy, err, test.want)
+		}
+	}
+}
+
+func TestEvalError(t *testing.T) {
+	for _, test := range []struct {
+		policy string
+		want   string
+	}{
+		{`reachable`, "policy result must be a string, not bool"},
+		{`"ignore"`, `policy result must be "report", "suppress", or "fail", not "ignore"`},
+		{`age_days > "30" ? "fail" : "report"`, "no such overload: >(int, string)"},
+		{`severity ? "fail" : "report"`, "no such overload: ?(string)"},
+		{`severity in "HIGH" ? "fail" : "report"`, "no such overload: in(string, string)"},
+		{`age_days > 9223372036854775807 + 1`, "integer overflow"},
+		{`age_days > -9223372036854775807 - 2`, "integer overflow"},
+		{`age_days > 1 - -9223372036854775807 - 1`, "integer overflow"},
+		{`age_days > -(-9223372036854775808)`, "integer overflow"},
+		{`age_days > -9223372036854775808 - -1 - age_days - 2`, "integer overflow"},
+	} {
+		p, err := Parse(test.policy)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", test.policy, err)
+		}
+		_, err = p.Eval(&Input{})
+		if err == nil || err.Error() != test.want {
+			t.Errorf("Eval(%q): got error %v, want %q", test.policy, err, test.want)
+		}
+	}
+}
+
+func TestSeverity(t *testing.T) {
+	for _, test := range []struct {
+		sevs []osv.Severity
+		want string
+	}{
+		{nil, ""},
+		{[]osv.Severity{{Type: osv.SeverityTypeCVSSV3, Score: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"}}, "CRITICAL"}, // 9.8
+		{[]osv.Severity{{Type: osv.SeverityTypeCVSSV3, Score: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:H"}}, "HIGH"},     // 7.5
+		{[]osv.Severity{{Type: osv.SeverityTypeCVSSV3, Score: "CVSS:3.0/AV:N/AC:L/PR:L/UI:R/S:C/C:L/I:L/A:N"}}, "MEDIUM"},   // 5.4
+		{[]osv.Severity{{Type: osv.SeverityTypeCVSSV3, Score: "CVSS:3.1/AV:L/AC:H/PR:H/UI:R/S:U/C:L/I:N/A:N"}}, "LOW"},      // 1.8
+		{[]osv.Severity{{Type: osv.SeverityTypeCVSSV3, Score: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:N"}}, "NONE"},
+		{[]osv.Severity{
+			{Type: osv.SeverityTypeCVSSV2, Score: "AV:N/AC:L/Au:N/C:P/I:P/A:P"},
+			{Type: osv.SeverityTypeCVSSV3, Score: "CVSS:3.1/AV:X"},
+			{Type: osv.SeverityTypeUbuntu, Score: "medium"},
+		}, "MEDIUM"},
+	} {
+		if got := Severity(test.sevs); got != test.want {
+			t.Errorf("Severity(%v) = %q, want %q", test.sevs, got, test.want)
+		}
+	}
+}
+
+func TestCVSS3BaseScore(t *testing.T) {
+	for _, test := range []struct {
+		vector string
+		want   float64
+	}{
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", 9.8},
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:C/C:H/I:H/A:H", 10},
+		{"CVSS:3.0/AV:N/AC:L/PR:L/UI:R/S:C/C:L/I:L/A:N", 5.4},
+		{"CVSS:3.1/AV:P/AC:H/PR:H/UI:R/S:U/C:L/I:N/A:N", 1.6},
+		{"CVSS:3.1/AV:L/AC:L/PR:L/UI:N/S:U/C:H/I:N/A:N", 5.5},
+	} {
+		got, ok := cvss3BaseScore(test.vector)
+		if !ok || got != test.want {
+			t.Errorf("cvss3BaseScore(%q) = %v, %t, want %v", test.vector, got, ok, test.want)
+		}
+	}
+}
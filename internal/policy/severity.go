@@ -0,0 +1,130 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package policy
+
+import (
+	"math"
+	"strings"
+
+	"golang.org/x/vuln/internal/osv"
+)
+
+// Severity returns the qualitative severity rating, one of "NONE",
+// "LOW", "MEDIUM", "HIGH", or "CRITICAL", of the first of sevs that
+// can be rated, or "" if there is none. CVSS v3 vectors are rated
+// by their base score; Ubuntu priorities are used as they are.
+func Severity(sevs []osv.Severity) string {
+	for _, s := range sevs {
+		switch s.Type {
+		case osv.SeverityTypeCVSSV3:
+			if score, ok := cvss3BaseScore(s.Score); ok {
+				return rating(score)
+			}
+		case osv.SeverityTypeUbuntu:
+			switch p := strings.ToUpper(s.Score); p {
+			case "NEGLIGIBLE":
+				return "NONE"
+			case "LOW", "MEDIUM", "HIGH", "CRITICAL":
+				return p
+			}
+		}
+	}
+	return ""
+}
+
+// rating returns the qualitative severity rating of a CVSS score.
+func rating(score float64) string {
+	switch {
+	case score == 0:
+		return "NONE"
+	case score < 4:
+		return "LOW"
+	case score < 7:
+		return "MEDIUM"
+	case score < 9:
+		return "HIGH"
+	}
+	return "CRITICAL"
+}
+
+// cvss3Weights are the weights of the values of
+// the CVSS v3 base metrics, except for PR.
+var cvss3Weights = map[string]map[string]float64{
+	"AV": {"N": 0.85, "A": 0.62, "L": 0.55, "P": 0.2},
+	"AC": {"L": 0.77, "H": 0.44},
+	"UI": {"N": 0.85, "R": 0.62},
+	"C":  {"H": 0.56, "L": 0.22, "N": 0},
+	"I":  {"H": 0.56, "L": 0.22, "N": 0},
+	"A":  {"H": 0.56, "L": 0.22, "N": 0},
+}
+
+// cvss3BaseScore returns the base score of a CVSS v3.0 or v3.1 vector,
+// as specified in https://www.first.org/cvss/v3.1/specification-document.
+func cvss3BaseScore(vector string) (float64, bool) {
+	parts := strings.Split(vector, "/")
+	if len(parts) == 0 || !strings.HasPrefix(parts[0], "CVSS:3.") {
+		return 0, false
+	}
+	m := make(map[string]string)
+	for _, p := range parts[1:] {
+		k, v, ok := strings.Cut(p, ":")
+		if !ok {
+			return 0, false
+		}
+		m[k] = v
+	}
+	w := make(map[string]float64)
+	for k, values := range cvss3Weights {
+		v, ok := values[m[k]]
+		if !ok {
+			return 0, false
+		}
+		w[k] = v
+	}
+	changed := m["S"] == "C"
+	if !changed && m["S"] != "U" {
+		return 0, false
+	}
+	switch m["PR"] {
+	case "N":
+		w["PR"] = 0.85
+	case "L":
+		w["PR"] = 0.62
+		if changed {
+			w["PR"] = 0.68
+		}
+	case "H":
+		w["PR"] = 0.27
+		if changed {
+			w["PR"] = 0.5
+		}
+	default:
+		return 0, false
+	}
+
+	iss := 1 - (1-w["C"])*(1-w["I"])*(1-w["A"])
+	impact := 6.42 * iss
+	if changed {
+		impact = 7.52*(iss-0.029) - 3.25*math.Pow(iss-0.02, 15)
+	}
+	if impact <= 0 {
+		return 0, true
+	}
+	exploitability := 8.22 * w["AV"] * w["AC"] * w["PR"] * w["UI"]
+	if changed {
+		return roundUp(math.Min(1.08*(impact+exploitability), 10)), true
+	}
+	return roundUp(math.Min(impact+exploitability, 10)), true
+}
+
+// roundUp returns the smallest number with one decimal place that
+// is at least x, avoiding floating point errors as CVSS v3.1 does.
+func roundUp(x float64) float64 {
+	i := int(math.Round(x * 100000))
+	if i%10000 == 0 {
+		return float64(i) / 100000
+	}
+	return float64(i/10000+1) / 10
+}
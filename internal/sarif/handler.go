@@ -9,7 +9,9 @@ import (
 	"fmt"
 	"io"
 	"path/filepath"
+	"slices"
 	"sort"
+	"strings"
 
 	"golang.org/x/vuln/internal"
 	"golang.org/x/vuln/internal/govulncheck"
@@ -28,6 +30,7 @@ type handler struct {
 	// an osv is indeed called, then all findings for
 	// the osv will have call stack info.
 	findings map[string][]*govulncheck.Finding
+	diags    []*govulncheck.Diagnostic
 }
 
 func NewHandler(w io.Writer) *handler {
@@ -51,6 +54,11 @@ func (h *handler) SBOM(s *govulncheck.SBOM) error {
 	return nil // not needed by sarif
 }
 
+func (h *handler) Diagnostic(d *govulncheck.Diagnostic) error {
+	h.diags = append(h.diags, d)
+	return nil
+}
+
 func (h *handler) OSV(e *osv.Entry) error {
 	h.osvs[e.ID] = e
 	return nil
@@ -131,7 +139,8 @@ func toSarif(h *handler) Log {
 				Rules:          rules(h),
 			},
 		},
-		Results: results(h),
+		Invocations: invocations(h),
+		Results:     results(h),
 	}
 
 	return Log{
@@ -141,6 +150,41 @@ func toSarif(h *handler) Log {
 	}
 }
 
+// invocations returns the invocation of govulncheck, with its
+// diagnostics as notifications, or nil if there are none.
+func invocations(h *handler) []Invocation {
+	if len(h.diags) == 0 {
+		return nil
+	}
+	inv := Invocation{ExecutionSuccessful: true}
+	for _, d := range h.diags {
+		n := Notification{
+			Descriptor: Descriptor{ID: string(d.Kind)},
+			Level:      "warning",
+			Message:    Description{Text: d.Message},
+		}
+		// Positions of diagnostics are relative to the module
+		// of their package, which can only be located when
+		// it is the main module.
+		if d.Position != nil && d.Module == "" {
+			n.Locations = []Location{{PhysicalLocation: PhysicalLocation{
+				ArtifactLocation: ArtifactLocation{
+					URI:       d.Position.Filename,
+					URIBaseID: SrcRootID,
+				},
+				Region: Region{
+					StartLine:   d.Position.Line,
+					StartColumn: d.Position.Column,
+				},
+			},
+				Message: Description{Text: d.Message},
+			}}
+		}
+		inv.ToolExecutionNotifications = append(inv.ToolExecutionNotifications, n)
+	}
+	return []Invocation{inv}
+}
+
 func rules(h *handler) []Rule {
 	rs := make([]Rule, 0, len(h.findings)) // must not be nil
 	for id := range h.findings {
@@ -198,6 +242,12 @@ func results(h *handler) []Result {
 			Stacks:    stacks(h, fs),
 			CodeFlows: codeFlows(h, fs),
 			Locations: locs,
+			Properties: ResultProperties{
+				Precision:   govulncheck.MostPrecise(fs, h.cfg.ScanMode),
+				Disclosure:  govulncheck.FirstDisclosure(fs),
+				ModuleChain: moduleChain(fs),
+				Owners:      owners(fs),
+			},
 		}
 		results = append(results, res)
 	}
@@ -205,6 +255,32 @@ func results(h *handler) []Result {
 	return results
 }
 
+// moduleChain returns the module chain of the first
+// of findings that has one, or nil if none has.
+func moduleChain(findings []*govulncheck.Finding) []string {
+	for _, f := range findings {
+		if f.ModuleChain != nil {
+			return f.ModuleChain
+		}
+	}
+	return nil
+}
+
+// owners returns the owners of findings, sorted,
+// or nil if none of them has owners.
+func owners(findings []*govulncheck.Finding) []string {
+	var owners []string
+	for _, f := range findings {
+		for _, o := range f.Owners {
+			if !slices.Contains(owners, o) {
+				owners = append(owners, o)
+			}
+		}
+	}
+	slices.Sort(owners)
+	return owners
+}
+
 func resultMessage(findings []*govulncheck.Finding, cfg *govulncheck.Config) string {
 	// We can infer the findings' level by just looking at the
 	// top trace frame of any finding.
@@ -334,16 +410,24 @@ func codeFlows(h *handler, fs []*govulncheck.Finding) []CodeFlow {
 	// group call stacks per symbol. There should
 	// be one call stack currently per symbol, but
 	// this might change in the future.
-	m := make(map[govulncheck.Frame][]*govulncheck.Finding)
+	type key struct {
+		module, version, pkg, function, receiver, typeArgs string
+		origin                                             govulncheck.Origin
+		pos                                                *govulncheck.Position
+	}
+	m := make(map[key][]*govulncheck.Finding)
 	for _, f := range fs {
 		// fr.Position is currently the position
 		// of the definition of the vuln symbol
-		fr := *f.Trace[0]
-		m[fr] = append(m[fr], f)
+		fr := f.Trace[0]
+		k := key{fr.Module, fr.Version, fr.Package, fr.Function, fr.Receiver,
+			strings.Join(fr.TypeArgs, ","), fr.Origin, fr.Position}
+		m[k] = append(m[k], f)
 	}
 
 	var codeFlows []CodeFlow
-	for fr, fs := range m {
+	for _, fs := range m {
+		fr := *fs[0].Trace[0]
 		tfs := threadFlows(h, fs)
 		codeFlows = append(codeFlows, CodeFlow{
 			ThreadFlows: tfs,
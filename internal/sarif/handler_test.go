@@ -347,3 +347,55 @@ func TestResultMessage(t *testing.T) {
 		}
 	}
 }
+
+func TestInvocations(t *testing.T) {
+	h := newTestHandler()
+	if got := invocations(h); got != nil {
+		t.Errorf("invocations() without diagnostics = %v, want nil", got)
+	}
+	for _, d := range []*govulncheck.Diagnostic{
+		{
+			Kind:     govulncheck.DiagnosticLoadError,
+			Package:  "golang.org/broken/lib",
+			Position: &govulncheck.Position{Filename: "lib/lib.go", Line: 3, Column: 9},
+			Message:  "undefined: missing",
+		},
+		{
+			Kind:     govulncheck.DiagnosticLoadError,
+			Module:   "example.com/dep",
+			Package:  "example.com/dep/p",
+			Position: &govulncheck.Position{Filename: "p/p.go", Line: 1},
+			Message:  "expected 'package', found 'EOF'",
+		},
+	} {
+		if err := h.Diagnostic(d); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := []Invocation{{
+		ExecutionSuccessful: true,
+		ToolExecutionNotifications: []Notification{
+			{
+				Descriptor: Descriptor{ID: "load-error"},
+				Level:      "warning",
+				Message:    Description{Text: "undefined: missing"},
+				Locations: []Location{{
+					PhysicalLocation: PhysicalLocation{
+						ArtifactLocation: ArtifactLocation{URI: "lib/lib.go", URIBaseID: SrcRootID},
+						Region:           Region{StartLine: 3, StartColumn: 9},
+					},
+					Message: Description{Text: "undefined: missing"},
+				}},
+			},
+			{
+				// Not in the main module, so not located.
+				Descriptor: Descriptor{ID: "load-error"},
+				Level:      "warning",
+				Message:    Description{Text: "expected 'package', found 'EOF'"},
+			},
+		},
+	}}
+	if diff := cmp.Diff(want, invocations(h)); diff != "" {
+		t.Errorf("invocations() mismatch (-want +got):\n%s", diff)
+	}
+}
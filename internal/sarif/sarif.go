@@ -64,11 +64,37 @@ type Log struct {
 // in this case govulncheck.
 type Run struct {
 	Tool Tool `json:"tool,omitempty"`
+	// Invocations describe the invocation of govulncheck, when
+	// it reported diagnostics.
+	Invocations []Invocation `json:"invocations,omitempty"`
 	// Results contain govulncheck findings. There should be exactly one
 	// Result per a detected use of an OSV.
 	Results []Result `json:"results"`
 }
 
+// Invocation describes the invocation of govulncheck.
+type Invocation struct {
+	// ExecutionSuccessful is always true: govulncheck
+	// produces no SARIF output when the scan fails.
+	ExecutionSuccessful bool `json:"executionSuccessful"`
+	// ToolExecutionNotifications are the diagnostics of the scan.
+	ToolExecutionNotifications []Notification `json:"toolExecutionNotifications,omitempty"`
+}
+
+// Notification is a govulncheck diagnostic. Its descriptor
+// is the kind of the diagnostic, such as "load-error".
+type Notification struct {
+	Descriptor Descriptor  `json:"descriptor"`
+	Level      string      `json:"level,omitempty"`
+	Message    Description `json:"message"`
+	Locations  []Location  `json:"locations,omitempty"`
+}
+
+// Descriptor identifies the kind of a Notification.
+type Descriptor struct {
+	ID string `json:"id"`
+}
+
 // Tool captures information about govulncheck analysis that was run.
 type Tool struct {
 	Driver Driver `json:"driver,omitempty"`
@@ -135,6 +161,22 @@ type Result struct {
 	CodeFlows []CodeFlow `json:"codeFlows,omitempty"`
 	// Stacks encode call stacks produced by govulncheck.
 	Stacks []Stack `json:"stacks,omitempty"`
+	// Properties describe how the findings were determined.
+	Properties ResultProperties `json:"properties"`
+}
+
+// ResultProperties defines the properties of a Result.
+type ResultProperties struct {
+	// Precision of the findings in the Result.
+	Precision govulncheck.Precision `json:"precision,omitempty"`
+	// Disclosure of the vulnerability of the Result, if known.
+	Disclosure *govulncheck.Disclosure `json:"disclosure,omitempty"`
+	// ModuleChain of the findings in the Result, if they are in
+	// a module that is only required indirectly.
+	ModuleChain []string `json:"module_chain,omitempty"`
+	// Owners of the code that the findings in the Result are found
+	// through, if known.
+	Owners []string `json:"owners,omitempty"`
 }
 
 // CodeFlow summarizes a detected offending flow of information in terms of
@@ -0,0 +1,221 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/vuln/internal/attest"
+	"golang.org/x/vuln/internal/govulncheck"
+	"golang.org/x/vuln/internal/osv"
+)
+
+// attestSubjects returns the subjects of the attestation of a scan with
+// cfg. In binary mode, it is the binary. In source mode, they are the
+// main modules, identified by the digests of their go.mod files and
+// the commit checked out in the directory of the scan, if any.
+func attestSubjects(ctx context.Context, cfg *config) ([]*attest.Subject, error) {
+	if cfg.ScanMode == govulncheck.ScanModeBinary {
+		digest, err := fileDigest(cfg.patterns[0])
+		if err != nil {
+			return nil, err
+		}
+		return []*attest.Subject{{
+			Name:   filepath.Base(cfg.patterns[0]),
+			Digest: map[string]string{"sha256": digest},
+		}}, nil
+	}
+	out, err := goListModules(ctx, cfg, "-f", "{{.Path}}\t{{.GoMod}}")
+	if err != nil {
+		return nil, err
+	}
+	// Scans outside of git checkouts are attested by their go.mod
+	// files alone.
+	commit, _ := gitOutput(ctx, cfg, "rev-parse", "HEAD")
+	var subjects []*attest.Subject
+	for _, line := range strings.Split(out, "\n") {
+		path, gomod, ok := strings.Cut(line, "\t")
+		if !ok || gomod == "" {
+			continue
+		}
+		digest, err := fileDigest(gomod)
+		if err != nil {
+			return nil, err
+		}
+		s := &attest.Subject{Name: path, Digest: map[string]string{"sha256": digest}}
+		if commit != "" {
+			s.Digest["gitCommit"] = commit
+		}
+		subjects = append(subjects, s)
+	}
+	return subjects, nil
+}
+
+// fileDigest returns the SHA-256 digest of the file
+// at path in hexadecimal.
+func fileDigest(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// newAttestHandler returns a handler that passes messages on to h and
+// writes an attestation of the scan of subjects to the file at path
+// when flushed. The attestation is signed with key, if not nil.
+func newAttestHandler(h govulncheck.Handler, path string, subjects []*attest.Subject, key crypto.Signer) *attestHandler {
+	return &attestHandler{
+		Wrapper:  govulncheck.Wrapper{Next: h},
+		path:     path,
+		subjects: subjects,
+		key:      key,
+		start:    time.Now().UTC(),
+		config:   &govulncheck.Config{},
+		osvs:     make(map[string]*osv.Entry),
+	}
+}
+
+// attestHandler collects the results of a scan to attest them.
+type attestHandler struct {
+	govulncheck.Wrapper
+	path     string
+	subjects []*attest.Subject
+	key      crypto.Signer
+	start    time.Time
+	config   *govulncheck.Config
+	osvs     map[string]*osv.Entry
+	findings []*govulncheck.Finding
+}
+
+func (h *attestHandler) Config(c *govulncheck.Config) error {
+	h.config = c
+	return h.Next.Config(c)
+}
+
+func (h *attestHandler) OSV(e *osv.Entry) error {
+	h.osvs[e.ID] = e
+	return h.Next.OSV(e)
+}
+
+func (h *attestHandler) Finding(f *govulncheck.Finding) error {
+	h.findings = append(h.findings, f)
+	return h.Next.Finding(f)
+}
+
+// Flush flushes the underlying handler, whose result may be an exit
+// code, before writing the attestation, so that the output of the scan
+// is written even if the attestation is not.
+func (h *attestHandler) Flush() error {
+	err := h.Wrapper.Flush()
+	if aerr := h.write(); aerr != nil {
+		return errors.Join(err, aerr)
+	}
+	return err
+}
+
+// write writes the attestation of the scan.
+func (h *attestHandler) write() error {
+	s := h.statement(time.Now().UTC())
+	var v any = s
+	if h.key != nil {
+		e, err := attest.Sign(s, h.key)
+		if err != nil {
+			return fmt.Errorf("signing attestation: %w", err)
+		}
+		v = e
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(h.path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("writing attestation: %w", err)
+	}
+	return nil
+}
+
+// statement returns the in-toto statement of the scan, which finished
+// at end. Its results are the vulnerabilities found, sorted by ID, with
+// an annotation for each affected module, from its most precise
+// findings.
+func (h *attestHandler) statement(end time.Time) *attest.Statement {
+	type key struct{ osv, module string }
+	best := make(map[key]*govulncheck.Finding)
+	for _, f := range h.findings {
+		if len(f.Trace) == 0 {
+			continue
+		}
+		k := key{f.OSV, f.Trace[0].Module}
+		if b := best[k]; b == nil || findingRank(f) > findingRank(b) {
+			best[k] = f
+		}
+	}
+	keys := make([]key, 0, len(best))
+	for k := range best {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].osv != keys[j].osv {
+			return keys[i].osv < keys[j].osv
+		}
+		return keys[i].module < keys[j].module
+	})
+	results := []attest.Result{}
+	for _, k := range keys {
+		if len(results) == 0 || results[len(results)-1].ID != k.osv {
+			r := attest.Result{ID: k.osv}
+			if e := h.osvs[k.osv]; e != nil {
+				for _, s := range e.Severity {
+					r.Severity = append(r.Severity, attest.Severity{Method: string(s.Type), Score: s.Score})
+				}
+			}
+			results = append(results, r)
+		}
+		f := best[k]
+		a := map[string]string{
+			"module":  k.module,
+			"version": moduleVersionString(k.module, f.Trace[0].Version),
+			"level":   string(findingLevel(f)),
+		}
+		if fixed := moduleVersionString(k.module, f.FixedVersion); fixed != "" {
+			a["fixed_version"] = fixed
+		}
+		r := &results[len(results)-1]
+		r.Annotations = append(r.Annotations, a)
+	}
+	return &attest.Statement{
+		Type:          attest.StatementType,
+		Subject:       h.subjects,
+		PredicateType: attest.VulnsPredicateType,
+		Predicate: &attest.VulnsPredicate{
+			Scanner: attest.Scanner{
+				URI:     osv.PackageURL("golang.org/x/vuln", h.config.ScannerVersion),
+				Version: h.config.ScannerVersion,
+				DB: attest.DB{
+					URI:        h.config.DB,
+					LastUpdate: h.config.DBLastModified,
+				},
+				Result: results,
+			},
+			Metadata: attest.Metadata{
+				ScanStartedOn:  h.start,
+				ScanFinishedOn: end,
+			},
+		},
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/vuln/internal/attest"
+	"golang.org/x/vuln/internal/govulncheck"
+	"golang.org/x/vuln/internal/osv"
+	"golang.org/x/vuln/internal/test"
+)
+
+func TestAttestSubjects(t *testing.T) {
+	bin := filepath.Join(t.TempDir(), "app")
+	if err := os.WriteFile(bin, []byte("binary"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config{patterns: []string{bin}}
+	cfg.ScanMode = govulncheck.ScanModeBinary
+	got, err := attestSubjects(context.Background(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*attest.Subject{{
+		Name: "app",
+		// The SHA-256 digest of "binary".
+		Digest: map[string]string{"sha256": "9a3a45d01531a20e89ac6ae10b0b0beb0492acd7216a368aa062d1a5fecaf9cd"},
+	}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want,+got):%s", diff)
+	}
+}
+
+func TestAttestHandler(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "attestation.json")
+	subjects := []*attest.Subject{{Name: "app", Digest: map[string]string{"sha256": "abc"}}}
+	mock := test.NewMockHandler()
+	h := govulncheck.Chain(mock, func(h govulncheck.Handler) govulncheck.Handler {
+		return newAttestHandler(h, path, subjects, key)
+	})
+
+	if err := h.Config(&govulncheck.Config{ScannerVersion: "v1.1.4", DB: "https://vuln.go.dev"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.OSV(&osv.Entry{
+		ID:       "GO-0000-0001",
+		Severity: []osv.Severity{{Type: osv.SeverityTypeCVSSV3, Score: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range []*govulncheck.Finding{
+		{OSV: "GO-0000-0001", FixedVersion: "v1.0.1", Trace: []*govulncheck.Frame{{Module: "example.com/a", Version: "v1.0.0"}}},
+		{OSV: "GO-0000-0001", FixedVersion: "v1.0.1", Trace: []*govulncheck.Frame{{Module: "example.com/a", Version: "v1.0.0", Package: "example.com/a/p", Function: "F"}}},
+		{OSV: "GO-0000-0001", Trace: []*govulncheck.Frame{{Module: "example.com/b", Version: "v0.1.0", Package: "example.com/b"}}},
+	} {
+		if err := h.Finding(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := govulncheck.Flush(h); err != nil {
+		t.Fatal(err)
+	}
+	if len(mock.FindingMessages) != 3 {
+		t.Errorf("got %d findings passed on, want 3", len(mock.FindingMessages))
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var e attest.Envelope
+	if err := json.Unmarshal(data, &e); err != nil {
+		t.Fatal(err)
+	}
+	s, err := attest.Verify(&e, key.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(subjects, s.Subject); diff != "" {
+		t.Errorf("subject mismatch (-want,+got):%s", diff)
+	}
+	scanner := s.Predicate.Scanner
+	if want := osv.PackageURL("golang.org/x/vuln", "v1.1.4"); scanner.URI != want || scanner.DB.URI != "https://vuln.go.dev" {
+		t.Errorf("got scanner %s with database %s, want %s with https://vuln.go.dev", scanner.URI, scanner.DB.URI, want)
+	}
+	want := []attest.Result{{
+		ID:       "GO-0000-0001",
+		Severity: []attest.Severity{{Method: "CVSS_V3", Score: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"}},
+		Annotations: []map[string]string{
+			{"module": "example.com/a", "version": "v1.0.0", "fixed_version": "v1.0.1", "level": "symbol"},
+			{"module": "example.com/b", "version": "v0.1.0", "level": "package"},
+		},
+	}}
+	if diff := cmp.Diff(want, scanner.Result); diff != "" {
+		t.Errorf("result mismatch (-want,+got):%s", diff)
+	}
+}
+
+func TestAttestHandlerWriteError(t *testing.T) {
+	next := &flushHandler{Handler: test.NewMockHandler(), err: errVulnerabilitiesFound}
+	path := filepath.Join(t.TempDir(), "missing", "attestation.json")
+	h := newAttestHandler(next, path, nil, nil)
+	err := h.Flush()
+	if !next.flushed {
+		t.Error("the output was not flushed")
+	}
+	if !errors.Is(err, errVulnerabilitiesFound) || !strings.Contains(err.Error(), "writing attestation") {
+		t.Errorf("got error %v, want both the exit code and the write error", err)
+	}
+}
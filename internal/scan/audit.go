@@ -0,0 +1,239 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/vuln/internal/client"
+	"golang.org/x/vuln/internal/govulncheck"
+	"golang.org/x/vuln/internal/osv"
+	isem "golang.org/x/vuln/internal/semver"
+	"golang.org/x/vuln/internal/vulncheck"
+)
+
+// runAudit reports all the vulnerabilities of the modules in the
+// config, whatever their versions. The modules are reported in an SBOM.
+// For modules given with a version, there is also a module level
+// finding for each vulnerability that affects that version.
+func runAudit(ctx context.Context, handler govulncheck.Handler, cfg *config, c *client.Client) error {
+	var mods []*govulncheck.Module
+	for _, pattern := range cfg.patterns {
+		mod, ver, err := parseAuditPattern(pattern)
+		if err != nil {
+			return err
+		}
+		if ver == "latest" {
+			if ver, err = latestVersion(ctx, cfg, mod); err != nil {
+				return err
+			}
+		}
+		mods = append(mods, &govulncheck.Module{Path: mod, Version: ver})
+	}
+	if err := handler.SBOM(&govulncheck.SBOM{Modules: mods}); err != nil {
+		return err
+	}
+
+	ids := make(map[string]bool)
+	for _, m := range mods {
+		if err := handler.Progress(auditProgressMessage(m.Path)); err != nil {
+			return err
+		}
+		resps, err := c.ByModules(ctx, []*client.ModuleRequest{{Path: m.Path}})
+		if err != nil {
+			return err
+		}
+		for _, e := range resps[0].Entries {
+			if !ids[e.ID] {
+				ids[e.ID] = true
+				if err := handler.OSV(e); err != nil {
+					return err
+				}
+			}
+			if m.Version == "" || !affects(e, m.Path, m.Version) {
+				continue
+			}
+			if err := handler.Finding(&govulncheck.Finding{
+				OSV:          e.ID,
+				FixedVersion: vulncheck.FixedVersion(m.Path, m.Version, e.Affected),
+				Trace:        []*govulncheck.Frame{{Module: m.Path, Version: m.Version}},
+			}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// affects reports whether e affects version of the module with path.
+func affects(e *osv.Entry, path, version string) bool {
+	for _, a := range e.Affected {
+		if a.Module.Path == path && isem.AffectsResolved(a.Ranges, version, isem.PseudoVersionResolver(version)) {
+			return true
+		}
+	}
+	return false
+}
+
+func auditProgressMessage(module string) *govulncheck.Progress {
+	return &govulncheck.Progress{
+		Message: fmt.Sprintf("Looking up all vulnerabilities in %s...", module),
+	}
+}
+
+// parseAuditPattern parses pattern as a module path, optionally
+// followed by @version or @latest. Versions, including Go tags such
+// as go1.21.3, are returned as module versions.
+func parseAuditPattern(pattern string) (mod, ver string, err error) {
+	if !strings.Contains(pattern, "@") {
+		return pattern, "", nil
+	}
+	if mod, ok := strings.CutSuffix(pattern, "@latest"); ok && mod != "" {
+		return mod, "latest", nil
+	}
+	if mod, ver, err = parseModuleQuery(pattern); err != nil {
+		return "", "", err
+	}
+	if strings.HasPrefix(ver, "go") {
+		ver = isem.GoTagToSemver(ver)
+	} else if !strings.HasPrefix(ver, "v") {
+		ver = "v" + ver
+	}
+	return mod, ver, nil
+}
+
+// latestVersion returns the latest version of the module with path,
+// as reported by 'go list -m'.
+func latestVersion(ctx context.Context, cfg *config, path string) (string, error) {
+	out, err := goListModules(ctx, cfg, "-f", "{{.Version}}", path+"@latest")
+	if err != nil {
+		return "", fmt.Errorf("finding the latest version of %s: %w", path, err)
+	}
+	return out, nil
+}
+
+// releaseTime returns when version of the module with path was
+// released, as recorded by the module proxy and reported by
+// 'go list -m'. Vendored modules are looked up in the proxy too.
+func releaseTime(ctx context.Context, cfg *config, path, version string) (time.Time, error) {
+	out, err := goListModules(ctx, cfg, "-mod=mod", "-json", path+"@"+version)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("finding the release time of %s@%s: %w", path, version, err)
+	}
+	var m struct{ Time *time.Time }
+	if err := json.Unmarshal([]byte(out), &m); err != nil || m.Time == nil {
+		return time.Time{}, fmt.Errorf("finding the release time of %s@%s: no time reported", path, version)
+	}
+	return *m.Time, nil
+}
+
+// auditResults prints the vulnerabilities of each audited module,
+// with the versions they affect.
+func (h *TextHandler) auditResults() {
+	// affected maps module@version to the findings
+	// of each vulnerability that affects it.
+	affected := make(map[string]map[string]*findingSummary)
+	for _, f := range h.findings {
+		mod := f.Trace[0].Module + "@" + f.Trace[0].Version
+		if affected[mod] == nil {
+			affected[mod] = make(map[string]*findingSummary)
+		}
+		affected[mod][f.Finding.OSV] = f
+	}
+	for i, m := range h.sbom.Modules {
+		if i > 0 {
+			h.print("\n")
+		}
+		name := m.Path
+		if m.Version != "" {
+			name += "@" + moduleVersionString(m.Path, m.Version)
+		}
+		h.style(sectionStyle, "=== ", name, " ===", "\n\n")
+		found := affected[m.Path+"@"+m.Version]
+		var count int
+		for _, e := range h.osvs {
+			var ranges []osv.Range
+			var ok bool
+			for _, a := range e.Affected {
+				if a.Module.Path == m.Path {
+					ranges = append(ranges, a.Ranges...)
+					ok = true
+				}
+			}
+			if !ok {
+				continue
+			}
+			count++
+			h.auditVuln(e, m, ranges, found[e.ID])
+			h.print("\n")
+		}
+		h.auditSummary(m, count, len(found))
+	}
+}
+
+// auditVuln prints vulnerability e of module m, which affects the
+// versions in ranges. f is the finding of e at the version of m,
+// if that version is affected.
+func (h *TextHandler) auditVuln(e *osv.Entry, m *govulncheck.Module, ranges []osv.Range, f *findingSummary) {
+	if f != nil {
+		h.style(osvCalledStyle, e.ID)
+		h.print(h.msgf(" (affects %s)", moduleVersionString(m.Path, m.Version)))
+	} else {
+		h.style(osvImportedStyle, e.ID)
+	}
+	h.print("\n")
+	h.style(detailsStyle)
+	description := e.Summary
+	if description == "" {
+		description = e.Details
+	}
+	h.wrap("    ", description, 80)
+	h.style(defaultStyle)
+	h.print("\n")
+	if len(e.Aliases) > 0 {
+		h.style(keyStyle, h.msg("  Aliases:"))
+		h.print(" ", strings.Join(e.Aliases, ", "), "\n")
+	}
+	h.style(keyStyle, h.msg("  Affected versions: "))
+	h.print(h.affectedRanges(m.Path, ranges), "\n")
+	if f != nil {
+		h.style(keyStyle, h.msg("  Fixed in: "))
+		if f.FixedVersion != "" {
+			h.print(moduleVersionString(m.Path, f.FixedVersion), "\n")
+		} else {
+			h.print(h.msg("N/A"), "\n")
+		}
+	}
+	if e.DatabaseSpecific != nil && e.DatabaseSpecific.URL != "" {
+		h.style(keyStyle, h.msg("  More info:"))
+		h.print(" ", e.DatabaseSpecific.URL, "\n")
+	}
+}
+
+// auditSummary prints how many vulnerabilities module m has and, if
+// its version was given, how many of them affect that version.
+func (h *TextHandler) auditSummary(m *govulncheck.Module, count, affected int) {
+	if count == 0 {
+		h.print(h.msg(noVulnsMessage), "\n")
+		return
+	}
+	vulns := func(n int) string {
+		return h.styled(valueStyle, h.vulnCount(n))
+	}
+	h.print(h.msgf("%s has %s.", m.Path, vulns(count)))
+	if m.Version != "" {
+		version := moduleVersionString(m.Path, m.Version)
+		if affected == 0 {
+			h.print(" ", h.msgf("Version %s is not affected by any of them.", version))
+		} else {
+			h.print(" ", h.msgf("Version %s is affected by %s.", version, vulns(affected)))
+		}
+	}
+	h.print("\n")
+}
@@ -0,0 +1,61 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseAuditPattern(t *testing.T) {
+	for _, tc := range []struct {
+		pattern, wantMod, wantVer string
+		wantErr                   string
+	}{
+		{
+			pattern: "golang.org/x/text",
+			wantMod: "golang.org/x/text",
+		},
+		{
+			pattern: "golang.org/x/text@latest",
+			wantMod: "golang.org/x/text",
+			wantVer: "latest",
+		},
+		{
+			pattern: "golang.org/x/text@0.3.7",
+			wantMod: "golang.org/x/text",
+			wantVer: "v0.3.7",
+		},
+		{
+			pattern: "stdlib@go1.21.3",
+			wantMod: "stdlib",
+			wantVer: "v1.21.3",
+		},
+		{
+			pattern: "golang.org/x/text@",
+			wantErr: "invalid query",
+		},
+		{
+			pattern: "golang.org/x/text@1.0.0.2",
+			wantErr: "not valid semver",
+		},
+	} {
+		t.Run(tc.pattern, func(t *testing.T) {
+			gotMod, gotVer, err := parseAuditPattern(tc.pattern)
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if gotMod != tc.wantMod || gotVer != tc.wantVer {
+					t.Errorf("parseAuditPattern = (%s, %s), want (%s, %s)", gotMod, gotVer, tc.wantMod, tc.wantVer)
+				}
+			} else {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Errorf("parseAuditPattern = %v, want err containing %s", err, tc.wantErr)
+				}
+			}
+		})
+	}
+}
@@ -8,14 +8,17 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"os"
 	"runtime/debug"
 
 	"golang.org/x/tools/go/packages"
+	"golang.org/x/vuln/internal/artifact"
 	"golang.org/x/vuln/internal/buildinfo"
 	"golang.org/x/vuln/internal/client"
 	"golang.org/x/vuln/internal/derrors"
 	"golang.org/x/vuln/internal/govulncheck"
+	"golang.org/x/vuln/internal/osv"
 	"golang.org/x/vuln/internal/vulncheck"
 )
 
@@ -23,6 +26,14 @@ import (
 func runBinary(ctx context.Context, handler govulncheck.Handler, cfg *config, client *client.Client) (err error) {
 	defer derrors.Wrap(&err, "govulncheck")
 
+	format, err := artifact.DetectFormat(cfg.patterns[0])
+	if err != nil {
+		return err
+	}
+	if format != "" {
+		return runArtifacts(ctx, handler, cfg, client)
+	}
+
 	bin, err := createBin(cfg.patterns[0])
 	if err != nil {
 		return err
@@ -35,6 +46,71 @@ func runBinary(ctx context.Context, handler govulncheck.Handler, cfg *config, cl
 	return vulncheck.Binary(ctx, handler, bin, &cfg.Config, client)
 }
 
+// runArtifacts detects presence of vulnerable symbols in
+// each Go binary of a Debian package, RPM package, or tar archive.
+func runArtifacts(ctx context.Context, handler govulncheck.Handler, cfg *config, client *client.Client) error {
+	dir, err := os.MkdirTemp("", "govulncheck-artifacts")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	bins, err := artifact.Extract(cfg.patterns[0], dir)
+	if err != nil {
+		return err
+	}
+	if len(bins) == 0 {
+		return fmt.Errorf("no Go binaries found in %s", cfg.patterns[0])
+	}
+	ah := newArtifactHandler(handler)
+	for _, b := range bins {
+		bin, err := createBin(b.File)
+		if err != nil {
+			return fmt.Errorf("%s: %v", b.Name, err)
+		}
+		ah.name = b.Name
+		p := &govulncheck.Progress{Message: fmt.Sprintf(artifactProgressMessage, b.Name)}
+		if err := handler.Progress(p); err != nil {
+			return err
+		}
+		if err := vulncheck.Binary(ctx, ah, bin, &cfg.Config, client); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// artifactHandler marks findings and SBOMs as being for the part of
+// the scan being run, a binary of a package or a module of a directory
+// tree, and passes on each OSV entry once over all the parts.
+type artifactHandler struct {
+	govulncheck.Wrapper
+	name string
+	osvs map[string]bool
+}
+
+func newArtifactHandler(h govulncheck.Handler) *artifactHandler {
+	return &artifactHandler{Wrapper: govulncheck.Wrapper{Next: h}, osvs: make(map[string]bool)}
+}
+
+func (h *artifactHandler) SBOM(s *govulncheck.SBOM) error {
+	s.Artifact = h.name
+	return h.Next.SBOM(s)
+}
+
+func (h *artifactHandler) OSV(e *osv.Entry) error {
+	if h.osvs[e.ID] {
+		return nil
+	}
+	h.osvs[e.ID] = true
+	return h.Next.OSV(e)
+}
+
+func (h *artifactHandler) Finding(f *govulncheck.Finding) error {
+	f.Artifact = h.name
+	return h.Next.Finding(f)
+}
+
 func createBin(path string) (*vulncheck.Bin, error) {
 	// First check if the path points to a Go binary. Otherwise, blob
 	// parsing might json decode a Go binary which takes time.
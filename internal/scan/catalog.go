@@ -0,0 +1,321 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"golang.org/x/text/feature/plural"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/message/catalog"
+)
+
+// messages holds the messages of the text handler in each language,
+// keyed by their English text. Messages are fmt format strings when
+// the handler formats them with arguments. Messages missing from a
+// language are printed in English.
+//
+// Messages with plural forms are selected with the plural rules of
+// each language, from the count given as their first argument. Their
+// keys are the English plural forms, and refer to the other arguments
+// by index when the count is not printed as is.
+var messages = newCatalog(map[language.Tag]translation{
+	language.Spanish: translationES,
+}, map[language.Tag]plurals{
+	language.English: pluralsEN,
+	language.Spanish: pluralsES,
+})
+
+// A translation maps messages to their text in one language.
+type translation map[string]string
+
+// plurals maps messages to their plural forms in one language.
+type plurals map[string]catalog.Message
+
+// newCatalog returns the catalog of translations and plurals.
+func newCatalog(trs map[language.Tag]translation, pls map[language.Tag]plurals) catalog.Catalog {
+	b := catalog.NewBuilder(catalog.Fallback(language.English))
+	for tag, tr := range trs {
+		for key, msg := range tr {
+			if err := b.SetString(tag, key, msg); err != nil {
+				panic(err)
+			}
+		}
+	}
+	for tag, ps := range pls {
+		for key, msg := range ps {
+			if err := b.Set(tag, key, msg); err != nil {
+				panic(err)
+			}
+		}
+	}
+	return b
+}
+
+// LangFlag is used for parsing and validation of
+// govulncheck -lang flag.
+type LangFlag string
+
+const langEnglish = "en"
+
+func (f *LangFlag) Get() interface{} { return *f }
+func (f *LangFlag) Set(s string) error {
+	if !hasLanguage(s) {
+		return fmt.Errorf("unsupported language %q, supported languages are %s", s, strings.Join(languages(), ", "))
+	}
+	*f = LangFlag(s)
+	return nil
+}
+func (f *LangFlag) String() string { return string(*f) }
+
+// Update the text handler h to print messages in the language of the flag.
+func (f LangFlag) Update(h *TextHandler) {
+	h.printer = newPrinter(string(f))
+}
+
+// newPrinter returns the printer of messages in lang.
+func newPrinter(lang string) *message.Printer {
+	tag, err := language.Parse(lang)
+	if err != nil {
+		tag = language.English
+	}
+	return message.NewPrinter(tag, message.Catalog(messages))
+}
+
+// languages returns the supported languages, sorted.
+func languages() []string {
+	var langs []string
+	for _, tag := range messages.Languages() {
+		langs = append(langs, tag.String())
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// hasLanguage reports whether lang is supported.
+func hasLanguage(lang string) bool {
+	for _, l := range languages() {
+		if l == lang {
+			return true
+		}
+	}
+	return false
+}
+
+// envLanguage returns the language of the locale in env, following the
+// POSIX precedence of LC_ALL, LC_MESSAGES and LANG. It returns English
+// if the locale's language has no messages.
+func envLanguage(env []string) LangFlag {
+	vars := make(map[string]string)
+	for _, e := range env {
+		if k, v, ok := strings.Cut(e, "="); ok {
+			vars[k] = v
+		}
+	}
+	for _, k := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if v := vars[k]; v != "" {
+			if l := localeLanguage(v); hasLanguage(l) {
+				return LangFlag(l)
+			}
+			return langEnglish
+		}
+	}
+	return langEnglish
+}
+
+// localeLanguage returns the language part of a locale name
+// such as "es_ES.UTF-8" or "es-MX".
+func localeLanguage(locale string) string {
+	l, _, _ := strings.Cut(locale, ".")
+	l, _, _ = strings.Cut(l, "@")
+	l, _, _ = strings.Cut(l, "_")
+	l, _, _ = strings.Cut(l, "-")
+	return strings.ToLower(l)
+}
+
+// msg returns the translation of the message s.
+func (h *TextHandler) msg(s string) string {
+	return h.printer.Sprintf(s)
+}
+
+// msgf formats the translation of the message format with args.
+func (h *TextHandler) msgf(format string, args ...any) string {
+	return h.printer.Sprintf(format, args...)
+}
+
+var pluralsEN = plurals{
+	"The package pattern matched the following %d root packages:": plural.Selectf(1, "%d",
+		"one", "The package pattern matched the following root package:",
+		"other", "The package pattern matched the following %d root packages:"),
+	"Checked %d vulnerabilities of the scanned modules:": plural.Selectf(1, "%d",
+		"one", "Checked %d vulnerability of the scanned modules:",
+		"other", "Checked %d vulnerabilities of the scanned modules:"),
+	"The results are partial: %d packages failed to load. Neither they nor the packages that import them were scanned.": plural.Selectf(1, "%d",
+		"one", "The results are partial: %d package failed to load. Neither it nor the packages that import it were scanned.",
+		"other", "The results are partial: %d packages failed to load. Neither they nor the packages that import them were scanned."),
+	" (and %d more call sites)": plural.Selectf(1, "%d",
+		"one", " (and %d more call site)",
+		"other", " (and %d more call sites)"),
+	" from %[2]s modules": plural.Selectf(1, "%d",
+		"one", " from %[2]s module",
+		"other", " from %[2]s modules"),
+	" from %[2]s modules and the Go standard library": plural.Selectf(1, "%d",
+		"one", " from %[2]s module and the Go standard library",
+		"other", " from %[2]s modules and the Go standard library"),
+	"Your code is affected by %[2]s vulnerabilities%[3]s.": plural.Selectf(1, "%d",
+		"one", "Your code is affected by %[2]s vulnerability%[3]s.",
+		"other", "Your code is affected by %[2]s vulnerabilities%[3]s."),
+	"Your code may be affected by %[2]s vulnerabilities%[3]s.": plural.Selectf(1, "%d",
+		"one", "Your code may be affected by %[2]s vulnerability%[3]s.",
+		"other", "Your code may be affected by %[2]s vulnerabilities%[3]s."),
+	"%d vulnerabilities": plural.Selectf(1, "%d",
+		"one", "%d vulnerability",
+		"other", "%d vulnerabilities"),
+	"%d informational vulnerabilities don't appear to affect your code and need no action.": plural.Selectf(1, "%d",
+		"one", "%d informational vulnerability doesn't appear to affect your code and needs no action.",
+		"other", "%d informational vulnerabilities don't appear to affect your code and need no action."),
+}
+
+var pluralsES = plurals{
+	"The package pattern matched the following %d root packages:": plural.Selectf(1, "%d",
+		"one", "El patrón coincide con el siguiente paquete raíz:",
+		"other", "El patrón coincide con los siguientes %d paquetes raíz:"),
+	"Checked %d vulnerabilities of the scanned modules:": plural.Selectf(1, "%d",
+		"one", "Se comprobó %d vulnerabilidad de los módulos analizados:",
+		"other", "Se comprobaron %d vulnerabilidades de los módulos analizados:"),
+	"The results are partial: %d packages failed to load. Neither they nor the packages that import them were scanned.": plural.Selectf(1, "%d",
+		"one", "Los resultados son parciales: %d paquete no se pudo cargar. No se analizaron ni él ni los paquetes que lo importan.",
+		"other", "Los resultados son parciales: %d paquetes no se pudieron cargar. No se analizaron ni ellos ni los paquetes que los importan."),
+	" (and %d more call sites)": plural.Selectf(1, "%d",
+		"one", " (y %d sitio de llamada más)",
+		"other", " (y %d sitios de llamada más)"),
+	" from %[2]s modules": plural.Selectf(1, "%d",
+		"one", " de %[2]s módulo",
+		"other", " de %[2]s módulos"),
+	" from %[2]s modules and the Go standard library": plural.Selectf(1, "%d",
+		"one", " de %[2]s módulo y la biblioteca estándar de Go",
+		"other", " de %[2]s módulos y la biblioteca estándar de Go"),
+	"Your code is affected by %[2]s vulnerabilities%[3]s.": plural.Selectf(1, "%d",
+		"one", "Su código está afectado por %[2]s vulnerabilidad%[3]s.",
+		"other", "Su código está afectado por %[2]s vulnerabilidades%[3]s."),
+	"Your code may be affected by %[2]s vulnerabilities%[3]s.": plural.Selectf(1, "%d",
+		"one", "Su código puede estar afectado por %[2]s vulnerabilidad%[3]s.",
+		"other", "Su código puede estar afectado por %[2]s vulnerabilidades%[3]s."),
+	"%d vulnerabilities": plural.Selectf(1, "%d",
+		"one", "%d vulnerabilidad",
+		"other", "%d vulnerabilidades"),
+	"%d informational vulnerabilities don't appear to affect your code and need no action.": plural.Selectf(1, "%d",
+		"one", "%d vulnerabilidad informativa no parece afectar a su código y no requiere ninguna acción.",
+		"other", "%d vulnerabilidades informativas no parecen afectar a su código y no requieren ninguna acción."),
+}
+
+var translationES = translation{
+	noVulnsMessage:      "No se encontraron vulnerabilidades.",
+	noOtherVulnsMessage: "No se encontraron otras vulnerabilidades.",
+
+	"Scanner: ":    "Analizador: ",
+	"DB: ":         "BD: ",
+	"DB updated: ": "BD actualizada: ",
+
+	"No packages matched the provided pattern.":                                 "Ningún paquete coincide con el patrón indicado.",
+	"Govulncheck scanned the following %d modules and the %s standard library:": "Govulncheck analizó los siguientes %d módulos y la biblioteca estándar de %s:",
+
+	"=== Symbol Results ===":  "=== Resultados por símbolo ===",
+	"=== Package Results ===": "=== Resultados por paquete ===",
+	"=== Module Results ===":  "=== Resultados por módulo ===",
+
+	"=== Unused Module Results ===": "=== Resultados por módulo sin usar ===",
+
+	"Artifact: ":                      "Artefacto: ",
+	"Module directory: ":              "Directorio del módulo: ",
+	"Summary: ":                       "Resumen: ",
+	"%d of %d binaries are affected.": "%d de %d binarios están afectados.",
+	"%d of %d modules are affected.":  "%d de %d módulos están afectados.",
+
+	"Vulnerability":                 "Vulnerabilidad",
+	"Package":                       "Paquete",
+	"Module":                        "Módulo",
+	"Upgrade to: ":                  "Actualizar a: ",
+	"  Vulnerabilities:":            "  Vulnerabilidades:",
+	" (no fixed version)":           " (sin versión corregida)",
+	"  More info:":                  "  Más información:",
+	"  Published:":                  "  Publicada:",
+	", last modified %s":            ", última modificación el %s",
+	" (available for %d days)":      " (disponible desde hace %d días)",
+	"Standard library":              "Biblioteca estándar",
+	"Module: ":                      "Módulo: ",
+	" (required but unused)":        " (requerido pero sin usar)",
+	"Found in: ":                    "Encontrada en: ",
+	"Required through: ":            "Requerida a través de: ",
+	"Owners: ":                      "Responsables: ",
+	"Upgrade: ":                     "Actualización: ",
+	"patch":                         "de parche",
+	"minor":                         "menor",
+	"major":                         "mayor",
+	"latest version %s":             "última versión %s",
+	"%s, latest version %s":         "%s, última versión %s",
+	"Fixed in: ":                    "Corregida en: ",
+	"N/A":                           "N/D",
+	"    Platforms: ":               "    Plataformas: ",
+	"    Precision: ":               "    Precisión: ",
+	"    Vulnerable symbols found:": "    Símbolos vulnerables encontrados:",
+	"    Example traces found:":     "    Ejemplos de trazas encontradas:",
+	"      Use '-show traces' to see the other %d found symbols": "      Use '-show traces' para ver los otros %d símbolos encontrados",
+	"      Use '-show all-traces' to see the folded call sites":  "      Use '-show all-traces' para ver los sitios de llamada agrupados",
+	"for function %s": "para la función %s",
+	"          Low-confidence path, calling a method through an interface at %s": "          Ruta de baja confianza, que llama a un método a través de una interfaz en %s",
+	"          Low-confidence path, calling a method through reflection at %s":   "          Ruta de baja confianza, que llama a un método mediante reflexión en %s",
+
+	" from the Go standard library": " de la biblioteca estándar de Go",
+
+	"This scan found no other vulnerabilities in packages you import or modules you require.":                                                   "Este análisis no encontró otras vulnerabilidades en los paquetes que importa ni en los módulos que requiere.",
+	"This scan found no other vulnerabilities in modules you require.":                                                                          "Este análisis no encontró otras vulnerabilidades en los módulos que requiere.",
+	"This scan also found %s in packages you import and %s in modules you require, but your code doesn't appear to call these vulnerabilities.": "Este análisis también encontró %s en los paquetes que importa y %s en los módulos que requiere, pero su código no parece llamar a estas vulnerabilidades.",
+	"This scan also found %s in modules you require.":                                                                                           "Este análisis también encontró %s en los módulos que requiere.",
+
+	"  Aliases:":              "  Alias:",
+	"  Withdrawn:":            "  Retirada:",
+	"Severity: ":              "Gravedad: ",
+	"    Affected versions: ": "    Versiones afectadas: ",
+	"    Package: ":           "    Paquete: ",
+	"      Symbols: ":         "      Símbolos: ",
+	"      Platforms: ":       "      Plataformas: ",
+	"  References:":           "  Referencias:",
+	"all":                     "todos",
+	"all versions":            "todas las versiones",
+	"before %s":               "anteriores a %s",
+	"from %s":                 "desde %s",
+	"from %s before %s":       "desde %s y anteriores a %s",
+
+	" (affects %s)":         " (afecta a %s)",
+	"  Affected versions: ": "  Versiones afectadas: ",
+	"  Fixed in: ":          "  Corregida en: ",
+	"%s has %s.":            "%s tiene %s.",
+	"Version %s is not affected by any of them.": "La versión %s no está afectada por ninguna de ellas.",
+	"Version %s is affected by %s.":              "La versión %s está afectada por %s.",
+
+	"=== Coverage ===":       "=== Cobertura ===",
+	"%d at symbol precision": "%d con precisión de símbolo",
+	"%d at package precision, because the scan is at package level":  "%d con precisión de paquete, porque el análisis es a nivel de paquete",
+	"%d at package precision, because the advisory lists no symbols": "%d con precisión de paquete, porque el aviso no enumera símbolos",
+	"%d at module precision, because the scan is at module level":    "%d con precisión de módulo, porque el análisis es a nivel de módulo",
+	"%d at module precision, because the binary has no symbol table": "%d con precisión de módulo, porque el binario no tiene tabla de símbolos",
+	"%d at module precision, because the advisory lists no packages": "%d con precisión de módulo, porque el aviso no enumera paquetes",
+
+	"=== Next Steps ===": "=== Próximos pasos ===",
+	"Upgrade the affected modules to their fixed versions:": "Actualice los módulos afectados a sus versiones corregidas:",
+	"Upgrade Go to %s or later.":                            "Actualice Go a %s o posterior.",
+	"No fixed version is available yet for %s in %s.":       "Todavía no hay una versión corregida de %s en %s.",
+	"Read the advisories for details:":                      "Consulte los avisos para ver más detalles:",
+
+	"=== Diagnostics ===": "=== Diagnósticos ===",
+
+	"Use '-show verbose' for more details.":                                                                  "Use '-show verbose' para ver más detalles.",
+	"Use '-scan symbol' for more fine grained vulnerability detection.":                                      "Use '-scan symbol' para una detección de vulnerabilidades más precisa.",
+	"Use '-scan symbol' for more fine grained vulnerability detection and '-show verbose' for more details.": "Use '-scan symbol' para una detección de vulnerabilidades más precisa y '-show verbose' para ver más detalles.",
+}
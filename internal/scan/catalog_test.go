@@ -0,0 +1,183 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"golang.org/x/vuln/internal/govulncheck"
+)
+
+func TestEnvLanguage(t *testing.T) {
+	for _, test := range []struct {
+		env  []string
+		want LangFlag
+	}{
+		{nil, "en"},
+		{[]string{"LANG=C"}, "en"},
+		{[]string{"LANG=es_ES.UTF-8"}, "es"},
+		{[]string{"LANG=es-MX"}, "es"},
+		{[]string{"LANG=fr_FR.UTF-8"}, "en"},
+		{[]string{"LANG=es_ES.UTF-8", "LC_MESSAGES=en_US.UTF-8"}, "en"},
+		{[]string{"LANG=en_US.UTF-8", "LC_ALL=es_AR"}, "es"},
+		{[]string{"LANG=es_ES", "LC_ALL="}, "es"},
+	} {
+		if got := envLanguage(test.env); got != test.want {
+			t.Errorf("envLanguage(%q) = %q, want %q", test.env, got, test.want)
+		}
+	}
+}
+
+func TestLangFlag(t *testing.T) {
+	var f LangFlag
+	for _, lang := range []string{"en", "es"} {
+		if err := f.Set(lang); err != nil || string(f) != lang {
+			t.Errorf("Set(%q) = %v, %q; want nil, %q", lang, err, f, lang)
+		}
+	}
+	if err := f.Set("xx"); err == nil {
+		t.Error(`Set("xx") succeeded unexpectedly`)
+	}
+}
+
+// TestTranslations checks that translations keep
+// the formatting verbs of their messages.
+func TestTranslations(t *testing.T) {
+	for msg, tr := range translationES {
+		for _, verb := range []string{"%s", "%d"} {
+			if strings.Count(msg, verb) != strings.Count(tr, verb) {
+				t.Errorf("%q: translation %q has different %s verbs", msg, tr, verb)
+			}
+		}
+	}
+}
+
+// TestMessages checks that the messages printed by the text handler
+// have a translation, and that every translation is of such a message,
+// so that translations are updated with the messages they translate.
+func TestMessages(t *testing.T) {
+	used, err := textMessages(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Some messages are not literals at the call sites.
+	for _, c := range coverages {
+		used[string(c)] = true
+	}
+	for _, d := range []govulncheck.UpgradeDifficulty{govulncheck.UpgradePatch, govulncheck.UpgradeMinor, govulncheck.UpgradeMajor} {
+		used[string(d)] = true
+	}
+
+	for msg := range used {
+		_, ok := translationES[msg]
+		if _, plural := pluralsES[msg]; !ok && !plural {
+			t.Errorf("no es translation of %q", msg)
+		}
+	}
+	for msg := range translationES {
+		if !used[msg] {
+			t.Errorf("es translation of unused message %q", msg)
+		}
+	}
+	for msg := range pluralsES {
+		if !used[msg] {
+			t.Errorf("es plural forms of unused message %q", msg)
+		}
+		if pluralsEN[msg] == nil {
+			t.Errorf("no en plural forms of %q", msg)
+		}
+	}
+}
+
+// textMessages returns the messages passed to the msg and msgf methods
+// of the text handler in the Go files of dir, when they are string
+// constants, choices between them, or local variables holding them.
+func textMessages(dir string) (map[string]bool, error) {
+	fset := token.NewFileSet()
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	var parsed []*ast.File
+	consts := make(map[string]string)
+	for _, name := range files {
+		if strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, name, nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		parsed = append(parsed, f)
+		for _, decl := range f.Decls {
+			if d, ok := decl.(*ast.GenDecl); ok && d.Tok == token.CONST {
+				for _, spec := range d.Specs {
+					vs := spec.(*ast.ValueSpec)
+					for i, v := range vs.Values {
+						if lit, ok := v.(*ast.BasicLit); ok && lit.Kind == token.STRING {
+							consts[vs.Names[i].Name], _ = strconv.Unquote(lit.Value)
+						}
+					}
+				}
+			}
+		}
+	}
+	msgs := make(map[string]bool)
+	var add func(e ast.Expr)
+	add = func(e ast.Expr) {
+		switch e := e.(type) {
+		case *ast.BasicLit:
+			if s, err := strconv.Unquote(e.Value); err == nil {
+				msgs[s] = true
+			}
+		case *ast.Ident:
+			if s, ok := consts[e.Name]; ok {
+				msgs[s] = true
+				return
+			}
+			// A local variable holding the message.
+			if as, ok := declOf(e).(*ast.AssignStmt); ok && len(as.Lhs) == len(as.Rhs) {
+				for i, l := range as.Lhs {
+					if id, ok := l.(*ast.Ident); ok && id.Name == e.Name {
+						add(as.Rhs[i])
+					}
+				}
+			}
+		case *ast.CallExpr:
+			if fn, ok := e.Fun.(*ast.IndexExpr); ok {
+				e.Fun = fn.X
+			}
+			if fn, ok := e.Fun.(*ast.Ident); ok && fn.Name == "choose" && len(e.Args) == 3 {
+				add(e.Args[1])
+				add(e.Args[2])
+			}
+		}
+	}
+	for _, f := range parsed {
+		ast.Inspect(f, func(n ast.Node) bool {
+			if call, ok := n.(*ast.CallExpr); ok && len(call.Args) > 0 {
+				if sel, ok := call.Fun.(*ast.SelectorExpr); ok && (sel.Sel.Name == "msg" || sel.Sel.Name == "msgf") {
+					add(call.Args[0])
+				}
+			}
+			return true
+		})
+	}
+	return msgs, nil
+}
+
+// declOf returns the declaration of id resolved by the parser, if any.
+func declOf(id *ast.Ident) any {
+	if id.Obj == nil {
+		return nil
+	}
+	return id.Obj.Decl
+}
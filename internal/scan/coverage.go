@@ -0,0 +1,79 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"golang.org/x/vuln/internal/govulncheck"
+	"golang.org/x/vuln/internal/osv"
+)
+
+// A coverage tells at which precision a vulnerability was checked,
+// and why when it was not checked at the precision of the scan. It
+// is the message of the text handler for the number of such
+// vulnerabilities.
+type coverage string
+
+// coverages are the coverages, from the most to the least precise.
+var coverages = []coverage{
+	coverageSymbol,
+	coveragePackageLevel,
+	coverageNoSymbols,
+	coverageModuleLevel,
+	coverageStripped,
+	coverageNoPackages,
+}
+
+const (
+	coverageSymbol       coverage = "%d at symbol precision"
+	coveragePackageLevel coverage = "%d at package precision, because the scan is at package level"
+	coverageNoSymbols    coverage = "%d at package precision, because the advisory lists no symbols"
+	coverageModuleLevel  coverage = "%d at module precision, because the scan is at module level"
+	coverageStripped     coverage = "%d at module precision, because the binary has no symbol table"
+	coverageNoPackages   coverage = "%d at module precision, because the advisory lists no packages"
+)
+
+// coverageOf returns the coverage of vulnerability e by a scan at
+// level of the given modules, where stripped tells whether a scanned
+// binary has no symbol table.
+//
+// The advisory must list vulnerable packages for a scan to check
+// them, and vulnerable symbols to check those. When it lists
+// several packages, the least precise of them decides.
+func coverageOf(e *osv.Entry, modules map[string]bool, level govulncheck.ScanLevel, stripped bool) coverage {
+	switch {
+	case level == govulncheck.ScanLevelModule:
+		return coverageModuleLevel
+	case stripped:
+		return coverageStripped
+	}
+	affected := e.Affected[:0:0]
+	for _, a := range e.Affected {
+		if modules[a.Module.Path] {
+			affected = append(affected, a)
+		}
+	}
+	if len(affected) == 0 {
+		// The scanned modules are not known.
+		affected = e.Affected
+	}
+	noSymbols := false
+	for _, a := range affected {
+		if len(a.EcosystemSpecific.Packages) == 0 {
+			return coverageNoPackages
+		}
+		for _, p := range a.EcosystemSpecific.Packages {
+			if len(p.Symbols) == 0 {
+				noSymbols = true
+			}
+		}
+	}
+	switch {
+	case level == govulncheck.ScanLevelPackage:
+		return coveragePackageLevel
+	case noSymbols:
+		return coverageNoSymbols
+	}
+	return coverageSymbol
+}
@@ -0,0 +1,83 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"testing"
+
+	"golang.org/x/vuln/internal/govulncheck"
+	"golang.org/x/vuln/internal/osv"
+)
+
+func TestCoverageOf(t *testing.T) {
+	affected := func(module string, pkgs ...osv.Package) osv.Affected {
+		return osv.Affected{
+			Module:            osv.Module{Path: module},
+			EcosystemSpecific: osv.EcosystemSpecific{Packages: pkgs},
+		}
+	}
+	withSymbols := osv.Package{Path: "example.com/m/p", Symbols: []string{"F"}}
+	noSymbols := osv.Package{Path: "example.com/m/q"}
+	modules := map[string]bool{"example.com/m": true}
+
+	for _, tc := range []struct {
+		name     string
+		affected []osv.Affected
+		level    govulncheck.ScanLevel
+		stripped bool
+		want     coverage
+	}{
+		{
+			name:     "symbols",
+			affected: []osv.Affected{affected("example.com/m", withSymbols)},
+			level:    govulncheck.ScanLevelSymbol,
+			want:     coverageSymbol,
+		},
+		{
+			name:     "package level",
+			affected: []osv.Affected{affected("example.com/m", withSymbols)},
+			level:    govulncheck.ScanLevelPackage,
+			want:     coveragePackageLevel,
+		},
+		{
+			name:     "module level",
+			affected: []osv.Affected{affected("example.com/m", withSymbols)},
+			level:    govulncheck.ScanLevelModule,
+			want:     coverageModuleLevel,
+		},
+		{
+			name:     "a package without symbols",
+			affected: []osv.Affected{affected("example.com/m", withSymbols, noSymbols)},
+			level:    govulncheck.ScanLevelSymbol,
+			want:     coverageNoSymbols,
+		},
+		{
+			name:     "no packages",
+			affected: []osv.Affected{affected("example.com/m")},
+			level:    govulncheck.ScanLevelPackage,
+			want:     coverageNoPackages,
+		},
+		{
+			name:     "stripped binary",
+			affected: []osv.Affected{affected("example.com/m", withSymbols)},
+			level:    govulncheck.ScanLevelSymbol,
+			stripped: true,
+			want:     coverageStripped,
+		},
+		{
+			name:     "unscanned modules are left out",
+			affected: []osv.Affected{affected("example.com/m", withSymbols), affected("example.com/other")},
+			level:    govulncheck.ScanLevelSymbol,
+			want:     coverageSymbol,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			e := &osv.Entry{ID: "GO-2024-0001", Affected: tc.affected}
+			if got := coverageOf(e, modules, tc.level, tc.stripped); got != tc.want {
+				t.Errorf("coverageOf() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,340 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/vuln/internal/derrors"
+	"golang.org/x/vuln/internal/govulncheck"
+	"golang.org/x/vuln/internal/osv"
+)
+
+// A dashboardFinding is a vulnerability of a module in the Generic
+// Findings Import format of DefectDojo, which is also what the
+// webhook uploader posts.
+type dashboardFinding struct {
+	Title            string `json:"title"`
+	Description      string `json:"description"`
+	Severity         string `json:"severity"`
+	Mitigation       string `json:"mitigation,omitempty"`
+	References       string `json:"references,omitempty"`
+	FilePath         string `json:"file_path,omitempty"`
+	Line             int    `json:"line,omitempty"`
+	ComponentName    string `json:"component_name"`
+	ComponentVersion string `json:"component_version,omitempty"`
+	VulnIDFromTool   string `json:"vuln_id_from_tool"`
+	UniqueIDFromTool string `json:"unique_id_from_tool"`
+	CVE              string `json:"cve,omitempty"`
+	StaticFinding    bool   `json:"static_finding"`
+}
+
+// dashboardSeverities maps qualitative severity
+// ratings to the severities of DefectDojo.
+var dashboardSeverities = map[string]string{
+	"CRITICAL": "Critical",
+	"HIGH":     "High",
+	"MEDIUM":   "Medium",
+	"LOW":      "Low",
+	"NONE":     "Info",
+}
+
+// dashboardFindings returns a finding for each vulnerability of each
+// module in r, from its most precise findings, sorted by vulnerability
+// and module.
+func dashboardFindings(r *uploadResults) []*dashboardFinding {
+	entries := make(map[string]*osv.Entry)
+	for _, e := range r.osvs {
+		entries[e.ID] = e
+	}
+	type key struct{ osv, module string }
+	groups := make(map[key][]*govulncheck.Finding)
+	for _, f := range r.findings {
+		if len(f.Trace) == 0 {
+			continue
+		}
+		k := key{f.OSV, f.Trace[0].Module}
+		switch fs := groups[k]; {
+		case len(fs) == 0 || findingRank(f) > findingRank(fs[0]):
+			groups[k] = []*govulncheck.Finding{f}
+		case findingRank(f) == findingRank(fs[0]):
+			groups[k] = append(fs, f)
+		}
+	}
+	keys := make([]key, 0, len(groups))
+	for k := range groups {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].osv != keys[j].osv {
+			return keys[i].osv < keys[j].osv
+		}
+		return keys[i].module < keys[j].module
+	})
+	var source bool
+	if r.config != nil {
+		source = r.config.ScanMode == govulncheck.ScanModeSource
+	}
+	dfs := make([]*dashboardFinding, 0, len(keys))
+	for _, k := range keys {
+		e := entries[k.osv]
+		if e == nil {
+			e = &osv.Entry{ID: k.osv}
+		}
+		dfs = append(dfs, newDashboardFinding(e, groups[k], source))
+	}
+	return dfs
+}
+
+// newDashboardFinding returns the finding for e in the module of
+// findings, which are its most precise findings in that module.
+func newDashboardFinding(e *osv.Entry, findings []*govulncheck.Finding, source bool) *dashboardFinding {
+	f := findings[0]
+	mod := f.Trace[0].Module
+	df := &dashboardFinding{
+		Title:            e.ID,
+		ComponentName:    mod,
+		ComponentVersion: moduleVersionString(mod, f.Trace[0].Version),
+		VulnIDFromTool:   e.ID,
+		UniqueIDFromTool: fingerprint(e.ID, mod),
+		StaticFinding:    true,
+	}
+	if e.DatabaseSpecific != nil {
+		df.References = e.DatabaseSpecific.URL
+	}
+	if e.Summary != "" {
+		df.Title += ": " + e.Summary
+	}
+	for _, a := range e.Aliases {
+		if strings.HasPrefix(a, "CVE-") {
+			df.CVE = a
+			break
+		}
+	}
+
+	// Vulnerabilities without a rating are assumed to be of medium
+	// severity when they are called, and of low severity otherwise.
+	df.Severity = dashboardSeverities[vulnSeverity(e, mod)]
+	if df.Severity == "" {
+		df.Severity = "Low"
+		if findingLevel(f) == govulncheck.ScanLevelSymbol {
+			df.Severity = "Medium"
+		}
+	}
+
+	var b strings.Builder
+	details := e.Details
+	if details == "" {
+		details = e.Summary
+	}
+	fmt.Fprintf(&b, "%s\n\nFound in: %s@%s\n", details, mod, df.ComponentVersion)
+	if fixed := moduleVersionString(mod, f.FixedVersion); fixed != "" {
+		fmt.Fprintf(&b, "Fixed in: %s@%s\n", mod, fixed)
+		df.Mitigation = fmt.Sprintf("Upgrade %s to %s or later.", mod, fixed)
+	} else {
+		fmt.Fprintf(&b, "Fixed in: N/A\n")
+		df.Mitigation = "No fixed version is available yet."
+	}
+	if findingLevel(f) == govulncheck.ScanLevelSymbol {
+		b.WriteString("\nExample traces:\n")
+		for _, f := range findings {
+			fmt.Fprintf(&b, "- %s\n", compactTrace(f))
+		}
+	}
+	df.Description = b.String()
+
+	if p := findingPosition(findings); p != nil {
+		df.FilePath, df.Line = p.Filename, p.Line
+	} else if source {
+		df.FilePath = "go.mod"
+	}
+	return df
+}
+
+// findingRank ranks findings by precision.
+func findingRank(f *govulncheck.Finding) int {
+	switch findingLevel(f) {
+	case govulncheck.ScanLevelSymbol:
+		return 2
+	case govulncheck.ScanLevelPackage:
+		return 1
+	}
+	return 0
+}
+
+// findingPosition returns the position of the first of findings with
+// one in the main module: that of its frame closest to the vulnerable
+// symbol. It returns nil if there is none.
+func findingPosition(findings []*govulncheck.Finding) *govulncheck.Position {
+	for _, f := range findings {
+		for _, fr := range f.Trace[1:] {
+			if fr.Origin == govulncheck.OriginMain && fr.Position != nil && fr.Position.Line > 0 {
+				return fr.Position
+			}
+		}
+	}
+	return nil
+}
+
+// fingerprint returns a stable identifier for the vulnerability id in
+// module, which does not change with its version or position, so that
+// dashboards can track it across scans.
+func fingerprint(id, module string) string {
+	sum := sha256.Sum256([]byte(id + "\n" + module))
+	return hex.EncodeToString(sum[:16])
+}
+
+// defectDojoUploader imports the results of a scan into the DefectDojo
+// engagement DEFECTDOJO_ENGAGEMENT of the instance at DEFECTDOJO_URL,
+// with the API token in DEFECTDOJO_TOKEN.
+type defectDojoUploader struct {
+	cfg *config
+}
+
+func (u *defectDojoUploader) upload(ctx context.Context, r *uploadResults, w io.Writer) (err error) {
+	defer derrors.Wrap(&err, "uploading to DefectDojo")
+
+	env := lookupEnv(u.cfg.env)
+	var vars [3]string
+	for i, name := range []string{"DEFECTDOJO_URL", "DEFECTDOJO_TOKEN", "DEFECTDOJO_ENGAGEMENT"} {
+		if vars[i], _ = env(name); vars[i] == "" {
+			return fmt.Errorf("%s is not set", name)
+		}
+	}
+	api, token, engagement := vars[0], vars[1], vars[2]
+
+	findings := dashboardFindings(r)
+	report, err := json.Marshal(struct {
+		Findings []*dashboardFinding `json:"findings"`
+	}{findings})
+	if err != nil {
+		return err
+	}
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for _, field := range [][2]string{
+		{"scan_type", "Generic Findings Import"},
+		{"engagement", engagement},
+		{"active", "true"},
+		{"verified", "false"},
+	} {
+		if err := mw.WriteField(field[0], field[1]); err != nil {
+			return err
+		}
+	}
+	fw, err := mw.CreateFormFile("file", "govulncheck.json")
+	if err != nil {
+		return err
+	}
+	if _, err := fw.Write(report); err != nil {
+		return err
+	}
+	if err := mw.Close(); err != nil {
+		return err
+	}
+	url := strings.TrimSuffix(api, "/") + "/api/v2/import-scan/"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Token "+token)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	if err := post(req, http.StatusCreated, nil); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Uploaded %d findings to DefectDojo engagement %s.\n", len(findings), engagement)
+	return nil
+}
+
+// webhookUploader posts the results of a scan as JSON to the URL in
+// GOVULNCHECK_WEBHOOK_URL, with the bearer token in
+// GOVULNCHECK_WEBHOOK_TOKEN, if set.
+type webhookUploader struct {
+	cfg *config
+}
+
+// webhookPayload is the JSON body of webhook requests.
+type webhookPayload struct {
+	Config   *webhookConfig      `json:"config,omitempty"`
+	Findings []*dashboardFinding `json:"findings"`
+}
+
+// webhookConfig describes the scan in webhook requests. It holds only
+// the fields of the configuration that describe the scanner and what
+// it scanned, leaving out flags and environment, as the webhook may
+// be a third party.
+type webhookConfig struct {
+	ScannerName    string     `json:"scanner_name,omitempty"`
+	ScannerVersion string     `json:"scanner_version,omitempty"`
+	DB             string     `json:"db,omitempty"`
+	DBLastModified *time.Time `json:"db_last_modified,omitempty"`
+	GoVersion      string     `json:"go_version,omitempty"`
+	ScanLevel      string     `json:"scan_level,omitempty"`
+	ScanMode       string     `json:"scan_mode,omitempty"`
+}
+
+// newWebhookConfig returns the description of the scan with c.
+func newWebhookConfig(c *govulncheck.Config) *webhookConfig {
+	if c == nil {
+		return nil
+	}
+	return &webhookConfig{
+		ScannerName:    c.ScannerName,
+		ScannerVersion: c.ScannerVersion,
+		DB:             c.DB,
+		DBLastModified: c.DBLastModified,
+		GoVersion:      c.GoVersion,
+		ScanLevel:      string(c.ScanLevel),
+		ScanMode:       string(c.ScanMode),
+	}
+}
+
+func (u *webhookUploader) upload(ctx context.Context, r *uploadResults, w io.Writer) (err error) {
+	defer derrors.Wrap(&err, "uploading to webhook")
+
+	env := lookupEnv(u.cfg.env)
+	url, _ := env("GOVULNCHECK_WEBHOOK_URL")
+	if url == "" {
+		return errors.New("GOVULNCHECK_WEBHOOK_URL is not set")
+	}
+	token, _ := env("GOVULNCHECK_WEBHOOK_TOKEN")
+
+	p := &webhookPayload{Config: newWebhookConfig(r.config), Findings: dashboardFindings(r)}
+	body, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	// Webhooks may answer with any successful status.
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return errors.New(resp.Status)
+	}
+	fmt.Fprintf(w, "Uploaded %d findings to the webhook.\n", len(p.Findings))
+	return nil
+}
@@ -0,0 +1,227 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"golang.org/x/vuln/internal/govulncheck"
+	"golang.org/x/vuln/internal/osv"
+)
+
+var testUploadResults = &uploadResults{
+	config: &govulncheck.Config{ScanMode: govulncheck.ScanModeSource},
+	osvs: []*osv.Entry{
+		{
+			ID:               "GO-0000-0001",
+			Aliases:          []string{"GHSA-xxxx-yyyy-zzzz", "CVE-0000-0001"},
+			Summary:          "Panic in Parse",
+			Details:          "Parse panics on invalid input.",
+			Severity:         []osv.Severity{{Type: osv.SeverityTypeCVSSV3, Score: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"}},
+			DatabaseSpecific: &osv.DatabaseSpecific{URL: "https://pkg.go.dev/vuln/GO-0000-0001"},
+		},
+		{ID: "GO-0000-0002", Summary: "Unrated vulnerability"},
+	},
+	findings: []*govulncheck.Finding{
+		{OSV: "GO-0000-0001", FixedVersion: "v1.0.1", Trace: []*govulncheck.Frame{{Module: "example.com/a", Version: "v1.0.0"}}},
+		{OSV: "GO-0000-0001", FixedVersion: "v1.0.1", Trace: []*govulncheck.Frame{
+			{Module: "example.com/a", Version: "v1.0.0", Package: "example.com/a/p", Function: "Parse", Origin: govulncheck.OriginDependency},
+			{Module: "example.com/main", Package: "example.com/main", Function: "main", Origin: govulncheck.OriginMain, Position: &govulncheck.Position{Filename: "main.go", Line: 12}},
+		}},
+		{OSV: "GO-0000-0002", Trace: []*govulncheck.Frame{{Module: "example.com/b", Version: "v0.1.0", Package: "example.com/b/q"}}},
+	},
+}
+
+func TestDashboardFindings(t *testing.T) {
+	got := dashboardFindings(testUploadResults)
+	want := []*dashboardFinding{
+		{
+			Title:            "GO-0000-0001: Panic in Parse",
+			Severity:         "Critical",
+			Mitigation:       "Upgrade example.com/a to v1.0.1 or later.",
+			References:       "https://pkg.go.dev/vuln/GO-0000-0001",
+			FilePath:         "main.go",
+			Line:             12,
+			ComponentName:    "example.com/a",
+			ComponentVersion: "v1.0.0",
+			VulnIDFromTool:   "GO-0000-0001",
+			UniqueIDFromTool: fingerprint("GO-0000-0001", "example.com/a"),
+			CVE:              "CVE-0000-0001",
+			StaticFinding:    true,
+		},
+		{
+			Title:            "GO-0000-0002: Unrated vulnerability",
+			Severity:         "Low",
+			Mitigation:       "No fixed version is available yet.",
+			FilePath:         "go.mod",
+			ComponentName:    "example.com/b",
+			ComponentVersion: "v0.1.0",
+			VulnIDFromTool:   "GO-0000-0002",
+			UniqueIDFromTool: fingerprint("GO-0000-0002", "example.com/b"),
+			StaticFinding:    true,
+		},
+	}
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreFields(dashboardFinding{}, "Description")); diff != "" {
+		t.Errorf("mismatch (-want,+got):%s", diff)
+	}
+	if d := got[0].Description; !strings.Contains(d, "Fixed in: example.com/a@v1.0.1") || !strings.Contains(d, "main.go:12") {
+		t.Errorf("got description %q, want the fixed version and an example trace", d)
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	a := fingerprint("GO-0000-0001", "example.com/a")
+	if a != fingerprint("GO-0000-0001", "example.com/a") {
+		t.Error("fingerprints are not stable")
+	}
+	if a == fingerprint("GO-0000-0001", "example.com/b") || a == fingerprint("GO-0000-0002", "example.com/a") {
+		t.Error("fingerprints of different findings are equal")
+	}
+}
+
+func TestDefectDojoUploader(t *testing.T) {
+	var fields map[string]string
+	var report struct {
+		Findings []*dashboardFinding `json:"findings"`
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v2/import-scan/" {
+			t.Errorf("got request %s %s", r.Method, r.URL.Path)
+		}
+		if auth := r.Header.Get("Authorization"); auth != "Token secret" {
+			t.Errorf("got Authorization %q", auth)
+		}
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Fatal(err)
+		}
+		fields = make(map[string]string)
+		for k, v := range r.MultipartForm.Value {
+			fields[k] = v[0]
+		}
+		f, _, err := r.FormFile("file")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		if err := json.NewDecoder(f).Decode(&report); err != nil {
+			t.Error(err)
+		}
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, `{"test": 7}`)
+	}))
+	defer srv.Close()
+
+	cfg := &config{env: []string{
+		"DEFECTDOJO_URL=" + srv.URL,
+		"DEFECTDOJO_TOKEN=secret",
+		"DEFECTDOJO_ENGAGEMENT=3",
+	}}
+	var out bytes.Buffer
+	if err := (&defectDojoUploader{cfg}).upload(context.Background(), testUploadResults, &out); err != nil {
+		t.Fatal(err)
+	}
+	if want := "Uploaded 2 findings to DefectDojo engagement 3.\n"; out.String() != want {
+		t.Errorf("got output %q, want %q", out.String(), want)
+	}
+	wantFields := map[string]string{
+		"scan_type":  "Generic Findings Import",
+		"engagement": "3",
+		"active":     "true",
+		"verified":   "false",
+	}
+	if diff := cmp.Diff(wantFields, fields); diff != "" {
+		t.Errorf("fields mismatch (-want,+got):%s", diff)
+	}
+	if diff := cmp.Diff(dashboardFindings(testUploadResults), report.Findings); diff != "" {
+		t.Errorf("findings mismatch (-want,+got):%s", diff)
+	}
+
+	err := (&defectDojoUploader{&config{}}).upload(context.Background(), testUploadResults, io.Discard)
+	if want := "DEFECTDOJO_URL is not set"; err == nil || !strings.HasSuffix(err.Error(), want) {
+		t.Errorf("got error %v, want one ending in %q", err, want)
+	}
+}
+
+func TestWebhookUploader(t *testing.T) {
+	var body []byte
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		var err error
+		if body, err = io.ReadAll(r.Body); err != nil {
+			t.Error(err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	r := *testUploadResults
+	r.config = &govulncheck.Config{
+		ScannerName: "govulncheck",
+		ScanMode:    govulncheck.ScanModeSource,
+		Flags:       []string{"-db=https://vulndb.example.com"},
+		GoFlags:     "-mod=mod",
+	}
+	cfg := &config{env: []string{"GOVULNCHECK_WEBHOOK_URL=" + srv.URL, "GOVULNCHECK_WEBHOOK_TOKEN=secret"}}
+	if err := (&webhookUploader{cfg}).upload(context.Background(), &r, io.Discard); err != nil {
+		t.Fatal(err)
+	}
+	if auth != "Bearer secret" {
+		t.Errorf("got Authorization %q", auth)
+	}
+	var got webhookPayload
+	if err := json.Unmarshal(body, &got); err != nil {
+		t.Fatal(err)
+	}
+	want := webhookPayload{
+		Config:   &webhookConfig{ScannerName: "govulncheck", ScanMode: "source"},
+		Findings: dashboardFindings(&r),
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want,+got):%s", diff)
+	}
+	// The flags and environment of the scan are not sent.
+	if bytes.Contains(body, []byte("vulndb.example.com")) || bytes.Contains(body, []byte("-mod=mod")) {
+		t.Errorf("the payload %s holds the flags of the scan", body)
+	}
+}
+
+func TestWebhookUploaderOutput(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	cfg := &config{env: []string{"GOVULNCHECK_WEBHOOK_URL=" + srv.URL}}
+	var out bytes.Buffer
+	h := newUploadHandler(context.Background(), newMarkdownHandler(&out), &webhookUploader{cfg}, io.Discard)
+	for _, e := range testUploadResults.osvs {
+		if err := h.OSV(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, f := range testUploadResults.findings {
+		if err := h.Finding(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+	err := h.Flush()
+	if want := "502 Bad Gateway"; err == nil || !strings.HasSuffix(err.Error(), want) {
+		t.Errorf("got error %v, want one ending in %q", err, want)
+	}
+	// The report is written even though the upload failed.
+	if !strings.Contains(out.String(), "GO-0000-0001") {
+		t.Errorf("got output %q, want the report", out.String())
+	}
+}
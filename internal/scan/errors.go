@@ -16,6 +16,12 @@ var (
 	// without the -json flag.
 	errVulnerabilitiesFound = &exitCodeError{message: "vulnerabilities found", code: 3}
 
+	// errPartialResults indicates that, with -allow-load-errors, some
+	// packages failed to load and were not scanned. This returns exit
+	// status 4, unless vulnerabilities were found, whatever the output
+	// format.
+	errPartialResults = &exitCodeError{message: "partial results", code: 4}
+
 	// errHelp indicates that usage help was requested.
 	errHelp = &exitCodeError{message: "help requested", code: 0}
 
@@ -10,40 +10,115 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path"
+	"path/filepath"
 	"strings"
 
 	"golang.org/x/tools/go/buildutil"
+	"golang.org/x/vuln/internal/client"
 	"golang.org/x/vuln/internal/govulncheck"
 )
 
 type config struct {
 	govulncheck.Config
-	patterns []string
-	db       string
-	dir      string
-	tags     buildutil.TagsFlag
-	test     bool
-	show     ShowFlag
-	format   FormatFlag
-	env      []string
+	patterns      []string
+	db            string
+	dbKey         string
+	dbWarn        bool
+	history       bool
+	policy        string
+	owners        string
+	upgrades      bool
+	noAdvice      bool
+	unusedModules bool
+	watch         WatchFlag
+	recursive     bool
+	maxMemory     MemoryFlag
+	dbMaxAge      int
+	requireFresh  bool
+	cpuProfile    string
+	memProfile    string
+	trace         string
+	debug         bool
+	dir           string
+	tags          buildutil.TagsFlag
+	buildFlags    BuildFlagsFlag
+	allowLoadErr  bool
+	test          bool
+	show          ShowFlag
+	lang          LangFlag
+	format        FormatFlag
+	groupBy       GroupByFlag
+	upload        string
+	attest        string
+	attestKey     string
+	env           []string
+}
+
+// flagValue returns the value of f to record in the configuration of
+// the scan. Like cfg.db once parsed, the -db flag is recorded without
+// the options of its databases, which may hold credentials.
+func flagValue(f *flag.Flag, env []string) string {
+	v := f.Value.String()
+	if f.Name != "db" {
+		return v
+	}
+	sources, err := client.ParseSources(v, lookupEnv(env))
+	if err != nil {
+		// The flag is invalid, so the scan does not run.
+		return ""
+	}
+	return client.SourceURLs(sources)
 }
 
 func parseFlags(cfg *config, stderr io.Writer, args []string) error {
 	var version bool
 	var json bool
+	var quiet, verbose bool
 	var scanFlag ScanFlag
 	var modeFlag ModeFlag
 	flags := flag.NewFlagSet("", flag.ContinueOnError)
 	flags.SetOutput(stderr)
 	flags.BoolVar(&json, "json", false, "output JSON (Go compatible legacy flag, see format flag)")
+	flags.BoolVar(&cfg.allowLoadErr, "allow-load-errors", false, "scan the packages that load when others fail to, reporting the failures and exiting with code 4 (only valid for source mode)")
+	flags.StringVar(&cfg.attest, "attest", "", "write an in-toto attestation of the scan results to `file` (only valid for source and binary modes)")
+	flags.StringVar(&cfg.attestKey, "attest-key", "", "sign the attestation with the PEM-encoded private key in `file`")
 	flags.BoolVar(&cfg.test, "test", false, "analyze test files (only valid for source mode, default false)")
 	flags.StringVar(&cfg.dir, "C", "", "change to `dir` before running govulncheck")
-	flags.StringVar(&cfg.db, "db", "https://vuln.go.dev", "vulnerability database `url`")
-	flags.Var(&modeFlag, "mode", "supports 'source', 'binary', and 'extract' (default 'source')")
+	flags.StringVar(&cfg.cpuProfile, "cpuprofile", "", "write a CPU profile to `file`")
+	flags.StringVar(&cfg.db, "db", "https://vuln.go.dev", "vulnerability database `url`, or comma-separated list of them with options (overrides GOVULNDB)")
+	flags.StringVar(&cfg.dbKey, "db-key", "", "verify the database indexes and entries against the Ed25519 public key in `file`")
+	flags.IntVar(&cfg.dbMaxAge, "db-max-age", defaultDBMaxAge, "warn if the vulnerability database was last modified more than `days` ago (only valid for query mode)")
+	flags.BoolVar(&cfg.dbWarn, "db-key-warn", false, "warn instead of failing when database verification fails")
+	flags.Var((*EntryFlag)(&cfg.Entries), "entry", "comma-separated `list` of packages, such as example.com/m/api/..., or functions, such as example.com/m/api.New*, to use as the only entry points (only valid for source mode)")
+	flags.Var((*ExcludeFlag)(&cfg.ExcludeDirs), "exclude-dirs", "comma-separated `list` of directories, such as gen,third_party, whose functions are not entry points (only valid for source mode)")
+	flags.BoolVar(&cfg.ExcludeLowConfidence, "exclude-low-confidence", false, "report vulnerable symbols reached only through calls of methods through interfaces as imported rather than called (only valid for source mode)")
+	flags.BoolVar(&cfg.debug, "debug", false, "print how long each phase of the scan took to standard error")
+	flags.BoolVar(&cfg.Deep, "deep", false, "refine the call graph to report fewer unreachable vulnerable calls, at the risk of missing calls made through reflection (only valid for source mode)")
+	flags.Var(&cfg.groupBy, "group-by", "organize the text output by 'vuln', 'module', or 'package' (default 'vuln')")
+	flags.BoolVar(&cfg.history, "history", false, "record findings in the local scan history (see -mode=history)")
+	flags.BoolVar(&cfg.Library, "library", false, "use the exported API of the public packages as entry points, as for a library (only valid for source mode)")
+	flags.Var(&cfg.lang, "lang", "print text output in `language`, one of "+strings.Join(languages(), ", ")+" (default from the LANG environment variable)")
+	flags.Var(&cfg.maxMemory, "max-memory", "keep memory use to about `size`, such as 4GiB, by analyzing more slowly (only valid for source mode)")
+	flags.StringVar(&cfg.memProfile, "memprofile", "", "write an allocation profile to `file`")
+	flags.Var(&modeFlag, "mode", "supports 'source', 'binary', 'extract', 'history', 'info', 'audit', and 'snapshot' (default 'source')")
+	flags.BoolVar(&cfg.noAdvice, "no-advice", false, "do not print the next steps, such as go get commands, after the findings (text output only)")
+	flags.StringVar(&cfg.owners, "owners", "", "attach to findings the owners of the scanned code they are found through, from the CODEOWNERS `file` (only valid for source mode)")
+	flags.StringVar(&cfg.policy, "policy", "", "decide whether to report, suppress, or fail on each finding with the CEL policy in `file`")
+	flags.BoolVar(&quiet, "q", false, "print only a summary line (text output only)")
+	flags.BoolVar(&cfg.requireFresh, "require-fresh", false, "fail instead of warning if the vulnerability database is older than -db-max-age (only valid for query mode)")
+	flags.BoolVar(&cfg.recursive, "recursive", false, "scan every module in the directory tree, reporting each separately (only valid for source mode)")
 	flags.Var(&cfg.tags, "tags", "comma-separated `list` of build tags")
-	flags.Var(&cfg.show, "show", "enable display of additional information specified by the comma separated `list`\nThe supported values are 'traces','color', 'version', and 'verbose'")
-	flags.Var(&cfg.format, "format", "specify format output\nThe supported values are 'text', 'json', 'sarif', and 'openvex' (default 'text')")
+	flags.Var(&cfg.buildFlags, "buildflags", "pass the build `flag`, such as -mod=vendor or -buildvcs=false, to the go command when loading packages; may be repeated (only valid for source mode)")
+	flags.StringVar(&cfg.trace, "trace", "", "write an execution trace to `file`")
+	flags.BoolVar(&cfg.unusedModules, "unused-modules", false, "also report vulnerabilities in modules that go.mod requires but no scanned package imports, as required but unused (only valid for source mode)")
+	flags.StringVar(&cfg.upload, "upload", "", "upload the results to `service`: 'github' for GitHub code scanning, 'defectdojo' for DefectDojo, or 'webhook' for a generic webhook, configured with environment variables (see the documentation)")
+	flags.BoolVar(&cfg.upgrades, "upgrades", false, "look up the latest version of each vulnerable module in the module proxy, and report how large the upgrade to the fixed version is (only valid for source and binary modes)")
+	flags.Var(&cfg.show, "show", "enable display of additional information specified by the comma separated `list`\nThe supported values are 'traces', 'all-traces', 'color', 'version', 'verbose', and 'coverage'")
+	flags.Var(&cfg.format, "format", "specify format output\nThe supported values are 'text', 'json', 'sarif', 'openvex', 'markdown', and 'html' (default 'text')")
+	flags.BoolVar(&verbose, "v", false, "print full traces, informational findings, and module details; same as -show traces,verbose")
 	flags.BoolVar(&version, "version", false, "print the version information")
+	flags.Var(&cfg.watch, "watch", "keep running and rescan when go.mod or go.sum change, printing only changes in findings\nUse -watch=src to also rescan when Go source files change")
 	flags.Var(&scanFlag, "scan", "set the scanning level desired, one of 'module', 'package', or 'symbol' (default 'symbol')")
 
 	// We don't want to print the whole usage message on each flags
@@ -70,6 +145,18 @@ Usage:
 		return errUsage
 	}
 	cfg.patterns = flags.Args()
+	dbSet := false
+	flags.Visit(func(f *flag.Flag) {
+		cfg.Flags = append(cfg.Flags, "-"+f.Name+"="+flagValue(f, cfg.env))
+		dbSet = dbSet || f.Name == "db"
+	})
+	if v, _ := lookupEnv(cfg.env)("GOVULNDB"); v != "" && !dbSet {
+		cfg.db = v
+	}
+	if quiet && (verbose || len(cfg.show) > 0) {
+		fmt.Fprintln(flags.Output(), "the -q flag cannot be used with the -v or -show flags")
+		return errUsage
+	}
 	if version {
 		cfg.show = append(cfg.show, "version")
 	}
@@ -79,6 +166,33 @@ Usage:
 		fmt.Fprintln(flags.Output(), err)
 		return errUsage
 	}
+	// -q and -v amount to -show values, but are validated
+	// on their own so that errors name the flags given.
+	if err := validateVerbosity(cfg, quiet, verbose); err != nil {
+		fmt.Fprintln(flags.Output(), err)
+		return errUsage
+	}
+	if quiet {
+		cfg.show = append(cfg.show, "quiet")
+	}
+	if verbose {
+		cfg.show = append(cfg.show, "traces", "verbose")
+	}
+	return nil
+}
+
+// validateVerbosity checks that the -q and -v flags,
+// if set, are supported by the output format of cfg.
+func validateVerbosity(cfg *config, quiet, verbose bool) error {
+	if cfg.format == formatText {
+		return nil
+	}
+	if quiet {
+		return fmt.Errorf("the -q flag is not supported for %s output", cfg.format)
+	}
+	if verbose {
+		return fmt.Errorf("the -v flag is not supported for %s output", cfg.format)
+	}
 	return nil
 }
 
@@ -101,10 +215,133 @@ func validateConfig(cfg *config, json bool) error {
 		}
 	}
 
+	if _, err := client.ParseSources(cfg.db, lookupEnv(cfg.env)); err != nil {
+		return err
+	}
+	if cfg.dbWarn && cfg.dbKey == "" {
+		return fmt.Errorf("the -db-key-warn flag requires the -db-key flag")
+	}
+
+	if cfg.history && cfg.ScanMode != govulncheck.ScanModeSource && cfg.ScanMode != govulncheck.ScanModeBinary {
+		return fmt.Errorf("the -history flag is only supported in source and binary mode")
+	}
+
+	if cfg.unusedModules && cfg.ScanMode != govulncheck.ScanModeSource {
+		return fmt.Errorf("the -unused-modules flag is only supported in source mode")
+	}
+	if cfg.upgrades && cfg.ScanMode != govulncheck.ScanModeSource && cfg.ScanMode != govulncheck.ScanModeBinary {
+		return fmt.Errorf("the -upgrades flag is only supported in source and binary mode")
+	}
+
+	if cfg.upload != "" {
+		switch cfg.upload {
+		case uploadGitHub, uploadDefectDojo, uploadWebhook:
+		default:
+			return fmt.Errorf("unsupported -upload value %q, supported values are %q, %q and %q", cfg.upload, uploadGitHub, uploadDefectDojo, uploadWebhook)
+		}
+		if cfg.ScanMode != govulncheck.ScanModeSource && cfg.ScanMode != govulncheck.ScanModeBinary && cfg.ScanMode != govulncheck.ScanModeConvert {
+			return fmt.Errorf("the -upload flag is only supported in source, binary and convert mode")
+		}
+	}
+
+	if cfg.attest != "" && cfg.ScanMode != govulncheck.ScanModeSource && cfg.ScanMode != govulncheck.ScanModeBinary {
+		return fmt.Errorf("the -attest flag is only supported in source and binary mode")
+	}
+	if cfg.attestKey != "" && cfg.attest == "" {
+		return fmt.Errorf("the -attest-key flag requires the -attest flag")
+	}
+
+	if cfg.policy != "" && (cfg.ScanMode == govulncheck.ScanModeExtract || cfg.ScanMode == govulncheck.ScanModeHistory || cfg.ScanMode == govulncheck.ScanModeInfo || cfg.ScanMode == govulncheck.ScanModeSnapshot) {
+		return fmt.Errorf("the -policy flag is not supported in %s mode", cfg.ScanMode)
+	}
+
+	if cfg.watch != watchOff {
+		if cfg.ScanMode != govulncheck.ScanModeSource {
+			return fmt.Errorf("the -watch flag is only supported in source mode")
+		}
+		if cfg.format != formatText {
+			return fmt.Errorf("the -watch flag is not supported for %s output", cfg.format)
+		}
+		if cfg.history {
+			return fmt.Errorf("the -watch and -history flags cannot be used together")
+		}
+		if cfg.upload != "" {
+			return fmt.Errorf("the -watch and -upload flags cannot be used together")
+		}
+		if cfg.attest != "" {
+			return fmt.Errorf("the -watch and -attest flags cannot be used together")
+		}
+	}
+
+	if cfg.recursive {
+		if cfg.ScanMode != govulncheck.ScanModeSource {
+			return fmt.Errorf("the -recursive flag is only supported in source mode")
+		}
+		if cfg.watch != watchOff {
+			return fmt.Errorf("the -recursive and -watch flags cannot be used together")
+		}
+	}
+
+	if cfg.ScanMode != govulncheck.ScanModeQuery {
+		if cfg.dbMaxAge != defaultDBMaxAge {
+			return fmt.Errorf("the -db-max-age flag is only supported in query mode")
+		}
+		if cfg.requireFresh {
+			return fmt.Errorf("the -require-fresh flag is only supported in query mode")
+		}
+	} else if cfg.dbMaxAge < 0 {
+		return fmt.Errorf("the -db-max-age flag must not be negative")
+	}
+
+	if cfg.Library && cfg.ScanMode != govulncheck.ScanModeSource {
+		return fmt.Errorf("the -library flag is only supported in source mode")
+	}
+
+	if cfg.Deep && cfg.ScanMode != govulncheck.ScanModeSource {
+		return fmt.Errorf("the -deep flag is only supported in source mode")
+	}
+
+	if cfg.ExcludeLowConfidence && cfg.ScanMode != govulncheck.ScanModeSource {
+		return fmt.Errorf("the -exclude-low-confidence flag is only supported in source mode")
+	}
+
+	if len(cfg.Entries) > 0 && cfg.ScanMode != govulncheck.ScanModeSource {
+		return fmt.Errorf("the -entry flag is only supported in source mode")
+	}
+
+	if len(cfg.ExcludeDirs) > 0 && cfg.ScanMode != govulncheck.ScanModeSource {
+		return fmt.Errorf("the -exclude-dirs flag is only supported in source mode")
+	}
+
+	if cfg.owners != "" && cfg.ScanMode != govulncheck.ScanModeSource {
+		return fmt.Errorf("the -owners flag is only supported in source mode")
+	}
+
+	if cfg.maxMemory != 0 && cfg.ScanMode != govulncheck.ScanModeSource {
+		return fmt.Errorf("the -max-memory flag is only supported in source mode")
+	}
+
+	if len(cfg.buildFlags) > 0 && cfg.ScanMode != govulncheck.ScanModeSource {
+		return fmt.Errorf("the -buildflags flag is only supported in source mode")
+	}
+
+	if cfg.allowLoadErr && cfg.ScanMode != govulncheck.ScanModeSource {
+		return fmt.Errorf("the -allow-load-errors flag is only supported in source mode")
+	}
+
 	// show flag is only supported with text output
 	if cfg.format != formatText && len(cfg.show) > 0 {
 		return fmt.Errorf("the -show flag is not supported for %s output", cfg.format)
 	}
+	if cfg.format != formatText && cfg.lang != "" {
+		return fmt.Errorf("the -lang flag is not supported for %s output", cfg.format)
+	}
+	if cfg.format != formatText && cfg.groupBy != "" {
+		return fmt.Errorf("the -group-by flag is not supported for %s output", cfg.format)
+	}
+	if cfg.format != formatText && cfg.noAdvice {
+		return fmt.Errorf("the -no-advice flag is not supported for %s output", cfg.format)
+	}
 
 	switch cfg.ScanMode {
 	case govulncheck.ScanModeSource:
@@ -144,8 +381,11 @@ func validateConfig(cfg *config, json bool) error {
 			return fmt.Errorf("%q is not a file (source extraction is not supported)", cfg.patterns[0])
 		}
 	case govulncheck.ScanModeConvert:
-		if len(cfg.patterns) != 0 {
-			return fmt.Errorf("patterns are not accepted in convert mode")
+		if len(cfg.patterns) > 1 {
+			return fmt.Errorf("only 1 file can be converted at a time")
+		}
+		if len(cfg.patterns) == 1 && !isFile(cfg.patterns[0]) {
+			return fmt.Errorf("%q is not a file", cfg.patterns[0])
 		}
 		if cfg.dir != "" {
 			return fmt.Errorf("the -C flag is not supported in convert mode")
@@ -156,6 +396,63 @@ func validateConfig(cfg *config, json bool) error {
 		if len(cfg.tags) > 0 {
 			return fmt.Errorf("the -tags flag is not supported in convert mode")
 		}
+	case govulncheck.ScanModeHistory:
+		if len(cfg.patterns) > 1 {
+			return fmt.Errorf("only 1 binary can be given in history mode")
+		}
+		if cfg.format != formatText {
+			return fmt.Errorf("the %s format is not supported in history mode", cfg.format)
+		}
+		if cfg.test {
+			return fmt.Errorf("the -test flag is not supported in history mode")
+		}
+		if len(cfg.tags) > 0 {
+			return fmt.Errorf("the -tags flag is not supported in history mode")
+		}
+	case govulncheck.ScanModeInfo:
+		if len(cfg.patterns) == 0 {
+			return fmt.Errorf("at least 1 vulnerability ID must be given in info mode")
+		}
+		if cfg.format != formatText && cfg.format != formatJSON {
+			return fmt.Errorf("the %s format is not supported in info mode", cfg.format)
+		}
+		if cfg.test {
+			return fmt.Errorf("the -test flag is not supported in info mode")
+		}
+		if len(cfg.tags) > 0 {
+			return fmt.Errorf("the -tags flag is not supported in info mode")
+		}
+	case govulncheck.ScanModeAudit:
+		if len(cfg.patterns) == 0 {
+			return fmt.Errorf("at least 1 module must be given in audit mode")
+		}
+		if cfg.format != formatText && cfg.format != formatJSON {
+			return fmt.Errorf("the %s format is not supported in audit mode", cfg.format)
+		}
+		if cfg.test {
+			return fmt.Errorf("the -test flag is not supported in audit mode")
+		}
+		if len(cfg.tags) > 0 {
+			return fmt.Errorf("the -tags flag is not supported in audit mode")
+		}
+		for _, pattern := range cfg.patterns {
+			if _, _, err := parseAuditPattern(pattern); err != nil {
+				return err
+			}
+		}
+	case govulncheck.ScanModeSnapshot:
+		if len(cfg.patterns) > 1 {
+			return fmt.Errorf("only 1 go.sum file can be given in snapshot mode")
+		}
+		if cfg.format != formatText {
+			return fmt.Errorf("the %s format is not supported in snapshot mode", cfg.format)
+		}
+		if cfg.test {
+			return fmt.Errorf("the -test flag is not supported in snapshot mode")
+		}
+		if len(cfg.tags) > 0 {
+			return fmt.Errorf("the -tags flag is not supported in snapshot mode")
+		}
 	case govulncheck.ScanModeQuery:
 		if cfg.test {
 			return fmt.Errorf("the -test flag is not supported in query mode")
@@ -169,7 +466,7 @@ func validateConfig(cfg *config, json bool) error {
 		for _, pattern := range cfg.patterns {
 			// Parse the input here so that we can catch errors before
 			// outputting the Config.
-			if _, _, err := parseModuleQuery(pattern); err != nil {
+			if _, _, _, err := parseVersionQuery(pattern); err != nil {
 				return err
 			}
 		}
@@ -192,10 +489,12 @@ var errFlagParse = errors.New("see -help for details")
 type ShowFlag []string
 
 var supportedShows = map[string]bool{
-	"traces":  true,
-	"color":   true,
-	"verbose": true,
-	"version": true,
+	"traces":     true,
+	"all-traces": true,
+	"color":      true,
+	"verbose":    true,
+	"version":    true,
+	"coverage":   true,
 }
 
 func (v *ShowFlag) Set(s string) error {
@@ -213,7 +512,7 @@ func (v *ShowFlag) Set(s string) error {
 }
 
 func (v *ShowFlag) Get() interface{} { return *v }
-func (v *ShowFlag) String() string   { return "" }
+func (v *ShowFlag) String() string   { return strings.Join(*v, ",") }
 
 // Update the text handler h with values of the flag.
 func (v ShowFlag) Update(h *TextHandler) {
@@ -221,33 +520,130 @@ func (v ShowFlag) Update(h *TextHandler) {
 		switch show {
 		case "traces":
 			h.showTraces = true
+		case "all-traces":
+			h.showAllTraces = true
 		case "color":
 			h.showColor = true
 		case "version":
 			h.showVersion = true
 		case "verbose":
 			h.showVerbose = true
+		case "coverage":
+			h.showCoverage = true
+		case "quiet":
+			// Set by the -q flag rather than -show.
+			h.showQuiet = true
+		case "advice":
+			// Set unless the -no-advice flag is given, rather than by -show.
+			h.showAdvice = true
 		}
 	}
 }
 
+// EntryFlag is used for parsing and validation of
+// govulncheck -entry flag.
+type EntryFlag []string
+
+func (v *EntryFlag) Set(s string) error {
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if _, err := path.Match(entry, ""); err != nil {
+			return err
+		}
+		*v = append(*v, entry)
+	}
+	return nil
+}
+
+func (v *EntryFlag) Get() interface{} { return *v }
+func (v *EntryFlag) String() string   { return strings.Join(*v, ",") }
+
+// ExcludeFlag is used for parsing and validation of
+// govulncheck -exclude-dirs flag.
+type ExcludeFlag []string
+
+func (v *ExcludeFlag) Set(s string) error {
+	for _, dir := range strings.Split(s, ",") {
+		// On Windows, directories may be given with backslashes.
+		dir = filepath.ToSlash(strings.TrimSpace(dir))
+		if dir == "" {
+			continue
+		}
+		if _, err := path.Match(dir, ""); err != nil {
+			return err
+		}
+		*v = append(*v, dir)
+	}
+	return nil
+}
+
+func (v *ExcludeFlag) Get() interface{} { return *v }
+func (v *ExcludeFlag) String() string   { return strings.Join(*v, ",") }
+
+// BuildFlagsFlag is used for parsing and validation of the
+// govulncheck -buildflags flag. Each use of the flag gives one
+// flag of the go command, as -name or -name=value, which is passed
+// to it when loading packages. Only flags that affect which files
+// and module versions make up the build are accepted.
+type BuildFlagsFlag []string
+
+// passedBuildFlags are the names of the go command flags accepted
+// by -buildflags. The -tags flag has its own govulncheck flag.
+var passedBuildFlags = map[string]bool{
+	"asmflags": true,
+	"buildvcs": true,
+	"compiler": true,
+	"gcflags":  true,
+	"ldflags":  true,
+	"mod":      true,
+	"modfile":  true,
+	"overlay":  true,
+	"pgo":      true,
+	"race":     true,
+	"trimpath": true,
+}
+
+func (v *BuildFlagsFlag) Set(s string) error {
+	name, _, _ := strings.Cut(strings.TrimPrefix(strings.TrimPrefix(s, "-"), "-"), "=")
+	switch {
+	case !strings.HasPrefix(s, "-") || name == "":
+		return fmt.Errorf("%q is not a flag of the go command", s)
+	case name == "tags":
+		return fmt.Errorf("use the -tags flag to set build tags")
+	case !passedBuildFlags[name]:
+		return fmt.Errorf("build flag -%s is not supported", name)
+	}
+	*v = append(*v, s)
+	return nil
+}
+
+func (v *BuildFlagsFlag) Get() interface{} { return *v }
+func (v *BuildFlagsFlag) String() string   { return strings.Join(*v, " ") }
+
 // FormatFlag is used for parsing and validation of
 // govulncheck -format flag.
 type FormatFlag string
 
 const (
-	formatUnset   = ""
-	formatJSON    = "json"
-	formatText    = "text"
-	formatSarif   = "sarif"
-	formatOpenVEX = "openvex"
+	formatUnset    = ""
+	formatJSON     = "json"
+	formatText     = "text"
+	formatSarif    = "sarif"
+	formatOpenVEX  = "openvex"
+	formatMarkdown = "markdown"
+	formatHTML     = "html"
 )
 
 var supportedFormats = map[string]bool{
-	formatJSON:    true,
-	formatText:    true,
-	formatSarif:   true,
-	formatOpenVEX: true,
+	formatJSON:     true,
+	formatText:     true,
+	formatSarif:    true,
+	formatOpenVEX:  true,
+	formatMarkdown: true,
+	formatHTML:     true,
 }
 
 func (f *FormatFlag) Get() interface{} { return *f }
@@ -258,18 +654,22 @@ func (f *FormatFlag) Set(s string) error {
 	*f = FormatFlag(s)
 	return nil
 }
-func (f *FormatFlag) String() string { return "" }
+func (f *FormatFlag) String() string { return string(*f) }
 
 // ModeFlag is used for parsing and validation of
 // govulncheck -mode flag.
 type ModeFlag string
 
 var supportedModes = map[string]bool{
-	govulncheck.ScanModeSource:  true,
-	govulncheck.ScanModeBinary:  true,
-	govulncheck.ScanModeConvert: true,
-	govulncheck.ScanModeQuery:   true,
-	govulncheck.ScanModeExtract: true,
+	govulncheck.ScanModeSource:   true,
+	govulncheck.ScanModeBinary:   true,
+	govulncheck.ScanModeConvert:  true,
+	govulncheck.ScanModeQuery:    true,
+	govulncheck.ScanModeExtract:  true,
+	govulncheck.ScanModeHistory:  true,
+	govulncheck.ScanModeInfo:     true,
+	govulncheck.ScanModeAudit:    true,
+	govulncheck.ScanModeSnapshot: true,
 }
 
 func (f *ModeFlag) Get() interface{} { return *f }
@@ -280,7 +680,7 @@ func (f *ModeFlag) Set(s string) error {
 	*f = ModeFlag(s)
 	return nil
 }
-func (f *ModeFlag) String() string { return "" }
+func (f *ModeFlag) String() string { return string(*f) }
 
 // ScanFlag is used for parsing and validation of
 // govulncheck -scan flag.
@@ -300,4 +700,31 @@ func (f *ScanFlag) Set(s string) error {
 	*f = ScanFlag(s)
 	return nil
 }
-func (f *ScanFlag) String() string { return "" }
+func (f *ScanFlag) String() string { return string(*f) }
+
+// GroupByFlag is used for parsing and validation of
+// govulncheck -group-by flag.
+type GroupByFlag string
+
+const (
+	groupingVuln    = "vuln"
+	groupingModule  = "module"
+	groupingPackage = "package"
+)
+
+func (f *GroupByFlag) Get() interface{} { return *f }
+func (f *GroupByFlag) Set(s string) error {
+	switch s {
+	case groupingVuln, groupingModule, groupingPackage:
+	default:
+		return errFlagParse
+	}
+	*f = GroupByFlag(s)
+	return nil
+}
+func (f *GroupByFlag) String() string { return string(*f) }
+
+// Update the text handler h to organize findings as set by the flag.
+func (f GroupByFlag) Update(h *TextHandler) {
+	h.groupBy = string(f)
+}
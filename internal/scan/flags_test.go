@@ -0,0 +1,28 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestBuildFlagsFlag(t *testing.T) {
+	var f BuildFlagsFlag
+	for _, in := range []string{"-mod=vendor", "--buildvcs=false", "-gcflags=all=-N -l", "-trimpath"} {
+		if err := f.Set(in); err != nil {
+			t.Errorf("Set(%q): %v", in, err)
+		}
+	}
+	if want := []string{"-mod=vendor", "--buildvcs=false", "-gcflags=all=-N -l", "-trimpath"}; !slices.Equal(f, want) {
+		t.Errorf("got %q, want %q", f, want)
+	}
+	for _, in := range []string{"", "-", "mod=vendor", "-tags=integration", "-o=out", "-toolexec=x"} {
+		var f BuildFlagsFlag
+		if err := f.Set(in); err == nil {
+			t.Errorf("Set(%q) succeeded, want error", in)
+		}
+	}
+}
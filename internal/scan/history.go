@@ -0,0 +1,150 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"golang.org/x/vuln/internal/govulncheck"
+	"golang.org/x/vuln/internal/history"
+)
+
+// historyTarget returns the path identifying what cfg scans in
+// the history store: the module directory or the binary.
+func historyTarget(cfg *config) (string, error) {
+	if cfg.ScanMode != govulncheck.ScanModeSource && len(cfg.patterns) == 1 {
+		return filepath.Abs(cfg.patterns[0])
+	}
+	return filepath.Abs(filepath.FromSlash(cfg.dir))
+}
+
+// newHistoryHandler returns a handler that passes messages on to h and
+// records the findings at the scan level of cfg for target in store
+// when flushed.
+func newHistoryHandler(h govulncheck.Handler, cfg *config, target string, store *history.Store) *historyHandler {
+	return &historyHandler{
+		Wrapper: govulncheck.Wrapper{Next: h},
+		store:   store,
+		scan: &history.Scan{
+			Time:      time.Now().UTC(),
+			Target:    target,
+			ScanMode:  string(cfg.ScanMode),
+			ScanLevel: string(cfg.ScanLevel),
+			DB:        cfg.db,
+			Findings:  []history.Finding{},
+		},
+		seen: make(map[history.Finding]bool),
+	}
+}
+
+// historyHandler records findings in the history store.
+type historyHandler struct {
+	govulncheck.Wrapper
+	store *history.Store
+	scan  *history.Scan
+	seen  map[history.Finding]bool
+}
+
+func (h *historyHandler) Finding(f *govulncheck.Finding) error {
+	if findingLevel(f) == govulncheck.ScanLevel(h.scan.ScanLevel) && len(f.Trace) > 0 {
+		hf := history.Finding{OSV: f.OSV, Module: f.Trace[0].Module, Version: f.Trace[0].Version}
+		if !h.seen[hf] {
+			h.seen[hf] = true
+			h.scan.Findings = append(h.scan.Findings, hf)
+		}
+	}
+	return h.Next.Finding(f)
+}
+
+// Flush flushes the underlying handler, whose result may be an exit
+// code, before adding the scan to the history, so that the output of
+// the scan is written even if the history is not.
+func (h *historyHandler) Flush() error {
+	err := h.Wrapper.Flush()
+	if aerr := h.store.Add(h.scan); aerr != nil {
+		return errors.Join(err, fmt.Errorf("recording history: %w", aerr))
+	}
+	return err
+}
+
+// findingLevel returns the most precise level of f.
+func findingLevel(f *govulncheck.Finding) govulncheck.ScanLevel {
+	switch {
+	case len(f.Trace) == 0:
+		return ""
+	case f.Trace[0].Function != "":
+		return govulncheck.ScanLevelSymbol
+	case f.Trace[0].Package != "":
+		return govulncheck.ScanLevelPackage
+	default:
+		return govulncheck.ScanLevelModule
+	}
+}
+
+// runHistory prints when each vulnerability recorded for the target
+// of cfg first appeared and when it was resolved.
+func runHistory(cfg *config, store *history.Store, w io.Writer) error {
+	target, err := historyTarget(cfg)
+	if err != nil {
+		return err
+	}
+	scans, err := store.Scans(target)
+	if err != nil {
+		return err
+	}
+	if len(scans) == 0 {
+		fmt.Fprintf(w, "No scans of %s have been recorded. Use the -history flag to record scans.\n", target)
+		return nil
+	}
+	last := scans[len(scans)-1]
+	fmt.Fprintf(w, "History of %s (%d scans, last on %s):\n\n", target, len(scans), formatHistoryTime(last.Time))
+	events := history.Timeline(scans)
+	if len(events) == 0 {
+		fmt.Fprintln(w, "No vulnerabilities found.")
+		return nil
+	}
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "VULNERABILITY\tMODULE\tFIRST SEEN\tRESOLVED")
+	for _, e := range events {
+		mod := e.Module
+		if e.Version != "" {
+			mod += "@" + e.Version
+		}
+		resolved := "-"
+		if !e.Resolved.IsZero() {
+			resolved = formatHistoryTime(e.Resolved)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", e.OSV, mod, formatHistoryTime(e.FirstSeen), resolved)
+	}
+	return tw.Flush()
+}
+
+func formatHistoryTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02 15:04:05 MST")
+}
+
+// historyStore returns the store named by GOVULNCHECK_HISTORY in the
+// environment of cfg, or else the store in the user's cache directory.
+func historyStore(cfg *config) (*history.Store, error) {
+	var path string
+	for _, env := range cfg.env {
+		if v, ok := strings.CutPrefix(env, "GOVULNCHECK_HISTORY="); ok {
+			path = v
+		}
+	}
+	if path == "" {
+		var err error
+		if path, err = history.DefaultPath(); err != nil {
+			return nil, err
+		}
+	}
+	return history.NewStore(path), nil
+}
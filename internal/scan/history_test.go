@@ -0,0 +1,90 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/vuln/internal/govulncheck"
+	"golang.org/x/vuln/internal/history"
+	"golang.org/x/vuln/internal/test"
+)
+
+func TestHistory(t *testing.T) {
+	store := history.NewStore(filepath.Join(t.TempDir(), "history.jsonl"))
+	dir := t.TempDir()
+	cfg := &config{dir: dir}
+	cfg.ScanMode = govulncheck.ScanModeSource
+	cfg.ScanLevel = govulncheck.ScanLevelSymbol
+
+	findings := []*govulncheck.Finding{
+		// Only the symbol level findings are recorded, once each.
+		{OSV: "GO-2024-0001", Trace: []*govulncheck.Frame{{Module: "example.com/a", Version: "v1.0.0"}}},
+		{OSV: "GO-2024-0001", Trace: []*govulncheck.Frame{{Module: "example.com/a", Version: "v1.0.0", Package: "example.com/a"}}},
+		{OSV: "GO-2024-0001", Trace: []*govulncheck.Frame{{Module: "example.com/a", Version: "v1.0.0", Package: "example.com/a", Function: "F"}}},
+		{OSV: "GO-2024-0001", Trace: []*govulncheck.Frame{{Module: "example.com/a", Version: "v1.0.0", Package: "example.com/a", Function: "G"}}},
+		{OSV: "GO-2024-0002", Trace: []*govulncheck.Frame{{Module: "example.com/b", Version: "v0.1.0", Package: "example.com/b"}}},
+	}
+	mock := test.NewMockHandler()
+	target, err := historyTarget(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := newHistoryHandler(mock, cfg, target, store)
+	for _, f := range findings {
+		if err := h.Finding(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := h.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if len(mock.FindingMessages) != len(findings) {
+		t.Errorf("passed on %d findings, want %d", len(mock.FindingMessages), len(findings))
+	}
+	scans, err := store.Scans(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(scans) != 1 || len(scans[0].Findings) != 1 || scans[0].Findings[0].OSV != "GO-2024-0001" {
+		t.Fatalf("recorded scans %+v, want one scan with GO-2024-0001", scans)
+	}
+
+	var buf bytes.Buffer
+	if err := runHistory(cfg, store, &buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{"1 scans", "GO-2024-0001", "example.com/a@v1.0.0"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("runHistory output does not contain %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestHistoryHandlerAddError(t *testing.T) {
+	// The store cannot be created under a regular file.
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	store := history.NewStore(filepath.Join(file, "history.jsonl"))
+	next := &flushHandler{Handler: test.NewMockHandler(), err: errVulnerabilitiesFound}
+	cfg := &config{}
+	cfg.ScanMode = govulncheck.ScanModeSource
+	h := newHistoryHandler(next, cfg, "target", store)
+	err := h.Flush()
+	if !next.flushed {
+		t.Error("the output was not flushed")
+	}
+	if !errors.Is(err, errVulnerabilitiesFound) || !strings.Contains(err.Error(), "recording history") {
+		t.Errorf("got error %v, want both the exit code and the history error", err)
+	}
+}
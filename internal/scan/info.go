@@ -0,0 +1,161 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"golang.org/x/vuln/internal"
+	"golang.org/x/vuln/internal/client"
+	"golang.org/x/vuln/internal/govulncheck"
+	"golang.org/x/vuln/internal/osv"
+)
+
+// runInfo emits the OSV entries with the IDs in the config, which may
+// also be aliases such as CVE IDs, to handler.
+func runInfo(ctx context.Context, handler govulncheck.Handler, cfg *config, c *client.Client) error {
+	entries, err := c.ByIDs(ctx, cfg.patterns)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if err := handler.OSV(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// advisories prints the details of the OSV entries
+// of the handler, for info mode.
+func (h *TextHandler) advisories() {
+	for i, e := range h.osvs {
+		if i > 0 {
+			h.print("\n")
+		}
+		h.advisory(e)
+	}
+}
+
+// advisory prints the details of e.
+func (h *TextHandler) advisory(e *osv.Entry) {
+	h.style(osvCalledStyle, e.ID)
+	h.print("\n")
+	h.style(detailsStyle)
+	if e.Summary != "" {
+		h.wrap("    ", e.Summary, 80)
+		h.print("\n\n")
+	}
+	h.wrap("    ", e.Details, 80)
+	h.style(defaultStyle)
+	h.print("\n")
+	if len(e.Aliases) > 0 {
+		h.style(keyStyle, h.msg("  Aliases:"))
+		h.print(" ", strings.Join(e.Aliases, ", "), "\n")
+	}
+	h.style(keyStyle, h.msg("  Published:"))
+	h.print(" ", e.Published.Format(time.DateOnly))
+	if e.Modified.After(e.Published) {
+		h.print(h.msgf(", last modified %s", e.Modified.Format(time.DateOnly)))
+	}
+	h.print("\n")
+	if e.Withdrawn != nil {
+		h.style(keyStyle, h.msg("  Withdrawn:"))
+		h.print(" ", e.Withdrawn.Format(time.DateOnly), "\n")
+	}
+	h.severity("  ", e.Severity)
+	for _, a := range e.Affected {
+		h.print("  ")
+		if a.Module.Path == internal.GoStdModulePath {
+			h.print(h.msg("Standard library"))
+		} else {
+			h.style(keyStyle, h.msg("Module: "))
+			h.print(a.Module.Path)
+		}
+		h.print("\n")
+		h.style(keyStyle, h.msg("    Affected versions: "))
+		h.print(h.affectedRanges(a.Module.Path, a.Ranges), "\n")
+		h.severity("    ", a.Severity)
+		for _, p := range a.EcosystemSpecific.Packages {
+			h.style(keyStyle, h.msg("    Package: "))
+			h.print(p.Path, "\n")
+			h.style(keyStyle, h.msg("      Symbols: "))
+			if len(p.Symbols) == 0 {
+				h.print(h.msg("all"), "\n")
+			} else {
+				h.print(strings.Join(p.Symbols, ", "), "\n")
+			}
+			pe := &osv.Entry{Affected: []osv.Affected{{
+				EcosystemSpecific: osv.EcosystemSpecific{Packages: []osv.Package{p}},
+			}}}
+			if platforms := platforms("", pe); len(platforms) > 0 {
+				h.style(keyStyle, h.msg("      Platforms: "))
+				h.print(strings.Join(platforms, ", "), "\n")
+			}
+		}
+	}
+	if len(e.References) > 0 {
+		h.style(keyStyle, h.msg("  References:"))
+		h.print("\n")
+		for _, r := range e.References {
+			h.print("    ", r.Type, ": ", r.URL, "\n")
+		}
+	}
+	if e.DatabaseSpecific != nil && e.DatabaseSpecific.URL != "" {
+		h.style(keyStyle, h.msg("  More info:"))
+		h.print(" ", e.DatabaseSpecific.URL, "\n")
+	}
+}
+
+// severity prints the severity scores in ss, if any, after indent.
+func (h *TextHandler) severity(indent string, ss []osv.Severity) {
+	for _, s := range ss {
+		h.print(indent)
+		h.style(keyStyle, h.msg("Severity: "))
+		h.print(s.Type, " ", s.Score, "\n")
+	}
+}
+
+// affectedRanges describes the versions of the module with path
+// that ranges affect, such as "from v1.2.0 before v1.2.5".
+func (h *TextHandler) affectedRanges(path string, ranges []osv.Range) string {
+	var parts []string
+	for _, r := range ranges {
+		version := func(v string) string {
+			if r.Type != osv.RangeTypeSemver {
+				return v
+			}
+			return moduleVersionString(path, "v"+v)
+		}
+		var introduced string
+		for _, ev := range r.Events {
+			switch {
+			case ev.Introduced == "0":
+				introduced = "0"
+			case ev.Introduced != "":
+				introduced = version(ev.Introduced)
+			case ev.Fixed != "" && introduced == "0":
+				parts = append(parts, h.msgf("before %s", version(ev.Fixed)))
+				introduced = ""
+			case ev.Fixed != "":
+				parts = append(parts, h.msgf("from %s before %s", introduced, version(ev.Fixed)))
+				introduced = ""
+			}
+		}
+		switch introduced {
+		case "":
+		case "0":
+			parts = append(parts, h.msg("all versions"))
+		default:
+			parts = append(parts, h.msgf("from %s", introduced))
+		}
+	}
+	if len(parts) == 0 {
+		return h.msg("all versions")
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,92 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"io"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+)
+
+// MemoryFlag is used for parsing and validation of
+// govulncheck -max-memory flag. Its value is a number
+// of bytes, optionally followed by a unit such as MiB
+// or GB.
+type MemoryFlag int64
+
+// memoryUnits are the units accepted by MemoryFlag,
+// longest first so that suffixes are matched correctly.
+var memoryUnits = []struct {
+	suffix string
+	bytes  int64
+}{
+	{"KiB", 1 << 10}, {"MiB", 1 << 20}, {"GiB", 1 << 30}, {"TiB", 1 << 40},
+	{"KB", 1e3}, {"MB", 1e6}, {"GB", 1e9}, {"TB", 1e12},
+	{"B", 1},
+}
+
+func (f *MemoryFlag) Get() interface{} { return *f }
+func (f *MemoryFlag) Set(s string) error {
+	num, unit := s, int64(1)
+	for _, u := range memoryUnits {
+		if n, ok := strings.CutSuffix(s, u.suffix); ok {
+			num, unit = n, u.bytes
+			break
+		}
+	}
+	n, err := strconv.ParseInt(strings.TrimSpace(num), 10, 64)
+	if err != nil || n <= 0 || n > (1<<62)/unit {
+		return errFlagParse
+	}
+	*f = MemoryFlag(n * unit)
+	return nil
+}
+func (f *MemoryFlag) String() string {
+	if *f == 0 {
+		return ""
+	}
+	// Use the largest unit that f is a multiple of.
+	best := memoryUnits[len(memoryUnits)-1]
+	for _, u := range memoryUnits {
+		if int64(*f)%u.bytes == 0 && u.bytes > best.bytes {
+			best = u
+		}
+	}
+	return strconv.FormatInt(int64(*f)/best.bytes, 10) + best.suffix
+}
+
+// memoryPerProc is roughly how much memory one thread of
+// package loading and analysis can use on large code bases.
+const memoryPerProc = 512 << 20
+
+// LimitMemory applies the -max-memory flag in args, if any, to the Go
+// runtime. As the settings of the runtime are shared by the whole
+// process, only cmd/govulncheck applies them, so that scans run with
+// golang.org/x/vuln/scan do not change the settings of the programs
+// running them. Invalid flags are left for the scan to report.
+func LimitMemory(args []string) {
+	cfg := &config{}
+	if err := parseFlags(cfg, io.Discard, args); err != nil || cfg.maxMemory == 0 {
+		return
+	}
+	limitMemory(int64(cfg.maxMemory))
+}
+
+// limitMemory sets a soft memory limit of n bytes for the Go
+// runtime, and lowers GOMAXPROCS so that about memoryPerProc
+// bytes are available to each thread. Analyses check the limit
+// to trade speed for lower peak memory use. The returned
+// function restores the previous settings.
+func limitMemory(n int64) (restore func()) {
+	limit := debug.SetMemoryLimit(n)
+	procs := runtime.GOMAXPROCS(0)
+	runtime.GOMAXPROCS(max(1, min(procs, int(n/memoryPerProc))))
+	return func() {
+		debug.SetMemoryLimit(limit)
+		runtime.GOMAXPROCS(procs)
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"runtime"
+	"runtime/debug"
+	"testing"
+)
+
+func TestMemoryFlag(t *testing.T) {
+	for _, test := range []struct {
+		in   string
+		want int64
+		str  string
+	}{
+		{"1048576", 1 << 20, "1MiB"},
+		{"4GiB", 4 << 30, "4GiB"},
+		{"512MiB", 512 << 20, "512MiB"},
+		{"2GB", 2e9, "2GB"},
+		{"1500KB", 1500e3, "1500KB"},
+		{"100B", 100, "100B"},
+	} {
+		var f MemoryFlag
+		if err := f.Set(test.in); err != nil {
+			t.Errorf("Set(%q): %v", test.in, err)
+			continue
+		}
+		if int64(f) != test.want {
+			t.Errorf("Set(%q) = %d, want %d", test.in, f, test.want)
+		}
+		if got := f.String(); got != test.str {
+			t.Errorf("Set(%q).String() = %q, want %q", test.in, got, test.str)
+		}
+	}
+	for _, in := range []string{"", "GiB", "-1GiB", "0", "1.5GiB", "4gib", "99999999TiB"} {
+		var f MemoryFlag
+		if err := f.Set(in); err == nil {
+			t.Errorf("Set(%q) = %d, want error", in, f)
+		}
+	}
+}
+
+func TestLimitMemory(t *testing.T) {
+	limit := debug.SetMemoryLimit(-1)
+	procs := runtime.GOMAXPROCS(0)
+	restore := limitMemory(1 << 20)
+	if got := debug.SetMemoryLimit(-1); got != 1<<20 {
+		t.Errorf("memory limit = %d, want %d", got, 1<<20)
+	}
+	if got := runtime.GOMAXPROCS(0); got != 1 {
+		t.Errorf("GOMAXPROCS = %d, want 1", got)
+	}
+	restore()
+	if got := debug.SetMemoryLimit(-1); got != limit {
+		t.Errorf("restored memory limit = %d, want %d", got, limit)
+	}
+	if got := runtime.GOMAXPROCS(0); got != procs {
+		t.Errorf("restored GOMAXPROCS = %d, want %d", got, procs)
+	}
+}
+
+func TestLimitMemoryFlag(t *testing.T) {
+	limit := debug.SetMemoryLimit(-1)
+	procs := runtime.GOMAXPROCS(0)
+	defer func() {
+		debug.SetMemoryLimit(limit)
+		runtime.GOMAXPROCS(procs)
+	}()
+	// Flags that the scan rejects change nothing.
+	LimitMemory([]string{"-mode", "binary", "-max-memory", "1MiB", "app"})
+	if got := debug.SetMemoryLimit(-1); got != limit {
+		t.Errorf("memory limit = %d, want %d", got, limit)
+	}
+	LimitMemory([]string{"-max-memory", "1MiB", "./..."})
+	if got := debug.SetMemoryLimit(-1); got != 1<<20 {
+		t.Errorf("memory limit = %d, want %d", got, 1<<20)
+	}
+}
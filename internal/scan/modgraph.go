@@ -0,0 +1,227 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/semver"
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/vuln/internal"
+	"golang.org/x/vuln/internal/govulncheck"
+)
+
+// moduleGraph is the module requirement graph of the main modules.
+type moduleGraph struct {
+	// prev maps each module path reachable from the main modules to
+	// the module that requires it on a shortest chain of requirements.
+	// Main modules map to "".
+	prev map[string]string
+	// versions maps module paths to their selected versions.
+	versions map[string]string
+	// replaced maps the paths of replacement modules to the paths
+	// of the modules they replace.
+	replaced map[string]string
+}
+
+// loadModuleGraph loads the requirement graph of the main modules
+// among mods, using 'go mod graph' in dir and their go.mod files. The
+// requirements that the go.mod file of a main module marks as indirect
+// are ignored, so that modules are reached through the dependency that
+// actually requires them.
+func loadModuleGraph(ctx context.Context, cfg *config, dir string, mods []*packages.Module) (*moduleGraph, error) {
+	cmd := exec.CommandContext(ctx, "go", "mod", "graph")
+	cmd.Dir = dir
+	cmd.Env = cfg.env
+	out, err := cmd.Output()
+	if err != nil {
+		var ee *exec.ExitError
+		if errors.As(err, &ee) && len(ee.Stderr) > 0 {
+			err = errors.New(strings.TrimSpace(string(ee.Stderr)))
+		}
+		return nil, fmt.Errorf("go mod graph: %w", err)
+	}
+	direct := make(map[string][]string)
+	for _, m := range mods {
+		if m.Main && m.GoMod != "" {
+			if direct[m.Path], err = requirements(m.GoMod, false); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return newModuleGraph(out, mods, direct), nil
+}
+
+// newModuleGraph returns the module graph of mods given the output
+// of 'go mod graph' and the direct requirements of the main modules.
+func newModuleGraph(out []byte, mods []*packages.Module, direct map[string][]string) *moduleGraph {
+	g := &moduleGraph{
+		prev:     make(map[string]string),
+		versions: make(map[string]string),
+		replaced: make(map[string]string),
+	}
+	for _, m := range mods {
+		if m.Replace != nil && m.Replace.Path != m.Path {
+			g.replaced[m.Replace.Path] = m.Path
+		}
+	}
+	// The selected version of a module is the highest
+	// version of it in the (pruned) requirement graph.
+	edges := make(map[string][]string)
+	sc := bufio.NewScanner(bytes.NewReader(out))
+	for sc.Scan() {
+		from, to, ok := strings.Cut(sc.Text(), " ")
+		if !ok {
+			continue
+		}
+		path, version, _ := strings.Cut(to, "@")
+		if path == "go" || path == "toolchain" {
+			continue
+		}
+		edges[from] = append(edges[from], path)
+		if semver.Compare(version, g.versions[path]) > 0 {
+			g.versions[path] = version
+		}
+	}
+
+	var queue []string
+	for _, m := range mods {
+		if reqs, ok := direct[m.Path]; ok {
+			// Main modules are listed in 'go mod graph'
+			// by path alone.
+			g.prev[m.Path] = ""
+			queue = append(queue, m.Path)
+			edges[m.Path] = reqs
+		}
+	}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		from := p
+		if _, ok := direct[p]; !ok {
+			from = p + "@" + g.versions[p]
+		}
+		for _, q := range edges[from] {
+			if _, ok := g.prev[q]; !ok {
+				g.prev[q] = p
+				queue = append(queue, q)
+			}
+		}
+	}
+	return g
+}
+
+// requirements returns the paths of the modules that the go.mod file
+// gomod requires, leaving out those it marks as indirect unless
+// indirect is set.
+func requirements(gomod string, indirect bool) ([]string, error) {
+	data, err := os.ReadFile(gomod)
+	if err != nil {
+		return nil, err
+	}
+	f, err := modfile.ParseLax(gomod, data, nil)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, r := range f.Require {
+		if indirect || !r.Indirect {
+			paths = append(paths, r.Mod.Path)
+		}
+	}
+	return paths, nil
+}
+
+// chain returns the shortest chain of requirements from a main module
+// to the module with path, as module@version strings except for the
+// main module, or nil if the module is a main module, a requirement of
+// one, or not reachable.
+func (g *moduleGraph) chain(path string) []string {
+	if p, ok := g.replaced[path]; ok {
+		path = p
+	}
+	var chain []string
+	for p := path; ; {
+		prev, ok := g.prev[p]
+		if !ok {
+			return nil
+		}
+		if prev == "" {
+			chain = append(chain, p)
+			break
+		}
+		chain = append(chain, p+"@"+g.versions[p])
+		p = prev
+	}
+	if len(chain) <= 2 {
+		return nil
+	}
+	for i, j := 0, len(chain)-1; i < j; i, j = i+1, j-1 {
+		chain[i], chain[j] = chain[j], chain[i]
+	}
+	return chain
+}
+
+// moduleChains returns middleware that sets the ModuleChain of findings
+// for modules that the main modules require indirectly, using the graph
+// returned by load. As loading the graph runs the go command, load is
+// only called for the first finding outside the standard library. If it
+// fails, findings are passed on without chains, and the failure is
+// reported in a progress message, which text output shows with
+// -show verbose. Findings are copied before they are changed.
+func moduleChains(load func() (*moduleGraph, error)) govulncheck.Middleware {
+	return func(next govulncheck.Handler) govulncheck.Handler {
+		return &moduleChainHandler{Wrapper: govulncheck.Wrapper{Next: next}, load: load}
+	}
+}
+
+type moduleChainHandler struct {
+	govulncheck.Wrapper
+	load   func() (*moduleGraph, error)
+	loaded bool
+	graph  *moduleGraph // nil if it failed to load
+}
+
+func (h *moduleChainHandler) Finding(f *govulncheck.Finding) error {
+	if len(f.Trace) > 0 && f.ModuleChain == nil && f.Trace[0].Module != internal.GoStdModulePath {
+		g, err := h.moduleGraph()
+		if err != nil {
+			return err
+		}
+		if g != nil {
+			if chain := g.chain(f.Trace[0].Module); chain != nil {
+				c := *f
+				c.ModuleChain = chain
+				f = &c
+			}
+		}
+	}
+	return h.Next.Finding(f)
+}
+
+// moduleGraph returns the module graph, loading it the first time.
+func (h *moduleChainHandler) moduleGraph() (*moduleGraph, error) {
+	if !h.loaded {
+		h.loaded = true
+		g, err := h.load()
+		if err != nil {
+			// The module graph is only context for
+			// findings, so the scan goes on without it.
+			return nil, h.Next.Progress(&govulncheck.Progress{
+				Message: fmt.Sprintf("Module chains are not reported, as the module graph failed to load: %v", err),
+			})
+		}
+		h.graph = g
+	}
+	return h.graph, nil
+}
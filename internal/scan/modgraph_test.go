@@ -0,0 +1,103 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/vuln/internal/govulncheck"
+	"golang.org/x/vuln/internal/test"
+)
+
+func TestModuleGraphChain(t *testing.T) {
+	const out = `example.com/main example.com/a@v1.0.0
+example.com/main example.com/b@v1.0.0
+example.com/main example.com/v@v0.2.0
+example.com/main go@1.21
+example.com/a@v1.0.0 example.com/c@v1.1.0
+example.com/a@v1.0.0 go@1.20
+example.com/b@v1.0.0 example.com/c@v1.0.0
+example.com/b@v1.0.0 example.com/r@v1.0.0
+example.com/c@v1.0.0 example.com/old@v1.0.0
+example.com/c@v1.1.0 example.com/v@v0.2.0
+example.com/c@v1.1.0 example.com/v@v0.1.0
+`
+	mods := []*packages.Module{
+		{Path: "example.com/main", Main: true},
+		{Path: "example.com/r", Version: "v1.0.0", Replace: &packages.Module{Path: "example.com/fork", Version: "v1.0.1"}},
+	}
+	// example.com/v is an indirect requirement of the main module.
+	g := newModuleGraph([]byte(out), mods, map[string][]string{
+		"example.com/main": {"example.com/a", "example.com/b"},
+	})
+	for _, test := range []struct {
+		path string
+		want []string
+	}{
+		{"example.com/main", nil},
+		{"example.com/a", nil},
+		{"example.com/c", []string{"example.com/main", "example.com/a@v1.0.0", "example.com/c@v1.1.0"}},
+		{"example.com/v", []string{"example.com/main", "example.com/a@v1.0.0", "example.com/c@v1.1.0", "example.com/v@v0.2.0"}},
+		{"example.com/fork", []string{"example.com/main", "example.com/b@v1.0.0", "example.com/r@v1.0.0"}},
+		// Only required by a version of c that is not selected.
+		{"example.com/old", nil},
+		{"stdlib", nil},
+	} {
+		if diff := cmp.Diff(test.want, g.chain(test.path)); diff != "" {
+			t.Errorf("chain(%q) (-want,+got):%s", test.path, diff)
+		}
+	}
+}
+
+func TestModuleChains(t *testing.T) {
+	g := newModuleGraph([]byte("example.com/main example.com/a@v1.0.0\nexample.com/a@v1.0.0 example.com/v@v0.1.0\n"),
+		[]*packages.Module{{Path: "example.com/main", Main: true}},
+		map[string][]string{"example.com/main": {"example.com/a"}})
+	stdlib := &govulncheck.Finding{OSV: "GO-0000-0001", Trace: []*govulncheck.Frame{{Module: "stdlib"}}}
+	indirect := &govulncheck.Finding{OSV: "GO-0000-0002", Trace: []*govulncheck.Frame{{Module: "example.com/v"}}}
+	for _, tc := range []struct {
+		name      string
+		err       error
+		wantChain []string
+	}{
+		{name: "loaded", wantChain: []string{"example.com/main", "example.com/a@v1.0.0", "example.com/v@v0.1.0"}},
+		{name: "failed", err: errors.New("go mod graph: no go.mod")},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			loads := 0
+			mock := test.NewMockHandler()
+			h := govulncheck.Chain(mock, moduleChains(func() (*moduleGraph, error) {
+				loads++
+				if tc.err != nil {
+					return nil, tc.err
+				}
+				return g, nil
+			}))
+			if err := h.Finding(stdlib); err != nil {
+				t.Fatal(err)
+			}
+			if loads != 0 {
+				t.Fatal("the module graph was loaded for a finding in the standard library")
+			}
+			for i := 0; i < 2; i++ {
+				if err := h.Finding(indirect); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if loads != 1 {
+				t.Errorf("the module graph was loaded %d times, want 1", loads)
+			}
+			if diff := cmp.Diff(tc.wantChain, mock.FindingMessages[2].ModuleChain); diff != "" {
+				t.Errorf("module chain (-want,+got):%s", diff)
+			}
+			if got := len(mock.ProgressMessages); (tc.err != nil) != (got == 1) || got > 1 {
+				t.Errorf("got %d progress messages, want one only if loading failed", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/vuln/internal/govulncheck"
+)
+
+// codeOwners are the rules of a CODEOWNERS file, which assign owners to
+// the files of a repository.
+type codeOwners struct {
+	// root is the directory that the patterns of rules are relative to.
+	root  string
+	rules []ownerRule
+}
+
+// An ownerRule assigns owners to the files that match a pattern.
+// Rules without owners leave the files they match unowned.
+type ownerRule struct {
+	pattern string
+	owners  []string
+}
+
+// loadCodeOwners loads the CODEOWNERS file, or a file in the same
+// syntax, with the given name. As for GitHub and GitLab, the patterns
+// of a file in a .github, .gitlab, or docs directory are relative to
+// the parent of that directory.
+func loadCodeOwners(file string) (*codeOwners, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := parseCodeOwners(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	root, err := filepath.Abs(filepath.Dir(file))
+	if err != nil {
+		return nil, err
+	}
+	switch filepath.Base(root) {
+	case ".github", ".gitlab", "docs":
+		root = filepath.Dir(root)
+	}
+	return &codeOwners{root: root, rules: rules}, nil
+}
+
+// parseCodeOwners parses the rules of a CODEOWNERS file: one pattern per
+// line followed by its owners, with comments starting with #. GitLab
+// sections, such as [Docs], are ignored.
+func parseCodeOwners(data []byte) ([]ownerRule, error) {
+	var rules []ownerRule
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; sc.Scan(); n++ {
+		line := sc.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "[") || strings.HasPrefix(fields[0], "^[") {
+			continue
+		}
+		for _, seg := range strings.Split(strings.Trim(fields[0], "/"), "/") {
+			if _, err := path.Match(seg, ""); err != nil {
+				return nil, fmt.Errorf("line %d: invalid pattern %s", n, fields[0])
+			}
+		}
+		rules = append(rules, ownerRule{pattern: fields[0], owners: fields[1:]})
+	}
+	return rules, sc.Err()
+}
+
+// owners returns the owners of the file with the given absolute path,
+// which are those of the last rule that matches it.
+func (o *codeOwners) owners(file string) []string {
+	rel, err := filepath.Rel(o.root, file)
+	if err != nil || !filepath.IsLocal(rel) {
+		return nil
+	}
+	rel = filepath.ToSlash(rel)
+	for i := len(o.rules) - 1; i >= 0; i-- {
+		if matchOwnersPattern(o.rules[i].pattern, rel) {
+			return o.rules[i].owners
+		}
+	}
+	return nil
+}
+
+// matchOwnersPattern reports whether the CODEOWNERS pattern matches
+// the slash-separated relative file path. As in .gitignore files, a
+// pattern is relative to the root if it starts with or contains a slash
+// other than a trailing one, and can match at any depth otherwise. A
+// pattern that matches a directory matches the files below it, except
+// when its last element is * and it does not end with a slash; a
+// pattern that ends with a slash only matches directories. The element
+// ** matches any number of directories.
+func matchOwnersPattern(pattern, file string) bool {
+	dirOnly := strings.HasSuffix(pattern, "/")
+	pattern = strings.TrimSuffix(pattern, "/")
+	anchored := strings.Contains(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+	pat := strings.Split(pattern, "/")
+	nested := dirOnly || pat[len(pat)-1] != "*"
+	segs := strings.Split(file, "/")
+	for start := range segs {
+		if anchored && start > 0 {
+			break
+		}
+		for end := start + 1; end <= len(segs); end++ {
+			if end == len(segs) && dirOnly || end < len(segs) && !nested {
+				continue
+			}
+			if matchSegments(pat, segs[start:end]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matchSegments reports whether the path elements segs
+// match the pattern elements pat.
+func matchSegments(pat, segs []string) bool {
+	if len(pat) == 0 {
+		return len(segs) == 0
+	}
+	if pat[0] == "**" {
+		for i := 0; i <= len(segs); i++ {
+			if matchSegments(pat[1:], segs[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segs) == 0 {
+		return false
+	}
+	ok, _ := path.Match(pat[0], segs[0])
+	return ok && matchSegments(pat[1:], segs[1:])
+}
+
+// codeOwnership returns middleware that sets the Owners of findings to
+// the owners in o of the file of the scanned code that is closest to the
+// vulnerable symbol in their trace. dirs maps the paths of the main
+// modules to their directories. Findings are copied before they are
+// changed.
+func codeOwnership(o *codeOwners, dirs map[string]string) govulncheck.Middleware {
+	return func(next govulncheck.Handler) govulncheck.Handler {
+		return &ownersHandler{govulncheck.Wrapper{Next: next}, o, dirs}
+	}
+}
+
+type ownersHandler struct {
+	govulncheck.Wrapper
+	codeOwners *codeOwners
+	dirs       map[string]string
+}
+
+func (h *ownersHandler) Finding(f *govulncheck.Finding) error {
+	for _, fr := range f.Trace {
+		dir, ok := h.dirs[fr.Module]
+		if !ok || fr.Origin != govulncheck.OriginMain || fr.Position == nil || fr.Position.Filename == "" {
+			continue
+		}
+		if owners := h.codeOwners.owners(filepath.Join(dir, filepath.FromSlash(fr.Position.Filename))); len(owners) > 0 {
+			c := *f
+			c.Owners = owners
+			f = &c
+		}
+		break
+	}
+	return h.Next.Finding(f)
+}
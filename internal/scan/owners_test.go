@@ -0,0 +1,82 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestMatchOwnersPattern(t *testing.T) {
+	for _, tc := range []struct {
+		pattern, file string
+		want          bool
+	}{
+		{"*", "main.go", true},
+		{"*", "a/b/main.go", true},
+		{"*.go", "a/b/main.go", true},
+		{"*.go", "a/b/README.md", false},
+		{"api", "api/server.go", true},
+		{"api", "internal/api/server.go", true},
+		{"api/", "internal/api/server.go", true},
+		{"api/", "api", false},
+		{"/api", "internal/api/server.go", false},
+		{"/api/", "api/v1/server.go", true},
+		{"internal/api", "internal/api/server.go", true},
+		{"internal/api", "x/internal/api/server.go", false},
+		{"docs/*", "docs/index.md", true},
+		{"docs/*", "docs/api/index.md", false},
+		{"docs/**", "docs/api/index.md", true},
+		{"**/gen", "a/b/gen/x.go", true},
+		{"a/**/b.go", "a/b.go", true},
+		{"a/**/b.go", "a/x/y/b.go", true},
+		{"/main.go", "cmd/main.go", false},
+	} {
+		if got := matchOwnersPattern(tc.pattern, tc.file); got != tc.want {
+			t.Errorf("matchOwnersPattern(%q, %q) = %t, want %t", tc.pattern, tc.file, got, tc.want)
+		}
+	}
+}
+
+func TestCodeOwners(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, ".github", "CODEOWNERS")
+	if err := os.Mkdir(filepath.Dir(file), 0755); err != nil {
+		t.Fatal(err)
+	}
+	data := `# Default owners.
+* @org/all  # everyone
+
+[Section]
+/api/ @org/api @alice
+/api/generated/
+`
+	if err := os.WriteFile(file, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	o, err := loadCodeOwners(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		file string
+		want []string
+	}{
+		{"main.go", []string{"@org/all"}},
+		{"api/server.go", []string{"@org/api", "@alice"}},
+		{"api/generated/api.go", nil},
+		{"../outside.go", nil},
+	} {
+		if got := o.owners(filepath.Join(dir, filepath.FromSlash(tc.file))); !slices.Equal(got, tc.want) {
+			t.Errorf("owners(%s) = %v, want %v", tc.file, got, tc.want)
+		}
+	}
+
+	if _, err := parseCodeOwners([]byte("/api/[ @org/api\n")); err == nil {
+		t.Error("parseCodeOwners succeeded on an invalid pattern, want error")
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"golang.org/x/vuln/internal/govulncheck"
+	"golang.org/x/vuln/internal/osv"
+	"golang.org/x/vuln/internal/policy"
+)
+
+// loadPolicy reads and parses the policy in file.
+func loadPolicy(file string) (*policy.Policy, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	p, err := policy.Parse(string(b))
+	if err != nil {
+		return nil, fmt.Errorf("%s:%v", file, err)
+	}
+	return p, nil
+}
+
+// newPolicyHandler returns a handler that evaluates p for each finding,
+// as of now, and passes on the findings that are not suppressed. The
+// policy rather than the findings determines whether flushing fails
+// with errVulnerabilitiesFound.
+func newPolicyHandler(h govulncheck.Handler, p *policy.Policy, now time.Time) *policyHandler {
+	return &policyHandler{
+		Wrapper: govulncheck.Wrapper{Next: h},
+		policy:  p,
+		now:     now,
+		osvs:    make(map[string]*osv.Entry),
+	}
+}
+
+type policyHandler struct {
+	govulncheck.Wrapper
+	policy *policy.Policy
+	now    time.Time
+	osvs   map[string]*osv.Entry
+	fail   bool
+}
+
+func (h *policyHandler) OSV(e *osv.Entry) error {
+	h.osvs[e.ID] = e
+	return h.Next.OSV(e)
+}
+
+func (h *policyHandler) Finding(f *govulncheck.Finding) error {
+	a, err := h.policy.Eval(policyInput(f, h.osvs[f.OSV], h.now))
+	if err != nil {
+		return fmt.Errorf("evaluating policy for %s: %v", f.OSV, err)
+	}
+	switch a {
+	case policy.Suppress:
+		return nil
+	case policy.Fail:
+		h.fail = true
+	}
+	return h.Next.Finding(f)
+}
+
+func (h *policyHandler) Flush() error {
+	err := h.Wrapper.Flush()
+	if err == errVulnerabilitiesFound {
+		err = nil
+	}
+	if err == nil && h.fail {
+		err = errVulnerabilitiesFound
+	}
+	return err
+}
+
+// policyInput returns the policy input for f, whose OSV entry is e,
+// as of now. e may be nil if it was not seen. The days for which a fix
+// has been available come from the Disclosure of f.
+func policyInput(f *govulncheck.Finding, e *osv.Entry, now time.Time) *policy.Input {
+	in := &policy.Input{
+		ID:           f.OSV,
+		FixedVersion: f.FixedVersion,
+		FixAvailable: f.FixedVersion != "",
+	}
+	if len(f.Trace) > 0 {
+		in.Module = f.Trace[0].Module
+		in.Reachable = f.Trace[0].Function != ""
+	}
+	for i := 0; i+1 < len(f.Trace); i++ {
+		if f.Trace[i].Module != f.Trace[i+1].Module {
+			in.ModuleDepth++
+		}
+	}
+	if d := f.Disclosure; d != nil && d.FixAvailableDays != nil {
+		in.FixAvailableDays = *d.FixAvailableDays
+	}
+	if e == nil {
+		return in
+	}
+	// Module specific severities take precedence.
+	var sevs []osv.Severity
+	for _, a := range e.Affected {
+		if a.Module.Path == in.Module {
+			sevs = append(sevs, a.Severity...)
+		}
+	}
+	in.Severity = policy.Severity(append(sevs, e.Severity...))
+	published := e.Published
+	if published.IsZero() {
+		published = e.Modified
+	}
+	if !published.IsZero() && now.After(published) {
+		in.AgeDays = int(now.Sub(published).Hours() / 24)
+	}
+	return in
+}
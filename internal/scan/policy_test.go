@@ -0,0 +1,93 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/vuln/internal/govulncheck"
+	"golang.org/x/vuln/internal/osv"
+	"golang.org/x/vuln/internal/policy"
+	"golang.org/x/vuln/internal/test"
+)
+
+func TestPolicyHandler(t *testing.T) {
+	p, err := policy.Parse(`reachable ? "fail" : fix_available ? "report" : "suppress"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	findings := []*govulncheck.Finding{
+		{OSV: "GO-2024-0001", FixedVersion: "v1.0.1", Trace: []*govulncheck.Frame{{Module: "example.com/a", Package: "example.com/a"}}},
+		{OSV: "GO-2024-0002", Trace: []*govulncheck.Frame{{Module: "example.com/b", Package: "example.com/b"}}},
+		{OSV: "GO-2024-0003", Trace: []*govulncheck.Frame{{Module: "example.com/c", Package: "example.com/c", Function: "F"}}},
+	}
+	for _, tc := range []struct {
+		findings []*govulncheck.Finding
+		wantOSVs []string
+		wantErr  error
+	}{
+		{findings, []string{"GO-2024-0001", "GO-2024-0003"}, errVulnerabilitiesFound},
+		{findings[:2], []string{"GO-2024-0001"}, nil},
+	} {
+		mock := test.NewMockHandler()
+		h := newPolicyHandler(mock, p, time.Now())
+		for _, f := range tc.findings {
+			if err := h.Finding(f); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := h.Flush(); err != tc.wantErr {
+			t.Errorf("Flush() = %v, want %v", err, tc.wantErr)
+		}
+		var got []string
+		for _, f := range mock.FindingMessages {
+			got = append(got, f.OSV)
+		}
+		if diff := cmp.Diff(tc.wantOSVs, got); diff != "" {
+			t.Errorf("passed on findings (-want,+got):%s", diff)
+		}
+	}
+}
+
+func TestPolicyInput(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	e := &osv.Entry{
+		ID:        "GO-2024-0001",
+		Published: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+		Severity:  []osv.Severity{{Type: osv.SeverityTypeUbuntu, Score: "low"}},
+		Affected: []osv.Affected{{
+			Module:   osv.Module{Path: "example.com/v"},
+			Severity: []osv.Severity{{Type: osv.SeverityTypeCVSSV3, Score: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:H"}},
+		}},
+	}
+	days := 10
+	f := &govulncheck.Finding{
+		OSV:          "GO-2024-0001",
+		FixedVersion: "v1.2.3",
+		Disclosure:   &govulncheck.Disclosure{Published: e.Published, FixAvailableDays: &days},
+		Trace: []*govulncheck.Frame{
+			{Module: "example.com/v", Package: "example.com/v", Function: "V"},
+			{Module: "example.com/w", Package: "example.com/w", Function: "W1"},
+			{Module: "example.com/w", Package: "example.com/w", Function: "W2"},
+			{Module: "example.com/main", Package: "example.com/main", Function: "main"},
+		},
+	}
+	want := &policy.Input{
+		ID:               "GO-2024-0001",
+		Module:           "example.com/v",
+		FixedVersion:     "v1.2.3",
+		Severity:         "HIGH",
+		Reachable:        true,
+		FixAvailable:     true,
+		ModuleDepth:      2,
+		AgeDays:          31,
+		FixAvailableDays: 10,
+	}
+	if diff := cmp.Diff(want, policyInput(f, e, now)); diff != "" {
+		t.Errorf("policyInput (-want,+got):%s", diff)
+	}
+}
@@ -38,7 +38,16 @@ func TestPrinting(t *testing.T) {
 				wantText, _ := fs.ReadFile(testdata, textfile)
 				got := &bytes.Buffer{}
 				handler := scan.NewTextHandler(got)
-				scan.ShowFlag(strings.Split(textname, "_")[1:]).Update(handler)
+				flags := strings.Split(textname, "_")[1:]
+				scan.ShowFlag(flags).Update(handler)
+				for _, f := range flags {
+					if lang, ok := strings.CutPrefix(f, "lang-"); ok {
+						scan.LangFlag(lang).Update(handler)
+					}
+					if by, ok := strings.CutPrefix(f, "group-by-"); ok {
+						scan.GroupByFlag(by).Update(handler)
+					}
+				}
 				testRunHandler(t, rawJSON, handler)
 				if diff := cmp.Diff(string(wantText), got.String()); diff != "" {
 					if *update {
@@ -0,0 +1,46 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStartProfiling(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &config{
+		cpuProfile: filepath.Join(dir, "cpu.out"),
+		memProfile: filepath.Join(dir, "mem.out"),
+		trace:      filepath.Join(dir, "trace.out"),
+	}
+	stop, err := startProfiling(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := stop(); err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range []string{cfg.cpuProfile, cfg.memProfile, cfg.trace} {
+		fi, err := os.Stat(file)
+		if err != nil {
+			t.Error(err)
+		} else if fi.Size() == 0 {
+			t.Errorf("%s is empty", file)
+		}
+	}
+
+	// A failure to start the trace stops the CPU profile.
+	cfg.trace = filepath.Join(dir, "missing", "trace.out")
+	if _, err := startProfiling(cfg); err == nil {
+		t.Fatal("startProfiling succeeded, want error")
+	}
+	stop, err = startProfiling(&config{cpuProfile: cfg.cpuProfile})
+	if err != nil {
+		t.Fatalf("CPU profile not stopped after error: %v", err)
+	}
+	stop()
+}
@@ -8,25 +8,75 @@ import (
 	"context"
 	"fmt"
 	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
+	"golang.org/x/vuln/internal"
 	"golang.org/x/vuln/internal/client"
 	"golang.org/x/vuln/internal/govulncheck"
+	"golang.org/x/vuln/internal/osv"
 	isem "golang.org/x/vuln/internal/semver"
+	"golang.org/x/vuln/internal/vulncheck"
 )
 
+// defaultDBMaxAge is the default of the -db-max-age flag, in days.
+const defaultDBMaxAge = 30
+
+// checkDBAge returns an error if the database of cfg was last modified
+// more than cfg.dbMaxAge days before now, or if it is not known when it
+// was last modified and a fresh database is required.
+func checkDBAge(cfg *config, now time.Time) error {
+	mod := cfg.DBLastModified
+	if mod == nil {
+		if cfg.requireFresh {
+			return fmt.Errorf("cannot determine when the vulnerability database was last modified")
+		}
+		return nil
+	}
+	days := int(now.Sub(*mod).Hours() / 24)
+	if days <= cfg.dbMaxAge {
+		return nil
+	}
+	return fmt.Errorf("the vulnerability database was last modified on %s, %d days ago, which is more than the %d days allowed by -db-max-age",
+		mod.Format(time.DateOnly), days, cfg.dbMaxAge)
+}
+
 // runQuery reports vulnerabilities that apply to the queries in the config.
+// For queries of several versions of a module, there is also a module
+// level finding for each version that a vulnerability affects.
 func runQuery(ctx context.Context, handler govulncheck.Handler, cfg *config, c *client.Client) error {
-	reqs := make([]*client.ModuleRequest, len(cfg.patterns))
-	for i, query := range cfg.patterns {
-		mod, ver, err := parseModuleQuery(query)
+	var reqs []*client.ModuleRequest
+	// matrix records which requests are part
+	// of a query of several versions.
+	var matrix []bool
+	var commits []*commitResolver
+	for _, query := range cfg.patterns {
+		mod, vers, err := queryVersions(ctx, cfg, query)
 		if err != nil {
 			return err
 		}
-		if err := handler.Progress(queryProgressMessage(mod, ver)); err != nil {
-			return err
-		}
-		reqs[i] = &client.ModuleRequest{
-			Path: mod, Version: ver,
+		cr := newCommitResolver(ctx, cfg, mod)
+		commits = append(commits, cr)
+		for _, ver := range vers {
+			if err := handler.Progress(queryProgressMessage(mod, ver)); err != nil {
+				return err
+			}
+			reqs = append(reqs, &client.ModuleRequest{
+				Path: mod, Version: ver,
+				// Lets GIT ranges match when querying the
+				// pseudo-version of a commit they mention,
+				// or else when their commits resolve to
+				// versions of the module.
+				Resolver: isem.ResolverFunc(func(t osv.RangeType, v string) (string, bool) {
+					if rv, ok := isem.PseudoVersionResolver(ver).Resolve(t, v); ok {
+						return rv, true
+					}
+					return cr.Resolve(t, v)
+				}),
+			})
+			matrix = append(matrix, len(vers) > 1)
 		}
 	}
 
@@ -35,8 +85,16 @@ func runQuery(ctx context.Context, handler govulncheck.Handler, cfg *config, c *
 		return err
 	}
 
+	for _, cr := range commits {
+		if d := cr.diagnostic(); d != nil {
+			if err := handler.Diagnostic(d); err != nil {
+				return err
+			}
+		}
+	}
+
 	ids := make(map[string]bool)
-	for _, resp := range resps {
+	for i, resp := range resps {
 		for _, entry := range resp.Entries {
 			if _, ok := ids[entry.ID]; !ok {
 				err := handler.OSV(entry)
@@ -45,12 +103,108 @@ func runQuery(ctx context.Context, handler govulncheck.Handler, cfg *config, c *
 				}
 				ids[entry.ID] = true
 			}
+			if !matrix[i] {
+				continue
+			}
+			if err := handler.Finding(&govulncheck.Finding{
+				OSV:          entry.ID,
+				FixedVersion: vulncheck.FixedVersion(resp.Path, resp.Version, entry.Affected),
+				Trace:        []*govulncheck.Frame{{Module: resp.Path, Version: resp.Version}},
+			}); err != nil {
+				return err
+			}
 		}
 	}
 
 	return nil
 }
 
+// commitResolver resolves the commits of the GIT ranges of a module
+// to versions of the module with the go command, which looks them up
+// in the repository of the module. Each commit is looked up once for
+// all the versions queried.
+type commitResolver struct {
+	// ctx is that of the query, as Resolve has no context.
+	ctx context.Context
+	cfg *config
+	mod string
+
+	mu       sync.Mutex
+	versions map[string]string // by commit; "" if unresolved
+}
+
+func newCommitResolver(ctx context.Context, cfg *config, mod string) *commitResolver {
+	return &commitResolver{ctx: ctx, cfg: cfg, mod: mod, versions: make(map[string]string)}
+}
+
+// Resolve returns the version of the module at commit v of a GIT range.
+func (r *commitResolver) Resolve(t osv.RangeType, v string) (string, bool) {
+	if t != osv.RangeTypeGit || r.mod == internal.GoStdModulePath || r.mod == internal.GoCmdModulePath {
+		return "", false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ver, ok := r.versions[v]
+	if !ok {
+		out, err := goListModules(r.ctx, r.cfg, "-f", "{{.Version}}", r.mod+"@"+v)
+		if err == nil && isem.Valid(out) {
+			ver = out
+		}
+		r.versions[v] = ver
+	}
+	return ver, ver != ""
+}
+
+// diagnostic returns a diagnostic for the commits that could not be
+// resolved, if any. The ranges with them cannot be evaluated, so
+// without it a vulnerability could be missed silently.
+func (r *commitResolver) diagnostic() *govulncheck.Diagnostic {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var unresolved []string
+	for c, v := range r.versions {
+		if v == "" {
+			unresolved = append(unresolved, c)
+		}
+	}
+	if len(unresolved) == 0 {
+		return nil
+	}
+	sort.Strings(unresolved)
+	return &govulncheck.Diagnostic{
+		Kind:   govulncheck.DiagnosticUnsupportedRange,
+		Module: r.mod,
+		Message: fmt.Sprintf("GIT range commits %s cannot be resolved to versions of %s, so the ranges with them cannot be evaluated",
+			strings.Join(unresolved, ", "), r.mod),
+	}
+}
+
+// queryVersions returns the module and the versions of query. The
+// versions in a range are those of the module that the go command
+// knows of.
+func queryVersions(ctx context.Context, cfg *config, query string) (string, []string, error) {
+	mod, vers, cs, err := parseVersionQuery(query)
+	if err != nil || cs == nil {
+		return mod, vers, err
+	}
+	if mod == internal.GoStdModulePath || mod == internal.GoCmdModulePath {
+		return "", nil, fmt.Errorf("invalid query %s: version ranges are not supported for %s", query, mod)
+	}
+	out, err := goListModules(ctx, cfg, "-versions", "-f", "{{range .Versions}}{{.}} {{end}}", mod)
+	if err != nil {
+		return "", nil, fmt.Errorf("listing the versions of %s: %w", mod, err)
+	}
+	for _, v := range strings.Fields(out) {
+		if cs.match(v) {
+			vers = append(vers, v)
+		}
+	}
+	if len(vers) == 0 {
+		return "", nil, fmt.Errorf("no versions of %s match %s", mod, cs)
+	}
+	return mod, vers, nil
+}
+
 func queryProgressMessage(module, version string) *govulncheck.Progress {
 	return &govulncheck.Progress{
 		Message: fmt.Sprintf("Looking up vulnerabilities in %s at %s...", module, version),
@@ -72,3 +226,88 @@ func parseModuleQuery(pattern string) (_ string, _ string, err error) {
 
 	return mod, ver, nil
 }
+
+// parseVersionQuery parses a query of the form module@versions, where
+// versions is a version, a comma-separated list of versions, or a range
+// of versions given by space-separated constraints such as
+// ">=v1.0.0 <v1.5.0". For ranges, it returns the constraints instead of
+// the versions.
+func parseVersionQuery(pattern string) (mod string, vers []string, cs constraints, err error) {
+	matches := modQueryRegex.FindStringSubmatch(pattern)
+	if len(matches) != 3 {
+		return "", nil, nil, fmt.Errorf("invalid query %s: must be of the form module@version", pattern)
+	}
+	mod, spec := matches[1], matches[2]
+	if strings.ContainsAny(spec[:1], "<>=") {
+		for _, f := range strings.Fields(spec) {
+			c, err := parseConstraint(f)
+			if err != nil {
+				return "", nil, nil, err
+			}
+			cs = append(cs, c)
+		}
+		return mod, nil, cs, nil
+	}
+	for _, ver := range strings.Split(spec, ",") {
+		if !isem.Valid(ver) {
+			return "", nil, nil, fmt.Errorf("version %s is not valid semver", ver)
+		}
+		vers = append(vers, ver)
+	}
+	return mod, vers, nil, nil
+}
+
+// A constraint restricts versions to those that compare
+// to its version as its operator says.
+type constraint struct {
+	op      string // one of "<", "<=", ">", ">=", "="
+	version string
+}
+
+func parseConstraint(s string) (constraint, error) {
+	for _, op := range []string{"<=", ">=", "<", ">", "="} {
+		if v, ok := strings.CutPrefix(s, op); ok {
+			if !isem.Valid(v) {
+				return constraint{}, fmt.Errorf("version %s is not valid semver", v)
+			}
+			return constraint{op, v}, nil
+		}
+	}
+	return constraint{}, fmt.Errorf("invalid version constraint %s: must start with one of <, <=, >, >=, or =", s)
+}
+
+func (c constraint) match(v string) bool {
+	switch c.op {
+	case "<":
+		return isem.Less(v, c.version)
+	case "<=":
+		return !isem.Less(c.version, v)
+	case ">":
+		return isem.Less(c.version, v)
+	case ">=":
+		return !isem.Less(v, c.version)
+	default:
+		return !isem.Less(v, c.version) && !isem.Less(c.version, v)
+	}
+}
+
+// constraints is a range of versions that
+// must satisfy all of its constraints.
+type constraints []constraint
+
+func (cs constraints) match(v string) bool {
+	for _, c := range cs {
+		if !c.match(v) {
+			return false
+		}
+	}
+	return true
+}
+
+func (cs constraints) String() string {
+	var parts []string
+	for _, c := range cs {
+		parts = append(parts, c.op+c.version)
+	}
+	return strings.Join(parts, " ")
+}
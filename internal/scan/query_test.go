@@ -6,11 +6,18 @@ package scan
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"golang.org/x/vuln/internal/client"
+	"golang.org/x/vuln/internal/govulncheck"
 	"golang.org/x/vuln/internal/osv"
 	"golang.org/x/vuln/internal/test"
 )
@@ -120,6 +127,10 @@ func TestRunQuery(t *testing.T) {
 			query: []string{"stdlib@1.18", "unfixable.com@2.0.0"},
 			want:  []*osv.Entry{stdlib, e},
 		},
+		{
+			query: []string{"bad.com@1.1.0,1.2.3"},
+			want:  []*osv.Entry{e, e2},
+		},
 	} {
 		t.Run(strings.Join(tc.query, ","), func(t *testing.T) {
 			h := test.NewMockHandler()
@@ -134,6 +145,60 @@ func TestRunQuery(t *testing.T) {
 	}
 }
 
+func TestRunQueryDBChanges(t *testing.T) {
+	e := &osv.Entry{
+		ID: "GO-1999-0001",
+		Affected: []osv.Affected{{
+			Module: osv.Module{Path: "bad.com"},
+			Ranges: []osv.Range{{
+				Type:   osv.RangeTypeSemver,
+				Events: []osv.RangeEvent{{Introduced: "0"}, {Fixed: "1.2.3"}},
+			}},
+		}},
+	}
+	db, err := client.NewInMemoryDB(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := db.Client()
+	ctx := context.Background()
+	cfg := &config{patterns: []string{"bad.com@1.0.0"}}
+
+	h := test.NewMockHandler()
+	if err := runQuery(ctx, h, cfg, c); err != nil {
+		t.Fatal(err)
+	}
+	if len(h.OSVMessages) != 0 {
+		t.Errorf("runQuery on empty database: got %d entries, want 0", len(h.OSVMessages))
+	}
+
+	// A newly published entry is picked up by the next query.
+	if err := db.Add(e); err != nil {
+		t.Fatal(err)
+	}
+	h = test.NewMockHandler()
+	if err := runQuery(ctx, h, cfg, c); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(h.OSVMessages, []*osv.Entry{e}); diff != "" {
+		t.Errorf("runQuery: unexpected diff:\n%s", diff)
+	}
+
+	// Database failures are reported and no entries are emitted.
+	errDB := errors.New("database unavailable")
+	db.Fail("ID/", errDB)
+	h = test.NewMockHandler()
+	if err := runQuery(ctx, h, cfg, c); !errors.Is(err, errDB) {
+		t.Errorf("runQuery() = %v, want %v", err, errDB)
+	}
+	if len(h.OSVMessages) != 0 {
+		t.Errorf("runQuery with failing database: got %d entries, want 0", len(h.OSVMessages))
+	}
+	if got := db.Requests("ID/"); got != 2 {
+		t.Errorf("got %d entry requests, want 2", got)
+	}
+}
+
 func TestParseModuleQuery(t *testing.T) {
 	for _, tc := range []struct {
 		pattern, wantMod, wantVer string
@@ -175,3 +240,247 @@ func TestParseModuleQuery(t *testing.T) {
 		})
 	}
 }
+
+func TestRunQueryVersions(t *testing.T) {
+	e := &osv.Entry{
+		ID: "GO-1999-0001",
+		Affected: []osv.Affected{{
+			Module: osv.Module{Path: "bad.com"},
+			Ranges: []osv.Range{{
+				Type:   osv.RangeTypeSemver,
+				Events: []osv.RangeEvent{{Introduced: "1.1.0"}, {Fixed: "1.2.3"}},
+			}},
+		}},
+	}
+	c, err := client.NewInMemoryClient([]*osv.Entry{e})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := test.NewMockHandler()
+	cfg := &config{patterns: []string{"bad.com@v1.0.0,v1.1.0,v1.2.0,v1.2.3"}}
+	if err := runQuery(context.Background(), h, cfg, c); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(h.OSVMessages, []*osv.Entry{e}); diff != "" {
+		t.Errorf("runQuery: unexpected entries diff:\n%s", diff)
+	}
+	var want []*govulncheck.Finding
+	for _, v := range []string{"v1.1.0", "v1.2.0"} {
+		want = append(want, &govulncheck.Finding{
+			OSV:          e.ID,
+			FixedVersion: "v1.2.3",
+			Trace:        []*govulncheck.Frame{{Module: "bad.com", Version: v}},
+		})
+	}
+	if diff := cmp.Diff(h.FindingMessages, want); diff != "" {
+		t.Errorf("runQuery: unexpected findings diff:\n%s", diff)
+	}
+}
+
+func TestRunQueryGitRanges(t *testing.T) {
+	const (
+		commit1   = "1111111111111111111111111111111111111111"
+		commit2   = "2222222222222222222222222222222222222222"
+		unknown   = "3333333333333333333333333333333333333333"
+		pseudo    = "v0.0.0-20230101000000-444444444444"
+		pseudoRev = "4444444444444444444444444444444444444444"
+	)
+	// A module proxy that knows the versions of the first two commits.
+	proxy := t.TempDir()
+	dir := filepath.Join(proxy, "bad.com", "@v")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for commit, v := range map[string]string{commit1: "v1.1.0", commit2: "v1.2.3"} {
+		info := fmt.Sprintf(`{"Version":%q,"Time":"2023-01-01T00:00:00Z"}`, v)
+		if err := os.WriteFile(filepath.Join(dir, commit+".info"), []byte(info), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	gitRange := func(introduced, fixed string) osv.Range {
+		return osv.Range{
+			Type:   osv.RangeTypeGit,
+			Repo:   "https://bad.com",
+			Events: []osv.RangeEvent{{Introduced: introduced}, {Fixed: fixed}},
+		}
+	}
+	old := osv.Range{
+		Type:   osv.RangeTypeSemver,
+		Events: []osv.RangeEvent{{Introduced: "0.1.0"}, {Fixed: "0.5.0"}},
+	}
+	env := append(os.Environ(),
+		"GOPROXY=file://"+filepath.ToSlash(proxy),
+		"GOSUMDB=off",
+		"GOFLAGS=",
+		"GOTOOLCHAIN=local",
+		"GOMODCACHE="+t.TempDir())
+	for _, tc := range []struct {
+		name     string
+		git      osv.Range
+		want     []string // versions of the findings
+		wantDiag string
+	}{
+		{
+			name: "resolved",
+			git:  gitRange(commit1, commit2),
+			want: []string{"v1.2.0"},
+		},
+		{
+			// The commit is resolved only when querying its
+			// pseudo-version, as the proxy does not know it.
+			name:     "pseudo-version",
+			git:      gitRange(pseudoRev, commit2),
+			want:     []string{pseudo},
+			wantDiag: "GIT range commits " + pseudoRev + " cannot be resolved to versions of bad.com, so the ranges with them cannot be evaluated",
+		},
+		{
+			name:     "unresolved",
+			git:      gitRange(commit1, unknown),
+			wantDiag: "GIT range commits " + unknown + " cannot be resolved to versions of bad.com, so the ranges with them cannot be evaluated",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			e := &osv.Entry{
+				ID: "GO-1999-0001",
+				Affected: []osv.Affected{{
+					Module: osv.Module{Path: "bad.com"},
+					Ranges: []osv.Range{old, tc.git},
+				}},
+			}
+			c, err := client.NewInMemoryClient([]*osv.Entry{e})
+			if err != nil {
+				t.Fatal(err)
+			}
+			h := test.NewMockHandler()
+			cfg := &config{
+				patterns: []string{"bad.com@v1.0.0," + pseudo + ",v1.2.0,v1.3.0"},
+				dir:      t.TempDir(),
+				env:      env,
+			}
+			if err := runQuery(context.Background(), h, cfg, c); err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, f := range h.FindingMessages {
+				got = append(got, f.Trace[0].Version)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("runQuery: unexpected finding versions diff (-want +got):\n%s", diff)
+			}
+			var wantDiags []*govulncheck.Diagnostic
+			if tc.wantDiag != "" {
+				wantDiags = append(wantDiags, &govulncheck.Diagnostic{
+					Kind:    govulncheck.DiagnosticUnsupportedRange,
+					Module:  "bad.com",
+					Message: tc.wantDiag,
+				})
+			}
+			if diff := cmp.Diff(wantDiags, h.DiagnosticMessages); diff != "" {
+				t.Errorf("runQuery: unexpected diagnostics diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestParseVersionQuery(t *testing.T) {
+	for _, tc := range []struct {
+		pattern  string
+		wantMod  string
+		wantVers []string
+		wantCons string
+		wantErr  string
+	}{
+		{
+			pattern:  "golang.org/x/text@v0.3.0",
+			wantMod:  "golang.org/x/text",
+			wantVers: []string{"v0.3.0"},
+		},
+		{
+			pattern:  "golang.org/x/text@v0.3.0,v0.3.7",
+			wantMod:  "golang.org/x/text",
+			wantVers: []string{"v0.3.0", "v0.3.7"},
+		},
+		{
+			pattern:  "golang.org/x/text@>=v0.3.0 <v0.4.0",
+			wantMod:  "golang.org/x/text",
+			wantCons: ">=v0.3.0 <v0.4.0",
+		},
+		{
+			pattern: "golang.org/x/text@v0.3.0,bad",
+			wantErr: "version bad is not valid semver",
+		},
+		{
+			pattern: "golang.org/x/text@>=v0.3.0 v0.4.0",
+			wantErr: "invalid version constraint v0.4.0",
+		},
+		{
+			pattern: "golang.org/x/text@<bad",
+			wantErr: "version bad is not valid semver",
+		},
+	} {
+		t.Run(tc.pattern, func(t *testing.T) {
+			gotMod, gotVers, gotCons, err := parseVersionQuery(tc.pattern)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Errorf("parseVersionQuery = %v, want err containing %s", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if gotMod != tc.wantMod || !slices.Equal(gotVers, tc.wantVers) || gotCons.String() != tc.wantCons {
+				t.Errorf("parseVersionQuery = (%s, %v, %q), want (%s, %v, %q)", gotMod, gotVers, gotCons, tc.wantMod, tc.wantVers, tc.wantCons)
+			}
+		})
+	}
+}
+
+func TestConstraintsMatch(t *testing.T) {
+	_, _, cs, err := parseVersionQuery("m@>v1.0.0 <=v1.2.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for v, want := range map[string]bool{
+		"v0.9.0":     false,
+		"v1.0.0":     false,
+		"v1.1.0":     true,
+		"v1.2.0-pre": true,
+		"v1.2.0":     true,
+		"v1.2.1":     false,
+		"v2.0.0":     false,
+	} {
+		if got := cs.match(v); got != want {
+			t.Errorf("match(%s) = %t, want %t", v, got, want)
+		}
+	}
+}
+
+func TestCheckDBAge(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	mod := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	for _, test := range []struct {
+		name         string
+		modified     *time.Time
+		maxAge       int
+		requireFresh bool
+		want         string
+	}{
+		{"fresh", &mod, 31, false, ""},
+		{"stale", &mod, 30, false, "the vulnerability database was last modified on 2024-05-01, 31 days ago, which is more than the 30 days allowed by -db-max-age"},
+		{"unknown", nil, 30, false, ""},
+		{"unknown required", nil, 30, true, "cannot determine when the vulnerability database was last modified"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := &config{dbMaxAge: test.maxAge, requireFresh: test.requireFresh}
+			cfg.DBLastModified = test.modified
+			var got string
+			if err := checkDBAge(cfg, now); err != nil {
+				got = err.Error()
+			}
+			if got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
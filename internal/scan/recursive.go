@@ -0,0 +1,120 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/vuln/internal/client"
+	"golang.org/x/vuln/internal/govulncheck"
+)
+
+// runRecursive scans each module in the directory tree rooted at dir
+// in turn, reporting the findings of each as being for its directory,
+// and flushes handler. A module that fails to scan does not stop the
+// others from being scanned; the failures are returned after flushing.
+func runRecursive(ctx context.Context, handler govulncheck.Handler, cfg *config, client *client.Client, dir string) error {
+	mods, err := moduleDirs(dir)
+	if err != nil {
+		return err
+	}
+	if len(mods) == 0 {
+		return errNoGoMod
+	}
+	ah := newArtifactHandler(handler)
+	var failed []string
+	for _, m := range mods {
+		mcfg := *cfg
+		if len(mcfg.patterns) == 0 && mcfg.ScanLevel.WantPackages() {
+			mcfg.patterns = []string{"./..."}
+		}
+		mdir := filepath.Join(dir, filepath.FromSlash(m))
+		// Modules can require different Go versions, and so
+		// be built with different toolchains and standard libraries.
+		if v := moduleGoVersion(&mcfg, mdir); v != "" {
+			mcfg.GoVersion = v
+		}
+		ah.name = m
+		p := &govulncheck.Progress{Message: fmt.Sprintf(moduleProgressMessage, m)}
+		if err := handler.Progress(p); err != nil {
+			return err
+		}
+		if err := runSource(ctx, ah, &mcfg, client, mdir); err != nil {
+			if ctx.Err() != nil {
+				return err
+			}
+			failed = append(failed, fmt.Sprintf("%s: %v", m, err))
+			p := &govulncheck.Progress{Message: fmt.Sprintf("Warning: failed to scan the module in %s: %v", m, err)}
+			if err := handler.Progress(p); err != nil {
+				return err
+			}
+		}
+	}
+	flushErr := Flush(handler)
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to scan %d of %d modules:\n\t%s", len(failed), len(mods), strings.Join(failed, "\n\t"))
+	}
+	return flushErr
+}
+
+// moduleDirs returns the sorted, slash-separated directories relative
+// to dir of the modules in the directory tree rooted at dir. Like the go
+// command, it skips vendor and testdata directories and directories
+// whose names begin with "." or "_".
+func moduleDirs(dir string) ([]string, error) {
+	var mods []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		name := d.Name()
+		if path != dir && (strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") || name == "testdata" || name == "vendor") {
+			return filepath.SkipDir
+		}
+		if _, err := os.Stat(filepath.Join(path, "go.mod")); err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		mods = append(mods, filepath.ToSlash(rel))
+		return nil
+	})
+	sort.Strings(mods)
+	return mods, err
+}
+
+// moduleGoVersion returns the version of the Go toolchain that the go
+// command selects for the module in dir, or "" if it is unknown or
+// was set in the environment of cfg.
+func moduleGoVersion(cfg *config, dir string) string {
+	for _, env := range cfg.env {
+		if strings.HasPrefix(env, "GOVERSION=") {
+			return ""
+		}
+	}
+	cmd := exec.Command("go", "env", "GOVERSION")
+	cmd.Dir = dir
+	cmd.Env = cfg.env
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
@@ -0,0 +1,44 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestModuleDirs(t *testing.T) {
+	dir := t.TempDir()
+	for _, f := range []string{
+		"go.mod",
+		"a/go.mod",
+		"a/b/go.mod",
+		"c/main.go",
+		"c/d/go.mod",
+		"testdata/e/go.mod",
+		"vendor/f/go.mod",
+		".git/g/go.mod",
+		"_h/go.mod",
+	} {
+		path := filepath.Join(dir, filepath.FromSlash(f))
+		if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, err := moduleDirs(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{".", "a", "a/b", "c/d"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("(-want,+got):%s", diff)
+	}
+}
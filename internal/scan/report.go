@@ -0,0 +1,289 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	htmltemplate "html/template"
+	"io"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"golang.org/x/vuln/internal/govulncheck"
+	"golang.org/x/vuln/internal/osv"
+)
+
+// reportHandler writes the results of a scan as a Markdown or HTML
+// document, for people to read once the scan is over.
+type reportHandler struct {
+	w        io.Writer
+	html     bool
+	cfg      *govulncheck.Config
+	osvs     []*osv.Entry
+	findings []*findingSummary
+	diags    []*govulncheck.Diagnostic
+}
+
+// newMarkdownHandler returns a handler that writes a Markdown report to w.
+func newMarkdownHandler(w io.Writer) *reportHandler {
+	return &reportHandler{w: w}
+}
+
+// newHTMLHandler returns a handler that writes an HTML report to w.
+func newHTMLHandler(w io.Writer) *reportHandler {
+	return &reportHandler{w: w, html: true}
+}
+
+func (h *reportHandler) Config(cfg *govulncheck.Config) error {
+	h.cfg = cfg
+	return nil
+}
+
+func (h *reportHandler) Progress(*govulncheck.Progress) error {
+	return nil
+}
+
+func (h *reportHandler) SBOM(*govulncheck.SBOM) error {
+	return nil
+}
+
+func (h *reportHandler) Diagnostic(d *govulncheck.Diagnostic) error {
+	h.diags = append(h.diags, d)
+	return nil
+}
+
+func (h *reportHandler) OSV(e *osv.Entry) error {
+	h.osvs = append(h.osvs, e)
+	return nil
+}
+
+func (h *reportHandler) Finding(f *govulncheck.Finding) error {
+	if err := validateFindings(f); err != nil {
+		return err
+	}
+	h.findings = append(h.findings, newFindingSummary(f))
+	return nil
+}
+
+// Flush writes the report.
+func (h *reportHandler) Flush() error {
+	cfg := h.cfg
+	if cfg == nil {
+		cfg = &govulncheck.Config{}
+	}
+	r := newReport(cfg, h.osvs, h.findings)
+	r.Diagnostics = h.diags
+	if h.html {
+		return htmlReportTemplate.Execute(h.w, r)
+	}
+	return markdownReportTemplate.Execute(h.w, r)
+}
+
+// A report is the data of Markdown and HTML reports.
+type report struct {
+	Config   *govulncheck.Config
+	Sections []reportSection
+	// Diagnostics are the warnings of the scan, which come
+	// after the results since they may qualify them.
+	Diagnostics []*govulncheck.Diagnostic
+}
+
+// A reportSection lists the vulnerabilities found at a scan level.
+type reportSection struct {
+	Title       string
+	Description string
+	Vulns       []reportVuln
+}
+
+type reportVuln struct {
+	ID      string
+	URL     string
+	Summary string
+	Aliases []string
+	Modules []reportModule
+}
+
+type reportModule struct {
+	Path      string
+	Found     string
+	Fixed     string
+	Precision govulncheck.Precision
+	Owners    []string
+	// Traces are example traces, in the compact form of text output.
+	Traces []string
+}
+
+// newReport returns the report of findings, whose vulnerabilities are
+// osvs, for a scan with cfg. There is a section for each level of the
+// scan, starting with the most precise one.
+func newReport(cfg *govulncheck.Config, osvs []*osv.Entry, findings []*findingSummary) *report {
+	fixupFindings(osvs, findings)
+	called, imported, required, _ := groupVulns(findings)
+	r := &report{Config: cfg}
+	level := cfg.ScanLevel
+	if level == "" {
+		level = govulncheck.ScanLevelSymbol
+	}
+	if level.WantSymbols() {
+		r.Sections = append(r.Sections, reportSection{
+			Title:       "Symbol results",
+			Description: "Your code calls vulnerable symbols of these vulnerabilities.",
+			Vulns:       reportVulns(cfg, called),
+		})
+	}
+	if level.WantPackages() {
+		r.Sections = append(r.Sections, reportSection{
+			Title: "Package results",
+			Description: "Your code imports vulnerable packages of these vulnerabilities" +
+				choose(level.WantSymbols(), ", but doesn't appear to call the vulnerable symbols.", "."),
+			Vulns: reportVulns(cfg, imported),
+		})
+	}
+	r.Sections = append(r.Sections, reportSection{
+		Title: "Module results",
+		Description: "Your code requires vulnerable modules of these vulnerabilities" +
+			choose(level.WantPackages(), ", but doesn't appear to import the vulnerable packages.", "."),
+		Vulns: reportVulns(cfg, required),
+	})
+	return r
+}
+
+// reportVulns returns the report of the findings of each vulnerability
+// in byVuln.
+func reportVulns(cfg *govulncheck.Config, byVuln [][]*findingSummary) []reportVuln {
+	var vulns []reportVuln
+	for _, findings := range byVuln {
+		e := findings[0].OSV
+		v := reportVuln{ID: e.ID, Summary: e.Summary, Aliases: e.Aliases}
+		if v.Summary == "" {
+			v.Summary = e.Details
+		}
+		if e.DatabaseSpecific != nil {
+			v.URL = e.DatabaseSpecific.URL
+		}
+		for _, module := range groupByModule(findings) {
+			frame := module[0].Trace[0]
+			m := reportModule{
+				Path:   frame.Module,
+				Found:  moduleVersionString(frame.Module, frame.Version),
+				Fixed:  moduleVersionString(frame.Module, module[0].FixedVersion),
+				Owners: owners(module),
+			}
+			var fs []*govulncheck.Finding
+			for _, f := range module {
+				fs = append(fs, f.Finding)
+			}
+			m.Precision = govulncheck.MostPrecise(fs, cfg.ScanMode)
+			var compacts []*findingSummary
+			for _, f := range module {
+				if f.Compact != "" {
+					compacts = append(compacts, f)
+				}
+			}
+			compacts, _ = foldTraces(compacts)
+			for _, f := range compacts {
+				m.Traces = append(m.Traces, f.Compact)
+			}
+			sort.Strings(m.Traces)
+			v.Modules = append(v.Modules, m)
+		}
+		vulns = append(vulns, v)
+	}
+	sort.Slice(vulns, func(i, j int) bool { return vulns[i].ID < vulns[j].ID })
+	return vulns
+}
+
+var reportFuncs = map[string]any{
+	"join": strings.Join,
+	"date": func(t *time.Time) string { return t.Format(time.DateOnly) },
+	// cell escapes the characters of s that would end a table cell.
+	"cell": func(s string) string { return strings.ReplaceAll(s, "|", `\|`) },
+	// subject is what a diagnostic is about.
+	"subject": diagnosticSubject,
+}
+
+var markdownReportTemplate = template.Must(template.New("markdown").Funcs(reportFuncs).Parse(`# Govulncheck report
+{{with .Config}}
+{{if .ScannerName}}- Scanner: {{.ScannerName}}{{with .ScannerVersion}}@{{.}}{{end}}
+{{end}}{{if .DB}}- Database: {{.DB}}{{with .DBLastModified}}, updated {{date .}}{{end}}
+{{end}}{{if .GoVersion}}- Go: {{.GoVersion}}
+{{end}}{{if .ScanMode}}- Scan: {{.ScanMode}} mode{{with .ScanLevel}}, {{.}} level{{end}}
+{{end}}{{end}}{{range .Sections}}
+## {{.Title}}
+
+{{.Description}}
+{{if not .Vulns}}
+No vulnerabilities found.
+{{end}}{{range .Vulns}}
+### {{if .URL}}[{{.ID}}]({{.URL}}){{else}}{{.ID}}{{end}}
+
+{{.Summary}}
+{{if .Aliases}}
+Aliases: {{join .Aliases ", "}}
+{{end}}
+| Module | Found in | Fixed in | Precision |
+| --- | --- | --- | --- |
+{{range .Modules}}| {{cell .Path}} | {{cell .Found}} | {{or (cell .Fixed) "N/A"}} | {{.Precision}} |
+{{end}}{{range .Modules}}{{if .Owners}}
+Owners of the code using {{.Path}}: {{join .Owners ", "}}
+{{end}}{{if .Traces}}
+Example traces in {{.Path}}:
+{{range .Traces}}
+- ` + "`{{.}}`" + `{{end}}
+{{end}}{{end}}{{end}}{{end}}{{if .Diagnostics}}
+## Diagnostics
+
+The scan reported these warnings, which may affect the results above.
+
+| Kind | Subject | Message |
+| --- | --- | --- |
+{{range .Diagnostics}}| {{.Kind}} | {{cell (subject .)}} | {{cell .Message}} |
+{{end}}{{end}}`))
+
+var htmlReportTemplate = htmltemplate.Must(htmltemplate.New("html").Funcs(reportFuncs).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Govulncheck report</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 0.2em 0.6em; text-align: left; }
+code { font-size: 0.9em; }
+</style>
+</head>
+<body>
+<h1>Govulncheck report</h1>
+{{with .Config}}<ul>
+{{if .ScannerName}}<li>Scanner: {{.ScannerName}}{{with .ScannerVersion}}@{{.}}{{end}}</li>
+{{end}}{{if .DB}}<li>Database: {{.DB}}{{with .DBLastModified}}, updated {{date .}}{{end}}</li>
+{{end}}{{if .GoVersion}}<li>Go: {{.GoVersion}}</li>
+{{end}}{{if .ScanMode}}<li>Scan: {{.ScanMode}} mode{{with .ScanLevel}}, {{.}} level{{end}}</li>
+{{end}}</ul>
+{{end}}{{range .Sections}}<h2>{{.Title}}</h2>
+<p>{{.Description}}</p>
+{{if not .Vulns}}<p>No vulnerabilities found.</p>
+{{end}}{{range .Vulns}}<h3 id="{{.ID}}">{{if .URL}}<a href="{{.URL}}">{{.ID}}</a>{{else}}{{.ID}}{{end}}</h3>
+<p>{{.Summary}}</p>
+{{if .Aliases}}<p>Aliases: {{join .Aliases ", "}}</p>
+{{end}}<table>
+<tr><th>Module</th><th>Found in</th><th>Fixed in</th><th>Precision</th></tr>
+{{range .Modules}}<tr><td>{{.Path}}</td><td>{{.Found}}</td><td>{{or .Fixed "N/A"}}</td><td>{{.Precision}}</td></tr>
+{{end}}</table>
+{{range .Modules}}{{if .Owners}}<p>Owners of the code using {{.Path}}: {{join .Owners ", "}}</p>
+{{end}}{{if .Traces}}<p>Example traces in {{.Path}}:</p>
+<ul>
+{{range .Traces}}<li><code>{{.}}</code></li>
+{{end}}</ul>
+{{end}}{{end}}{{end}}{{end}}{{if .Diagnostics}}<h2>Diagnostics</h2>
+<p>The scan reported these warnings, which may affect the results above.</p>
+<table>
+<tr><th>Kind</th><th>Subject</th><th>Message</th></tr>
+{{range .Diagnostics}}<tr><td>{{.Kind}}</td><td>{{subject .}}</td><td>{{.Message}}</td></tr>
+{{end}}</table>
+{{end}}</body>
+</html>
+`))
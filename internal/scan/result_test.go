@@ -59,6 +59,22 @@ func TestFrame(t *testing.T) {
 			short:    true,
 			wantFunc: "http.Get",
 		},
+		{
+			name:     "generic function",
+			frame:    &govulncheck.Frame{Package: "example.com/p", Function: "Map", TypeArgs: []string{"int", "string"}},
+			wantFunc: "example.com/p.Map[int, string]",
+		},
+		{
+			name:     "generic receiver",
+			frame:    &govulncheck.Frame{Package: "example.com/p", Receiver: "*List", Function: "Push", TypeArgs: []string{"int"}},
+			wantFunc: "example.com/p.List[int].Push",
+		},
+		{
+			name:     "generic short",
+			frame:    &govulncheck.Frame{Package: "example.com/p", Function: "Map", TypeArgs: []string{"int", "string"}},
+			short:    true,
+			wantFunc: "p.Map",
+		},
 	} {
 		t.Run(test.name, func(t *testing.T) {
 			buf := &strings.Builder{}
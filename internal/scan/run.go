@@ -6,35 +6,80 @@ package scan
 
 import (
 	"context"
+	"crypto"
 	"fmt"
 	"io"
+	"os"
 	"os/exec"
 	"path"
 	"path/filepath"
 	"runtime/debug"
 	"strings"
+	"sync"
 	"time"
 
 	"golang.org/x/telemetry/counter"
+	"golang.org/x/vuln/internal"
+	"golang.org/x/vuln/internal/attest"
 	"golang.org/x/vuln/internal/client"
 	"golang.org/x/vuln/internal/govulncheck"
 	"golang.org/x/vuln/internal/openvex"
 	"golang.org/x/vuln/internal/sarif"
+	"golang.org/x/vuln/internal/timing"
 )
 
 // RunGovulncheck performs main govulncheck functionality and exits the
 // program upon success with an appropriate exit status. Otherwise,
 // returns an error.
-func RunGovulncheck(ctx context.Context, env []string, r io.Reader, stdout io.Writer, stderr io.Writer, args []string) error {
+func RunGovulncheck(ctx context.Context, env []string, r io.Reader, stdout io.Writer, stderr io.Writer, args []string) (err error) {
 	cfg := &config{env: env}
 	if err := parseFlags(cfg, stderr, args); err != nil {
 		return err
 	}
 
-	client, err := client.NewClient(cfg.db, nil)
+	stop, err := startProfiling(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if serr := stop(); err == nil {
+			err = serr
+		}
+	}()
+	if cfg.debug {
+		rec := &timing.Recorder{}
+		ctx = timing.NewContext(ctx, rec)
+		start := time.Now()
+		defer func() {
+			fmt.Fprintf(stderr, "govulncheck: %v (total %v)\n", rec, time.Since(start).Round(time.Millisecond))
+		}()
+	}
+
+	if cfg.ScanMode == govulncheck.ScanModeHistory {
+		store, err := historyStore(cfg)
+		if err != nil {
+			return err
+		}
+		return runHistory(cfg, store, stdout)
+	}
+
+	warnings := &dbWarnings{}
+	opts, err := clientOptions(cfg, warnings)
+	if err != nil {
+		return err
+	}
+	sources, err := client.ParseSources(cfg.db, lookupEnv(cfg.env))
+	if err != nil {
+		return err
+	}
+	// From now on, the databases are only reported, and their
+	// options, which may hold credentials, are left out.
+	cfg.db = client.SourceURLs(sources)
+	client, err := client.NewMultiClient(sources, opts)
 	if err != nil {
 		return fmt.Errorf("creating client: %w", err)
 	}
+	defer client.Close()
 
 	prepareConfig(ctx, cfg, client)
 	var handler govulncheck.Handler
@@ -45,21 +90,59 @@ func RunGovulncheck(ctx context.Context, env []string, r io.Reader, stdout io.Wr
 		handler = sarif.NewHandler(stdout)
 	case formatOpenVEX:
 		handler = openvex.NewHandler(stdout)
+	case formatMarkdown:
+		handler = newMarkdownHandler(stdout)
+	case formatHTML:
+		handler = newHTMLHandler(stdout)
 	default:
 		th := NewTextHandler(stdout)
 		cfg.show.Update(th)
+		if !cfg.noAdvice {
+			th.showAdvice = true
+		}
+		lang := cfg.lang
+		if lang == "" {
+			lang = envLanguage(cfg.env)
+		}
+		lang.Update(th)
+		cfg.groupBy.Update(th)
+		if useColor(cfg.env, stdout) {
+			th.showColor = true
+		}
 		handler = th
 	}
+	mws, err := middleware(ctx, cfg, stderr)
+	if err != nil {
+		return err
+	}
+	handler = govulncheck.Chain(handler, mws...)
 
 	if err := handler.Config(&cfg.Config); err != nil {
 		return err
 	}
+	if cfg.ScanMode == govulncheck.ScanModeQuery {
+		if err := checkDBAge(cfg, time.Now()); err != nil {
+			if cfg.requireFresh {
+				return err
+			}
+			warnings.add(&govulncheck.Diagnostic{Kind: govulncheck.DiagnosticStaleDB, Message: err.Error()})
+		}
+	}
+	if err := warnings.flush(handler); err != nil {
+		return err
+	}
 
 	incTelemetryFlagCounters(cfg)
 
 	switch cfg.ScanMode {
 	case govulncheck.ScanModeSource:
 		dir := filepath.FromSlash(cfg.dir)
+		if cfg.watch != watchOff {
+			return runWatch(ctx, handler, cfg, client, dir, stdout)
+		}
+		if cfg.recursive {
+			return runRecursive(ctx, handler, cfg, client, dir)
+		}
 		err = runSource(ctx, handler, cfg, client, dir)
 	case govulncheck.ScanModeBinary:
 		err = runBinary(ctx, handler, cfg, client)
@@ -67,15 +150,273 @@ func RunGovulncheck(ctx context.Context, env []string, r io.Reader, stdout io.Wr
 		return runExtract(cfg, stdout)
 	case govulncheck.ScanModeQuery:
 		err = runQuery(ctx, handler, cfg, client)
+	case govulncheck.ScanModeInfo:
+		err = runInfo(ctx, handler, cfg, client)
+	case govulncheck.ScanModeAudit:
+		err = runAudit(ctx, handler, cfg, client)
+	case govulncheck.ScanModeSnapshot:
+		return runSnapshot(ctx, cfg, client, stdout)
 	case govulncheck.ScanModeConvert:
-		err = govulncheck.HandleJSON(r, handler)
+		err = runConvert(handler, cfg, r)
 	}
 	if err != nil {
 		return err
 	}
+	// Entries that failed verification are only known after the scan.
+	if err := warnings.flush(handler); err != nil {
+		return err
+	}
+	for _, chain := range client.Answers() {
+		if err := handler.Progress(&govulncheck.Progress{Message: answersMessage(chain)}); err != nil {
+			return err
+		}
+	}
+	defer timing.Start(ctx, "output")()
 	return Flush(handler)
 }
 
+// fixReleased returns the function that Disclose uses to find when
+// fixed versions were released, or nil if they are not looked up.
+// Looking them up runs 'go list' and queries the module proxy for
+// each version, so it is only done for policies, which may use
+// fix_available_days, and not in convert and query modes or when
+// GOPROXY is off.
+func fixReleased(ctx context.Context, cfg *config) func(path, version string) time.Time {
+	if cfg.policy == "" || cfg.ScanMode == govulncheck.ScanModeConvert || cfg.ScanMode == govulncheck.ScanModeQuery {
+		return nil
+	}
+	if proxy, _ := lookupEnv(cfg.env)("GOPROXY"); proxy == "off" {
+		return nil
+	}
+	return func(path, version string) time.Time {
+		if path == internal.GoStdModulePath || path == internal.GoCmdModulePath {
+			return time.Time{}
+		}
+		// Release times are only informative, so
+		// failing to find them does not fail the scan.
+		t, _ := releaseTime(ctx, cfg, path, version)
+		return t
+	}
+}
+
+// answersMessage describes which databases of a chain of fallbacks
+// answered the requests of the scan.
+func answersMessage(chain []client.Answer) string {
+	var urls, answers []string
+	for _, a := range chain {
+		urls = append(urls, a.URL)
+		answers = append(answers, fmt.Sprintf("%s answered %d requests", a.URL, a.Requests))
+	}
+	return fmt.Sprintf("Database %s: %s.", strings.Join(urls, "|"), strings.Join(answers, ", "))
+}
+
+// runConvert passes the JSON output of a previous run to handler.
+// It reads the output from the file given as argument, if any,
+// or else from r.
+func runConvert(handler govulncheck.Handler, cfg *config, r io.Reader) error {
+	if len(cfg.patterns) == 1 {
+		f, err := os.Open(cfg.patterns[0])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+	return govulncheck.HandleJSON(r, handler)
+}
+
+// middleware returns the handler middleware for the features
+// enabled in cfg, which apply regardless of the output format.
+// Uploaders print where they posted the results to stderr.
+func middleware(ctx context.Context, cfg *config, stderr io.Writer) ([]govulncheck.Middleware, error) {
+	now := time.Now()
+	var mws []govulncheck.Middleware
+	if cfg.allowLoadErr {
+		// Partial results come first, so that they are
+		// reported unless the scan fails otherwise.
+		mws = append(mws, func(h govulncheck.Handler) govulncheck.Handler {
+			return &partialHandler{Wrapper: govulncheck.Wrapper{Next: h}}
+		})
+	}
+	mws = append(mws, govulncheck.Disclose(now, fixReleased(ctx, cfg)), govulncheck.PackageURLs())
+	if cfg.policy != "" {
+		// The policy comes first, so that suppressed
+		// findings are not recorded in the history.
+		p, err := loadPolicy(cfg.policy)
+		if err != nil {
+			return nil, err
+		}
+		mws = append(mws, func(h govulncheck.Handler) govulncheck.Handler {
+			return newPolicyHandler(h, p, now)
+		})
+	}
+	if cfg.history {
+		store, err := historyStore(cfg)
+		if err != nil {
+			return nil, err
+		}
+		target, err := historyTarget(cfg)
+		if err != nil {
+			return nil, err
+		}
+		mws = append(mws, func(h govulncheck.Handler) govulncheck.Handler {
+			return newHistoryHandler(h, cfg, target, store)
+		})
+	}
+	if cfg.upgrades {
+		// Upgrades come after the policy, so that the module
+		// proxy is not queried for suppressed findings.
+		mws = append(mws, func(h govulncheck.Handler) govulncheck.Handler {
+			return newUpgradeHandler(h, func(path string) string {
+				if path == internal.GoStdModulePath || path == internal.GoCmdModulePath {
+					return ""
+				}
+				// The latest version is only a hint, so
+				// failing to find it does not fail the scan.
+				v, _ := latestVersion(ctx, cfg, path)
+				return v
+			})
+		})
+	}
+	if cfg.attest != "" {
+		// The attestation comes after the policy, so that
+		// it attests what the output of the scan reports.
+		subjects, err := attestSubjects(ctx, cfg)
+		if err != nil {
+			return nil, fmt.Errorf("attesting: %w", err)
+		}
+		var key crypto.Signer
+		if cfg.attestKey != "" {
+			data, err := os.ReadFile(cfg.attestKey)
+			if err != nil {
+				return nil, err
+			}
+			if key, err = attest.ParsePrivateKey(data); err != nil {
+				return nil, fmt.Errorf("reading %s: %w", cfg.attestKey, err)
+			}
+		}
+		mws = append(mws, func(h govulncheck.Handler) govulncheck.Handler {
+			return newAttestHandler(h, cfg.attest, subjects, key)
+		})
+	}
+	if cfg.upload != "" {
+		// Uploads come after the policy and upgrades, so that
+		// they post what the output of the scan reports.
+		u, err := newUploader(cfg)
+		if err != nil {
+			return nil, err
+		}
+		mws = append(mws, func(h govulncheck.Handler) govulncheck.Handler {
+			return newUploadHandler(ctx, h, u, stderr)
+		})
+	}
+	// Ordering comes last, so that every output format
+	// sees the same messages in the same order.
+	mws = append(mws, govulncheck.Order())
+	return mws, nil
+}
+
+// useColor reports whether text output to w should be colored
+// without being asked for with -show color: w must be a terminal,
+// and neither NO_COLOR nor TERM=dumb may be set in env.
+func useColor(env []string, w io.Writer) bool {
+	for _, e := range env {
+		if v, ok := strings.CutPrefix(e, "NO_COLOR="); ok && v != "" {
+			return false
+		}
+		if e == "TERM=dumb" {
+			return false
+		}
+	}
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// dbWarnings collects the warnings about the databases of a scan.
+// Entries are verified as they are read, possibly concurrently,
+// so warnings may be added during the scan.
+type dbWarnings struct {
+	mu    sync.Mutex
+	diags []*govulncheck.Diagnostic
+}
+
+func (w *dbWarnings) add(d *govulncheck.Diagnostic) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.diags = append(w.diags, d)
+}
+
+// flush passes the warnings added since the last flush on to h.
+func (w *dbWarnings) flush(h govulncheck.Handler) error {
+	w.mu.Lock()
+	diags := w.diags
+	w.diags = nil
+	w.mu.Unlock()
+	for _, d := range diags {
+		if err := h.Diagnostic(d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// clientOptions returns the options for the database client. When
+// verification failures are only warnings, they are added to warnings.
+func clientOptions(cfg *config, warnings *dbWarnings) (*client.Options, error) {
+	opts := &client.Options{}
+	if dir := cacheDir(cfg); dir != "" {
+		opts.CacheDir = filepath.Join(dir, "http")
+		opts.IndexCacheDir = filepath.Join(dir, "index")
+	}
+	if cfg.dbKey == "" {
+		return opts, nil
+	}
+	b, err := os.ReadFile(cfg.dbKey)
+	if err != nil {
+		return nil, err
+	}
+	key, err := client.ParsePublicKey(b)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", cfg.dbKey, err)
+	}
+	opts.VerifyKey = key
+	if cfg.dbWarn {
+		opts.VerifyWarn = func(err error) {
+			warnings.add(&govulncheck.Diagnostic{Kind: govulncheck.DiagnosticUnverifiedDB, Message: err.Error()})
+		}
+	}
+	return opts, nil
+}
+
+// cacheDir returns the directory named by GOVULNCHECK_CACHE in the
+// environment of cfg, or else a directory in the user's cache
+// directory, in which to cache the responses of databases and the
+// vulnerabilities of modules between runs. It returns "" if caching
+// is off, with GOVULNCHECK_CACHE=off.
+func cacheDir(cfg *config) string {
+	var dir string
+	for _, env := range cfg.env {
+		if v, ok := strings.CutPrefix(env, "GOVULNCHECK_CACHE="); ok {
+			dir = v
+		}
+	}
+	if dir == "off" {
+		return ""
+	}
+	if dir == "" {
+		cache, err := os.UserCacheDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(cache, "govulncheck")
+	}
+	return dir
+}
+
 func prepareConfig(ctx context.Context, cfg *config, client *client.Client) {
 	cfg.ProtocolVersion = govulncheck.ProtocolVersion
 	cfg.DB = cfg.db
@@ -91,6 +432,12 @@ func prepareConfig(ctx context.Context, cfg *config, client *client.Client) {
 				cfg.GoVersion = strings.TrimSpace(string(out))
 			}
 		}
+		cmd := exec.Command("go", "env", "GOFLAGS")
+		cmd.Dir = cfg.dir
+		cmd.Env = cfg.env
+		if out, err := cmd.Output(); err == nil {
+			cfg.GoFlags = strings.TrimSpace(string(out))
+		}
 	}
 	if bi, ok := debug.ReadBuildInfo(); ok {
 		scannerVersion(cfg, bi)
@@ -106,6 +453,7 @@ func scannerVersion(cfg *config, bi *debug.BuildInfo) {
 	if bi.Path != "" {
 		cfg.ScannerName = path.Base(bi.Path)
 	}
+	cfg.ScannerGoVersion = bi.GoVersion
 	if bi.Main.Version != "" && bi.Main.Version != "(devel)" {
 		cfg.ScannerVersion = bi.Main.Version
 		return
@@ -152,9 +500,7 @@ func incTelemetryFlagCounters(cfg *config) {
 	}
 }
 
+// Flush flushes h if it buffers its output. See govulncheck.Flush.
 func Flush(h govulncheck.Handler) error {
-	if th, ok := h.(interface{ Flush() error }); ok {
-		return th.Flush()
-	}
-	return nil
+	return govulncheck.Flush(h)
 }
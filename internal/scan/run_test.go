@@ -5,12 +5,21 @@
 package scan
 
 import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
 	"runtime/debug"
 	"testing"
+
+	"golang.org/x/vuln/internal/govulncheck"
 )
 
 func TestGovulncheckVersion(t *testing.T) {
 	bi := &debug.BuildInfo{
+		GoVersion: "go1.22.1",
 		Settings: []debug.BuildSetting{
 			{Key: "vcs.revision", Value: "1234567890001234"},
 			{Key: "vcs.time", Value: "2023-01-25T19:57:54Z"},
@@ -23,4 +32,110 @@ func TestGovulncheckVersion(t *testing.T) {
 	if got.ScannerVersion != want {
 		t.Errorf("got %s; want %s", got.ScannerVersion, want)
 	}
+	if got.ScannerGoVersion != bi.GoVersion {
+		t.Errorf("got Go version %s; want %s", got.ScannerGoVersion, bi.GoVersion)
+	}
+}
+
+func TestConfigFlags(t *testing.T) {
+	cfg := &config{}
+	if err := parseFlags(cfg, io.Discard, []string{"-show", "traces", "-scan", "package", "-test", "./..."}); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"-scan=package", "-show=traces", "-test=true"}
+	if !reflect.DeepEqual(cfg.Flags, want) {
+		t.Errorf("got flags %v; want %v", cfg.Flags, want)
+	}
+}
+
+func TestConfigFlagsDB(t *testing.T) {
+	cfg := &config{env: []string{"TOKEN=secret2"}}
+	args := []string{"-db", "https://vulndb.example.com;token=secret|https://vuln.go.dev;header=X-Key:$TOKEN", "./..."}
+	if err := parseFlags(cfg, io.Discard, args); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"-db=https://vulndb.example.com|https://vuln.go.dev"}
+	if !reflect.DeepEqual(cfg.Flags, want) {
+		t.Errorf("got flags %v; want %v", cfg.Flags, want)
+	}
+}
+
+func TestClientOptionsCache(t *testing.T) {
+	dir := t.TempDir()
+	opts, err := clientOptions(&config{env: []string{"GOVULNCHECK_CACHE=" + dir}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(dir, "http"); opts.CacheDir != want {
+		t.Errorf("got CacheDir %q, want %q", opts.CacheDir, want)
+	}
+	if want := filepath.Join(dir, "index"); opts.IndexCacheDir != want {
+		t.Errorf("got IndexCacheDir %q, want %q", opts.IndexCacheDir, want)
+	}
+
+	opts, err = clientOptions(&config{env: []string{"GOVULNCHECK_CACHE=off"}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.CacheDir != "" || opts.IndexCacheDir != "" {
+		t.Errorf("got CacheDir %q and IndexCacheDir %q with the cache off, want none", opts.CacheDir, opts.IndexCacheDir)
+	}
+}
+
+func TestFixReleased(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		cfg  *config
+		want bool
+	}{
+		{"no policy", &config{}, false},
+		{"policy", &config{policy: "policy.cel"}, true},
+		{"convert", &config{policy: "policy.cel", Config: govulncheck.Config{ScanMode: govulncheck.ScanModeConvert}}, false},
+		{"query", &config{policy: "policy.cel", Config: govulncheck.Config{ScanMode: govulncheck.ScanModeQuery}}, false},
+		{"offline", &config{policy: "policy.cel", env: []string{"GOPROXY=off"}}, false},
+	} {
+		if got := fixReleased(context.Background(), test.cfg) != nil; got != test.want {
+			t.Errorf("%s: looked up release times: got %t, want %t", test.name, got, test.want)
+		}
+	}
+}
+
+func TestUseColor(t *testing.T) {
+	var buf bytes.Buffer
+	if useColor(nil, &buf) {
+		t.Error("useColor(buffer) = true, want false")
+	}
+	// Whether a file is a terminal depends on the test environment,
+	// but NO_COLOR and TERM=dumb always disable color.
+	for _, env := range [][]string{{"NO_COLOR=1"}, {"TERM=dumb"}} {
+		if useColor(env, os.Stdout) {
+			t.Errorf("useColor(%q, stdout) = true, want false", env)
+		}
+	}
+}
+
+func TestVerbosityFlags(t *testing.T) {
+	for _, test := range []struct {
+		args    []string
+		want    ShowFlag
+		wantErr bool
+	}{
+		{args: []string{"-q"}, want: ShowFlag{"quiet"}},
+		{args: []string{"-v"}, want: ShowFlag{"traces", "verbose"}},
+		{args: []string{"-v", "-show", "color"}, want: ShowFlag{"color", "traces", "verbose"}},
+		{args: []string{"-q", "-v"}, wantErr: true},
+		{args: []string{"-q", "-show", "traces"}, wantErr: true},
+		{args: []string{"-q", "-format", "json"}, wantErr: true},
+		{args: []string{"-v", "-format", "sarif"}, wantErr: true},
+	} {
+		cfg := &config{}
+		err := parseFlags(cfg, io.Discard, test.args)
+		if (err != nil) != test.wantErr {
+			t.Errorf("parseFlags(%q) = %v, want error %t", test.args, err, test.wantErr)
+			continue
+		}
+		if err == nil && !reflect.DeepEqual(cfg.show, test.want) {
+			t.Errorf("parseFlags(%q): show = %q, want %q", test.args, cfg.show, test.want)
+		}
+	}
 }
@@ -0,0 +1,88 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/vuln/internal"
+	"golang.org/x/vuln/internal/client"
+	"golang.org/x/vuln/internal/derrors"
+	"golang.org/x/vuln/internal/osv"
+)
+
+// runSnapshot writes to out a zip database holding the entries of the
+// vulnerability database that affect the modules of a go.sum file and
+// the standard library. The go.sum file is cfg.patterns[0], or the one
+// in that directory, and defaults to the one in the -C directory.
+func runSnapshot(ctx context.Context, cfg *config, c *client.Client, out io.Writer) (err error) {
+	defer derrors.Wrap(&err, "govulncheck")
+
+	file := filepath.Join(filepath.FromSlash(cfg.dir), "go.sum")
+	if len(cfg.patterns) == 1 {
+		file = cfg.patterns[0]
+		if !isFile(file) {
+			file = filepath.Join(file, "go.sum")
+		}
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	mods, err := parseGoSum(f)
+	if err != nil {
+		return err
+	}
+	mods = append(mods, internal.GoStdModulePath, internal.GoCmdModulePath)
+
+	reqs := make([]*client.ModuleRequest, len(mods))
+	for i, m := range mods {
+		reqs[i] = &client.ModuleRequest{Path: m}
+	}
+	resps, err := c.ByModules(ctx, reqs)
+	if err != nil {
+		return err
+	}
+	var entries []*osv.Entry
+	seen := make(map[string]bool)
+	for _, resp := range resps {
+		for _, e := range resp.Entries {
+			if !seen[e.ID] {
+				seen[e.ID] = true
+				entries = append(entries, e)
+			}
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].ID < entries[j].ID })
+	return client.WriteZip(out, entries)
+}
+
+// parseGoSum returns the sorted paths of the modules in the go.sum
+// file read from r.
+func parseGoSum(r io.Reader) ([]string, error) {
+	seen := make(map[string]bool)
+	var mods []string
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+		if len(fields) == 0 || seen[fields[0]] {
+			continue
+		}
+		seen[fields[0]] = true
+		mods = append(mods, fields[0])
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	sort.Strings(mods)
+	return mods, nil
+}
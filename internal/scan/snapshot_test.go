@@ -0,0 +1,28 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseGoSum(t *testing.T) {
+	const sum = `golang.org/x/text v0.3.7 h1:olpwvP2KacW1ZWvsR7uQhoyTYvKAupfQrRGBFM352Gk=
+golang.org/x/text v0.3.7/go.mod h1:u+2+/6zg+i71rQMx5EYifcz6MCKuco9NR6JIITiCfzQ=
+golang.org/x/text v0.3.8/go.mod h1:E6s5w1FMmriuDzIBO73fBruAKo1PCIq6d2Q6DHfQ8WQ=
+
+example.com/m v1.0.0/go.mod h1:u+2+/6zg+i71rQMx5EYifcz6MCKuco9NR6JIITiCfzQ=
+`
+	got, err := parseGoSum(strings.NewReader(sum))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"example.com/m", "golang.org/x/text"}
+	if !slices.Equal(got, want) {
+		t.Errorf("parseGoSum() = %v, want %v", got, want)
+	}
+}
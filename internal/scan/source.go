@@ -7,11 +7,16 @@ package scan
 import (
 	"context"
 	"fmt"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 
 	"golang.org/x/tools/go/packages"
 	"golang.org/x/vuln/internal/client"
 	"golang.org/x/vuln/internal/derrors"
 	"golang.org/x/vuln/internal/govulncheck"
+	"golang.org/x/vuln/internal/timing"
 	"golang.org/x/vuln/internal/vulncheck"
 )
 
@@ -31,11 +36,26 @@ func runSource(ctx context.Context, handler govulncheck.Handler, cfg *config, cl
 	}
 	graph := vulncheck.NewPackageGraph(cfg.GoVersion)
 	pkgConfig := &packages.Config{
-		Dir:   dir,
-		Tests: cfg.test,
-		Env:   cfg.env,
+		Dir:        dir,
+		Tests:      cfg.test,
+		Env:        cfg.env,
+		BuildFlags: slices.Clone(cfg.buildFlags),
 	}
-	if err := graph.LoadPackagesAndMods(pkgConfig, cfg.tags, cfg.patterns, cfg.ScanLevel == govulncheck.ScanLevelSymbol); err != nil {
+	end := timing.Start(ctx, "load")
+	err = graph.LoadPackagesAndMods(pkgConfig, cfg.tags, cfg.patterns, cfg.ScanLevel == govulncheck.ScanLevelSymbol)
+	end()
+	if err != nil && cfg.allowLoadErr {
+		// Scan the packages that loaded, and report the others.
+		if broken := graph.RemoveBroken(); len(broken) > 0 {
+			for _, d := range loadDiagnostics(broken) {
+				if err := handler.Diagnostic(d); err != nil {
+					return err
+				}
+			}
+			err = nil
+		}
+	}
+	if err != nil {
 		if isGoVersionMismatchError(err) {
 			return fmt.Errorf("%v\n\n%v", errGoVersionMismatch, err)
 		}
@@ -45,5 +65,118 @@ func runSource(ctx context.Context, handler govulncheck.Handler, cfg *config, cl
 	if cfg.ScanLevel.WantPackages() && len(graph.TopPkgs()) == 0 {
 		return nil // early exit
 	}
+	if cfg.unusedModules {
+		mods, err := unusedModules(ctx, cfg, dir, graph.Modules())
+		if err != nil {
+			return err
+		}
+		graph.AddModules(mods...)
+		unused := make(map[string]bool)
+		for _, m := range mods {
+			unused[m.Path] = true
+			if m.Replace != nil {
+				unused[m.Replace.Path] = true
+			}
+		}
+		handler = govulncheck.Chain(handler, markUnused(unused))
+	}
+	handler = govulncheck.Chain(handler, moduleChains(func() (*moduleGraph, error) {
+		return loadModuleGraph(ctx, cfg, dir, graph.Modules())
+	}))
+	if cfg.owners != "" {
+		o, err := loadCodeOwners(cfg.owners)
+		if err != nil {
+			return err
+		}
+		dirs := make(map[string]string)
+		for _, m := range graph.Modules() {
+			if m.Main {
+				dirs[m.Path] = m.Dir
+			}
+		}
+		handler = govulncheck.Chain(handler, codeOwnership(o, dirs))
+	}
 	return vulncheck.Source(ctx, handler, &cfg.Config, client, graph)
 }
+
+// loadDiagnostics returns the diagnostics for the errors of pkgs,
+// which failed to load. As for findings, their positions are relative
+// to the module of their package.
+func loadDiagnostics(pkgs []*packages.Package) []*govulncheck.Diagnostic {
+	var diags []*govulncheck.Diagnostic
+	for _, p := range pkgs {
+		var module string
+		if p.Module != nil && !p.Module.Main {
+			module = p.Module.Path
+		}
+		for _, e := range p.Errors {
+			pos := errorPosition(e.Pos)
+			if pos != nil && p.Module != nil {
+				mod := p.Module
+				if mod.Replace != nil {
+					mod = mod.Replace
+				}
+				if rel, err := filepath.Rel(mod.Dir, pos.Filename); err == nil && mod.Dir != "" {
+					pos.Filename = filepath.ToSlash(rel)
+				}
+			}
+			diags = append(diags, &govulncheck.Diagnostic{
+				Kind:     govulncheck.DiagnosticLoadError,
+				Module:   module,
+				Package:  p.PkgPath,
+				Position: pos,
+				Message:  e.Msg,
+			})
+		}
+	}
+	return diags
+}
+
+// errorPosition parses the position of a packages.Error, which is
+// of the form file:line:column or file:line, or is empty or "-" when
+// the error has no position.
+func errorPosition(pos string) *govulncheck.Position {
+	if pos == "" || pos == "-" {
+		return nil
+	}
+	p := &govulncheck.Position{Filename: pos}
+	for _, n := range []*int{&p.Column, &p.Line} {
+		i := strings.LastIndexByte(p.Filename, ':')
+		if i < 0 {
+			break
+		}
+		v, err := strconv.Atoi(p.Filename[i+1:])
+		if err != nil {
+			break
+		}
+		*n, p.Filename = v, p.Filename[:i]
+	}
+	if p.Line == 0 {
+		// Only a line was given.
+		p.Line, p.Column = p.Column, 0
+	}
+	return p
+}
+
+// partialHandler makes flushing fail with errPartialResults when
+// packages failed to load, unless it fails otherwise, such as with
+// errVulnerabilitiesFound.
+type partialHandler struct {
+	govulncheck.Wrapper
+	partial bool
+}
+
+func (h *partialHandler) Diagnostic(d *govulncheck.Diagnostic) error {
+	if d.Kind == govulncheck.DiagnosticLoadError {
+		h.partial = true
+	}
+	return h.Next.Diagnostic(d)
+}
+
+func (h *partialHandler) Flush() error {
+	err := h.Wrapper.Flush()
+	if err == nil && h.partial {
+		err = errPartialResults
+	}
+	return err
+}
@@ -74,3 +74,22 @@ func stringToFinding(s string) *govulncheck.Finding {
 	}
 	return f
 }
+
+func TestErrorPosition(t *testing.T) {
+	for _, test := range []struct {
+		in   string
+		want *govulncheck.Position
+	}{
+		{"", nil},
+		{"-", nil},
+		{"/m/p/p.go:6:9", &govulncheck.Position{Filename: "/m/p/p.go", Line: 6, Column: 9}},
+		{"/m/p/p.go:6", &govulncheck.Position{Filename: "/m/p/p.go", Line: 6}},
+		{`C:\m\p\p.go:6`, &govulncheck.Position{Filename: `C:\m\p\p.go`, Line: 6}},
+		{"/m/p/p.go", &govulncheck.Position{Filename: "/m/p/p.go"}},
+	} {
+		got := errorPosition(test.in)
+		if (got == nil) != (test.want == nil) || got != nil && *got != *test.want {
+			t.Errorf("errorPosition(%q) = %+v, want %+v", test.in, got, test.want)
+		}
+	}
+}
@@ -180,6 +180,19 @@ func posToString(p *govulncheck.Position) string {
 	}.String()
 }
 
+// diagnosticSubject returns what d is about: its OSV, module,
+// package and position, separated by ": ", leaving out those
+// that are unset.
+func diagnosticSubject(d *govulncheck.Diagnostic) string {
+	var parts []string
+	for _, s := range []string{d.OSV, d.Module, d.Package, posToString(d.Position)} {
+		if s != "" {
+			parts = append(parts, s)
+		}
+	}
+	return strings.Join(parts, ": ")
+}
+
 func symbol(frame *govulncheck.Frame, short bool) string {
 	buf := &strings.Builder{}
 	addSymbol(buf, frame, short)
@@ -188,7 +201,72 @@ func symbol(frame *govulncheck.Frame, short bool) string {
 
 func symbolName(frame *govulncheck.Frame) string {
 	buf := &strings.Builder{}
-	addSymbolName(buf, frame)
+	addSymbolName(buf, frame, true)
+	return buf.String()
+}
+
+// foldTraces folds summaries whose traces share a suffix, that is,
+// that reach the vulnerable symbol through the same calls into its
+// module. It returns the shortest summary of each group, in the
+// order the groups first appear, and the number of other summaries
+// folded into each of those with any.
+func foldTraces(summaries []*findingSummary) ([]*findingSummary, map[*findingSummary]int) {
+	var folded []*findingSummary
+	var counts []int
+	index := make(map[string]int)
+	for _, s := range summaries {
+		k := traceSuffix(s.Trace)
+		i, ok := index[k]
+		if !ok {
+			index[k] = len(folded)
+			folded = append(folded, s)
+			counts = append(counts, 0)
+			continue
+		}
+		counts[i]++
+		if len(s.Trace) < len(folded[i].Trace) {
+			folded[i] = s
+		}
+	}
+	more := make(map[*findingSummary]int)
+	for i, n := range counts {
+		if n > 0 {
+			more[folded[i]] = n
+		}
+	}
+	return folded, more
+}
+
+// traceSuffix returns a key identifying the end of trace: the
+// frames in the module of the vulnerable symbol, and the first
+// frame outside of it, which calls into that module.
+func traceSuffix(trace []*govulncheck.Frame) string {
+	var b strings.Builder
+	for _, f := range trace {
+		b.WriteString(symbol(f, false))
+		b.WriteByte('\n')
+		if f.Module != trace[0].Module {
+			break
+		}
+	}
+	return b.String()
+}
+
+// dispatchSite returns the position and the function of the call
+// through which the trace of finding is a low-confidence path, such
+// as "print.go:673:29: fmt.pp.handleMethods", or "" if it is not one.
+func dispatchSite(finding *govulncheck.Finding) string {
+	d := finding.Dispatch
+	if d == nil || d.Frame < 0 || d.Frame >= len(finding.Trace) {
+		return ""
+	}
+	frame := finding.Trace[d.Frame]
+	buf := &strings.Builder{}
+	if pos := posToString(frame.Position); pos != "" {
+		buf.WriteString(pos)
+		buf.WriteString(": ")
+	}
+	addSymbol(buf, frame, true)
 	return buf.String()
 }
 
@@ -273,18 +351,28 @@ func addSymbol(w io.Writer, frame *govulncheck.Frame, short bool) {
 		io.WriteString(w, pkg)
 		io.WriteString(w, ".")
 	}
-	addSymbolName(w, frame)
+	addSymbolName(w, frame, !short)
 }
 
-func addSymbolName(w io.Writer, frame *govulncheck.Frame) {
+// addSymbolName writes the name of the symbol of frame, with its
+// type arguments, if any, when typeArgs is set. The type arguments
+// follow the receiver of methods and the name of functions.
+func addSymbolName(w io.Writer, frame *govulncheck.Frame, typeArgs bool) {
+	targs := ""
+	if typeArgs && len(frame.TypeArgs) > 0 {
+		targs = "[" + strings.Join(frame.TypeArgs, ", ") + "]"
+	}
 	if frame.Receiver != "" {
 		if frame.Receiver[0] == '*' {
 			io.WriteString(w, frame.Receiver[1:])
 		} else {
 			io.WriteString(w, frame.Receiver)
 		}
+		io.WriteString(w, targs)
 		io.WriteString(w, ".")
+		targs = ""
 	}
 	funcname := strings.Split(frame.Function, "$")[0]
 	io.WriteString(w, funcname)
+	io.WriteString(w, targs)
 }
@@ -7,12 +7,17 @@ package scan
 import (
 	"fmt"
 	"io"
+	"slices"
 	"sort"
 	"strings"
+	"time"
 
+	"golang.org/x/mod/semver"
+	"golang.org/x/text/message"
 	"golang.org/x/vuln/internal"
 	"golang.org/x/vuln/internal/govulncheck"
 	"golang.org/x/vuln/internal/osv"
+	"golang.org/x/vuln/internal/policy"
 	"golang.org/x/vuln/internal/vulncheck"
 )
 
@@ -30,23 +35,37 @@ const (
 
 // NewtextHandler returns a handler that writes govulncheck output as text.
 func NewTextHandler(w io.Writer) *TextHandler {
-	return &TextHandler{w: w}
+	return &TextHandler{w: w, printer: newPrinter(langEnglish)}
 }
 
 type TextHandler struct {
 	w         io.Writer
 	sbom      *govulncheck.SBOM
+	artifacts []*govulncheck.SBOM
 	osvs      []*osv.Entry
 	findings  []*findingSummary
+	diags     []*govulncheck.Diagnostic
 	scanLevel govulncheck.ScanLevel
 	scanMode  govulncheck.ScanMode
 
 	err error
 
-	showColor   bool
-	showTraces  bool
-	showVersion bool
-	showVerbose bool
+	showColor     bool
+	showTraces    bool
+	showAllTraces bool
+	showVersion   bool
+	showVerbose   bool
+	showQuiet     bool
+	showCoverage  bool
+	showAdvice    bool
+
+	// groupBy organizes the results by vulnerability,
+	// module, or package, as set by the -group-by flag.
+	groupBy string
+
+	// printer prints messages in the language
+	// set by the -lang flag.
+	printer *message.Printer
 }
 
 const (
@@ -54,39 +73,170 @@ const (
 
 	binaryProgressMessage = `Scanning your binary for known vulnerabilities...`
 
-	noVulnsMessage = `No vulnerabilities found.`
+	artifactProgressMessage = `Scanning %s for known vulnerabilities...`
 
-	noOtherVulnsMessage = `No other vulnerabilities found.`
+	moduleProgressMessage = `Scanning the module in %s for known vulnerabilities...`
 
-	verboseMessage = `'-show verbose' for more details`
+	noVulnsMessage = `No vulnerabilities found.`
 
-	symbolMessage = `'-scan symbol' for more fine grained vulnerability detection`
+	noOtherVulnsMessage = `No other vulnerabilities found.`
 )
 
 func (h *TextHandler) Flush() error {
-	if h.showVerbose {
-		h.printSBOM()
+	if h.scanMode == govulncheck.ScanModeInfo {
+		h.advisories()
+		h.diagnostics()
+		return h.err
+	}
+	if h.scanMode == govulncheck.ScanModeAudit {
+		h.auditResults()
+		h.diagnostics()
+		if h.err != nil {
+			return h.err
+		}
+		if len(h.findings) > 0 {
+			return errVulnerabilitiesFound
+		}
+		return nil
 	}
-	if len(h.findings) == 0 {
-		h.print(noVulnsMessage + "\n")
+	if len(h.artifacts) > 0 {
+		h.artifactResults()
 	} else {
-		fixupFindings(h.osvs, h.findings)
-		counters := h.allVulns(h.findings)
-		h.summary(counters)
+		h.results(h.findings)
+	}
+	if h.showCoverage {
+		h.coverage()
 	}
+	h.diagnostics()
 	if h.err != nil {
 		return h.err
 	}
-	// We found vulnerabilities when the findings' level matches the scan level.
-	if (isCalled(h.findings) && h.scanLevel == govulncheck.ScanLevelSymbol) ||
-		(isImported(h.findings) && h.scanLevel == govulncheck.ScanLevelPackage) ||
-		(isRequired(h.findings) && h.scanLevel == govulncheck.ScanLevelModule) {
+	if h.affected(h.findings) {
 		return errVulnerabilitiesFound
 	}
-
 	return nil
 }
 
+// affected reports whether findings has vulnerabilities, which is
+// when the level of some of the findings matches the scan level.
+func (h *TextHandler) affected(findings []*findingSummary) bool {
+	return (isCalled(findings) && h.scanLevel == govulncheck.ScanLevelSymbol) ||
+		(isImported(findings) && h.scanLevel == govulncheck.ScanLevelPackage) ||
+		(isRequired(findings) && h.scanLevel == govulncheck.ScanLevelModule)
+}
+
+// results prints findings, preceded by the SBOM in verbose mode.
+func (h *TextHandler) results(findings []*findingSummary) {
+	if h.showVerbose {
+		h.printSBOM()
+	}
+	if len(findings) == 0 {
+		h.print(h.msg(noVulnsMessage), "\n")
+	} else if h.showQuiet {
+		fixupFindings(h.osvs, findings)
+		_, _, _, counters := groupVulns(findings)
+		h.summaryLine(counters)
+	} else {
+		fixupFindings(h.osvs, findings)
+		counters := h.allVulns(findings)
+		h.summary(counters)
+		if h.showAdvice {
+			h.nextSteps(findings)
+		}
+	}
+}
+
+// artifactResults prints the results for each part of the scan in
+// turn, followed by a summary: the binaries of a scanned package, or
+// the modules of a directory tree scanned with -recursive.
+func (h *TextHandler) artifactResults() {
+	byArtifact := make(map[string][]*findingSummary)
+	for _, f := range h.findings {
+		byArtifact[f.Artifact] = append(byArtifact[f.Artifact], f)
+	}
+	binary := h.scanMode == govulncheck.ScanModeBinary
+	affected := 0
+	for i, sbom := range h.artifacts {
+		if i > 0 {
+			h.print("\n")
+		}
+		h.style(keyStyle, h.msg(choose(binary, "Artifact: ", "Module directory: ")))
+		h.print(sbom.Artifact, "\n\n")
+		h.sbom = sbom
+		findings := byArtifact[sbom.Artifact]
+		h.results(findings)
+		if h.affected(findings) {
+			affected++
+		}
+	}
+	h.print("\n")
+	h.style(keyStyle, h.msg("Summary: "))
+	h.print(h.msgf(choose(binary,
+		"%d of %d binaries are affected.",
+		"%d of %d modules are affected."), affected, len(h.artifacts)), "\n")
+}
+
+// diagnostics prints the problems that make the results incomplete,
+// if any.
+func (h *TextHandler) diagnostics() {
+	if len(h.diags) == 0 {
+		return
+	}
+	h.print("\n")
+	h.style(sectionStyle, h.msg("=== Diagnostics ==="), "\n\n")
+	failed := make(map[string]bool)
+	for _, d := range h.diags {
+		if s := diagnosticSubject(d); s != "" {
+			h.print(s, ": ")
+		}
+		h.print(d.Message, "\n")
+		if d.Kind == govulncheck.DiagnosticLoadError {
+			failed[d.Package] = true
+		}
+	}
+	if n := len(failed); n > 0 {
+		h.print("\n")
+		h.wrap("", h.msgf("The results are partial: %d packages failed to load. Neither they nor the packages that import them were scanned.", n), 80)
+		h.print("\n")
+	}
+}
+
+// coverage prints how many vulnerabilities of the scanned modules
+// were checked at each precision, and why some were checked less
+// precisely than the scan level allows.
+func (h *TextHandler) coverage() {
+	if h.scanMode != govulncheck.ScanModeSource && h.scanMode != govulncheck.ScanModeBinary {
+		return
+	}
+	modules := make(map[string]bool)
+	for _, sbom := range append([]*govulncheck.SBOM{h.sbom}, h.artifacts...) {
+		if sbom == nil {
+			continue
+		}
+		for _, m := range sbom.Modules {
+			modules[m.Path] = true
+		}
+	}
+	stripped := false
+	for _, d := range h.diags {
+		if d.Kind == govulncheck.DiagnosticStrippedBinary {
+			stripped = true
+		}
+	}
+	counts := make(map[coverage]int)
+	for _, e := range h.osvs {
+		counts[coverageOf(e, modules, h.scanLevel, stripped)]++
+	}
+	h.print("\n")
+	h.style(sectionStyle, h.msg("=== Coverage ==="), "\n\n")
+	h.print(h.msgf("Checked %d vulnerabilities of the scanned modules:", len(h.osvs)), "\n")
+	for _, c := range coverages {
+		if n := counts[c]; n > 0 {
+			h.print("  ", h.msgf(string(c), n), "\n")
+		}
+	}
+}
+
 // Config writes version information only if --version was set.
 func (h *TextHandler) Config(config *govulncheck.Config) error {
 	h.scanLevel = config.ScanLevel
@@ -100,7 +250,7 @@ func (h *TextHandler) Config(config *govulncheck.Config) error {
 		h.print(config.GoVersion, "\n")
 	}
 	if config.ScannerName != "" {
-		h.style(keyStyle, "Scanner: ")
+		h.style(keyStyle, h.msg("Scanner: "))
 		h.print(config.ScannerName)
 		if config.ScannerVersion != "" {
 			h.print(`@`, config.ScannerVersion)
@@ -108,10 +258,10 @@ func (h *TextHandler) Config(config *govulncheck.Config) error {
 		h.print("\n")
 	}
 	if config.DB != "" {
-		h.style(keyStyle, "DB: ")
+		h.style(keyStyle, h.msg("DB: "))
 		h.print(config.DB, "\n")
 		if config.DBLastModified != nil {
-			h.style(keyStyle, "DB updated: ")
+			h.style(keyStyle, h.msg("DB updated: "))
 			h.print(*config.DBLastModified, "\n")
 		}
 	}
@@ -121,12 +271,15 @@ func (h *TextHandler) Config(config *govulncheck.Config) error {
 
 func (h *TextHandler) SBOM(sbom *govulncheck.SBOM) error {
 	h.sbom = sbom
+	if sbom.Artifact != "" {
+		h.artifacts = append(h.artifacts, sbom)
+	}
 	return nil
 }
 
 func (h *TextHandler) printSBOM() error {
 	if h.sbom == nil {
-		h.print("No packages matched the provided pattern.\n")
+		h.print(h.msg("No packages matched the provided pattern."), "\n")
 		return nil
 	}
 
@@ -134,11 +287,7 @@ func (h *TextHandler) printSBOM() error {
 
 	for i, root := range h.sbom.Roots {
 		if i == 0 {
-			if len(h.sbom.Roots) > 1 {
-				h.print("The package pattern matched the following ", len(h.sbom.Roots), " root packages:\n")
-			} else {
-				h.print("The package pattern matched the following root package:\n")
-			}
+			h.print(h.msgf("The package pattern matched the following %d root packages:", len(h.sbom.Roots)), "\n")
 		}
 
 		h.print("  ", root, "\n")
@@ -146,7 +295,7 @@ func (h *TextHandler) printSBOM() error {
 	}
 	for i, mod := range h.sbom.Modules {
 		if i == 0 && mod.Path != "stdlib" {
-			h.print("Govulncheck scanned the following ", len(h.sbom.Modules)-1, " modules and the ", h.sbom.GoVersion, " standard library:\n")
+			h.print(h.msgf("Govulncheck scanned the following %d modules and the %s standard library:", len(h.sbom.Modules)-1, h.sbom.GoVersion), "\n")
 		}
 
 		if mod.Path == "stdlib" {
@@ -180,6 +329,12 @@ func (h *TextHandler) OSV(entry *osv.Entry) error {
 	return nil
 }
 
+// Diagnostic gathers diagnostics to be written.
+func (h *TextHandler) Diagnostic(d *govulncheck.Diagnostic) error {
+	h.diags = append(h.diags, d)
+	return nil
+}
+
 // Finding gathers vulnerability findings to be written.
 func (h *TextHandler) Finding(finding *govulncheck.Finding) error {
 	if err := validateFindings(finding); err != nil {
@@ -189,17 +344,17 @@ func (h *TextHandler) Finding(finding *govulncheck.Finding) error {
 	return nil
 }
 
-func (h *TextHandler) allVulns(findings []*findingSummary) summaryCounters {
+// groupVulns groups findings by vulnerability into those
+// that are called, imported, and only required, and counts them.
+func groupVulns(findings []*findingSummary) (called, imported, required [][]*findingSummary, c summaryCounters) {
 	byVuln := groupByVuln(findings)
-	var called, imported, required [][]*findingSummary
 	mods := map[string]struct{}{}
-	stdlibCalled := false
 	for _, findings := range byVuln {
 		switch {
 		case isCalled(findings):
 			called = append(called, findings)
 			if isStdFindings(findings) {
-				stdlibCalled = true
+				c.StdlibCalled = true
 			} else {
 				mods[findings[0].Trace[0].Module] = struct{}{}
 			}
@@ -209,48 +364,217 @@ func (h *TextHandler) allVulns(findings []*findingSummary) summaryCounters {
 			required = append(required, findings)
 		}
 	}
+	c.VulnerabilitiesCalled = len(called)
+	c.VulnerabilitiesImported = len(imported)
+	c.VulnerabilitiesRequired = len(required)
+	c.ModulesCalled = len(mods)
+	return called, imported, required, c
+}
+
+func (h *TextHandler) allVulns(findings []*findingSummary) summaryCounters {
+	called, imported, required, counters := groupVulns(findings)
 
 	if h.scanLevel.WantSymbols() {
-		h.style(sectionStyle, "=== Symbol Results ===\n\n")
+		h.style(sectionStyle, h.msg("=== Symbol Results ==="), "\n\n")
 		if len(called) == 0 {
-			h.print(noVulnsMessage, "\n\n")
-		}
-		for index, findings := range called {
-			h.vulnerability(index, findings)
+			h.print(h.msg(noVulnsMessage), "\n\n")
 		}
+		h.vulnerabilities(called)
 	}
 
 	if h.scanLevel == govulncheck.ScanLevelPackage || (h.scanLevel.WantPackages() && h.showVerbose) {
-		h.style(sectionStyle, "=== Package Results ===\n\n")
+		h.style(sectionStyle, h.msg("=== Package Results ==="), "\n\n")
 		if len(imported) == 0 {
-			h.print(choose(!h.scanLevel.WantSymbols(), noVulnsMessage, noOtherVulnsMessage), "\n\n")
-		}
-		for index, findings := range imported {
-			h.vulnerability(index, findings)
+			h.print(h.msg(choose(!h.scanLevel.WantSymbols(), noVulnsMessage, noOtherVulnsMessage)), "\n\n")
 		}
+		h.vulnerabilities(imported)
 	}
 
 	if h.showVerbose || h.scanLevel == govulncheck.ScanLevelModule {
-		h.style(sectionStyle, "=== Module Results ===\n\n")
+		h.style(sectionStyle, h.msg("=== Module Results ==="), "\n\n")
 		if len(required) == 0 {
-			h.print(choose(!h.scanLevel.WantPackages(), noVulnsMessage, noOtherVulnsMessage), "\n\n")
+			h.print(h.msg(choose(!h.scanLevel.WantPackages(), noVulnsMessage, noOtherVulnsMessage)), "\n\n")
+		}
+		h.vulnerabilities(required)
+	} else if unused := unusedVulns(required); len(unused) > 0 {
+		// Vulnerabilities in modules that are required but unused
+		// are only found when asked for, so they are always shown.
+		h.style(sectionStyle, h.msg("=== Unused Module Results ==="), "\n\n")
+		h.vulnerabilities(unused)
+	}
+
+	return counters
+}
+
+// unusedVulns returns the vulnerabilities of vulns that were
+// only found in modules that are required but unused.
+func unusedVulns(vulns [][]*findingSummary) [][]*findingSummary {
+	var unused [][]*findingSummary
+	for _, findings := range vulns {
+		if isUnused(findings) {
+			unused = append(unused, findings)
+		}
+	}
+	return unused
+}
+
+// isUnused reports whether findings are all in
+// modules that are required but unused.
+func isUnused(findings []*findingSummary) bool {
+	for _, f := range findings {
+		if f.Precision != govulncheck.PrecisionModuleUnused {
+			return false
 		}
-		for index, findings := range required {
+	}
+	return true
+}
+
+// vulnerabilities prints vulns, each of which holds the findings of a
+// vulnerability, one by one or grouped by module or package.
+func (h *TextHandler) vulnerabilities(vulns [][]*findingSummary) {
+	if h.groupBy != groupingModule && h.groupBy != groupingPackage {
+		for index, findings := range vulns {
 			h.vulnerability(index, findings)
 		}
+		return
+	}
+	// Only keep the most precise findings of each vulnerability,
+	// so that a module or package is not listed for findings of
+	// another level of the scan.
+	var findings []*findingSummary
+	for _, vuln := range vulns {
+		depth := 0
+		for _, f := range vuln {
+			depth = max(depth, findingDepth(f))
+		}
+		for _, f := range vuln {
+			if findingDepth(f) == depth {
+				findings = append(findings, f)
+			}
+		}
 	}
+	compare := func(left, right *findingSummary) int {
+		return strings.Compare(left.Trace[0].Module, right.Trace[0].Module)
+	}
+	if h.groupBy == groupingPackage {
+		compare = func(left, right *findingSummary) int {
+			if c := strings.Compare(left.Trace[0].Package, right.Trace[0].Package); c != 0 {
+				return c
+			}
+			return strings.Compare(left.Trace[0].Module, right.Trace[0].Module)
+		}
+	}
+	for index, group := range groupBy(findings, compare) {
+		h.group(index, group)
+	}
+}
 
-	return summaryCounters{
-		VulnerabilitiesCalled:   len(called),
-		VulnerabilitiesImported: len(imported),
-		VulnerabilitiesRequired: len(required),
-		ModulesCalled:           len(mods),
-		StdlibCalled:            stdlibCalled,
+// findingDepth returns how deep in the code f was found:
+// 2 for a symbol, 1 for a package, and 0 for a module.
+func findingDepth(f *findingSummary) int {
+	switch {
+	case f.Trace[0].Function != "":
+		return 2
+	case f.Trace[0].Package != "":
+		return 1
 	}
+	return 0
+}
+
+// group prints the findings of a module or package, which are in
+// the same module: its worst severity, its vulnerabilities, and the
+// version to upgrade to, which fixes all of those that have a fix.
+func (h *TextHandler) group(index int, findings []*findingSummary) {
+	frame := findings[0].Trace[0]
+	pkg := h.groupBy == groupingPackage && frame.Package != ""
+	h.style(keyStyle, h.msg(choose(pkg, "Package", "Module")))
+	h.print(" #", index+1, ": ")
+	name := frame.Module
+	switch {
+	case pkg:
+		name = frame.Package
+	case name == internal.GoStdModulePath:
+		name = h.msg("Standard library")
+	}
+	h.style(choose(isCalled(findings), osvCalledStyle, osvImportedStyle), name)
+	h.print("\n")
+	if pkg {
+		h.print("  ")
+		if frame.Module == internal.GoStdModulePath {
+			h.print(h.msg("Standard library"), "\n")
+		} else {
+			h.style(keyStyle, h.msg("Module: "))
+			h.print(frame.Module, "\n")
+		}
+	}
+
+	byVuln := groupByVuln(findings)
+	var fixed, severity string
+	for _, vuln := range byVuln {
+		if semver.Compare(vuln[0].FixedVersion, fixed) > 0 {
+			fixed = vuln[0].FixedVersion
+		}
+		if s := vulnSeverity(vuln[0].OSV, frame.Module); severityRank[s] > severityRank[severity] {
+			severity = s
+		}
+	}
+	if severity != "" {
+		h.print("  ")
+		h.style(keyStyle, h.msg("Severity: "))
+		h.print(severity, "\n")
+	}
+	version := func(v string) string {
+		if frame.Module == internal.GoStdModulePath || frame.Module == internal.GoCmdModulePath {
+			return moduleVersionString(frame.Module, v)
+		}
+		return frame.Module + "@" + v
+	}
+	h.print("  ")
+	h.style(keyStyle, h.msg("Found in: "))
+	h.print(version(frame.Version), "\n")
+	h.print("  ")
+	h.style(keyStyle, h.msg("Upgrade to: "))
+	if fixed != "" {
+		h.print(version(fixed), "\n")
+	} else {
+		h.print(h.msg("N/A"), "\n")
+	}
+	h.style(keyStyle, h.msg("  Vulnerabilities:"))
+	h.print("\n")
+	// byVuln is sorted by decreasing ID.
+	for i := len(byVuln) - 1; i >= 0; i-- {
+		e := byVuln[i][0].OSV
+		h.print("    ")
+		h.style(choose(isCalled(byVuln[i]), osvCalledStyle, osvImportedStyle), e.ID)
+		if u := e.DatabaseSpecific.URL; u != "" {
+			h.print(" ", u)
+		}
+		if byVuln[i][0].FixedVersion == "" {
+			h.print(h.msg(" (no fixed version)"))
+		}
+		h.print("\n")
+	}
+	h.print("\n")
+}
+
+// severityRank orders the qualitative severity ratings.
+var severityRank = map[string]int{"NONE": 1, "LOW": 2, "MEDIUM": 3, "HIGH": 4, "CRITICAL": 5}
+
+// vulnSeverity returns the qualitative severity rating of e for the
+// module with path, whose own severities take precedence, or "" if e
+// has no severity that can be rated.
+func vulnSeverity(e *osv.Entry, path string) string {
+	var sevs []osv.Severity
+	for _, a := range e.Affected {
+		if a.Module.Path == path {
+			sevs = append(sevs, a.Severity...)
+		}
+	}
+	return policy.Severity(append(sevs, e.Severity...))
 }
 
 func (h *TextHandler) vulnerability(index int, findings []*findingSummary) {
-	h.style(keyStyle, "Vulnerability")
+	h.style(keyStyle, h.msg("Vulnerability"))
 	h.print(" #", index+1, ": ")
 	if isCalled(findings) {
 		h.style(osvCalledStyle, findings[0].OSV.ID)
@@ -266,8 +590,16 @@ func (h *TextHandler) vulnerability(index int, findings []*findingSummary) {
 	h.wrap("    ", description, 80)
 	h.style(defaultStyle)
 	h.print("\n")
-	h.style(keyStyle, "  More info:")
+	h.style(keyStyle, h.msg("  More info:"))
 	h.print(" ", findings[0].OSV.DatabaseSpecific.URL, "\n")
+	if d := govulncheck.FirstDisclosure(summarized(findings)); h.showVerbose && d != nil {
+		h.style(keyStyle, h.msg("  Published:"))
+		h.print(" ", d.Published.Format(time.DateOnly))
+		if d.Modified.After(d.Published) {
+			h.print(h.msgf(", last modified %s", d.Modified.Format(time.DateOnly)))
+		}
+		h.print("\n")
+	}
 
 	byModule := groupByModule(findings)
 	first := true
@@ -294,24 +626,45 @@ func (h *TextHandler) vulnerability(index int, findings []*findingSummary) {
 		first = false
 		h.print("  ")
 		if mod == internal.GoStdModulePath {
-			h.print("Standard library")
+			h.print(h.msg("Standard library"))
 		} else {
-			h.style(keyStyle, "Module: ")
+			h.style(keyStyle, h.msg("Module: "))
 			h.print(mod)
+			if isUnused(module) {
+				h.print(h.msg(" (required but unused)"))
+			}
 		}
 		h.print("\n    ")
-		h.style(keyStyle, "Found in: ")
+		h.style(keyStyle, h.msg("Found in: "))
 		h.print(path, "@", foundVersion, "\n    ")
-		h.style(keyStyle, "Fixed in: ")
+		h.style(keyStyle, h.msg("Fixed in: "))
 		if fixedVersion != "" {
 			h.print(path, "@", fixedVersion)
+			if d := govulncheck.FirstDisclosure(summarized(module)); h.showVerbose && d != nil && d.FixAvailableDays != nil {
+				h.print(h.msgf(" (available for %d days)", *d.FixAvailableDays))
+			}
 		} else {
-			h.print("N/A")
+			h.print(h.msg("N/A"))
+		}
+		if chain := moduleChain(module); chain != nil {
+			h.print("\n    ")
+			h.style(keyStyle, h.msg("Required through: "))
+			h.print(strings.Join(chain, " → "))
+		}
+		if owners := owners(module); owners != nil {
+			h.print("\n    ")
+			h.style(keyStyle, h.msg("Owners: "))
+			h.print(strings.Join(owners, ", "))
+		}
+		if u := upgrade(module); u != nil {
+			h.print("\n    ")
+			h.style(keyStyle, h.msg("Upgrade: "))
+			h.print(h.upgrade(u))
 		}
 		h.print("\n")
 		platforms := platforms(mod, module[0].OSV)
 		if len(platforms) > 0 {
-			h.style(keyStyle, "    Platforms: ")
+			h.style(keyStyle, h.msg("    Platforms: "))
 			for ip, p := range platforms {
 				if ip > 0 {
 					h.print(", ")
@@ -320,11 +673,79 @@ func (h *TextHandler) vulnerability(index int, findings []*findingSummary) {
 			}
 			h.print("\n")
 		}
+		if h.showVerbose {
+			h.style(keyStyle, h.msg("    Precision: "))
+			h.print(h.precision(module), "\n")
+		}
 		h.traces(module)
 	}
 	h.print("\n")
 }
 
+// moduleChain returns the module chain of the
+// first of findings that has one, or nil if none has.
+func moduleChain(findings []*findingSummary) []string {
+	for _, f := range findings {
+		if f.ModuleChain != nil {
+			return f.ModuleChain
+		}
+	}
+	return nil
+}
+
+// upgrade returns the upgrade of the
+// first of findings that has one, or nil if none has.
+func upgrade(findings []*findingSummary) *govulncheck.Upgrade {
+	for _, f := range findings {
+		if f.Upgrade != nil {
+			return f.Upgrade
+		}
+	}
+	return nil
+}
+
+// upgrade describes u: the difficulty of the
+// upgrade to the fixed version and the latest version.
+func (h *TextHandler) upgrade(u *govulncheck.Upgrade) string {
+	switch {
+	case u.Difficulty == "":
+		return h.msgf("latest version %s", u.Latest)
+	case u.Latest == "":
+		return h.msg(string(u.Difficulty))
+	default:
+		return h.msgf("%s, latest version %s", h.msg(string(u.Difficulty)), u.Latest)
+	}
+}
+
+// owners returns the owners of findings, sorted,
+// or nil if none of them has owners.
+func owners(findings []*findingSummary) []string {
+	var owners []string
+	for _, f := range findings {
+		for _, o := range f.Owners {
+			if !slices.Contains(owners, o) {
+				owners = append(owners, o)
+			}
+		}
+	}
+	slices.Sort(owners)
+	return owners
+}
+
+// precision returns the most precise precision of findings.
+func (h *TextHandler) precision(findings []*findingSummary) govulncheck.Precision {
+	return govulncheck.MostPrecise(summarized(findings), h.scanMode)
+}
+
+// summarized returns the findings of summaries.
+func summarized(summaries []*findingSummary) []*govulncheck.Finding {
+	fs := make([]*govulncheck.Finding, len(summaries))
+	for i, s := range summaries {
+		fs[i] = s.Finding
+	}
+	return fs
+}
+
 // pkg gives the package information for findings summaries
 // if one exists. This is only used to print package path
 // instead of a module for stdlib vulnerabilities at symbol
@@ -358,6 +779,15 @@ func (h *TextHandler) traces(traces []*findingSummary) {
 		}
 	}
 
+	// Fold traces that share a suffix into the shortest of
+	// them, unless '-show all-traces' is on. Large programs
+	// often reach a vulnerable symbol the same way from many
+	// entry points.
+	var more map[*findingSummary]int
+	if !h.showAllTraces {
+		compacts, more = foldTraces(compacts)
+	}
+
 	// binLimit is a limit on the number of binary traces
 	// to show. Traces for binaries are less interesting
 	// as users cannot act on them and they can hence
@@ -367,22 +797,23 @@ func (h *TextHandler) traces(traces []*findingSummary) {
 	for i, entry := range compacts {
 		if i == 0 {
 			if binary {
-				h.style(keyStyle, "    Vulnerable symbols found:\n")
+				h.style(keyStyle, h.msg("    Vulnerable symbols found:"), "\n")
 			} else {
-				h.style(keyStyle, "    Example traces found:\n")
+				h.style(keyStyle, h.msg("    Example traces found:"), "\n")
 			}
 		}
 
 		// skip showing all symbols in binary mode unless '-show traces' is on.
 		if binary && (i+1) > binLimit && !h.showTraces {
-			h.print("      Use '-show traces' to see the other ", len(compacts)-binLimit, " found symbols\n")
+			h.print(h.msgf("      Use '-show traces' to see the other %d found symbols", len(compacts)-binLimit), "\n")
 			break
 		}
 
 		h.print("      #", i+1, ": ")
 
 		if !h.showTraces { // show summarized traces
-			h.print(entry.Compact, "\n")
+			h.print(entry.Compact, h.moreCallSites(more[entry]), "\n")
+			h.dispatch(entry.Finding)
 			continue
 		}
 
@@ -391,7 +822,7 @@ func (h *TextHandler) traces(traces []*findingSummary) {
 			// so just show the full symbol name.
 			h.print(symbol(entry.Trace[0], false), "\n")
 		} else {
-			h.print("for function ", symbol(entry.Trace[0], false), "\n")
+			h.print(h.msgf("for function %s", symbol(entry.Trace[0], false)), h.moreCallSites(more[entry]), "\n")
 			for i := len(entry.Trace) - 1; i >= 0; i-- {
 				t := entry.Trace[i]
 				h.print("        ")
@@ -401,8 +832,35 @@ func (h *TextHandler) traces(traces []*findingSummary) {
 				}
 				h.print("\n")
 			}
+			h.dispatch(entry.Finding)
 		}
 	}
+	if len(more) > 0 {
+		h.print(h.msg("      Use '-show all-traces' to see the folded call sites"), "\n")
+	}
+}
+
+// dispatch prints the call through which the trace of
+// f is a low-confidence path, if it is one.
+func (h *TextHandler) dispatch(f *govulncheck.Finding) {
+	site := dispatchSite(f)
+	if site == "" {
+		return
+	}
+	if f.Dispatch.Kind == govulncheck.DispatchReflection {
+		h.print(h.msgf("          Low-confidence path, calling a method through reflection at %s", site), "\n")
+	} else {
+		h.print(h.msgf("          Low-confidence path, calling a method through an interface at %s", site), "\n")
+	}
+}
+
+// moreCallSites returns the note for a trace that
+// n other traces were folded into, if any.
+func (h *TextHandler) moreCallSites(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return h.msgf(" (and %d more call sites)", n)
 }
 
 // symbolPath returns a user-friendly path to a symbol.
@@ -413,33 +871,7 @@ func symbolPath(t *govulncheck.Frame) string {
 }
 
 func (h *TextHandler) summary(c summaryCounters) {
-	// print short summary of findings identified at the desired level of scan precision
-	var vulnCount int
-	h.print("Your code ", choose(h.scanLevel.WantSymbols(), "is", "may be"), " affected by ")
-	switch h.scanLevel {
-	case govulncheck.ScanLevelSymbol:
-		vulnCount = c.VulnerabilitiesCalled
-	case govulncheck.ScanLevelPackage:
-		vulnCount = c.VulnerabilitiesImported
-	case govulncheck.ScanLevelModule:
-		vulnCount = c.VulnerabilitiesRequired
-	}
-	h.style(valueStyle, vulnCount)
-	h.print(choose(vulnCount == 1, ` vulnerability`, ` vulnerabilities`))
-	if h.scanLevel.WantSymbols() {
-		h.print(choose(c.ModulesCalled > 0 || c.StdlibCalled, ` from `, ``))
-		if c.ModulesCalled > 0 {
-			h.style(valueStyle, c.ModulesCalled)
-			h.print(choose(c.ModulesCalled == 1, ` module`, ` modules`))
-		}
-		if c.StdlibCalled {
-			if c.ModulesCalled != 0 {
-				h.print(` and `)
-			}
-			h.print(`the Go standard library`)
-		}
-	}
-	h.print(".\n")
+	h.summaryLine(c)
 
 	// print summary for vulnerabilities found at other levels of scan precision
 	if other := h.summaryOtherVulns(c); other != "" {
@@ -454,48 +886,165 @@ func (h *TextHandler) summary(c summaryCounters) {
 	}
 }
 
+// summaryLine prints a short summary of findings identified
+// at the desired level of scan precision.
+func (h *TextHandler) summaryLine(c summaryCounters) {
+	var vulnCount int
+	switch h.scanLevel {
+	case govulncheck.ScanLevelSymbol:
+		vulnCount = c.VulnerabilitiesCalled
+	case govulncheck.ScanLevelPackage:
+		vulnCount = c.VulnerabilitiesImported
+	case govulncheck.ScanLevelModule:
+		vulnCount = c.VulnerabilitiesRequired
+	}
+	var from string
+	if h.scanLevel.WantSymbols() {
+		mods := h.styled(valueStyle, c.ModulesCalled)
+		switch {
+		case c.ModulesCalled > 0 && c.StdlibCalled:
+			from = h.msgf(" from %[2]s modules and the Go standard library", c.ModulesCalled, mods)
+		case c.ModulesCalled > 0:
+			from = h.msgf(" from %[2]s modules", c.ModulesCalled, mods)
+		case c.StdlibCalled:
+			from = h.msg(" from the Go standard library")
+		}
+	}
+	format := choose(h.scanLevel.WantSymbols(),
+		"Your code is affected by %[2]s vulnerabilities%[3]s.",
+		"Your code may be affected by %[2]s vulnerabilities%[3]s.")
+	h.print(h.msgf(format, vulnCount, h.styled(valueStyle, vulnCount), from), "\n")
+}
+
 func (h *TextHandler) summaryOtherVulns(c summaryCounters) string {
-	var summary strings.Builder
 	if c.VulnerabilitiesRequired+c.VulnerabilitiesImported == 0 {
-		summary.WriteString("This scan found no other vulnerabilities in ")
 		if h.scanLevel.WantSymbols() {
-			summary.WriteString("packages you import or ")
-		}
-		summary.WriteString("modules you require.")
-	} else {
-		summary.WriteString(choose(h.scanLevel.WantPackages(), "This scan also found ", ""))
-		if h.scanLevel.WantSymbols() {
-			summary.WriteString(fmt.Sprint(c.VulnerabilitiesImported))
-			summary.WriteString(choose(c.VulnerabilitiesImported == 1, ` vulnerability `, ` vulnerabilities `))
-			summary.WriteString("in packages you import and ")
-		}
-		if h.scanLevel.WantPackages() {
-			summary.WriteString(fmt.Sprint(c.VulnerabilitiesRequired))
-			summary.WriteString(choose(c.VulnerabilitiesRequired == 1, ` vulnerability `, ` vulnerabilities `))
-			summary.WriteString("in modules you require")
-			summary.WriteString(choose(h.scanLevel.WantSymbols(), ", but your code doesn't appear to call these vulnerabilities.", "."))
+			return h.msg("This scan found no other vulnerabilities in packages you import or modules you require.")
 		}
+		return h.msg("This scan found no other vulnerabilities in modules you require.")
 	}
-	return summary.String()
+	switch {
+	case h.scanLevel.WantSymbols():
+		return h.msgf("This scan also found %s in packages you import and %s in modules you require, but your code doesn't appear to call these vulnerabilities.",
+			h.vulnCount(c.VulnerabilitiesImported), h.vulnCount(c.VulnerabilitiesRequired))
+	case h.scanLevel.WantPackages():
+		return h.msgf("This scan also found %s in modules you require.", h.vulnCount(c.VulnerabilitiesRequired))
+	}
+	return ""
+}
+
+// vulnCount returns the translated count of n vulnerabilities.
+func (h *TextHandler) vulnCount(n int) string {
+	return h.msgf("%d vulnerabilities", n)
 }
 
 func (h *TextHandler) summarySuggestion() string {
-	var sugg strings.Builder
 	switch h.scanLevel {
 	case govulncheck.ScanLevelSymbol:
 		if !h.showVerbose {
-			sugg.WriteString("Use " + verboseMessage + ".")
+			return h.msg("Use '-show verbose' for more details.")
 		}
 	case govulncheck.ScanLevelPackage:
-		sugg.WriteString("Use " + symbolMessage)
 		if !h.showVerbose {
-			sugg.WriteString(" and " + verboseMessage)
+			return h.msg("Use '-scan symbol' for more fine grained vulnerability detection and '-show verbose' for more details.")
 		}
-		sugg.WriteString(".")
+		return h.msg("Use '-scan symbol' for more fine grained vulnerability detection.")
+	case govulncheck.ScanLevelModule:
+		return h.msg("Use '-scan symbol' for more fine grained vulnerability detection.")
+	}
+	return ""
+}
+
+// nextSteps prints what to do about the vulnerabilities affecting
+// the code: the go get commands that upgrade each affected module to
+// a version fixing all of them, the Go release fixing the standard
+// library, the advisories to read, and how many informational
+// vulnerabilities need no action.
+func (h *TextHandler) nextSteps(findings []*findingSummary) {
+	called, imported, required, _ := groupVulns(findings)
+	var affected [][]*findingSummary
+	informational := 0
+	switch h.scanLevel {
+	case govulncheck.ScanLevelSymbol:
+		affected, informational = called, len(imported)+len(required)
+	case govulncheck.ScanLevelPackage:
+		affected, informational = imported, len(required)
 	case govulncheck.ScanLevelModule:
-		sugg.WriteString("Use " + symbolMessage + ".")
+		affected = required
+	}
+	if len(affected) == 0 {
+		return
+	}
+
+	fixes := map[string]string{} // module path to the highest fixed version
+	var goFix string
+	var unfixed []string
+	for _, vuln := range affected {
+		for _, f := range vuln {
+			mod := f.Trace[0].Module
+			if f.FixedVersion == "" {
+				if u := h.msgf("No fixed version is available yet for %s in %s.", f.OSV.ID, mod); !slices.Contains(unfixed, u) {
+					unfixed = append(unfixed, u)
+				}
+				continue
+			}
+			if mod == internal.GoStdModulePath || mod == internal.GoCmdModulePath {
+				if semver.Compare(f.FixedVersion, goFix) > 0 {
+					goFix = f.FixedVersion
+				}
+			} else if semver.Compare(f.FixedVersion, fixes[mod]) > 0 {
+				fixes[mod] = f.FixedVersion
+			}
+		}
 	}
-	return sugg.String()
+
+	h.print("\n")
+	h.style(sectionStyle, h.msg("=== Next Steps ==="), "\n\n")
+	if len(fixes) > 0 {
+		h.print(h.msg("Upgrade the affected modules to their fixed versions:"), "\n")
+		mods := make([]string, 0, len(fixes))
+		for mod := range fixes {
+			mods = append(mods, mod)
+		}
+		sort.Strings(mods)
+		for _, mod := range mods {
+			h.print("  go get ", mod, "@", fixes[mod], "\n")
+		}
+	}
+	if goFix != "" {
+		h.print(h.msgf("Upgrade Go to %s or later.", semverToGoTag(goFix)), "\n")
+	}
+	sort.Strings(unfixed)
+	for _, u := range unfixed {
+		h.print(u, "\n")
+	}
+	var urls []string
+	for _, vuln := range affected {
+		if u := vuln[0].OSV.DatabaseSpecific.URL; u != "" {
+			urls = append(urls, u)
+		}
+	}
+	if len(urls) > 0 {
+		sort.Strings(urls)
+		h.print(h.msg("Read the advisories for details:"), "\n")
+		for _, u := range urls {
+			h.print("  ", u, "\n")
+		}
+	}
+	if informational > 0 {
+		h.wrap("", h.msgf("%d informational vulnerabilities don't appear to affect your code and need no action.", informational), 80)
+		h.print("\n")
+	}
+}
+
+// styled returns the text of values in style.
+func (h *TextHandler) styled(style style, values ...any) string {
+	var b strings.Builder
+	w, err := h.w, h.err
+	h.w, h.err = &b, nil
+	h.style(style, values...)
+	h.w, h.err = w, err
+	return b.String()
 }
 
 func (h *TextHandler) style(style style, values ...any) {
@@ -0,0 +1,104 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"sort"
+
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/vuln/internal/govulncheck"
+)
+
+// unusedModules returns the modules that the go.mod files of the main
+// modules among mods require, directly or indirectly, but that are not
+// among mods because none of the loaded packages belongs to them. The
+// modules are at their selected versions, as listed by 'go list -m'
+// in dir.
+func unusedModules(ctx context.Context, cfg *config, dir string, mods []*packages.Module) ([]*packages.Module, error) {
+	used := make(map[string]bool)
+	for _, m := range mods {
+		used[m.Path] = true
+	}
+	unused := make(map[string]bool)
+	for _, m := range mods {
+		if !m.Main || m.GoMod == "" {
+			continue
+		}
+		reqs, err := requirements(m.GoMod, true)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range reqs {
+			if !used[r] {
+				unused[r] = true
+			}
+		}
+	}
+	if len(unused) == 0 {
+		return nil, nil
+	}
+	paths := make([]string, 0, len(unused))
+	for p := range unused {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	cmd := exec.CommandContext(ctx, "go", append([]string{"list", "-m", "-json"}, paths...)...)
+	cmd.Dir = dir
+	cmd.Env = cfg.env
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("listing required modules: %v\n%s", err, bytes.TrimSpace(stderr.Bytes()))
+	}
+	return decodeModules(out)
+}
+
+// decodeModules decodes the modules in out, the output of
+// 'go list -m -json'.
+func decodeModules(out []byte) ([]*packages.Module, error) {
+	var mods []*packages.Module
+	dec := json.NewDecoder(bytes.NewReader(out))
+	for {
+		m := new(packages.Module)
+		if err := dec.Decode(m); err != nil {
+			if errors.Is(err, io.EOF) {
+				return mods, nil
+			}
+			return nil, err
+		}
+		mods = append(mods, m)
+	}
+}
+
+// markUnused returns middleware that sets the precision of the
+// module-level findings of the modules with paths in unused to
+// PrecisionModuleUnused. Findings are copied before they are changed.
+func markUnused(unused map[string]bool) govulncheck.Middleware {
+	return func(next govulncheck.Handler) govulncheck.Handler {
+		return &unusedHandler{govulncheck.Wrapper{Next: next}, unused}
+	}
+}
+
+type unusedHandler struct {
+	govulncheck.Wrapper
+	unused map[string]bool
+}
+
+func (h *unusedHandler) Finding(f *govulncheck.Finding) error {
+	if len(f.Trace) > 0 && f.Trace[0].Package == "" && h.unused[f.Trace[0].Module] {
+		c := *f
+		c.Precision = govulncheck.PrecisionModuleUnused
+		f = &c
+	}
+	return h.Next.Finding(f)
+}
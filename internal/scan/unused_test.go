@@ -0,0 +1,95 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/vuln/internal/govulncheck"
+	"golang.org/x/vuln/internal/test"
+)
+
+func TestRequirements(t *testing.T) {
+	gomod := filepath.Join(t.TempDir(), "go.mod")
+	if err := os.WriteFile(gomod, []byte(`module example.com/main
+
+go 1.21
+
+require example.com/a v1.0.0
+
+require example.com/b v1.1.0 // indirect
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		indirect bool
+		want     []string
+	}{
+		{false, []string{"example.com/a"}},
+		{true, []string{"example.com/a", "example.com/b"}},
+	} {
+		got, err := requirements(gomod, test.indirect)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(test.want, got); diff != "" {
+			t.Errorf("requirements(%t) (-want,+got):%s", test.indirect, diff)
+		}
+	}
+}
+
+func TestDecodeModules(t *testing.T) {
+	const out = `{
+	"Path": "example.com/a",
+	"Version": "v1.2.0"
+}
+{
+	"Path": "example.com/b",
+	"Version": "v1.0.0",
+	"Replace": {
+		"Path": "example.com/fork",
+		"Version": "v1.0.1"
+	}
+}
+`
+	got, err := decodeModules([]byte(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*packages.Module{
+		{Path: "example.com/a", Version: "v1.2.0"},
+		{Path: "example.com/b", Version: "v1.0.0", Replace: &packages.Module{Path: "example.com/fork", Version: "v1.0.1"}},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want,+got):%s", diff)
+	}
+}
+
+func TestMarkUnused(t *testing.T) {
+	mock := test.NewMockHandler()
+	h := govulncheck.Chain(mock, markUnused(map[string]bool{"example.com/unused": true}))
+	findings := []*govulncheck.Finding{
+		{OSV: "GO-0000-0001", Trace: []*govulncheck.Frame{{Module: "example.com/unused", Version: "v1.0.0"}}},
+		{OSV: "GO-0000-0002", Trace: []*govulncheck.Frame{{Module: "example.com/used", Version: "v1.0.0"}}},
+	}
+	for _, f := range findings {
+		if err := h.Finding(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := []govulncheck.Precision{govulncheck.PrecisionModuleUnused, ""}
+	for i, f := range mock.FindingMessages {
+		if f.Precision != want[i] {
+			t.Errorf("%s: got precision %q, want %q", f.OSV, f.Precision, want[i])
+		}
+	}
+	if findings[0].Precision != "" {
+		t.Errorf("the original finding was changed")
+	}
+}
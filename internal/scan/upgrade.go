@@ -0,0 +1,69 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"golang.org/x/mod/semver"
+	"golang.org/x/vuln/internal/govulncheck"
+)
+
+// newUpgradeHandler returns a handler that adds an Upgrade to findings
+// that have none, with the latest version of their module as returned
+// by latest, which is called once per module. Findings are copied
+// before they are changed.
+func newUpgradeHandler(h govulncheck.Handler, latest func(path string) string) *upgradeHandler {
+	return &upgradeHandler{
+		Wrapper: govulncheck.Wrapper{Next: h},
+		latest:  latest,
+		latests: make(map[string]string),
+	}
+}
+
+type upgradeHandler struct {
+	govulncheck.Wrapper
+	latest  func(path string) string
+	latests map[string]string
+}
+
+func (h *upgradeHandler) Finding(f *govulncheck.Finding) error {
+	if f.Upgrade != nil || len(f.Trace) == 0 {
+		return h.Next.Finding(f)
+	}
+	mod := f.Trace[0].Module
+	latest, ok := h.latests[mod]
+	if !ok {
+		latest = h.latest(mod)
+		h.latests[mod] = latest
+	}
+	u := govulncheck.Upgrade{
+		Latest:     latest,
+		Difficulty: upgradeDifficulty(f.Trace[0].Version, f.FixedVersion),
+	}
+	if u != (govulncheck.Upgrade{}) {
+		c := *f
+		c.Upgrade = &u
+		f = &c
+	}
+	return h.Next.Finding(f)
+}
+
+// upgradeDifficulty returns the difficulty of the upgrade from version
+// to fixed, or "" if either is not a valid semantic version.
+func upgradeDifficulty(version, fixed string) govulncheck.UpgradeDifficulty {
+	if !semver.IsValid(version) || !semver.IsValid(fixed) {
+		return ""
+	}
+	switch {
+	case semver.Major(version) != semver.Major(fixed):
+		return govulncheck.UpgradeMajor
+	case semver.MajorMinor(version) != semver.MajorMinor(fixed):
+		if semver.Major(version) == "v0" {
+			return govulncheck.UpgradeMajor
+		}
+		return govulncheck.UpgradeMinor
+	default:
+		return govulncheck.UpgradePatch
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/vuln/internal/govulncheck"
+	"golang.org/x/vuln/internal/test"
+)
+
+func TestUpgradeHandler(t *testing.T) {
+	mock := test.NewMockHandler()
+	var lookups []string
+	h := newUpgradeHandler(mock, func(path string) string {
+		lookups = append(lookups, path)
+		if path == "m" {
+			return "v2.0.0"
+		}
+		return ""
+	})
+	f := func(mod, version, fixed string) *govulncheck.Finding {
+		return &govulncheck.Finding{
+			OSV:          "GO-0000-0001",
+			FixedVersion: fixed,
+			Trace:        []*govulncheck.Frame{{Module: mod, Version: version}},
+		}
+	}
+	findings := []*govulncheck.Finding{
+		f("m", "v1.0.0", "v1.0.1"),
+		f("m", "v1.0.0", "v1.2.0"),
+		f("m", "v1.0.0", ""),
+		f("n", "v0.1.0", "v0.2.0"),
+		f("n", "v1.9.0", "v2.0.0+incompatible"),
+		f("n", "v1.0.0", ""),
+	}
+	for _, f := range findings {
+		if err := h.Finding(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := []*govulncheck.Upgrade{
+		{Latest: "v2.0.0", Difficulty: govulncheck.UpgradePatch},
+		{Latest: "v2.0.0", Difficulty: govulncheck.UpgradeMinor},
+		{Latest: "v2.0.0"},
+		{Difficulty: govulncheck.UpgradeMajor},
+		{Difficulty: govulncheck.UpgradeMajor},
+		nil,
+	}
+	var got []*govulncheck.Upgrade
+	for _, f := range mock.FindingMessages {
+		got = append(got, f.Upgrade)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("upgrade mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"m", "n"}, lookups); diff != "" {
+		t.Errorf("lookups mismatch (-want +got):\n%s", diff)
+	}
+	if findings[0].Upgrade != nil {
+		t.Error("upgrade handler modified its input")
+	}
+}
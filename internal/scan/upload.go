@@ -0,0 +1,278 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os/exec"
+	"strings"
+
+	"golang.org/x/vuln/internal/derrors"
+	"golang.org/x/vuln/internal/govulncheck"
+	"golang.org/x/vuln/internal/osv"
+	"golang.org/x/vuln/internal/sarif"
+)
+
+// The values of the -upload flag.
+const (
+	uploadGitHub     = "github"
+	uploadDefectDojo = "defectdojo"
+	uploadWebhook    = "webhook"
+)
+
+// An uploader posts the results of a scan to a service, such as a
+// security dashboard, once the scan is complete.
+type uploader interface {
+	// upload posts r, printing where it was posted to w.
+	upload(ctx context.Context, r *uploadResults, w io.Writer) error
+}
+
+// newUploader returns the uploader for the -upload flag of cfg.
+// Uploaders are configured with environment variables, so that
+// tokens are not recorded with the flags of the scan.
+func newUploader(cfg *config) (uploader, error) {
+	switch cfg.upload {
+	case uploadGitHub:
+		return &githubUploader{cfg}, nil
+	case uploadDefectDojo:
+		return &defectDojoUploader{cfg}, nil
+	case uploadWebhook:
+		return &webhookUploader{cfg}, nil
+	}
+	return nil, fmt.Errorf("unsupported -upload value %q", cfg.upload)
+}
+
+// uploadResults are the messages of a scan that uploaders post.
+type uploadResults struct {
+	config   *govulncheck.Config
+	osvs     []*osv.Entry
+	findings []*govulncheck.Finding
+	diags    []*govulncheck.Diagnostic
+}
+
+// replay passes the messages of r on to h and flushes it.
+func (r *uploadResults) replay(h govulncheck.Handler) error {
+	cfg := r.config
+	if cfg == nil {
+		cfg = &govulncheck.Config{}
+	}
+	if err := h.Config(cfg); err != nil {
+		return err
+	}
+	for _, e := range r.osvs {
+		if err := h.OSV(e); err != nil {
+			return err
+		}
+	}
+	for _, f := range r.findings {
+		if err := h.Finding(f); err != nil {
+			return err
+		}
+	}
+	for _, d := range r.diags {
+		if err := h.Diagnostic(d); err != nil {
+			return err
+		}
+	}
+	return govulncheck.Flush(h)
+}
+
+// newUploadHandler returns a handler that passes messages on to h and
+// posts the results of the scan with u, printing where to w, when
+// flushed.
+func newUploadHandler(ctx context.Context, h govulncheck.Handler, u uploader, w io.Writer) *uploadHandler {
+	return &uploadHandler{
+		Wrapper:  govulncheck.Wrapper{Next: h},
+		ctx:      ctx,
+		uploader: u,
+		w:        w,
+		results:  &uploadResults{},
+	}
+}
+
+// uploadHandler collects the results of a scan to upload them.
+type uploadHandler struct {
+	govulncheck.Wrapper
+	ctx      context.Context
+	uploader uploader
+	w        io.Writer
+	results  *uploadResults
+}
+
+func (h *uploadHandler) Config(c *govulncheck.Config) error {
+	h.results.config = c
+	return h.Next.Config(c)
+}
+
+func (h *uploadHandler) OSV(e *osv.Entry) error {
+	h.results.osvs = append(h.results.osvs, e)
+	return h.Next.OSV(e)
+}
+
+func (h *uploadHandler) Finding(f *govulncheck.Finding) error {
+	h.results.findings = append(h.results.findings, f)
+	return h.Next.Finding(f)
+}
+
+func (h *uploadHandler) Diagnostic(d *govulncheck.Diagnostic) error {
+	h.results.diags = append(h.results.diags, d)
+	return h.Next.Diagnostic(d)
+}
+
+// Flush flushes the underlying handler, whose result may be an exit
+// code, before uploading the results, so that the output of the scan
+// is written even if the upload fails.
+func (h *uploadHandler) Flush() error {
+	err := h.Wrapper.Flush()
+	if uerr := h.uploader.upload(h.ctx, h.results, h.w); uerr != nil {
+		return errors.Join(err, uerr)
+	}
+	return err
+}
+
+// defaultGitHubAPI is the GitHub API used
+// unless GITHUB_API_URL is set.
+const defaultGitHubAPI = "https://api.github.com"
+
+// githubUploader uploads the results of a scan as SARIF to the code
+// scanning API of the GitHub repository named by GITHUB_REPOSITORY,
+// with the token in GITHUB_TOKEN. The results are for the commit and
+// ref in GITHUB_SHA and GITHUB_REF, as set in GitHub Actions, or else
+// for the commit and branch checked out in the directory of the scan.
+type githubUploader struct {
+	cfg *config
+}
+
+func (u *githubUploader) upload(ctx context.Context, r *uploadResults, w io.Writer) (err error) {
+	defer derrors.Wrap(&err, "uploading to GitHub code scanning")
+
+	env := lookupEnv(u.cfg.env)
+	token, _ := env("GITHUB_TOKEN")
+	if token == "" {
+		return errors.New("GITHUB_TOKEN is not set")
+	}
+	repo, _ := env("GITHUB_REPOSITORY")
+	if repo == "" {
+		return errors.New("GITHUB_REPOSITORY is not set")
+	}
+	commit, _ := env("GITHUB_SHA")
+	if commit == "" {
+		if commit, err = gitOutput(ctx, u.cfg, "rev-parse", "HEAD"); err != nil {
+			return err
+		}
+	}
+	ref, _ := env("GITHUB_REF")
+	if ref == "" {
+		if ref, err = gitOutput(ctx, u.cfg, "symbolic-ref", "HEAD"); err != nil {
+			return err
+		}
+	}
+	api, _ := env("GITHUB_API_URL")
+	if api == "" {
+		api = defaultGitHubAPI
+	}
+
+	var data bytes.Buffer
+	if err := r.replay(sarif.NewHandler(&data)); err != nil {
+		return err
+	}
+	sarif, err := encodeSARIF(data.Bytes())
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(map[string]string{
+		"commit_sha": commit,
+		"ref":        ref,
+		"sarif":      sarif,
+		"tool_name":  "govulncheck",
+	})
+	if err != nil {
+		return err
+	}
+	url := strings.TrimSuffix(api, "/") + "/repos/" + repo + "/code-scanning/sarifs"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	var result struct {
+		ID string `json:"id"`
+	}
+	if err := post(req, http.StatusAccepted, &result); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Uploaded the results to GitHub code scanning of %s for %s (upload %s).\n", repo, ref, result.ID)
+	return nil
+}
+
+// encodeSARIF returns data gzipped and encoded
+// in base64, as the code scanning API expects.
+func encodeSARIF(data []byte) (string, error) {
+	var b bytes.Buffer
+	zw := gzip.NewWriter(&b)
+	if _, err := zw.Write(data); err != nil {
+		return "", err
+	}
+	if err := zw.Close(); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(b.Bytes()), nil
+}
+
+// post sends req and decodes the JSON response into result, if not
+// nil. It fails unless the response has status code want, with the
+// message of the response if it has one.
+func post(req *http.Request, want int, result any) error {
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != want {
+		var e struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(body, &e) == nil && e.Message != "" {
+			return fmt.Errorf("%s: %s", resp.Status, e.Message)
+		}
+		return errors.New(resp.Status)
+	}
+	if result != nil {
+		// The response is only informative,
+		// so it may fail to decode.
+		_ = json.Unmarshal(body, result)
+	}
+	return nil
+}
+
+// gitOutput runs git with args in the directory of cfg and returns
+// its output without surrounding space.
+func gitOutput(ctx context.Context, cfg *config, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = cfg.dir
+	cmd.Env = cfg.env
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %v\n%s", strings.Join(args, " "), err, bytes.TrimSpace(stderr.Bytes()))
+	}
+	return string(bytes.TrimSpace(out)), nil
+}
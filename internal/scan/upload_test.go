@@ -0,0 +1,202 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/vuln/internal/govulncheck"
+	"golang.org/x/vuln/internal/sarif"
+)
+
+func TestGitHubUploader(t *testing.T) {
+	var got map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/repos/owner/repo/code-scanning/sarifs" {
+			t.Errorf("got request %s %s", r.Method, r.URL.Path)
+		}
+		if auth := r.Header.Get("Authorization"); auth != "Bearer secret" {
+			t.Errorf("got Authorization %q", auth)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Error(err)
+		}
+		w.WriteHeader(http.StatusAccepted)
+		io.WriteString(w, `{"id": "42", "url": "https://example.com/42"}`)
+	}))
+	defer srv.Close()
+
+	cfg := &config{env: []string{
+		"GITHUB_TOKEN=secret",
+		"GITHUB_REPOSITORY=owner/repo",
+		"GITHUB_SHA=0123456789abcdef",
+		"GITHUB_REF=refs/heads/main",
+		"GITHUB_API_URL=" + srv.URL + "/",
+	}}
+	r := &uploadResults{config: &govulncheck.Config{ScannerName: "govulncheck"}}
+	var out bytes.Buffer
+	if err := (&githubUploader{cfg}).upload(context.Background(), r, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "upload 42") {
+		t.Errorf("got output %q, want the upload ID", out.String())
+	}
+
+	// The SARIF is gzipped and encoded in base64.
+	data, err := base64.StdEncoding.DecodeString(got["sarif"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var log struct {
+		Version string `json:"version"`
+	}
+	if err := json.Unmarshal(decoded, &log); err != nil {
+		t.Fatalf("the uploaded SARIF does not decode: %v", err)
+	}
+	if log.Version != "2.1.0" {
+		t.Errorf("got SARIF version %q, want 2.1.0", log.Version)
+	}
+	delete(got, "sarif")
+	want := map[string]string{
+		"commit_sha": "0123456789abcdef",
+		"ref":        "refs/heads/main",
+		"tool_name":  "govulncheck",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("request mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestGitHubUploaderErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		io.WriteString(w, `{"message": "Resource not accessible by integration"}`)
+	}))
+	defer srv.Close()
+
+	env := []string{
+		"GITHUB_REPOSITORY=owner/repo",
+		"GITHUB_SHA=0123456789abcdef",
+		"GITHUB_REF=refs/heads/main",
+		"GITHUB_API_URL=" + srv.URL,
+	}
+	for _, test := range []struct {
+		name string
+		env  []string
+		want string
+	}{
+		{"no token", env, "GITHUB_TOKEN is not set"},
+		{"forbidden", append(env, "GITHUB_TOKEN=secret"), "403 Forbidden: Resource not accessible by integration"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			err := (&githubUploader{&config{env: test.env}}).upload(context.Background(), &uploadResults{}, io.Discard)
+			if err == nil || !strings.HasSuffix(err.Error(), test.want) {
+				t.Errorf("got error %v, want one ending in %q", err, test.want)
+			}
+		})
+	}
+}
+
+type fakeUploader struct {
+	results *uploadResults
+	err     error
+}
+
+func (u *fakeUploader) upload(_ context.Context, r *uploadResults, _ io.Writer) error {
+	u.results = r
+	return u.err
+}
+
+func TestUploadHandler(t *testing.T) {
+	errUpload := errors.New("upload failed")
+	for _, test := range []struct {
+		name      string
+		uploadErr error
+		flushErr  error
+		want      error
+	}{
+		{"ok", nil, nil, nil},
+		{"vulnerabilities found", nil, errVulnerabilitiesFound, errVulnerabilitiesFound},
+		{"upload failed", errUpload, errVulnerabilitiesFound, errUpload},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			u := &fakeUploader{err: test.uploadErr}
+			next := &flushHandler{Handler: govulncheck.NewJSONHandler(io.Discard), err: test.flushErr}
+			h := newUploadHandler(context.Background(), next, u, io.Discard)
+			f := &govulncheck.Finding{OSV: "GO-0000-0001", Trace: []*govulncheck.Frame{{Module: "example.com/m"}}}
+			if err := h.Finding(f); err != nil {
+				t.Fatal(err)
+			}
+			if err := h.Flush(); !errors.Is(err, test.want) {
+				t.Errorf("got error %v, want %v", err, test.want)
+			}
+			if u.results == nil || len(u.results.findings) != 1 {
+				t.Errorf("the finding was not uploaded")
+			}
+			if !next.flushed {
+				t.Errorf("the output was not flushed")
+			}
+		})
+	}
+}
+
+// flushHandler is a handler whose Flush returns err.
+type flushHandler struct {
+	govulncheck.Handler
+	err     error
+	flushed bool
+}
+
+func (h *flushHandler) Flush() error {
+	h.flushed = true
+	return h.err
+}
+
+func TestUploadHandlerOutput(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	cfg := &config{env: []string{
+		"GITHUB_TOKEN=secret",
+		"GITHUB_REPOSITORY=owner/repo",
+		"GITHUB_SHA=0123456789abcdef",
+		"GITHUB_REF=refs/heads/main",
+		"GITHUB_API_URL=" + srv.URL,
+	}}
+	var out bytes.Buffer
+	h := newUploadHandler(context.Background(), sarif.NewHandler(&out), &githubUploader{cfg}, io.Discard)
+	if err := h.Config(&govulncheck.Config{ScannerName: "govulncheck"}); err != nil {
+		t.Fatal(err)
+	}
+	err := h.Flush()
+	if want := "500 Internal Server Error"; err == nil || !strings.HasSuffix(err.Error(), want) {
+		t.Errorf("got error %v, want one ending in %q", err, want)
+	}
+	// The output of the scan is written even though the upload failed.
+	if !strings.Contains(out.String(), `"version": "2.1.0"`) {
+		t.Errorf("got output %q, want the SARIF log", out.String())
+	}
+}
@@ -5,6 +5,8 @@
 package scan
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -49,6 +51,24 @@ func moduleVersionString(modulePath, version string) string {
 	return version
 }
 
+// goListModules runs 'go list -m' with args in the directory of cfg and
+// returns its output without surrounding space. Errors include what the
+// go command printed.
+func goListModules(ctx context.Context, cfg *config, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "go", append([]string{"list", "-m"}, args...)...)
+	cmd.Dir = cfg.dir
+	cmd.Env = cfg.env
+	out, err := cmd.Output()
+	if err != nil {
+		var ee *exec.ExitError
+		if errors.As(err, &ee) && len(ee.Stderr) > 0 {
+			return "", errors.New(strings.TrimSpace(string(ee.Stderr)))
+		}
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
 func gomodExists(dir string) bool {
 	cmd := exec.Command("go", "env", "GOMOD")
 	cmd.Dir = dir
@@ -58,3 +78,18 @@ func gomodExists(dir string) bool {
 	// If module-aware mode is disabled, GOMOD will be the empty string.
 	return err == nil && !(output == os.DevNull || output == "")
 }
+
+// lookupEnv returns a function that looks up variables in env, like
+// os.LookupEnv does in the environment of the process. Later values
+// of a variable take precedence, as for the go command.
+func lookupEnv(env []string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		value, found := "", false
+		for _, e := range env {
+			if v, ok := strings.CutPrefix(e, name+"="); ok {
+				value, found = v, true
+			}
+		}
+		return value, found
+	}
+}
@@ -0,0 +1,346 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/vuln/internal/client"
+	"golang.org/x/vuln/internal/govulncheck"
+	"golang.org/x/vuln/internal/history"
+	"golang.org/x/vuln/internal/osv"
+)
+
+// WatchFlag is used for parsing and validation of
+// govulncheck -watch flag. It can be used as a boolean
+// flag, in which case only module files are watched.
+type WatchFlag string
+
+const (
+	watchOff     = ""
+	watchModules = "mod"
+	watchSource  = "src"
+)
+
+func (f *WatchFlag) IsBoolFlag() bool { return true }
+func (f *WatchFlag) Get() interface{} { return *f }
+func (f *WatchFlag) Set(s string) error {
+	switch s {
+	case "true", watchModules:
+		*f = watchModules
+	case "false":
+		*f = watchOff
+	case watchSource:
+		*f = watchSource
+	default:
+		return errFlagParse
+	}
+	return nil
+}
+func (f *WatchFlag) String() string { return string(*f) }
+
+// watchInterval is how often watched files are checked for changes.
+var watchInterval = time.Second
+
+// moduleFiles are the files that determine a module's dependencies.
+var moduleFiles = []string{"go.mod", "go.sum", "go.work", "go.work.sum"}
+
+// runWatch scans the module in dir, reporting the results to handler,
+// and then rescans it whenever its module files (and, for -watch=src,
+// its Go source files) change, printing only the changes in findings
+// to w. It returns when ctx is done.
+//
+// Rescans reuse client, so unchanged database content is not downloaded
+// again.
+func runWatch(ctx context.Context, handler govulncheck.Handler, cfg *config, client *client.Client, dir string, w io.Writer) error {
+	source := cfg.watch == watchSource
+	stamps, err := watchStamps(dir, source)
+	if err != nil {
+		return err
+	}
+	prev := newFindingSet(cfg.ScanLevel)
+	if err := runSource(ctx, &teeHandler{handler, prev}, cfg, client, dir); err != nil {
+		return err
+	}
+	if err := Flush(handler); err != nil && !errors.Is(err, errVulnerabilitiesFound) && !errors.Is(err, errPartialResults) {
+		return err
+	}
+	fmt.Fprintf(w, "\nWatching for changes. Press Ctrl+C to stop.\n")
+
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+	next := func() (map[string]fileStamp, error) { return watchStamps(dir, source) }
+	scan := func(ctx context.Context) (*findingSet, error) {
+		cur := newFindingSet(cfg.ScanLevel)
+		return cur, runSource(ctx, cur, cfg, client, dir)
+	}
+	watchLoop(ctx, ticker.C, stamps, next, scan, prev, w)
+	return nil
+}
+
+// watchLoop checks the watched files with next at each tick, starting
+// from stamps, and rescans them with scan once they have changed and
+// then stayed unchanged for a tick, so that a burst of changes, such as
+// an editor saving many files, causes a single rescan. A rescan that is
+// still running when files change again is canceled, and its changes
+// are rescanned with the new ones. The changes in findings since prev
+// are printed to w. It returns when ctx is done.
+func watchLoop(ctx context.Context, ticks <-chan time.Time, stamps map[string]fileStamp,
+	next func() (map[string]fileStamp, error), scan func(context.Context) (*findingSet, error),
+	prev *findingSet, w io.Writer) {
+	type result struct {
+		cur *findingSet
+		err error
+	}
+	// start runs scan in the background and returns the
+	// channel of its result, and a function to cancel it.
+	start := func() (chan result, context.CancelFunc) {
+		ctx, cancel := context.WithCancel(ctx)
+		results := make(chan result, 1)
+		go func() {
+			cur, err := scan(ctx)
+			results <- result{cur, err}
+		}()
+		return results, cancel
+	}
+	var (
+		pending  []string           // files changed since the running scan started
+		scanning []string           // files changed before the running scan started
+		results  chan result        // nil when no scan is running
+		cancel   context.CancelFunc // cancels the running scan
+		canceled bool               // whether the running scan was canceled
+	)
+	for {
+		select {
+		case <-ctx.Done():
+			if results != nil {
+				cancel()
+				<-results
+			}
+			return
+		case r := <-results:
+			cancel()
+			switch {
+			case canceled:
+				// Superseded by newer changes.
+			case r.err != nil:
+				fmt.Fprintf(w, "Scan failed: %v\n", r.err)
+			default:
+				printDelta(w, prev, r.cur)
+				prev = r.cur
+			}
+			results, scanning, canceled = nil, nil, false
+		case <-ticks:
+			cur, err := next()
+			if err != nil {
+				// Files may be in the middle of being rewritten.
+				continue
+			}
+			if changed := changedFiles(stamps, cur); len(changed) > 0 {
+				stamps = cur
+				pending = mergeFiles(pending, changed)
+				if results != nil && !canceled {
+					cancel()
+					canceled = true
+					pending = mergeFiles(pending, scanning)
+				}
+				continue
+			}
+			if len(pending) == 0 || results != nil {
+				continue
+			}
+			fmt.Fprintf(w, "\n%s changed, rescanning...\n", strings.Join(pending, ", "))
+			scanning, pending = pending, nil
+			results, cancel = start()
+		}
+	}
+}
+
+// mergeFiles returns the sorted union of the sorted paths a and b.
+func mergeFiles(a, b []string) []string {
+	merged := append(slices.Clone(a), b...)
+	slices.Sort(merged)
+	return slices.Compact(merged)
+}
+
+// fileStamp records the state of a watched file.
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// watchStamps returns the state of the watched files in dir, keyed by
+// slash-separated path relative to dir.
+func watchStamps(dir string, source bool) (map[string]fileStamp, error) {
+	stamps := make(map[string]fileStamp)
+	add := func(rel string, fi fs.FileInfo) {
+		stamps[rel] = fileStamp{modTime: fi.ModTime(), size: fi.Size()}
+	}
+	for _, name := range moduleFiles {
+		fi, err := os.Stat(filepath.Join(dir, name))
+		if err == nil {
+			add(name, fi)
+		} else if !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+	if !source {
+		return stamps, nil
+	}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		name := d.Name()
+		if d.IsDir() {
+			// Skip directories ignored by the go command.
+			if path != dir && (strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") || name == "testdata" || name == "vendor") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(name, ".go") {
+			return nil
+		}
+		fi, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		add(filepath.ToSlash(rel), fi)
+		return nil
+	})
+	return stamps, err
+}
+
+// changedFiles returns the sorted paths of files that were
+// added, removed, or modified between old and new.
+func changedFiles(old, new map[string]fileStamp) []string {
+	var changed []string
+	for name, s := range new {
+		if o, ok := old[name]; !ok || o != s {
+			changed = append(changed, name)
+		}
+	}
+	for name := range old {
+		if _, ok := new[name]; !ok {
+			changed = append(changed, name)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// findingSet is a handler that collects the findings
+// at a scan level, along with their OSV entries.
+type findingSet struct {
+	level    govulncheck.ScanLevel
+	findings map[history.Finding]bool
+	osvs     map[string]*osv.Entry
+}
+
+func newFindingSet(level govulncheck.ScanLevel) *findingSet {
+	return &findingSet{
+		level:    level,
+		findings: make(map[history.Finding]bool),
+		osvs:     make(map[string]*osv.Entry),
+	}
+}
+
+func (s *findingSet) Config(*govulncheck.Config) error         { return nil }
+func (s *findingSet) SBOM(*govulncheck.SBOM) error             { return nil }
+func (s *findingSet) Progress(*govulncheck.Progress) error     { return nil }
+func (s *findingSet) Diagnostic(*govulncheck.Diagnostic) error { return nil }
+
+func (s *findingSet) OSV(e *osv.Entry) error {
+	s.osvs[e.ID] = e
+	return nil
+}
+
+func (s *findingSet) Finding(f *govulncheck.Finding) error {
+	if findingLevel(f) == s.level {
+		s.findings[history.Finding{OSV: f.OSV, Module: f.Trace[0].Module, Version: f.Trace[0].Version}] = true
+	}
+	return nil
+}
+
+// minus returns the findings in s that are not in other, sorted.
+func (s *findingSet) minus(other *findingSet) []history.Finding {
+	var out []history.Finding
+	for f := range s.findings {
+		if !other.findings[f] {
+			out = append(out, f)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].OSV != out[j].OSV {
+			return out[i].OSV < out[j].OSV
+		}
+		return out[i].Module < out[j].Module
+	})
+	return out
+}
+
+// printDelta prints the findings that were added
+// and removed between the scans prev and cur.
+func printDelta(w io.Writer, prev, cur *findingSet) {
+	added, removed := cur.minus(prev), prev.minus(cur)
+	if len(added) == 0 && len(removed) == 0 {
+		fmt.Fprintln(w, "No changes in vulnerabilities.")
+		return
+	}
+	for _, f := range added {
+		summary := ""
+		if e := cur.osvs[f.OSV]; e != nil && e.Summary != "" {
+			summary = ": " + e.Summary
+		}
+		fmt.Fprintf(w, "+ %s in %s@%s%s\n", f.OSV, f.Module, f.Version, summary)
+	}
+	for _, f := range removed {
+		fmt.Fprintf(w, "- %s in %s@%s (no longer found)\n", f.OSV, f.Module, f.Version)
+	}
+}
+
+// teeHandler passes messages on to two handlers.
+type teeHandler struct {
+	a, b govulncheck.Handler
+}
+
+func (t *teeHandler) Config(c *govulncheck.Config) error {
+	return errors.Join(t.a.Config(c), t.b.Config(c))
+}
+
+func (t *teeHandler) SBOM(s *govulncheck.SBOM) error {
+	return errors.Join(t.a.SBOM(s), t.b.SBOM(s))
+}
+
+func (t *teeHandler) Progress(p *govulncheck.Progress) error {
+	return errors.Join(t.a.Progress(p), t.b.Progress(p))
+}
+
+func (t *teeHandler) OSV(e *osv.Entry) error {
+	return errors.Join(t.a.OSV(e), t.b.OSV(e))
+}
+
+func (t *teeHandler) Finding(f *govulncheck.Finding) error {
+	return errors.Join(t.a.Finding(f), t.b.Finding(f))
+}
+
+func (t *teeHandler) Diagnostic(d *govulncheck.Diagnostic) error {
+	return errors.Join(t.a.Diagnostic(d), t.b.Diagnostic(d))
+}
@@ -0,0 +1,221 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scan
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/vuln/internal/govulncheck"
+	"golang.org/x/vuln/internal/osv"
+)
+
+func TestWatchFlag(t *testing.T) {
+	for in, want := range map[string]WatchFlag{
+		"true":  watchModules,
+		"mod":   watchModules,
+		"src":   watchSource,
+		"false": watchOff,
+	} {
+		var f WatchFlag
+		if err := f.Set(in); err != nil || f != want {
+			t.Errorf("Set(%q) = %v, %q; want nil, %q", in, err, f, want)
+		}
+	}
+	var f WatchFlag
+	if err := f.Set("all"); err == nil {
+		t.Error(`Set("all") succeeded unexpectedly`)
+	}
+}
+
+func TestWatchStamps(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string, mod time.Time) {
+		t.Helper()
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(p, mod, mod); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	write("go.mod", "module m\n", t0)
+	write("main.go", "package main\n", t0)
+	write("testdata/x.go", "package x\n", t0)
+
+	stamps := func(source bool) map[string]fileStamp {
+		t.Helper()
+		s, err := watchStamps(dir, source)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	mods, srcs := stamps(false), stamps(true)
+	if got, want := len(mods), 1; got != want {
+		t.Errorf("watching module files: got %d files, want %d", got, want)
+	}
+	if got, want := len(srcs), 2; got != want {
+		t.Errorf("watching source files: got %d files, want %d", got, want)
+	}
+
+	t1 := t0.Add(time.Hour)
+	write("go.mod", "module m\n", t1)
+	write("go.sum", "", t1)
+	write("main.go", "package main\n\n", t1)
+	if diff := cmp.Diff([]string{"go.mod", "go.sum"}, changedFiles(mods, stamps(false))); diff != "" {
+		t.Errorf("changed module files mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"go.mod", "go.sum", "main.go"}, changedFiles(srcs, stamps(true))); diff != "" {
+		t.Errorf("changed source files mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestPrintDelta(t *testing.T) {
+	finding := func(id, mod, version, fn string) *govulncheck.Finding {
+		return &govulncheck.Finding{OSV: id, Trace: []*govulncheck.Frame{{
+			Module: mod, Version: version, Package: mod, Function: fn,
+		}}}
+	}
+	set := func(fs ...*govulncheck.Finding) *findingSet {
+		s := newFindingSet(govulncheck.ScanLevelSymbol)
+		s.OSV(&osv.Entry{ID: "GO-2024-0002", Summary: "Crash in b"})
+		for _, f := range fs {
+			s.Finding(f)
+		}
+		return s
+	}
+	prev := set(
+		finding("GO-2024-0001", "example.com/a", "v1.0.0", "F"),
+		finding("GO-2024-0003", "example.com/c", "v1.0.0", ""), // not called
+	)
+	cur := set(finding("GO-2024-0002", "example.com/b", "v0.1.0", "G"))
+
+	var buf bytes.Buffer
+	printDelta(&buf, prev, cur)
+	want := `+ GO-2024-0002 in example.com/b@v0.1.0: Crash in b
+- GO-2024-0001 in example.com/a@v1.0.0 (no longer found)
+`
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("printDelta mismatch (-want +got):\n%s", diff)
+	}
+
+	buf.Reset()
+	printDelta(&buf, cur, cur)
+	if got, want := buf.String(), "No changes in vulnerabilities.\n"; got != want {
+		t.Errorf("printDelta() = %q, want %q", got, want)
+	}
+}
+
+func TestWatchLoop(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ticks := make(chan time.Time)
+	stamps := make(chan map[string]fileStamp)
+	started := make(chan int)
+	var scans int
+	scan := func(ctx context.Context) (*findingSet, error) {
+		scans++
+		started <- scans
+		cur := newFindingSet(govulncheck.ScanLevelModule)
+		if scans == 1 {
+			// The first scan runs until it is superseded.
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		cur.Finding(&govulncheck.Finding{OSV: "GO-2024-0001", Trace: []*govulncheck.Frame{{Module: "example.com/a", Version: "v1.0.0"}}})
+		return cur, nil
+	}
+	out := make(chanWriter, 100)
+	done := make(chan struct{})
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	go func() {
+		watchLoop(ctx, ticks, map[string]fileStamp{"go.mod": {t0, 1}}, func() (map[string]fileStamp, error) {
+			return <-stamps, nil
+		}, scan, newFindingSet(govulncheck.ScanLevelModule), out)
+		close(done)
+	}()
+	// tick checks the files, which are in the given state, and
+	// returns the number of the scan that started, or 0 if none did.
+	tick := func(files map[string]fileStamp) int {
+		ticks <- t0
+		stamps <- files
+		select {
+		case n := <-started:
+			return n
+		case <-time.After(10 * time.Millisecond):
+			return 0
+		}
+	}
+	s1 := map[string]fileStamp{"go.mod": {t0, 2}}
+	s2 := map[string]fileStamp{"go.mod": {t0, 2}, "go.sum": {t0, 1}}
+	if n := tick(s1); n != 0 {
+		t.Fatalf("scan %d started before the files settled", n)
+	}
+	n := tick(s1)
+	if n == 0 {
+		// The scan may be slow to start.
+		select {
+		case n = <-started:
+		case <-time.After(10 * time.Second):
+		}
+	}
+	if n != 1 {
+		t.Fatalf("got scan %d, want scan 1 once the files settled", n)
+	}
+	if n := tick(s2); n != 0 {
+		t.Fatalf("scan %d started while the files changed", n)
+	}
+	// The first scan is canceled; the second one starts once the
+	// files have settled and the first scan has returned.
+	for i := 0; ; i++ {
+		if i == 100 {
+			t.Fatal("the second scan did not start")
+		}
+		if n := tick(s2); n == 2 {
+			break
+		}
+	}
+	// Wait for the second scan to report its results.
+	var got string
+	for !strings.Contains(got, "GO-2024-0001") {
+		select {
+		case w := <-out:
+			got += w
+		case <-time.After(10 * time.Second):
+			t.Fatalf("the second scan did not report its results:\n%s", got)
+		}
+	}
+	cancel()
+	<-done
+	want := `
+go.mod changed, rescanning...
+
+go.mod, go.sum changed, rescanning...
++ GO-2024-0001 in example.com/a@v1.0.0
+`
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("output mismatch (-want +got):\n%s", diff)
+	}
+}
+
+// chanWriter sends what is written to it on the channel.
+type chanWriter chan string
+
+func (w chanWriter) Write(b []byte) (int, error) {
+	w <- string(b)
+	return len(b), nil
+}
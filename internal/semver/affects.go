@@ -6,30 +6,132 @@ package semver
 
 import (
 	"sort"
+	"strings"
 
+	"golang.org/x/mod/module"
 	"golang.org/x/vuln/internal/osv"
 )
 
+// A Resolver maps the versions of non-SEMVER ranges, such as
+// git commit hashes, to Go module versions so that those ranges
+// can be ordered against the version being checked.
+type Resolver interface {
+	// Resolve returns the module version corresponding to version v
+	// of a range of type t, and whether v could be resolved.
+	Resolve(t osv.RangeType, v string) (string, bool)
+}
+
+// ResolverFunc is an adapter to allow the use of an ordinary
+// function as a Resolver.
+type ResolverFunc func(t osv.RangeType, v string) (string, bool)
+
+// Resolve calls f(t, v).
+func (f ResolverFunc) Resolve(t osv.RangeType, v string) (string, bool) {
+	return f(t, v)
+}
+
+// PseudoVersionResolver returns a Resolver that maps a git commit
+// hash to the first of versions that is a pseudo-version for that
+// commit. A version matches a commit if the revision encoded in the
+// pseudo-version is a prefix of the commit hash.
+func PseudoVersionResolver(versions ...string) Resolver {
+	return ResolverFunc(func(t osv.RangeType, v string) (string, bool) {
+		if t != osv.RangeTypeGit {
+			return "", false
+		}
+		for _, pv := range versions {
+			rev, err := module.PseudoVersionRev(canonicalizeSemverPrefix(pv))
+			if err == nil && rev != "" && strings.HasPrefix(v, rev) {
+				return pv, true
+			}
+		}
+		return "", false
+	})
+}
+
+// Affects reports whether module version v is in one of the ranges
+// in a. It is equivalent to AffectsResolved(a, v, nil).
 func Affects(a []osv.Range, v string) bool {
+	return AffectsResolved(a, v, nil)
+}
+
+// AffectsResolved reports whether module version v is in one of the
+// ranges in a.
+//
+// SEMVER ranges are always evaluated. ECOSYSTEM ranges are evaluated
+// if each of their versions is a valid module version or can be
+// resolved to one with r. GIT ranges are evaluated only if r resolves
+// each of their commits. If r is nil, no versions are resolved.
+//
+// If a has no ranges, or none of them can be evaluated, all versions
+// are considered affected.
+func AffectsResolved(a []osv.Range, v string, r Resolver) bool {
 	if len(a) == 0 {
 		// No ranges implies all versions are affected
 		return true
 	}
-	var semverRangePresent bool
-	for _, r := range a {
-		if r.Type != osv.RangeTypeSemver {
+	var evaluated bool
+	for _, ar := range a {
+		sr, ok := toSemverRange(ar, r)
+		if !ok {
 			continue
 		}
-		semverRangePresent = true
-		if ContainsSemver(r, v) {
+		evaluated = true
+		if ContainsSemver(sr, v) {
 			return true
 		}
 	}
-	// If there were no semver ranges present we
+	// If there were no ranges we could evaluate we
 	// assume that all semvers are affected, similarly
 	// to how to we assume all semvers are affected
 	// if there are no ranges at all.
-	return !semverRangePresent
+	return !evaluated
+}
+
+// Evaluable reports whether range ar can be evaluated by
+// AffectsResolved, using r to resolve its versions.
+func Evaluable(ar osv.Range, r Resolver) bool {
+	_, ok := toSemverRange(ar, r)
+	return ok
+}
+
+// toSemverRange converts ar into an equivalent SEMVER range, using r
+// to resolve versions that are not valid semantic versions. It
+// reports false if ar cannot be converted.
+func toSemverRange(ar osv.Range, r Resolver) (osv.Range, bool) {
+	switch ar.Type {
+	case osv.RangeTypeSemver:
+		return ar, true
+	case osv.RangeTypeEcosystem, osv.RangeTypeGit:
+	default:
+		return osv.Range{}, false
+	}
+	resolve := func(v string) (string, bool) {
+		if v == "" || v == "0" {
+			return v, true
+		}
+		if ar.Type == osv.RangeTypeEcosystem && Valid(v) {
+			return removeSemverPrefix(v), true
+		}
+		if r == nil {
+			return "", false
+		}
+		rv, ok := r.Resolve(ar.Type, v)
+		if !ok || !Valid(rv) {
+			return "", false
+		}
+		return removeSemverPrefix(rv), true
+	}
+	sr := osv.Range{Type: osv.RangeTypeSemver}
+	for _, e := range ar.Events {
+		in, ok1 := resolve(e.Introduced)
+		fix, ok2 := resolve(e.Fixed)
+		if !ok1 || !ok2 {
+			return osv.Range{}, false
+		}
+		sr.Events = append(sr.Events, osv.RangeEvent{Introduced: in, Fixed: fix})
+	}
+	return sr, true
 }
 
 // ContainsSemver checks if semver version v is in the
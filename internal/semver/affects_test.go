@@ -135,3 +135,139 @@ func TestAffectsSemver(t *testing.T) {
 		}
 	}
 }
+
+func TestAffectsResolved(t *testing.T) {
+	const (
+		commit1 = "1111111111112222222222222222222222222222"
+		commit2 = "3333333333334444444444444444444444444444"
+		pseudo1 = "v0.0.0-20230101000000-111111111111"
+		pseudo2 = "v0.0.0-20230201000000-333333333333"
+	)
+	gitRange := osv.Range{
+		Type:   osv.RangeTypeGit,
+		Repo:   "https://example.com/repo",
+		Events: []osv.RangeEvent{{Introduced: commit1}, {Fixed: commit2}},
+	}
+	cases := []struct {
+		name     string
+		affects  []osv.Range
+		version  string
+		resolver Resolver
+		want     bool
+	}{
+		{
+			name: "ecosystem range affected",
+			affects: []osv.Range{{Type: osv.RangeTypeEcosystem,
+				Events: []osv.RangeEvent{{Introduced: "v1.0.0"}, {Fixed: "v1.2.0"}}}},
+			version: "v1.1.0",
+			want:    true,
+		},
+		{
+			name: "ecosystem range not affected",
+			affects: []osv.Range{{Type: osv.RangeTypeEcosystem,
+				Events: []osv.RangeEvent{{Introduced: "0"}, {Fixed: "1.2.0"}}}},
+			version: "v1.2.0",
+			want:    false,
+		},
+		{
+			name: "unresolvable ecosystem range",
+			affects: []osv.Range{{Type: osv.RangeTypeEcosystem,
+				Events: []osv.RangeEvent{{Introduced: "0"}, {Fixed: "release-2"}}}},
+			version: "v1.2.0",
+			want:    true,
+		},
+		{
+			name:    "git range without resolver",
+			affects: []osv.Range{gitRange},
+			version: "v1.0.0",
+			want:    true,
+		},
+		{
+			name:     "git range resolved, affected",
+			affects:  []osv.Range{gitRange},
+			version:  pseudo1,
+			resolver: PseudoVersionResolver(pseudo1, pseudo2),
+			want:     true,
+		},
+		{
+			name:     "git range resolved, fixed",
+			affects:  []osv.Range{gitRange},
+			version:  pseudo2,
+			resolver: PseudoVersionResolver(pseudo1, pseudo2),
+			want:     false,
+		},
+		{
+			name:     "git range partially resolved",
+			affects:  []osv.Range{gitRange},
+			version:  pseudo2,
+			resolver: PseudoVersionResolver(pseudo2),
+			want:     true,
+		},
+		{
+			name:    "semver range wins over unresolvable git range",
+			affects: []osv.Range{gitRange, {Type: osv.RangeTypeSemver, Events: []osv.RangeEvent{{Introduced: "2.0.0"}}}},
+			version: "v1.0.0",
+			want:    false,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := AffectsResolved(c.affects, c.version, c.resolver); got != c.want {
+				t.Errorf("AffectsResolved(%s) = %t, want %t", c.version, got, c.want)
+			}
+		})
+	}
+}
+
+func TestEvaluable(t *testing.T) {
+	const commit = "1111111111112222222222222222222222222222"
+	gitRange := osv.Range{
+		Type:   osv.RangeTypeGit,
+		Events: []osv.RangeEvent{{Introduced: "0"}, {Fixed: commit}},
+	}
+	cases := []struct {
+		name     string
+		r        osv.Range
+		resolver Resolver
+		want     bool
+	}{
+		{
+			name: "semver range",
+			r:    osv.Range{Type: osv.RangeTypeSemver, Events: []osv.RangeEvent{{Introduced: "0"}, {Fixed: "1.2.0"}}},
+			want: true,
+		},
+		{
+			name: "ecosystem range of semantic versions",
+			r:    osv.Range{Type: osv.RangeTypeEcosystem, Events: []osv.RangeEvent{{Introduced: "v1.0.0"}}},
+			want: true,
+		},
+		{
+			name: "ecosystem range of other versions",
+			r:    osv.Range{Type: osv.RangeTypeEcosystem, Events: []osv.RangeEvent{{Fixed: "release-2"}}},
+			want: false,
+		},
+		{
+			name: "git range without resolver",
+			r:    gitRange,
+			want: false,
+		},
+		{
+			name:     "git range with resolver",
+			r:        gitRange,
+			resolver: PseudoVersionResolver("v0.0.0-20230101000000-111111111111"),
+			want:     true,
+		},
+		{
+			name: "unknown range type",
+			r:    osv.Range{Type: "UNKNOWN"},
+			want: false,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := Evaluable(c.r, c.resolver); got != c.want {
+				t.Errorf("Evaluable() = %t, want %t", got, c.want)
+			}
+		})
+	}
+}
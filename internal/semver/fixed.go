@@ -9,8 +9,15 @@ import "golang.org/x/vuln/internal/osv"
 // NonSupersededFix returns a fixed version from ranges
 // that is not superseded by any other fix or any other
 // introduction of a vulnerability. Returns "" in case
-// there is no such fixed version.
+// there is no such fixed version, or if any of the ranges
+// is not a SEMVER range, as its versions cannot be ordered
+// against the fix without resolving them.
 func NonSupersededFix(ranges []osv.Range) string {
+	for _, r := range ranges {
+		if r.Type != osv.RangeTypeSemver {
+			return ""
+		}
+	}
 	var latestFixed string
 	for _, r := range ranges {
 		if r.Type == "SEMVER" {
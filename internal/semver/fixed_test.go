@@ -127,6 +127,24 @@ func TestNonSupersededFix(t *testing.T) {
 			}},
 			want: "0.0.0-20240824120805-hij",
 		},
+		{
+			name: "git range",
+			ranges: []osv.Range{{
+				Type: osv.RangeTypeSemver,
+				Events: []osv.RangeEvent{
+					{Introduced: "0"},
+					{Fixed: "1.0.4"},
+				},
+			}, {
+				Type: osv.RangeTypeGit,
+				Repo: "https://example.com/repo",
+				Events: []osv.RangeEvent{
+					{Introduced: "abc"},
+					{Fixed: "def"},
+				},
+			}},
+			want: "",
+		},
 	}
 
 	for _, test := range tests {
@@ -16,11 +16,12 @@ import (
 //
 // For use in tests.
 type MockHandler struct {
-	ConfigMessages   []*govulncheck.Config
-	SBOMMessages     []*govulncheck.SBOM
-	ProgressMessages []*govulncheck.Progress
-	OSVMessages      []*osv.Entry
-	FindingMessages  []*govulncheck.Finding
+	ConfigMessages     []*govulncheck.Config
+	SBOMMessages       []*govulncheck.SBOM
+	ProgressMessages   []*govulncheck.Progress
+	OSVMessages        []*osv.Entry
+	FindingMessages    []*govulncheck.Finding
+	DiagnosticMessages []*govulncheck.Diagnostic
 }
 
 func NewMockHandler() *MockHandler {
@@ -52,6 +53,11 @@ func (h *MockHandler) Finding(finding *govulncheck.Finding) error {
 	return nil
 }
 
+func (h *MockHandler) Diagnostic(diagnostic *govulncheck.Diagnostic) error {
+	h.DiagnosticMessages = append(h.DiagnosticMessages, diagnostic)
+	return nil
+}
+
 func (h *MockHandler) Sort() {
 	sort.Slice(h.FindingMessages, func(i, j int) bool {
 		if h.FindingMessages[i].OSV > h.FindingMessages[j].OSV {
@@ -102,6 +108,11 @@ func (h *MockHandler) Write(to govulncheck.Handler) error {
 			return err
 		}
 	}
+	for _, diagnostic := range h.DiagnosticMessages {
+		if err := to.Diagnostic(diagnostic); err != nil {
+			return err
+		}
+	}
 	seen := map[string]bool{}
 	for _, finding := range h.FindingMessages {
 		if !seen[finding.OSV] {
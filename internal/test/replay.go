@@ -0,0 +1,147 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// An exchange is a request of a test and the response to it,
+// as recorded in a file for ReplayClient.
+type exchange struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Status int         `json:"status"`
+	Header http.Header `json:"header,omitempty"`
+	Body   []byte      `json:"body,omitempty"`
+}
+
+// ReplayClient returns an HTTP client for t that answers requests
+// with the responses recorded in file, without making any request,
+// so that tests of a database such as https://vuln.go.dev are hermetic.
+//
+// With record set, the client instead makes the requests and records
+// the responses to them in file at the end of t, replacing its previous
+// contents. Recording is meant to be done by hand, behind a test flag,
+// and the file checked in as testdata.
+//
+// Responses are replayed in the order they were recorded for each
+// method and URL. Requests without a recorded response fail.
+func ReplayClient(t testing.TB, file string, record bool) *http.Client {
+	t.Helper()
+	rt := &replayTransport{}
+	if record {
+		rt.base = http.DefaultTransport
+		t.Cleanup(func() {
+			if err := rt.save(file); err != nil {
+				t.Errorf("saving the recorded responses: %v", err)
+			}
+		})
+	} else {
+		b, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal(b, &rt.exchanges); err != nil {
+			t.Fatalf("%s: %v", file, err)
+		}
+	}
+	return &http.Client{Transport: rt}
+}
+
+// replayTransport records the responses of base, if set,
+// and otherwise replays its exchanges.
+type replayTransport struct {
+	base http.RoundTripper
+
+	mu        sync.Mutex
+	exchanges []*exchange
+	// replayed counts the exchanges already replayed, by method and URL.
+	replayed map[string]int
+}
+
+func (rt *replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if rt.base != nil {
+		return rt.record(req)
+	}
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	key := req.Method + " " + req.URL.String()
+	if rt.replayed == nil {
+		rt.replayed = make(map[string]int)
+	}
+	n := rt.replayed[key]
+	for _, e := range rt.exchanges {
+		if e.Method+" "+e.URL != key {
+			continue
+		}
+		if n > 0 {
+			n--
+			continue
+		}
+		rt.replayed[key]++
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", e.Status, http.StatusText(e.Status)),
+			StatusCode:    e.Status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        e.Header.Clone(),
+			Body:          io.NopCloser(bytes.NewReader(e.Body)),
+			ContentLength: int64(len(e.Body)),
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("no recorded response for %s", key)
+}
+
+// record makes req and records the response to it.
+func (rt *replayTransport) record(req *http.Request) (*http.Response, error) {
+	resp, err := rt.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	header := resp.Header.Clone()
+	// The date of the response would change the file
+	// with every recording.
+	header.Del("Date")
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	rt.exchanges = append(rt.exchanges, &exchange{
+		Method: req.Method,
+		URL:    req.URL.String(),
+		Status: resp.StatusCode,
+		Header: header,
+		Body:   body,
+	})
+	return resp, nil
+}
+
+func (rt *replayTransport) save(file string) error {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	b, err := json.MarshalIndent(rt.exchanges, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0777); err != nil {
+		return err
+	}
+	return os.WriteFile(file, append(b, '\n'), 0666)
+}
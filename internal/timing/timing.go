@@ -0,0 +1,83 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package timing records how long the phases of a scan take.
+package timing
+
+import (
+	"context"
+	"fmt"
+	"runtime/trace"
+	"strings"
+	"sync"
+	"time"
+)
+
+// A Recorder accumulates the durations of phases.
+// It is safe for concurrent use.
+type Recorder struct {
+	mu     sync.Mutex
+	phases []Phase
+}
+
+// A Phase is a named part of a scan and the total
+// time spent in it.
+type Phase struct {
+	Name     string
+	Duration time.Duration
+}
+
+type recorderKey struct{}
+
+// NewContext returns a context that carries r, so that
+// phases started with it are recorded in r.
+func NewContext(ctx context.Context, r *Recorder) context.Context {
+	return context.WithValue(ctx, recorderKey{}, r)
+}
+
+// Start starts the phase name, which ends when the returned
+// function is called. The phase is recorded in the Recorder of
+// ctx, if any, and is also a region of the execution trace.
+// The returned function must be called from the same goroutine.
+func Start(ctx context.Context, name string) (end func()) {
+	region := trace.StartRegion(ctx, name)
+	r, _ := ctx.Value(recorderKey{}).(*Recorder)
+	start := time.Now()
+	return func() {
+		region.End()
+		if r != nil {
+			r.add(name, time.Since(start))
+		}
+	}
+}
+
+func (r *Recorder) add(name string, d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.phases {
+		if r.phases[i].Name == name {
+			r.phases[i].Duration += d
+			return
+		}
+	}
+	r.phases = append(r.phases, Phase{name, d})
+}
+
+// Phases returns the recorded phases in the order
+// they were first ended.
+func (r *Recorder) Phases() []Phase {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Phase(nil), r.phases...)
+}
+
+// String returns a one line summary of the recorded phases,
+// such as "load 1.2s, build SSA 2.315s".
+func (r *Recorder) String() string {
+	var parts []string
+	for _, p := range r.Phases() {
+		parts = append(parts, fmt.Sprintf("%s %v", p.Name, p.Duration.Round(time.Millisecond)))
+	}
+	return strings.Join(parts, ", ")
+}
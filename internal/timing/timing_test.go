@@ -0,0 +1,40 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package timing
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRecorder(t *testing.T) {
+	r := &Recorder{}
+	ctx := NewContext(context.Background(), r)
+	for _, name := range []string{"load", "build", "load"} {
+		end := Start(ctx, name)
+		time.Sleep(time.Millisecond)
+		end()
+	}
+	// Phases without a recorder are not recorded.
+	Start(context.Background(), "output")()
+
+	phases := r.Phases()
+	if len(phases) != 2 || phases[0].Name != "load" || phases[1].Name != "build" {
+		t.Fatalf("got phases %v, want load and build", phases)
+	}
+	if phases[0].Duration < 2*time.Millisecond || phases[1].Duration < time.Millisecond {
+		t.Errorf("got durations %v, want at least 2ms and 1ms", phases)
+	}
+}
+
+func TestRecorderString(t *testing.T) {
+	r := &Recorder{}
+	r.add("load", 1200*time.Millisecond)
+	r.add("build SSA", 2314567*time.Microsecond)
+	if got, want := r.String(), "load 1.2s, build SSA 2.315s"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"golang.org/x/vuln/internal/client"
 	"golang.org/x/vuln/internal/govulncheck"
 	"golang.org/x/vuln/internal/semver"
+	"golang.org/x/vuln/internal/timing"
 )
 
 // Bin is an abstraction of Go binary containing
@@ -38,7 +39,7 @@ func Binary(ctx context.Context, handler govulncheck.Handler, bin *Bin, cfg *gov
 		return err
 	}
 	if cfg.ScanLevel.WantSymbols() {
-		return emitCallFindings(handler, binaryCallstacks(vr))
+		return emitCallFindings(handler, binaryCallstacks(vr), nil, govulncheck.PrecisionBinaryImprecise)
 	}
 	return nil
 }
@@ -51,7 +52,11 @@ func binary(ctx context.Context, handler govulncheck.Handler, bin *Bin, cfg *gov
 	mods := append(bin.Modules, graph.GetModule(internal.GoStdModulePath))
 
 	if bin.Main != nil {
-		mods = append(mods, bin.Main)
+		// Mark the main module so that frames in it
+		// are reported as such.
+		main := *bin.Main
+		main.Main = true
+		mods = append(mods, &main)
 	}
 
 	graph.AddModules(mods...)
@@ -73,6 +78,9 @@ func binary(ctx context.Context, handler govulncheck.Handler, bin *Bin, cfg *gov
 	if err := emitOSVs(handler, mv); err != nil {
 		return nil, err
 	}
+	if err := emitRangeDiagnostics(handler, mv); err != nil {
+		return nil, err
+	}
 
 	if err := handler.Progress(&govulncheck.Progress{Message: checkingBinVulnsMessage}); err != nil {
 		return nil, err
@@ -81,18 +89,25 @@ func binary(ctx context.Context, handler govulncheck.Handler, bin *Bin, cfg *gov
 	// Emit warning message for ancient Go binaries, defined as binaries
 	// built with Go version without support for debug.BuildInfo (< go1.18).
 	if semver.Valid(bin.GoVersion) && semver.Less(bin.GoVersion, "go1.18") {
-		p := &govulncheck.Progress{Message: fmt.Sprintf("warning: binary built with Go version %s, only standard library vulnerabilities will be checked", bin.GoVersion)}
-		if err := handler.Progress(p); err != nil {
+		d := &govulncheck.Diagnostic{
+			Kind:    govulncheck.DiagnosticBinaryBuildInfo,
+			Message: fmt.Sprintf("binary built with Go version %s, only standard library vulnerabilities will be checked", bin.GoVersion),
+		}
+		if err := handler.Diagnostic(d); err != nil {
 			return nil, err
 		}
 	}
 
 	if bin.GOOS == "" || bin.GOARCH == "" {
-		p := &govulncheck.Progress{Message: fmt.Sprintf("warning: failed to extract build system specification GOOS: %s GOARCH: %s\n", bin.GOOS, bin.GOARCH)}
-		if err := handler.Progress(p); err != nil {
+		d := &govulncheck.Diagnostic{
+			Kind:    govulncheck.DiagnosticBinaryBuildInfo,
+			Message: fmt.Sprintf("failed to extract build system specification GOOS: %s GOARCH: %s", bin.GOOS, bin.GOARCH),
+		}
+		if err := handler.Diagnostic(d); err != nil {
 			return nil, err
 		}
 	}
+	defer timing.Start(ctx, "matching")()
 	affVulns := affectingVulnerabilities(mv, bin.GOOS, bin.GOARCH)
 	if err := emitModuleFindings(handler, affVulns); err != nil {
 		return nil, err
@@ -109,6 +124,13 @@ func binary(ctx context.Context, handler govulncheck.Handler, bin *Bin, cfg *gov
 		// symbols for stripped binaries (see #57764), so we report
 		// vulnerabilities at the go.mod-level precision.
 		pkgSymbols = allKnownVulnerableSymbols(affVulns)
+		d := &govulncheck.Diagnostic{
+			Kind:    govulncheck.DiagnosticStrippedBinary,
+			Message: "binary has no symbol table, so vulnerable symbols are reported whether or not the binary contains them",
+		}
+		if err := handler.Diagnostic(d); err != nil {
+			return nil, err
+		}
 	} else {
 		pkgSymbols = packagesAndSymbols(bin)
 	}
@@ -116,7 +138,7 @@ func binary(ctx context.Context, handler govulncheck.Handler, bin *Bin, cfg *gov
 	impVulns := binImportedVulnPackages(graph, pkgSymbols, affVulns)
 	// Emit information on imported vulnerable packages now to
 	// mimic behavior of source.
-	if err := emitPackageFindings(handler, impVulns); err != nil {
+	if err := emitPackageFindings(handler, impVulns, govulncheck.PrecisionPackageImported); err != nil {
 		return nil, err
 	}
 
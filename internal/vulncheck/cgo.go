@@ -0,0 +1,102 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vulncheck
+
+import (
+	"sort"
+
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/vuln/internal/govulncheck"
+)
+
+// cgoMessage is the message of the diagnostic for a package that uses cgo.
+const cgoMessage = "package uses cgo: calls from its C code are not analyzed, so the vulnerabilities it imports are reported at package precision"
+
+// cgoPackages returns the packages that use cgo among the top packages
+// of graph and the packages they import, sorted by path. Packages of the
+// standard library are left out: their C code does not call the code of
+// other modules.
+//
+// The go command adds an import of runtime/cgo to the packages that it
+// processes with cgo, which is how they are told apart.
+func cgoPackages(graph *PackageGraph) []*packages.Package {
+	var pkgs []*packages.Package
+	seen := make(map[string]bool)
+	var visit func(*packages.Package)
+	visit = func(p *packages.Package) {
+		if seen[p.PkgPath] {
+			return
+		}
+		seen[p.PkgPath] = true
+		if _, ok := p.Imports["runtime/cgo"]; ok && !IsStdPackage(p.PkgPath) {
+			pkgs = append(pkgs, p)
+		}
+		for _, imp := range p.Imports {
+			visit(imp)
+		}
+	}
+	for _, p := range graph.TopPkgs() {
+		visit(p)
+	}
+	sort.Slice(pkgs, func(i, j int) bool { return pkgs[i].PkgPath < pkgs[j].PkgPath })
+	return pkgs
+}
+
+// cgoImported returns the vulnerabilities of vulns whose package is
+// one of cgoPkgs or is imported by one of them, directly or not, and
+// whose vulnerability and package are not those of one of reported.
+func cgoImported(vulns []*Vuln, cgoPkgs []*packages.Package, reported []*Vuln) []*Vuln {
+	if len(cgoPkgs) == 0 {
+		return nil
+	}
+	imported := make(map[string]bool)
+	var visit func(*packages.Package)
+	visit = func(p *packages.Package) {
+		if imported[p.PkgPath] {
+			return
+		}
+		imported[p.PkgPath] = true
+		for _, imp := range p.Imports {
+			visit(imp)
+		}
+	}
+	for _, p := range cgoPkgs {
+		visit(p)
+	}
+
+	type key struct{ id, pkg string }
+	seen := make(map[key]bool)
+	for _, v := range reported {
+		seen[key{v.OSV.ID, v.Package.PkgPath}] = true
+	}
+	var cgoVulns []*Vuln
+	for _, v := range vulns {
+		k := key{v.OSV.ID, v.Package.PkgPath}
+		if imported[v.Package.PkgPath] && !seen[k] {
+			seen[k] = true
+			cgoVulns = append(cgoVulns, v)
+		}
+	}
+	return cgoVulns
+}
+
+// emitCgoDiagnostics emits a diagnostic for each package of pkgs,
+// which use cgo.
+func emitCgoDiagnostics(handler govulncheck.Handler, pkgs []*packages.Package) error {
+	for _, p := range pkgs {
+		d := &govulncheck.Diagnostic{
+			Kind:    govulncheck.DiagnosticCgo,
+			Package: p.PkgPath,
+			Message: cgoMessage,
+		}
+		if p.Module != nil && !p.Module.Main {
+			d.Module = p.Module.Path
+		}
+		if err := handler.Diagnostic(d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vulncheck
+
+import (
+	"context"
+	"path"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/tools/go/packages/packagestest"
+	"golang.org/x/vuln/internal/client"
+	"golang.org/x/vuln/internal/govulncheck"
+	"golang.org/x/vuln/internal/osv"
+	"golang.org/x/vuln/internal/test"
+	"golang.org/x/vuln/internal/testenv"
+)
+
+func TestCgo(t *testing.T) {
+	testenv.NeedsGoBuild(t)
+
+	// The vulnerable symbol is only called from C code,
+	// through a function exported to it.
+	e := packagestest.Export(t, packagestest.Modules, []packagestest.Module{
+		{
+			Name: "golang.org/entry",
+			Files: map[string]interface{}{
+				"x/x.go": `
+			package main
+
+			/*
+			extern void callback(void);
+			static void run(void) { callback(); }
+			*/
+			import "C"
+
+			import "golang.org/vmod/vuln"
+
+			//export callback
+			func callback() {
+				vuln.V()
+			}
+
+			func main() {
+				C.run()
+			}`,
+			},
+		},
+		{
+			Name: "golang.org/vmod@v1.2.3",
+			Files: map[string]interface{}{"vuln/vuln.go": `
+			package vuln
+
+			func V() {}
+			`},
+		},
+	})
+	defer e.Cleanup()
+	e.Config.Env = append(e.Config.Env, "CGO_ENABLED=1")
+
+	c, err := client.NewInMemoryClient(
+		[]*osv.Entry{
+			{
+				ID: "V",
+				Affected: []osv.Affected{{
+					Module: osv.Module{Path: "golang.org/vmod"},
+					Ranges: []osv.Range{{Type: osv.RangeTypeSemver, Events: []osv.RangeEvent{{Introduced: "1.2.0"}}}},
+					EcosystemSpecific: osv.EcosystemSpecific{
+						Packages: []osv.Package{{
+							Path:    "golang.org/vmod/vuln",
+							Symbols: []string{"V"},
+						}},
+					},
+				}},
+			},
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	graph := NewPackageGraph("go1.18")
+	err = graph.LoadPackagesAndMods(e.Config, nil, []string{path.Join(e.Temp(), "entry/x")}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := test.NewMockHandler()
+	cfg := &govulncheck.Config{ScanLevel: "symbol"}
+	if err := Source(context.Background(), h, cfg, c, graph); err != nil {
+		t.Fatal(err)
+	}
+
+	wantDiags := []*govulncheck.Diagnostic{{
+		Kind:    govulncheck.DiagnosticCgo,
+		Package: "golang.org/entry/x",
+		Message: cgoMessage,
+	}}
+	if diff := cmp.Diff(wantDiags, h.DiagnosticMessages); diff != "" {
+		t.Errorf("diagnostics mismatch (-want, +got):\n%s", diff)
+	}
+
+	var got []govulncheck.Precision
+	for _, f := range h.FindingMessages {
+		got = append(got, f.Precision)
+	}
+	want := []govulncheck.Precision{
+		govulncheck.PrecisionModuleRequired,
+		govulncheck.PrecisionPackageImported,
+		govulncheck.PrecisionCgoImported,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("precisions mismatch (-want, +got):\n%s", diff)
+	}
+}
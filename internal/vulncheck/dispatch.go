@@ -0,0 +1,84 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vulncheck
+
+import (
+	"golang.org/x/vuln/internal/govulncheck"
+)
+
+// reflectivePackages are the standard library packages that call
+// methods of the values passed to them, which they inspect with
+// reflection, through interfaces such as fmt.Stringer or
+// json.Marshaler.
+var reflectivePackages = map[string]bool{
+	"reflect":       true,
+	"fmt":           true,
+	"encoding/gob":  true,
+	"encoding/json": true,
+	"encoding/xml":  true,
+	"html/template": true,
+	"text/template": true,
+}
+
+// lowConfidence returns the dispatches of the call stacks of the
+// vulnerabilities of res that are low-confidence paths: those whose
+// vulnerable symbol cannot be reached from the entry points of res
+// without calling a method through an interface.
+func lowConfidence(res *Result, callstacks map[*Vuln]CallStack) map[*Vuln]*govulncheck.Dispatch {
+	entries := make(map[*FuncNode]bool)
+	for _, e := range res.EntryFunctions {
+		entries[e] = true
+	}
+	dispatches := make(map[*Vuln]*govulncheck.Dispatch)
+	for v, stack := range callstacks {
+		if stack == nil || staticallyReachable(v.CallSink, entries) {
+			continue
+		}
+		if d := dispatchOf(stack); d != nil {
+			dispatches[v] = d
+		}
+	}
+	return dispatches
+}
+
+// staticallyReachable reports whether f is reachable
+// from entries without a call through an interface.
+func staticallyReachable(f *FuncNode, entries map[*FuncNode]bool) bool {
+	seen := map[*FuncNode]bool{f: true}
+	queue := []*FuncNode{f}
+	for len(queue) > 0 {
+		f := queue[0]
+		queue = queue[1:]
+		if entries[f] {
+			return true
+		}
+		for _, cs := range f.CallSites {
+			if cs.RecvType != "" || seen[cs.Parent] {
+				continue
+			}
+			seen[cs.Parent] = true
+			queue = append(queue, cs.Parent)
+		}
+	}
+	return false
+}
+
+// dispatchOf returns the dispatch of the first call through an interface
+// of stack, starting from the entry point, or nil if there is none. The
+// frame of the dispatch is its index in the trace of stack, which is in
+// reverse order.
+func dispatchOf(stack CallStack) *govulncheck.Dispatch {
+	for i, e := range stack {
+		if e.Call == nil || e.Call.RecvType == "" {
+			continue
+		}
+		kind := govulncheck.DispatchKind(govulncheck.DispatchInterface)
+		if p := e.Function.Package; p != nil && reflectivePackages[p.PkgPath] {
+			kind = govulncheck.DispatchReflection
+		}
+		return &govulncheck.Dispatch{Kind: kind, Frame: len(stack) - 1 - i}
+	}
+	return nil
+}
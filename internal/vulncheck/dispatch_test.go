@@ -0,0 +1,58 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vulncheck
+
+import (
+	"reflect"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/vuln/internal/govulncheck"
+	"golang.org/x/vuln/internal/osv"
+)
+
+func TestLowConfidence(t *testing.T) {
+	// Call graph structure for the test program
+	//    entry1       entry2
+	//      |            |
+	//    interm1    fmt.Println
+	//      :   \        :
+	//      :  interm2   :
+	//      :    |       :
+	//    vuln1  vuln2  vuln3
+	//
+	// where dotted edges are calls through interfaces.
+	o := &osv.Entry{ID: "o"}
+	fmtPkg := &packages.Package{PkgPath: "fmt"}
+	e1 := &FuncNode{Name: "entry1"}
+	e2 := &FuncNode{Name: "entry2"}
+	i1 := &FuncNode{Name: "interm1", CallSites: []*CallSite{{Parent: e1, Resolved: true}}}
+	i2 := &FuncNode{Name: "interm2", CallSites: []*CallSite{{Parent: i1, Resolved: true}}}
+	p := &FuncNode{Name: "Println", Package: fmtPkg, CallSites: []*CallSite{{Parent: e2, Resolved: true}}}
+	v1 := &FuncNode{Name: "vuln1", CallSites: []*CallSite{{Parent: i1, RecvType: "I"}}}
+	v2 := &FuncNode{Name: "vuln2", CallSites: []*CallSite{{Parent: i2, Resolved: true}}}
+	v3 := &FuncNode{Name: "vuln3", CallSites: []*CallSite{{Parent: p, RecvType: "fmt.Stringer"}}}
+
+	vp := &packages.Package{PkgPath: "v1", Module: &packages.Module{Path: "m1"}}
+	vuln1 := &Vuln{CallSink: v1, Package: vp, OSV: o, Symbol: "vuln1"}
+	vuln2 := &Vuln{CallSink: v2, Package: vp, OSV: o, Symbol: "vuln2"}
+	vuln3 := &Vuln{CallSink: v3, Package: vp, OSV: o, Symbol: "vuln3"}
+	res := &Result{
+		EntryFunctions: []*FuncNode{e1, e2},
+		Vulns:          []*Vuln{vuln1, vuln2, vuln3},
+	}
+
+	got := make(map[string]*govulncheck.Dispatch)
+	for v, d := range lowConfidence(res, sourceCallstacks(res)) {
+		got[v.Symbol] = d
+	}
+	want := map[string]*govulncheck.Dispatch{
+		"vuln1": {Kind: govulncheck.DispatchInterface, Frame: 1},
+		"vuln3": {Kind: govulncheck.DispatchReflection, Frame: 1},
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("want %v; got %v", want, got)
+	}
+}
@@ -5,13 +5,18 @@
 package vulncheck
 
 import (
+	"fmt"
 	"go/token"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
 
 	"golang.org/x/tools/go/packages"
+	"golang.org/x/vuln/internal"
 	"golang.org/x/vuln/internal/govulncheck"
+	"golang.org/x/vuln/internal/semver"
 )
 
 // emitOSVs emits all OSV vuln entries in modVulns to handler.
@@ -26,6 +31,48 @@ func emitOSVs(handler govulncheck.Handler, modVulns []*ModVulns) error {
 	return nil
 }
 
+// emitRangeDiagnostics emits a diagnostic for each vulnerability
+// in modVulns whose ranges for its module cannot all be evaluated.
+func emitRangeDiagnostics(handler govulncheck.Handler, modVulns []*ModVulns) error {
+	for _, mv := range modVulns {
+		for _, v := range mv.Vulns {
+			for _, a := range v.Affected {
+				if a.Module.Path != mv.Module.Path {
+					continue
+				}
+				var types []string
+				unsupported := 0
+				for _, r := range a.Ranges {
+					if semver.Evaluable(r, nil) {
+						continue
+					}
+					unsupported++
+					if !slices.Contains(types, string(r.Type)) {
+						types = append(types, string(r.Type))
+					}
+				}
+				if unsupported == 0 {
+					continue
+				}
+				effect := "they are ignored"
+				if unsupported == len(a.Ranges) {
+					effect = "all versions are considered affected"
+				}
+				d := &govulncheck.Diagnostic{
+					Kind:    govulncheck.DiagnosticUnsupportedRange,
+					OSV:     v.ID,
+					Module:  a.Module.Path,
+					Message: fmt.Sprintf("%s ranges cannot be evaluated, so %s", strings.Join(types, ", "), effect),
+				}
+				if err := handler.Diagnostic(d); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
 // emitModuleFindings emits module-level findings for vulnerabilities in modVulns.
 func emitModuleFindings(handler govulncheck.Handler, affVulns affectingVulns) error {
 	for _, vuln := range affVulns {
@@ -34,6 +81,7 @@ func emitModuleFindings(handler govulncheck.Handler, affVulns affectingVulns) er
 				OSV:          osv.ID,
 				FixedVersion: FixedVersion(modPath(vuln.Module), modVersion(vuln.Module), osv.Affected),
 				Trace:        []*govulncheck.Frame{frameFromModule(vuln.Module)},
+				Precision:    govulncheck.PrecisionModuleRequired,
 			}); err != nil {
 				return err
 			}
@@ -42,13 +90,15 @@ func emitModuleFindings(handler govulncheck.Handler, affVulns affectingVulns) er
 	return nil
 }
 
-// emitPackageFinding emits package-level findings fod vulnerabilities in vulns.
-func emitPackageFindings(handler govulncheck.Handler, vulns []*Vuln) error {
+// emitPackageFindings emits package-level findings, with the given
+// precision, for vulnerabilities in vulns.
+func emitPackageFindings(handler govulncheck.Handler, vulns []*Vuln, precision govulncheck.Precision) error {
 	for _, v := range vulns {
 		if err := handler.Finding(&govulncheck.Finding{
 			OSV:          v.OSV.ID,
 			FixedVersion: FixedVersion(modPath(v.Package.Module), modVersion(v.Package.Module), v.OSV.Affected),
 			Trace:        []*govulncheck.Frame{frameFromPackage(v.Package)},
+			Precision:    precision,
 		}); err != nil {
 			return err
 		}
@@ -56,13 +106,26 @@ func emitPackageFindings(handler govulncheck.Handler, vulns []*Vuln) error {
 	return nil
 }
 
-// emitCallFindings emits call-level findings for vulnerabilities
-// that have a call stack in callstacks.
-func emitCallFindings(handler govulncheck.Handler, callstacks map[*Vuln]CallStack) error {
+// emitCallFindings emits call-level findings, with the given
+// precision, for vulnerabilities that have a call stack in callstacks.
+// The findings of the vulnerabilities in dispatches are low-confidence
+// paths.
+func emitCallFindings(handler govulncheck.Handler, callstacks map[*Vuln]CallStack, dispatches map[*Vuln]*govulncheck.Dispatch, precision govulncheck.Precision) error {
 	var vulns []*Vuln
 	for v := range callstacks {
 		vulns = append(vulns, v)
 	}
+	// Sort for deterministic output.
+	sort.SliceStable(vulns, func(i, j int) bool {
+		vi, vj := vulns[i], vulns[j]
+		if vi.OSV.ID != vj.OSV.ID {
+			return vi.OSV.ID < vj.OSV.ID
+		}
+		if vi.Package.PkgPath != vj.Package.PkgPath {
+			return vi.Package.PkgPath < vj.Package.PkgPath
+		}
+		return vi.Symbol < vj.Symbol
+	})
 
 	for _, vuln := range vulns {
 		stack := callstacks[vuln]
@@ -74,6 +137,8 @@ func emitCallFindings(handler govulncheck.Handler, callstacks map[*Vuln]CallStac
 			OSV:          vuln.OSV.ID,
 			FixedVersion: fixed,
 			Trace:        traceFromEntries(stack),
+			Precision:    precision,
+			Dispatch:     dispatches[vuln],
 		}); err != nil {
 			return err
 		}
@@ -91,6 +156,7 @@ func traceFromEntries(vcs CallStack) []*govulncheck.Frame {
 		fr := frameFromPackage(e.Function.Package)
 		fr.Function = e.Function.Name
 		fr.Receiver = e.Function.Receiver()
+		fr.TypeArgs = e.Function.TypeArgs
 		isSink := i == (len(vcs) - 1)
 		fr.Position = posFromStackEntry(e, isSink)
 		frames = append(frames, fr)
@@ -175,6 +241,7 @@ func frameFromPackage(pkg *packages.Package) *govulncheck.Frame {
 		fr.Module = pkg.Module.Path
 		fr.Version = pkg.Module.Version
 		fr.Package = pkg.PkgPath
+		fr.Origin = moduleOrigin(pkg.Module)
 	}
 	if pkg.Module.Replace != nil {
 		fr.Module = pkg.Module.Replace.Path
@@ -187,6 +254,7 @@ func frameFromModule(mod *packages.Module) *govulncheck.Frame {
 	fr := &govulncheck.Frame{
 		Module:  mod.Path,
 		Version: mod.Version,
+		Origin:  moduleOrigin(mod),
 	}
 
 	if mod.Replace != nil {
@@ -196,3 +264,17 @@ func frameFromModule(mod *packages.Module) *govulncheck.Frame {
 
 	return fr
 }
+
+// moduleOrigin returns the origin of code in mod.
+func moduleOrigin(mod *packages.Module) govulncheck.Origin {
+	switch {
+	case mod == nil:
+		return ""
+	case mod.Path == internal.GoStdModulePath:
+		return govulncheck.OriginStdlib
+	case mod.Main:
+		return govulncheck.OriginMain
+	default:
+		return govulncheck.OriginDependency
+	}
+}
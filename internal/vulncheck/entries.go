@@ -5,8 +5,12 @@
 package vulncheck
 
 import (
+	"path"
+	"path/filepath"
+	"runtime"
 	"strings"
 
+	"golang.org/x/tools/go/packages"
 	"golang.org/x/tools/go/ssa"
 )
 
@@ -14,11 +18,19 @@ import (
 // points of govulncheck analysis: main, inits, and exported methods
 // and functions.
 //
+// For a library, the entry points are instead the inits and exported
+// methods and functions of its public packages, that is, the packages
+// that other modules can import: main and internal packages are left
+// out.
+//
 // TODO(https://go.dev/issue/57221): currently, entry functions
 // that are generics are not considered an entry point.
-func entryPoints(topPackages []*ssa.Package) []*ssa.Function {
+func entryPoints(topPackages []*ssa.Package, library bool) []*ssa.Function {
 	var entries []*ssa.Function
 	for _, pkg := range topPackages {
+		if library && (pkg.Pkg.Name() == "main" || isInternal(pkg.Pkg.Path())) {
+			continue
+		}
 		if pkg.Pkg.Name() == "main" {
 			// for "main" packages the only valid entry points are the "main"
 			// function and any "init#" functions, even if there are other
@@ -45,6 +57,13 @@ func entryPoints(topPackages []*ssa.Package) []*ssa.Function {
 	return entries
 }
 
+// isInternal reports whether the package with path pkg is an
+// internal package, which only its parent tree can import.
+func isInternal(pkg string) bool {
+	return pkg == "internal" || strings.HasPrefix(pkg, "internal/") ||
+		strings.HasSuffix(pkg, "/internal") || strings.Contains(pkg, "/internal/")
+}
+
 func isEntry(f *ssa.Function) bool {
 	// it should be safe to ignore checking that the signature of the "init" function
 	// is valid, since it is synthetic
@@ -54,3 +73,122 @@ func isEntry(f *ssa.Function) bool {
 
 	return f.Synthetic == "" && f.Object() != nil && f.Object().Exported()
 }
+
+// selectEntries returns the entries that match one of patterns. A
+// pattern matches the entry points of a package, such as
+// "example.com/m/api", or of the packages in a tree, such as
+// "example.com/m/api/...". Otherwise, it is matched with [path.Match]
+// against the qualified name of a function, such as
+// "example.com/m/api.Client.Get" or "example.com/m/cmd/tool.main",
+// so that "example.com/m/api.New*" matches the functions of api whose
+// names start with New.
+func selectEntries(entries []*ssa.Function, patterns []string) []*ssa.Function {
+	var selected []*ssa.Function
+	for _, f := range entries {
+		pkg := pkgPath(f)
+		name := pkg + "." + dbFuncName(f)
+		for _, pattern := range patterns {
+			if matchPackage(pattern, pkg) {
+				selected = append(selected, f)
+				break
+			}
+			if ok, _ := path.Match(pattern, name); ok {
+				selected = append(selected, f)
+				break
+			}
+		}
+	}
+	return selected
+}
+
+// matchPackage reports whether the package pattern, which may end
+// in "/...", matches the package path pkg.
+func matchPackage(pattern, pkg string) bool {
+	if prefix, ok := strings.CutSuffix(pattern, "/..."); ok {
+		return pkg == prefix || strings.HasPrefix(pkg, prefix+"/")
+	}
+	return pkg == pattern
+}
+
+// excludeEntries splits entries into those that are defined outside of
+// the directories matched by patterns and those that are defined in
+// them, such as generated code, which are not considered entry points.
+//
+// A pattern is matched against the directory of a package relative to
+// the root of its module. A pattern without a slash, such as "gen",
+// matches a directory of that name at any depth; otherwise, such as
+// "internal/*/gen", it matches a directory and its subdirectories. The
+// elements of a pattern use the syntax of [path.Match]. On Windows,
+// directories are matched regardless of case.
+func excludeEntries(entries []*ssa.Function, patterns []string, graph *PackageGraph) (included, excluded []*ssa.Function) {
+	isExcluded := make(map[*ssa.Package]bool)
+	for _, f := range entries {
+		ex, ok := isExcluded[f.Pkg]
+		if !ok {
+			ex = f.Pkg != nil && matchDir(packageDir(graph.GetPackage(f.Pkg.Pkg.Path())), patterns)
+			isExcluded[f.Pkg] = ex
+		}
+		if ex {
+			excluded = append(excluded, f)
+		} else {
+			included = append(included, f)
+		}
+	}
+	return included, excluded
+}
+
+// packageDir returns the directory of pkg relative to the root of
+// its module, using slashes, or "" if it is not known.
+func packageDir(pkg *packages.Package) string {
+	if pkg.Module == nil || len(pkg.Syntax) == 0 {
+		return ""
+	}
+	mod := pkg.Module
+	if mod.Replace != nil {
+		mod = mod.Replace // for replace directive
+	}
+	file := pkg.Fset.Position(pkg.Syntax[0].Package).Filename
+	rel, err := filepath.Rel(mod.Dir, filepath.Dir(file))
+	if err != nil || mod.Dir == "" {
+		return ""
+	}
+	return filepath.ToSlash(rel)
+}
+
+// foldDirs is whether directories are matched regardless of case, as
+// they are named on Windows file systems.
+var foldDirs = runtime.GOOS == "windows"
+
+// matchDir reports whether the slash-separated directory dir is
+// matched by one of patterns, as described in excludeEntries.
+func matchDir(dir string, patterns []string) bool {
+	if dir == "" || dir == "." || strings.HasPrefix(dir, "../") {
+		return false
+	}
+	if foldDirs {
+		dir = strings.ToLower(dir)
+	}
+	elems := strings.Split(dir, "/")
+	for _, pattern := range patterns {
+		pattern = strings.Trim(pattern, "/")
+		if foldDirs {
+			pattern = strings.ToLower(pattern)
+		}
+		if !strings.Contains(pattern, "/") {
+			for _, e := range elems {
+				if ok, _ := path.Match(pattern, e); ok {
+					return true
+				}
+			}
+			continue
+		}
+		n := strings.Count(pattern, "/") + 1
+		if n > len(elems) {
+			continue
+		}
+		if ok, _ := path.Match(pattern, strings.Join(elems[:n], "/")); ok {
+			return true
+		}
+	}
+	return false
+}
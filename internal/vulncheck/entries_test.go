@@ -0,0 +1,91 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vulncheck
+
+import "testing"
+
+func TestMatchDir(t *testing.T) {
+	patterns := []string{"gen", "third_party/", "internal/*/testdata"}
+	for _, test := range []struct {
+		dir  string
+		want bool
+	}{
+		{".", false},
+		{"", false},
+		{"gen", true},
+		{"api/gen", true},
+		{"api/gen/v1", true},
+		{"api/generated", false},
+		{"third_party", true},
+		{"third_party/x", true},
+		{"x/third_party", true},
+		{"internal/a/testdata", true},
+		{"internal/a/testdata/b", true},
+		{"internal/testdata", false},
+		{"x/internal/a/testdata", false},
+		{"../gen", false},
+	} {
+		if got := matchDir(test.dir, patterns); got != test.want {
+			t.Errorf("matchDir(%q) = %t, want %t", test.dir, got, test.want)
+		}
+	}
+}
+
+func TestMatchDirFold(t *testing.T) {
+	defer func(fold bool) { foldDirs = fold }(foldDirs)
+	patterns := []string{"Gen", "third_party/x"}
+	for _, fold := range []bool{false, true} {
+		foldDirs = fold
+		for _, test := range []struct {
+			dir  string
+			want bool
+		}{
+			{"Gen", true},
+			{"api/gen", fold},
+			{"Third_Party/X/y", fold},
+		} {
+			if got := matchDir(test.dir, patterns); got != test.want {
+				t.Errorf("foldDirs=%t: matchDir(%q) = %t, want %t", fold, test.dir, got, test.want)
+			}
+		}
+	}
+}
+
+func TestMatchPackage(t *testing.T) {
+	for _, test := range []struct {
+		pattern, pkg string
+		want         bool
+	}{
+		{"example.com/m/api", "example.com/m/api", true},
+		{"example.com/m/api", "example.com/m/api/v2", false},
+		{"example.com/m/api/...", "example.com/m/api", true},
+		{"example.com/m/api/...", "example.com/m/api/v2", true},
+		{"example.com/m/api/...", "example.com/m/apis", false},
+		{"example.com/m/api.New*", "example.com/m/api", false},
+	} {
+		if got := matchPackage(test.pattern, test.pkg); got != test.want {
+			t.Errorf("matchPackage(%q, %q) = %t, want %t", test.pattern, test.pkg, got, test.want)
+		}
+	}
+}
+
+func TestIsInternal(t *testing.T) {
+	for _, test := range []struct {
+		pkg  string
+		want bool
+	}{
+		{"internal", true},
+		{"internal/a", true},
+		{"example.com/m/internal", true},
+		{"example.com/m/internal/a", true},
+		{"example.com/m/a", false},
+		{"example.com/m/internals", false},
+		{"example.com/internalm/a", false},
+	} {
+		if got := isInternal(test.pkg); got != test.want {
+			t.Errorf("isInternal(%q) = %t, want %t", test.pkg, got, test.want)
+		}
+	}
+}
@@ -10,10 +10,12 @@ import (
 
 	"golang.org/x/tools/go/packages"
 	"golang.org/x/vuln/internal/client"
+	"golang.org/x/vuln/internal/timing"
 )
 
 // FetchVulnerabilities fetches vulnerabilities that affect the supplied modules.
 func FetchVulnerabilities(ctx context.Context, c *client.Client, modules []*packages.Module) ([]*ModVulns, error) {
+	defer timing.Start(ctx, "fetch")()
 	mreqs := make([]*client.ModuleRequest, len(modules))
 	for i, mod := range modules {
 		modPath := mod.Path
@@ -196,9 +196,11 @@ func (g *PackageGraph) GetPackage(path string) *packages.Package {
 
 // LoadPackages loads the packages specified by the patterns into the graph.
 // See golang.org/x/tools/go/packages.Load for details of how it works.
+// The build tags are passed to the go command along with the build
+// flags of cfg.
 func (g *PackageGraph) LoadPackagesAndMods(cfg *packages.Config, tags []string, patterns []string, wantSymbols bool) error {
 	if len(tags) > 0 {
-		cfg.BuildFlags = []string{fmt.Sprintf("-tags=%s", strings.Join(tags, ","))}
+		cfg.BuildFlags = append(cfg.BuildFlags, fmt.Sprintf("-tags=%s", strings.Join(tags, ",")))
 	}
 
 	addLoadMode(cfg, wantSymbols)
@@ -226,6 +228,51 @@ func (g *PackageGraph) LoadPackagesAndMods(cfg *packages.Config, tags []string,
 	return err
 }
 
+// RemoveBroken removes from the top-level packages of g those that
+// have errors, or that import packages with errors, since they cannot
+// be analyzed. It returns the packages with errors, sorted by path.
+func (g *PackageGraph) RemoveBroken() []*packages.Package {
+	// broken reports whether p or its imports have errors,
+	// memoized by package.
+	memo := make(map[*packages.Package]bool)
+	var broken func(p *packages.Package) bool
+	broken = func(p *packages.Package) bool {
+		if b, ok := memo[p]; ok {
+			return b
+		}
+		memo[p] = false // imports cycles are errors of their own
+		b := len(p.Errors) > 0
+		for _, i := range p.Imports {
+			b = broken(i) || b
+		}
+		memo[p] = b
+		return b
+	}
+	var tops []*packages.Package
+	for _, p := range g.topPkgs {
+		if !broken(p) {
+			tops = append(tops, p)
+		}
+	}
+	g.topPkgs = tops
+
+	var errPkgs []*packages.Package
+	for p := range memo {
+		if len(p.Errors) > 0 {
+			errPkgs = append(errPkgs, p)
+		}
+	}
+	slices.SortFunc(errPkgs, func(a, b *packages.Package) int {
+		return strings.Compare(a.PkgPath, b.PkgPath)
+	})
+	return errPkgs
+}
+
+// addLoadMode adds to cfg the load mode needed for a scan. Package
+// and module level scans only need the import graph and modules, which
+// the go command reports without type checking, or even compiling, any
+// package. Syntax and types are loaded only when wantSymbols is set,
+// since building SSA and the call graph needs them.
 func addLoadMode(cfg *packages.Config, wantSymbols bool) {
 	cfg.Mode |=
 		packages.NeedModule |
@@ -0,0 +1,231 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vulncheck
+
+import (
+	"go/constant"
+	"go/types"
+
+	"golang.org/x/tools/go/callgraph"
+	"golang.org/x/tools/go/ssa"
+)
+
+// refineCallGraph returns the part of cg that is reachable from
+// entries once calls that cannot happen are removed:
+//
+//   - calls in blocks of code ruled out by constant conditions, such
+//     as "if debug" with a constant debug or "if runtime.GOOS == os",
+//   - if invokes is set, calls of methods through interfaces to types
+//     that no reachable code converts to an interface.
+//
+// The second refinement is that of rapid type analysis. It assumes
+// that all values stored in interfaces come from the analyzed code,
+// which does not hold for the exported API of a package, so invokes
+// should only be set for programs.
+func refineCallGraph(cg *callgraph.Graph, entries []*ssa.Function, invokes bool) *callgraph.Graph {
+	r := &refiner{
+		cg:      cg,
+		invokes: invokes,
+		// Like cg, the refined graph has no root node.
+		refined: &callgraph.Graph{Nodes: make(map[*ssa.Function]*callgraph.Node)},
+		live:    make(map[*ssa.Function]map[*ssa.BasicBlock]bool),
+		types:   make(map[*types.TypeName]bool),
+		pending: make(map[*types.TypeName][]*callgraph.Edge),
+		reached: make(map[*ssa.Function]bool),
+	}
+	for _, e := range entries {
+		r.reach(e)
+	}
+	for len(r.queue) > 0 {
+		f := r.queue[0]
+		r.queue = r.queue[1:]
+		r.visit(f)
+	}
+	return r.refined
+}
+
+type refiner struct {
+	cg      *callgraph.Graph
+	invokes bool
+	refined *callgraph.Graph
+
+	// live holds the blocks of each function that
+	// constant conditions do not rule out.
+	live map[*ssa.Function]map[*ssa.BasicBlock]bool
+	// types are the named types converted to interfaces in reachable
+	// code. If allTypes is set, a type parameter is, and calls through
+	// interfaces are not refined anymore.
+	types    map[*types.TypeName]bool
+	allTypes bool
+	// pending are the calls through interfaces to methods of types
+	// that are not in types yet.
+	pending map[*types.TypeName][]*callgraph.Edge
+
+	reached map[*ssa.Function]bool
+	queue   []*ssa.Function
+}
+
+// reach marks f as reachable, to be visited.
+func (r *refiner) reach(f *ssa.Function) {
+	if r.reached[f] {
+		return
+	}
+	r.reached[f] = true
+	r.refined.CreateNode(f)
+	r.queue = append(r.queue, f)
+}
+
+// visit records the types that f converts to interfaces and
+// keeps the calls of f that are not ruled out.
+func (r *refiner) visit(f *ssa.Function) {
+	live := r.liveBlocks(f)
+	for b := range live {
+		for _, instr := range b.Instrs {
+			if mi, ok := instr.(*ssa.MakeInterface); ok {
+				r.addType(mi.X.Type())
+			}
+		}
+	}
+	n := r.cg.Nodes[f]
+	if n == nil {
+		return
+	}
+	for _, e := range n.Out {
+		if e.Site != nil && !live[e.Site.Block()] {
+			continue
+		}
+		if r.invokes && !r.allTypes && e.Site != nil && e.Site.Common().IsInvoke() {
+			if tn := receiverType(e.Callee.Func); tn != nil && !r.types[tn] {
+				r.pending[tn] = append(r.pending[tn], e)
+				continue
+			}
+		}
+		r.keep(e)
+	}
+}
+
+// keep adds e to the refined call graph.
+func (r *refiner) keep(e *callgraph.Edge) {
+	callgraph.AddEdge(r.refined.CreateNode(e.Caller.Func), e.Site, r.refined.CreateNode(e.Callee.Func))
+	r.reach(e.Callee.Func)
+}
+
+// addType records that values of type t are converted to interfaces,
+// and keeps the pending calls to its methods. Those include the
+// methods promoted from its embedded fields, whose types are
+// recorded too.
+func (r *refiner) addType(t types.Type) {
+	t = types.Unalias(t)
+	if p, ok := t.(*types.Pointer); ok {
+		t = types.Unalias(p.Elem())
+	}
+	switch t := t.(type) {
+	case *types.Named:
+		tn := t.Origin().Obj()
+		if r.types[tn] {
+			return
+		}
+		r.types[tn] = true
+		edges := r.pending[tn]
+		delete(r.pending, tn)
+		for _, e := range edges {
+			r.keep(e)
+		}
+		r.addEmbedded(t.Underlying())
+	case *types.Struct:
+		r.addEmbedded(t)
+	case *types.TypeParam:
+		// The type is only known in instances, so
+		// calls through interfaces cannot be refined.
+		r.allTypes = true
+		for tn, edges := range r.pending {
+			delete(r.pending, tn)
+			for _, e := range edges {
+				r.keep(e)
+			}
+		}
+	}
+}
+
+// addEmbedded records the types of the embedded fields of t,
+// if it is a struct.
+func (r *refiner) addEmbedded(t types.Type) {
+	s, ok := t.(*types.Struct)
+	if !ok {
+		return
+	}
+	for i := range s.NumFields() {
+		if f := s.Field(i); f.Embedded() {
+			r.addType(f.Type())
+		}
+	}
+}
+
+// liveBlocks returns the blocks of f that can be executed
+// when the branches of constant conditions are followed.
+func (r *refiner) liveBlocks(f *ssa.Function) map[*ssa.BasicBlock]bool {
+	if live, ok := r.live[f]; ok {
+		return live
+	}
+	live := make(map[*ssa.BasicBlock]bool)
+	var walk func(b *ssa.BasicBlock)
+	walk = func(b *ssa.BasicBlock) {
+		if live[b] {
+			return
+		}
+		live[b] = true
+		succs := b.Succs
+		if len(b.Instrs) > 0 {
+			if i, ok := b.Instrs[len(b.Instrs)-1].(*ssa.If); ok {
+				if c, ok := i.Cond.(*ssa.Const); ok && c.Value != nil && c.Value.Kind() == constant.Bool {
+					// Succs[0] is the branch of a true condition.
+					if constant.BoolVal(c.Value) {
+						succs = succs[:1]
+					} else {
+						succs = succs[1:]
+					}
+				}
+			}
+		}
+		for _, s := range succs {
+			walk(s)
+		}
+	}
+	if len(f.Blocks) > 0 {
+		walk(f.Blocks[0])
+	}
+	if f.Recover != nil {
+		walk(f.Recover)
+	}
+	r.live[f] = live
+	return live
+}
+
+// receiverType returns the named type of the receiver
+// of method f, or nil if f is not such a method.
+func receiverType(f *ssa.Function) *types.TypeName {
+	recv := f.Signature.Recv()
+	if recv == nil {
+		return nil
+	}
+	t := recv.Type()
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	if named, ok := types.Unalias(t).(*types.Named); ok {
+		return named.Origin().Obj()
+	}
+	return nil
+}
+
+// onlyMain reports whether all of pkgs are main packages.
+func onlyMain(pkgs []*ssa.Package) bool {
+	for _, pkg := range pkgs {
+		if pkg.Pkg.Name() != "main" {
+			return false
+		}
+	}
+	return true
+}
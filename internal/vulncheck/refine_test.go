@@ -0,0 +1,118 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vulncheck
+
+import (
+	"path"
+	"reflect"
+	"testing"
+
+	"golang.org/x/tools/go/callgraph/cha"
+	"golang.org/x/tools/go/packages/packagestest"
+	"golang.org/x/tools/go/ssa"
+	"golang.org/x/tools/go/ssa/ssautil"
+)
+
+func TestRefineCallGraph(t *testing.T) {
+	p := `
+package refine
+
+const debug = false
+
+type I interface {
+	Foo()
+}
+
+type A struct{}
+
+func (a A) Foo() {}
+
+// not reachable: A is only converted to I under debug
+func (a *A) Bar() {}
+
+type B struct{}
+
+func (b B) Foo() {}
+
+type E struct{ C }
+
+type C struct{}
+
+func (c C) Foo() {}
+
+func trace() {}
+
+func call(i I) {
+	i.Foo()
+}
+
+func Do() {
+	if debug {
+		trace()
+		call(A{})
+	}
+	call(B{})
+}
+
+func Embed() {
+	call(E{})
+}`
+
+	e := packagestest.Export(t, packagestest.Modules, []packagestest.Module{
+		{
+			Name:  "some/module",
+			Files: map[string]interface{}{"refine/refine.go": p},
+		},
+	})
+
+	graph := NewPackageGraph("go1.18")
+	err := graph.LoadPackagesAndMods(e.Config, nil, []string{path.Join(e.Temp(), "/module/refine")}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prog, ssaPkgs := ssautil.AllPackages(graph.TopPkgs(), 0)
+	prog.Build()
+	pkg := ssaPkgs[0]
+	cg := cha.CallGraph(prog)
+
+	for _, test := range []struct {
+		entries []string
+		invokes bool
+		want    []string
+	}{
+		{
+			entries: []string{"Do"},
+			invokes: true,
+			want:    []string{"Do", "call", "B.Foo"},
+		},
+		{
+			entries: []string{"Do"},
+			invokes: false,
+			want:    []string{"Do", "call", "A.Foo", "B.Foo", "C.Foo", "E.Foo"},
+		},
+		{
+			entries: []string{"Embed"},
+			invokes: true,
+			want:    []string{"Embed", "call", "C.Foo", "E.Foo"},
+		},
+	} {
+		var entries []*ssa.Function
+		for _, name := range test.entries {
+			entries = append(entries, pkg.Func(name))
+		}
+		refined := refineCallGraph(cg, entries, test.invokes)
+		got := make(map[string]bool)
+		for f := range refined.Nodes {
+			got[dbFuncName(f)] = true
+		}
+		want := make(map[string]bool)
+		for _, name := range test.want {
+			want[name] = true
+		}
+		if !reflect.DeepEqual(want, got) {
+			t.Errorf("entries %v, invokes %t: want %v; got %v", test.entries, test.invokes, want, got)
+		}
+	}
+}
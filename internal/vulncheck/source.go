@@ -14,6 +14,7 @@ import (
 	"golang.org/x/vuln/internal/client"
 	"golang.org/x/vuln/internal/govulncheck"
 	"golang.org/x/vuln/internal/osv"
+	"golang.org/x/vuln/internal/timing"
 )
 
 // Source detects vulnerabilities in pkgs and emits the findings to handler.
@@ -24,7 +25,22 @@ func Source(ctx context.Context, handler govulncheck.Handler, cfg *govulncheck.C
 	}
 
 	if cfg.ScanLevel.WantSymbols() {
-		return emitCallFindings(handler, sourceCallstacks(vr))
+		callstacks := sourceCallstacks(vr)
+		dispatches := lowConfidence(vr, callstacks)
+		if cfg.ExcludeLowConfidence {
+			// The package-level findings of these
+			// vulnerabilities are already emitted.
+			for v := range dispatches {
+				delete(callstacks, v)
+			}
+		}
+		if err := emitCallFindings(handler, callstacks, dispatches, govulncheck.PrecisionSymbolReachable); err != nil {
+			return err
+		}
+		if err := emitPackageFindings(handler, vr.ExcludedVulns, govulncheck.PrecisionExcludedReachable); err != nil {
+			return err
+		}
+		return emitPackageFindings(handler, vr.CgoVulns, govulncheck.PrecisionCgoImported)
 	}
 	return nil
 }
@@ -41,8 +57,9 @@ func source(ctx context.Context, handler govulncheck.Handler, cfg *govulncheck.C
 	// with fetching vulnerabilities. If the vulns set is empty, return without
 	// waiting for SSA construction or callgraph to finish.
 	var (
-		wg       sync.WaitGroup // guards entries, cg, and buildErr
+		wg       sync.WaitGroup // guards entries, excluded, cg, and buildErr
 		entries  []*ssa.Function
+		excluded []*ssa.Function
 		cg       *callgraph.Graph
 		buildErr error
 	)
@@ -51,9 +68,28 @@ func source(ctx context.Context, handler govulncheck.Handler, cfg *govulncheck.C
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
+			end := timing.Start(ctx, "build SSA")
 			prog, ssaPkgs := buildSSA(graph.TopPkgs(), fset)
-			entries = entryPoints(ssaPkgs)
+			entries = entryPoints(ssaPkgs, cfg.Library)
+			end()
+			end = timing.Start(ctx, "call graph")
+			// Excluded entry points are still part of the call graph,
+			// so that their findings can be told apart.
 			cg, buildErr = callGraph(ctx, prog, entries)
+			end()
+			if buildErr == nil && cfg.Deep {
+				end = timing.Start(ctx, "refine call graph")
+				// The exported functions of other packages are entry points,
+				// which may be called with values of any of their types.
+				cg = refineCallGraph(cg, entries, !cfg.Library && onlyMain(ssaPkgs))
+				end()
+			}
+			if len(cfg.Entries) > 0 {
+				entries = selectEntries(entries, cfg.Entries)
+			}
+			if len(cfg.ExcludeDirs) > 0 {
+				entries, excluded = excludeEntries(entries, cfg.ExcludeDirs, graph)
+			}
 		}()
 	}
 
@@ -74,12 +110,17 @@ func source(ctx context.Context, handler govulncheck.Handler, cfg *govulncheck.C
 	if err := emitOSVs(handler, mv); err != nil {
 		return nil, err
 	}
+	if err := emitRangeDiagnostics(handler, mv); err != nil {
+		return nil, err
+	}
 
 	if err := handler.Progress(&govulncheck.Progress{Message: checkingSrcVulnsMessage}); err != nil {
 		return nil, err
 	}
 
+	endMatching := timing.Start(ctx, "matching")
 	affVulns := affectingVulnerabilities(mv, "", "")
+	endMatching()
 	if err := emitModuleFindings(handler, affVulns); err != nil {
 		return nil, err
 	}
@@ -88,10 +129,12 @@ func source(ctx context.Context, handler govulncheck.Handler, cfg *govulncheck.C
 		return &Result{}, nil
 	}
 
+	endMatching = timing.Start(ctx, "matching")
 	impVulns := importedVulnPackages(affVulns, graph)
+	endMatching()
 	// Emit information on imported vulnerable packages now as
 	// call graph computation might take a while.
-	if err := emitPackageFindings(handler, impVulns); err != nil {
+	if err := emitPackageFindings(handler, impVulns, govulncheck.PrecisionPackageImported); err != nil {
 		return nil, err
 	}
 
@@ -101,13 +144,47 @@ func source(ctx context.Context, handler govulncheck.Handler, cfg *govulncheck.C
 		return &Result{Vulns: impVulns}, nil
 	}
 
+	// Calls from C code are not in the call graph,
+	// so symbols reached that way would be missed.
+	cgoPkgs := cgoPackages(graph)
+	if err := emitCgoDiagnostics(handler, cgoPkgs); err != nil {
+		return nil, err
+	}
+
 	wg.Wait() // wait for build to finish
 	if buildErr != nil {
-		return nil, err
+		return nil, buildErr
 	}
 
+	endMatching = timing.Start(ctx, "matching")
 	entryFuncs, callVulns := calledVulnSymbols(entries, affVulns, cg, graph)
-	return &Result{EntryFunctions: entryFuncs, Vulns: callVulns}, nil
+	var excludedVulns []*Vuln
+	if len(excluded) > 0 {
+		_, excludedVulns = calledVulnSymbols(excluded, affVulns, cg, graph)
+		excludedVulns = onlyExcluded(excludedVulns, callVulns)
+	}
+	cgoVulns := cgoImported(impVulns, cgoPkgs, append(callVulns, excludedVulns...))
+	endMatching()
+	return &Result{EntryFunctions: entryFuncs, Vulns: callVulns, ExcludedVulns: excludedVulns, CgoVulns: cgoVulns}, nil
+}
+
+// onlyExcluded returns the vulnerabilities of excluded, one per
+// vulnerability and package, that are not among called.
+func onlyExcluded(excluded, called []*Vuln) []*Vuln {
+	type key struct{ id, pkg string }
+	seen := make(map[key]bool)
+	for _, v := range called {
+		seen[key{v.OSV.ID, v.Package.PkgPath}] = true
+	}
+	var vulns []*Vuln
+	for _, v := range excluded {
+		k := key{v.OSV.ID, v.Package.PkgPath}
+		if !seen[k] {
+			seen[k] = true
+			vulns = append(vulns, v)
+		}
+	}
+	return vulns
 }
 
 // importedVulnPackages detects imported vulnerable packages.
@@ -293,9 +370,10 @@ func createNode(nodes map[*ssa.Function]*FuncNode, f *ssa.Function, graph *Packa
 		return fn
 	}
 	fn := &FuncNode{
-		Name:     f.Name(),
+		Name:     stripTypeArgs(f.Name()),
 		Package:  graph.GetPackage(pkgPath(f)),
-		RecvType: funcRecvType(f),
+		RecvType: stripTypeArgs(funcRecvType(f)),
+		TypeArgs: funcTypeArgs(f),
 		Pos:      funcPosition(f),
 	}
 	nodes[f] = fn
@@ -9,6 +9,8 @@ import (
 	"context"
 	"go/token"
 	"go/types"
+	"math"
+	"runtime/debug"
 	"sort"
 	"strings"
 
@@ -27,8 +29,15 @@ import (
 // buildSSA creates an ssa representation for pkgs. Returns
 // the ssa program encapsulating the packages and top level
 // ssa packages corresponding to pkgs.
+//
+// Packages are built one at a time when memory is limited, see
+// lowMemory.
 func buildSSA(pkgs []*packages.Package, fset *token.FileSet) (*ssa.Program, []*ssa.Package) {
-	prog := ssa.NewProgram(fset, ssa.InstantiateGenerics)
+	mode := ssa.InstantiateGenerics
+	if lowMemory() {
+		mode |= ssa.BuildSerially
+	}
+	prog := ssa.NewProgram(fset, mode)
 
 	imports := make(map[*packages.Package]*ssa.Package)
 	var createImports func(map[string]*packages.Package)
@@ -59,6 +68,14 @@ func buildSSA(pkgs []*packages.Package, fset *token.FileSet) (*ssa.Program, []*s
 	return prog, ssaPkgs
 }
 
+// lowMemory reports whether a memory limit was set for the Go
+// runtime, with the GOMEMLIMIT environment variable or the
+// -max-memory flag, in which case analyses prefer lower peak
+// memory use to speed.
+func lowMemory() bool {
+	return debug.SetMemoryLimit(-1) != math.MaxInt64
+}
+
 // callGraph builds a call graph of prog based on VTA analysis.
 func callGraph(ctx context.Context, prog *ssa.Program, entries []*ssa.Function) (*callgraph.Graph, error) {
 	entrySlice := make(map[*ssa.Function]bool)
@@ -223,6 +240,39 @@ func funcRecvType(f *ssa.Function) string {
 	return buf.String()
 }
 
+// funcTypeArgs returns the type arguments of f,
+// if f is an instantiation of a generic function.
+func funcTypeArgs(f *ssa.Function) []string {
+	var targs []string
+	for _, t := range f.TypeArgs() {
+		targs = append(targs, types.TypeString(t, nil))
+	}
+	return targs
+}
+
+// stripTypeArgs removes the type arguments from the name of an
+// instantiated function or type, such as "Map[int string]$1" or
+// "*example.com/p.List[int]".
+func stripTypeArgs(name string) string {
+	i := strings.Index(name, "[")
+	if i < 0 {
+		return name
+	}
+	depth := 0
+	for j := i; j < len(name); j++ {
+		switch name[j] {
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				return name[:i] + name[j+1:]
+			}
+		}
+	}
+	return name
+}
+
 func FixedVersion(modulePath, version string, affected []osv.Affected) string {
 	fixed := earliestValidFix(modulePath, version, affected)
 	// Add "v" prefix if one does not exist. moduleVersionString
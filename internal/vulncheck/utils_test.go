@@ -5,11 +5,19 @@
 package vulncheck
 
 import (
+	"fmt"
+	"math"
 	"path"
+	"runtime"
+	"runtime/debug"
+	"runtime/metrics"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"golang.org/x/tools/go/packages"
 	"golang.org/x/tools/go/packages/packagestest"
+	"golang.org/x/tools/go/ssa"
 	"golang.org/x/tools/go/ssa/ssautil"
 	"golang.org/x/vuln/internal/osv"
 )
@@ -254,3 +262,140 @@ func TestDbSymbolName(t *testing.T) {
 		t.Errorf("(-want;got+): %s", diff)
 	}
 }
+
+func TestStripTypeArgs(t *testing.T) {
+	for _, test := range []struct {
+		in, want string
+	}{
+		{"Foo", "Foo"},
+		{"Map[int string]", "Map"},
+		{"Map[int string]$1", "Map$1"},
+		{"*example.com/p.List[example.com/p.Pair[int, string]]", "*example.com/p.List"},
+		{"Bad[int", "Bad[int"},
+	} {
+		if got := stripTypeArgs(test.in); got != test.want {
+			t.Errorf("stripTypeArgs(%q) = %q, want %q", test.in, got, test.want)
+		}
+	}
+}
+
+func TestInstanceNodes(t *testing.T) {
+	e := packagestest.Export(t, packagestest.Modules, []packagestest.Module{
+		{
+			Name: "golang.org/package",
+			Files: map[string]interface{}{
+				"x/x.go": `
+			package x
+
+			func Foo() {
+				b := B[a]{}
+				b.P()
+				Z[int, a]()
+			}
+
+			type a struct{}
+
+			type B[T any] struct{}
+
+			func (b *B[T]) P() {}
+
+			func Z[T, U any]() {}
+			`},
+		},
+	})
+	defer e.Cleanup()
+
+	graph := NewPackageGraph("go1.18")
+	err := graph.LoadPackagesAndMods(e.Config, nil, []string{path.Join(e.Temp(), "package/x")}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]bool{
+		"*golang.org/package/x.B.P [golang.org/package/x.a]": true,
+		"Z [int golang.org/package/x.a]":                     true,
+	}
+
+	prog, _ := buildSSA(graph.TopPkgs(), graph.TopPkgs()[0].Fset)
+	nodes := make(map[*ssa.Function]*FuncNode)
+	got := make(map[string]bool)
+	for f := range ssautil.AllFunctions(prog) {
+		fn := createNode(nodes, f, graph)
+		if len(fn.TypeArgs) == 0 {
+			continue
+		}
+		name := fn.Name
+		if fn.RecvType != "" {
+			name = fn.RecvType + "." + name
+		}
+		got[fmt.Sprintf("%s %v", name, fn.TypeArgs)] = true
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("(-want;got+): %s", diff)
+	}
+}
+
+// BenchmarkBuildSSA reports the peak heap growth while building SSA
+// for this package, and the heap retained afterwards, without and
+// with a memory limit.
+func BenchmarkBuildSSA(b *testing.B) {
+	for _, bm := range []struct {
+		name  string
+		limit int64
+	}{
+		{"unlimited", math.MaxInt64},
+		{"limited", 640 << 20},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			defer debug.SetMemoryLimit(debug.SetMemoryLimit(bm.limit))
+			var peak, live uint64
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				graph := NewPackageGraph("")
+				if err := graph.LoadPackagesAndMods(&packages.Config{}, nil, []string{"."}, true); err != nil {
+					b.Fatal(err)
+				}
+				runtime.GC()
+				base := heapObjects()
+				b.StartTimer()
+
+				done := make(chan bool)
+				maxc := make(chan uint64)
+				go func() {
+					var maxMem uint64
+					for {
+						if h := heapObjects(); h > maxMem {
+							maxMem = h
+						}
+						select {
+						case <-done:
+							maxc <- maxMem
+							return
+						case <-time.After(time.Millisecond):
+						}
+					}
+				}()
+				prog, _ := buildSSA(graph.TopPkgs(), graph.TopPkgs()[0].Fset)
+				close(done)
+				peak += <-maxc - base
+
+				b.StopTimer()
+				runtime.GC()
+				live += heapObjects()
+				runtime.KeepAlive(prog)
+				runtime.KeepAlive(graph)
+				b.StartTimer()
+			}
+			b.ReportMetric(float64(peak)/float64(b.N)/1e6, "peak-MB")
+			b.ReportMetric(float64(live)/float64(b.N)/1e6, "live-MB")
+		})
+	}
+}
+
+// heapObjects returns the number of bytes in heap objects,
+// without stopping the world.
+func heapObjects() uint64 {
+	s := []metrics.Sample{{Name: "/memory/classes/heap/objects:bytes"}}
+	metrics.Read(s)
+	return s[0].Value.Uint64()
+}
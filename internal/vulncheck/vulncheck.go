@@ -31,6 +31,17 @@ type Result struct {
 
 	// Vulns contains information on detected vulnerabilities.
 	Vulns []*Vuln
+
+	// ExcludedVulns contains the vulnerabilities whose symbols are
+	// reachable only from entry points in excluded directories, one
+	// per vulnerability and package. They are not in Vulns.
+	ExcludedVulns []*Vuln
+
+	// CgoVulns contains the vulnerabilities of the packages imported,
+	// directly or not, by packages that use cgo, whose symbols are not
+	// reachable from the entry points, one per vulnerability and
+	// package. C code may call them in ways the call graph misses.
+	CgoVulns []*Vuln
 }
 
 // Vuln provides information on a detected vulnerability. For call
@@ -70,6 +81,10 @@ type FuncNode struct {
 	// RecvType is the receiver object type of this function, if any.
 	RecvType string
 
+	// TypeArgs are the type arguments of the function, or of its
+	// receiver type, if the function is an instantiation.
+	TypeArgs []string
+
 	// Package is the package the function is part of.
 	Package *packages.Package
 
@@ -135,7 +150,7 @@ func affectingVulnerabilities(vulns []*ModVulns, os, arch string) affectingVulns
 		var filteredVulns []*osv.Entry
 		for _, v := range mod.Vulns {
 			// Ignore vulnerabilities that have been withdrawn
-			if v.Withdrawn != nil && v.Withdrawn.Before(now) {
+			if v.IsWithdrawn(now) {
 				continue
 			}
 
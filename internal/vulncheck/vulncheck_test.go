@@ -449,3 +449,37 @@ func TestReceiver(t *testing.T) {
 		})
 	}
 }
+
+func TestAddLoadMode(t *testing.T) {
+	const typed = packages.NeedSyntax | packages.NeedTypes | packages.NeedTypesInfo | packages.NeedTypesSizes | packages.NeedExportFile
+	cfg := &packages.Config{}
+	addLoadMode(cfg, false)
+	if graph := packages.NeedImports | packages.NeedDeps | packages.NeedModule; cfg.Mode&graph != graph {
+		t.Errorf("package scan mode %v does not load the import graph", cfg.Mode)
+	}
+	if cfg.Mode&typed != 0 {
+		t.Errorf("package scan mode %v loads syntax or types", cfg.Mode)
+	}
+	cfg = &packages.Config{}
+	addLoadMode(cfg, true)
+	if want := packages.NeedSyntax | packages.NeedTypes | packages.NeedTypesInfo; cfg.Mode&want != want {
+		t.Errorf("symbol scan mode %v does not load syntax and types", cfg.Mode)
+	}
+}
+
+func TestRemoveBroken(t *testing.T) {
+	broken := &packages.Package{PkgPath: "example.com/m/broken", Errors: []packages.Error{{Msg: "undefined: x"}}}
+	user := &packages.Package{PkgPath: "example.com/m/user", Imports: map[string]*packages.Package{broken.PkgPath: broken}}
+	ok := &packages.Package{PkgPath: "example.com/m/ok", Imports: map[string]*packages.Package{"fmt": {PkgPath: "fmt"}}}
+	graph := NewPackageGraph("go1.22")
+	graph.AddPackages(broken, user, ok)
+	graph.topPkgs = []*packages.Package{broken, user, ok}
+
+	got := graph.RemoveBroken()
+	if len(got) != 1 || got[0] != broken {
+		t.Errorf("RemoveBroken() = %v, want [%s]", got, broken.PkgPath)
+	}
+	if tops := graph.TopPkgs(); len(tops) != 1 || tops[0] != ok {
+		t.Errorf("TopPkgs() = %v, want [%s]", tops, ok.PkgPath)
+	}
+}
@@ -0,0 +1,245 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+/*
+Package protocol defines the streaming JSON output of govulncheck, as
+printed with -format json, for programs that consume it.
+
+A stream is a sequence of [Message] values, each with exactly one field
+set. The first message of a stream is a [Config], whose ProtocolVersion
+field is the version of the protocol that the stream follows, a semantic
+version such as "v1.0.0". See the documentation of the types for the
+meaning of the fields, and of the govulncheck command for the order of
+messages.
+
+# Compatibility
+
+Within a major version of the protocol, streams only change in ways that
+existing consumers can ignore: a new minor version may add fields, kinds of
+messages, and values of enumerations such as [Precision], but fields are not
+removed, renamed, or given a different meaning. Consumers should therefore
+ignore fields and values that they do not know, as [Decoder] does, and
+reject streams of other major versions, which [Decoder] reports with an
+error that wraps [ErrIncompatible].
+
+The testdata directory of this package holds a conformance suite of
+streams, in which the streams named after a protocol version must be
+accepted by consumers of that major version, and those under incompatible
+must be rejected. The stream of the current version has every field. The stream of v1.99.0 stands for a later minor version,
+with fields, values, and messages that are not known yet.
+
+# Versions
+
+The minor versions of the protocol added:
+
+  - v1.4.0: the scanner_go_version, flags, go_flags, exclude_dirs,
+    library, and entries fields of [Config]; the artifact field of
+    [SBOM]; the precision, disclosure, module_chain, owners, and artifact
+    fields of [Finding]; the type_args and origin fields of [Frame]; the
+    history, info, and audit values of [ScanMode]; and the related and
+    severity fields of [OSV], the severity and database_specific fields of
+    its affected packages, the repo field and the ECOSYSTEM and GIT types
+    of their ranges, and the contact and type fields of its credits.
+  - v1.5.0: the [Diagnostic] kind of messages.
+  - v1.6.0: the deep and exclude_low_confidence fields of [Config]; the
+    purl, dispatch, and upgrade fields of [Finding]; the purl field of
+    the affected packages of [OSV]; the cgo-imported and module-unused
+    values of [Precision]; the cgo kind of [Diagnostic]; and the
+    snapshot value of [ScanMode].
+*/
+package protocol
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/mod/semver"
+	"golang.org/x/vuln/internal/govulncheck"
+	"golang.org/x/vuln/internal/osv"
+)
+
+// Version is the version of the protocol that this package implements
+// and that [NewEncoder] writes.
+const Version = govulncheck.ProtocolVersion
+
+type (
+	// Message is an entry in a stream.
+	Message = govulncheck.Message
+	// Config describes how a stream was produced.
+	Config = govulncheck.Config
+	// SBOM describes what was scanned.
+	SBOM = govulncheck.SBOM
+	// Module is a module of an SBOM.
+	Module = govulncheck.Module
+	// Progress is an informational message.
+	Progress = govulncheck.Progress
+	// OSV is an entry of the vulnerability database, in the OSV format.
+	OSV = osv.Entry
+	// Finding is a vulnerability found by a scan.
+	Finding = govulncheck.Finding
+	// Frame is an entry in the trace of a Finding.
+	Frame = govulncheck.Frame
+	// Position is a source position of a Frame.
+	Position = govulncheck.Position
+	// Disclosure describes when the vulnerability of a Finding was disclosed.
+	Disclosure = govulncheck.Disclosure
+	// Dispatch is the call through which the trace of a Finding is a
+	// low-confidence path.
+	Dispatch = govulncheck.Dispatch
+	// DispatchKind describes how the callee of a Dispatch is resolved.
+	DispatchKind = govulncheck.DispatchKind
+	// Upgrade describes how to upgrade the vulnerable module of a Finding.
+	Upgrade = govulncheck.Upgrade
+	// UpgradeDifficulty is the size of an Upgrade.
+	UpgradeDifficulty = govulncheck.UpgradeDifficulty
+	// Origin describes where the code of a Frame comes from.
+	Origin = govulncheck.Origin
+	// Precision describes the depth of the analysis behind a Finding.
+	Precision = govulncheck.Precision
+	// ScanLevel is the level of detail of a scan.
+	ScanLevel = govulncheck.ScanLevel
+	// ScanMode is the kind of scan.
+	ScanMode = govulncheck.ScanMode
+	// Diagnostic is a problem that makes the results of a scan incomplete.
+	Diagnostic = govulncheck.Diagnostic
+	// DiagnosticKind is the kind of problem of a Diagnostic.
+	DiagnosticKind = govulncheck.DiagnosticKind
+	// Handler handles the messages of a stream.
+	Handler = govulncheck.Handler
+)
+
+const (
+	OriginMain       Origin = govulncheck.OriginMain
+	OriginDependency Origin = govulncheck.OriginDependency
+	OriginStdlib     Origin = govulncheck.OriginStdlib
+
+	PrecisionSymbolReachable   Precision = govulncheck.PrecisionSymbolReachable
+	PrecisionPackageImported   Precision = govulncheck.PrecisionPackageImported
+	PrecisionExcludedReachable Precision = govulncheck.PrecisionExcludedReachable
+	PrecisionCgoImported       Precision = govulncheck.PrecisionCgoImported
+	PrecisionModuleRequired    Precision = govulncheck.PrecisionModuleRequired
+	PrecisionModuleUnused      Precision = govulncheck.PrecisionModuleUnused
+	PrecisionBinaryImprecise   Precision = govulncheck.PrecisionBinaryImprecise
+
+	ScanLevelModule  ScanLevel = govulncheck.ScanLevelModule
+	ScanLevelPackage ScanLevel = govulncheck.ScanLevelPackage
+	ScanLevelSymbol  ScanLevel = govulncheck.ScanLevelSymbol
+
+	ScanModeSource   ScanMode = govulncheck.ScanModeSource
+	ScanModeBinary   ScanMode = govulncheck.ScanModeBinary
+	ScanModeConvert  ScanMode = govulncheck.ScanModeConvert
+	ScanModeQuery    ScanMode = govulncheck.ScanModeQuery
+	ScanModeExtract  ScanMode = govulncheck.ScanModeExtract
+	ScanModeHistory  ScanMode = govulncheck.ScanModeHistory
+	ScanModeInfo     ScanMode = govulncheck.ScanModeInfo
+	ScanModeAudit    ScanMode = govulncheck.ScanModeAudit
+	ScanModeSnapshot ScanMode = govulncheck.ScanModeSnapshot
+
+	DispatchInterface  DispatchKind = govulncheck.DispatchInterface
+	DispatchReflection DispatchKind = govulncheck.DispatchReflection
+
+	UpgradePatch UpgradeDifficulty = govulncheck.UpgradePatch
+	UpgradeMinor UpgradeDifficulty = govulncheck.UpgradeMinor
+	UpgradeMajor UpgradeDifficulty = govulncheck.UpgradeMajor
+
+	DiagnosticLoadError        DiagnosticKind = govulncheck.DiagnosticLoadError
+	DiagnosticStrippedBinary   DiagnosticKind = govulncheck.DiagnosticStrippedBinary
+	DiagnosticBinaryBuildInfo  DiagnosticKind = govulncheck.DiagnosticBinaryBuildInfo
+	DiagnosticStaleDB          DiagnosticKind = govulncheck.DiagnosticStaleDB
+	DiagnosticUnverifiedDB     DiagnosticKind = govulncheck.DiagnosticUnverifiedDB
+	DiagnosticUnsupportedRange DiagnosticKind = govulncheck.DiagnosticUnsupportedRange
+	DiagnosticCgo              DiagnosticKind = govulncheck.DiagnosticCgo
+)
+
+// ErrIncompatible is wrapped by the errors for streams of a protocol
+// version that is not compatible with Version.
+var ErrIncompatible = errors.New("incompatible protocol version")
+
+// Compatible reports whether streams of protocol version v can be
+// read by this package, which is when v has the major version of
+// Version.
+func Compatible(v string) bool {
+	return semver.IsValid(v) && semver.Major(v) == semver.Major(Version)
+}
+
+// A Decoder reads the messages of a stream.
+type Decoder struct {
+	dec     *json.Decoder
+	version string
+}
+
+// NewDecoder returns a decoder of the stream read from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{dec: json.NewDecoder(r)}
+}
+
+// Decode returns the next message of the stream, or io.EOF at its end.
+// It returns an error if the stream does not start with a Config, or if
+// the protocol version of the stream is not compatible with Version.
+func (d *Decoder) Decode() (*Message, error) {
+	if !d.dec.More() {
+		return nil, io.EOF
+	}
+	var msg Message
+	if err := d.dec.Decode(&msg); err != nil {
+		return nil, err
+	}
+	if msg.Config != nil {
+		v := msg.Config.ProtocolVersion
+		if !Compatible(v) {
+			return nil, fmt.Errorf("%w: the stream has version %q, want %s", ErrIncompatible, v, semver.Major(Version))
+		}
+		d.version = v
+	} else if d.version == "" {
+		return nil, errors.New("invalid stream: it does not start with a config message")
+	}
+	return &msg, nil
+}
+
+// Version returns the protocol version of the stream,
+// once its first message has been decoded.
+func (d *Decoder) Version() string {
+	return d.version
+}
+
+// Handle reads the stream from r and passes its messages to h,
+// stopping at the first error.
+func Handle(r io.Reader, h Handler) error {
+	d := NewDecoder(r)
+	for {
+		msg, err := d.Decode()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch {
+		case msg.Config != nil:
+			err = h.Config(msg.Config)
+		case msg.Progress != nil:
+			err = h.Progress(msg.Progress)
+		case msg.SBOM != nil:
+			err = h.SBOM(msg.SBOM)
+		case msg.OSV != nil:
+			err = h.OSV(msg.OSV)
+		case msg.Finding != nil:
+			err = h.Finding(msg.Finding)
+		case msg.Diagnostic != nil:
+			err = h.Diagnostic(msg.Diagnostic)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// NewEncoder returns a handler that writes the messages it is
+// given to w as a stream, in the format of govulncheck. The
+// ProtocolVersion of Config messages should be Version.
+func NewEncoder(w io.Writer) Handler {
+	return govulncheck.NewJSONHandler(w)
+}
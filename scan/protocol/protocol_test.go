@@ -0,0 +1,204 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package protocol_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/vuln/internal/test"
+	"golang.org/x/vuln/scan/protocol"
+)
+
+func TestCompatible(t *testing.T) {
+	for v, want := range map[string]bool{
+		"v1.0.0":  true,
+		"v1.7.2":  true,
+		"v2.0.0":  false,
+		"v0.9.0":  false,
+		"1.0.0":   false,
+		"":        false,
+		"unknown": false,
+	} {
+		if got := protocol.Compatible(v); got != want {
+			t.Errorf("Compatible(%q) = %t, want %t", v, got, want)
+		}
+	}
+}
+
+// TestConformance checks that the streams of the
+// conformance suite are accepted or rejected.
+func TestConformance(t *testing.T) {
+	files, err := filepath.Glob("testdata/*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			f, err := os.Open(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			h := test.NewMockHandler()
+			if err := protocol.Handle(f, h); err != nil {
+				t.Fatal(err)
+			}
+			want := strings.TrimSuffix(filepath.Base(file), ".json")
+			if len(h.ConfigMessages) != 1 || h.ConfigMessages[0].ProtocolVersion != want {
+				t.Errorf("got config messages %v, want one of version %s", h.ConfigMessages, want)
+			}
+			if len(h.SBOMMessages) != 1 || len(h.OSVMessages) != 1 || len(h.FindingMessages) != 1 {
+				t.Errorf("got %d SBOM, %d OSV, and %d finding messages, want 1 of each",
+					len(h.SBOMMessages), len(h.OSVMessages), len(h.FindingMessages))
+			}
+		})
+	}
+
+	files, err = filepath.Glob("testdata/incompatible/*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		t.Run("incompatible/"+filepath.Base(file), func(t *testing.T) {
+			f, err := os.Open(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			if err := protocol.Handle(f, test.NewMockHandler()); err == nil {
+				t.Fatal("Handle succeeded, want error")
+			}
+		})
+	}
+}
+
+func TestDecodeIncompatible(t *testing.T) {
+	b, err := os.ReadFile("testdata/incompatible/v2.0.0.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := protocol.NewDecoder(bytes.NewReader(b))
+	if _, err := d.Decode(); !errors.Is(err, protocol.ErrIncompatible) {
+		t.Errorf("Decode() = %v, want %v", err, protocol.ErrIncompatible)
+	}
+	if v := d.Version(); v != "" {
+		t.Errorf("Version() = %q, want empty", v)
+	}
+}
+
+// TestRoundTrip checks that the encoder writes
+// streams as the decoder reads them.
+func TestRoundTrip(t *testing.T) {
+	want, err := os.ReadFile("testdata/" + protocol.Version + ".json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got bytes.Buffer
+	if err := protocol.Handle(bytes.NewReader(want), protocol.NewEncoder(&got)); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(string(want), got.String()); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+// TestFields checks that the stream of Version has every field of
+// the protocol, so that the conformance suite covers the additions
+// of each version.
+func TestFields(t *testing.T) {
+	f, err := os.Open("testdata/" + protocol.Version + ".json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	msg := reflect.TypeOf(protocol.Message{})
+	missing := make(map[string]bool)
+	addFields(msg, missing)
+	dec := json.NewDecoder(f)
+	for {
+		var v any
+		if err := dec.Decode(&v); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		markFields(msg, v, missing)
+	}
+	var names []string
+	for name := range missing {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		t.Errorf("testdata/%s.json has no %s field", protocol.Version, name)
+	}
+}
+
+// fields returns the types of the JSON fields of the struct type t,
+// by name, and the name of t. It returns nil if the values of t are
+// not JSON objects with fields.
+func fields(t reflect.Type) (map[string]reflect.Type, string) {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || t == reflect.TypeOf(time.Time{}) {
+		return nil, ""
+	}
+	fs := make(map[string]reflect.Type)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if !f.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fs[name] = f.Type
+	}
+	return fs, t.Name()
+}
+
+// addFields adds the fields of t and of the types
+// of its fields, recursively, to missing.
+func addFields(t reflect.Type, missing map[string]bool) {
+	fs, typ := fields(t)
+	for name, ft := range fs {
+		if key := typ + "." + name; !missing[key] {
+			missing[key] = true
+			addFields(ft, missing)
+		}
+	}
+}
+
+// markFields removes the fields of t set in v, the
+// JSON value of a t, from missing, recursively.
+func markFields(t reflect.Type, v any, missing map[string]bool) {
+	fs, typ := fields(t)
+	switch v := v.(type) {
+	case []any:
+		for _, e := range v {
+			markFields(t, e, missing)
+		}
+	case map[string]any:
+		for name, fv := range v {
+			if ft, ok := fs[name]; ok {
+				delete(missing, typ+"."+name)
+				markFields(ft, fv, missing)
+			}
+		}
+	}
+}
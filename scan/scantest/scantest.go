@@ -0,0 +1,124 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+/*
+Package scantest runs govulncheck from Go tests, so that a module can be
+checked for vulnerabilities with go test, for instance in continuous
+integration.
+
+A test that fails when the code of its module calls vulnerable functions is
+then
+
+	func TestVulnerabilities(t *testing.T) {
+		if testing.Short() {
+			t.Skip("skipping vulnerability scan in short mode")
+		}
+		scantest.Check(t, nil)
+	}
+
+Scans use the vulnerability database at https://vuln.go.dev unless
+Options.Flags selects another one with -db.
+*/
+package scantest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/vuln/scan"
+)
+
+// Options configures Check. A nil *Options is the same as the zero value.
+type Options struct {
+	// Dir is the directory of the module to scan. If empty, it is the
+	// root of the module containing the current directory, which is
+	// the directory of the package being tested.
+	Dir string
+
+	// Patterns are the package patterns to scan, relative to Dir.
+	// If empty, all the packages of the module are scanned.
+	Patterns []string
+
+	// Policy is the path of a policy file that decides which findings
+	// fail the test, as for the -policy flag of govulncheck. If empty,
+	// the test fails if the code calls vulnerable functions.
+	Policy string
+
+	// Flags are additional flags for govulncheck, such as "-test" or
+	// "-db=file:///path/to/db". Output flags such as -format must not
+	// be used.
+	Flags []string
+}
+
+// Check scans a module with govulncheck and fails t if vulnerabilities
+// are found, or if the policy of opts fails any of them, reporting the
+// output of govulncheck. The output is logged in any case.
+func Check(t testing.TB, opts *Options) {
+	t.Helper()
+	if opts == nil {
+		opts = &Options{}
+	}
+	dir := opts.Dir
+	if dir == "" {
+		var err error
+		if dir, err = moduleRoot(); err != nil {
+			t.Fatalf("govulncheck: %v", err)
+			return
+		}
+	}
+	args := []string{"-C", dir}
+	if opts.Policy != "" {
+		policy, err := filepath.Abs(opts.Policy)
+		if err != nil {
+			t.Fatalf("govulncheck: %v", err)
+			return
+		}
+		args = append(args, "-policy", policy)
+	}
+	args = append(args, opts.Flags...)
+	if len(opts.Patterns) > 0 {
+		args = append(args, opts.Patterns...)
+	} else {
+		args = append(args, "./...")
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := scan.Command(context.Background(), args...)
+	cmd.Stdin = strings.NewReader("")
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Start()
+	if err == nil {
+		err = cmd.Wait()
+	}
+	var exit interface{ ExitCode() int }
+	switch {
+	case err == nil:
+		t.Logf("govulncheck %s:\n%s", strings.Join(args, " "), &stdout)
+	case errors.As(err, &exit) && exit.ExitCode() == 3: // vulnerabilities found
+		t.Errorf("govulncheck %s found vulnerabilities:\n%s", strings.Join(args, " "), &stdout)
+	default:
+		t.Fatalf("govulncheck %s: %v\n%s%s", strings.Join(args, " "), err, &stdout, &stderr)
+	}
+}
+
+// moduleRoot returns the root directory of the main module
+// of the current directory.
+func moduleRoot() (string, error) {
+	out, err := exec.Command("go", "env", "GOMOD").Output()
+	if err != nil {
+		return "", err
+	}
+	gomod := strings.TrimSpace(string(out))
+	if gomod == "" || gomod == os.DevNull {
+		return "", errors.New("the current directory is not in a module")
+	}
+	return filepath.Dir(gomod), nil
+}
@@ -0,0 +1,85 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scantest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/vuln/internal/web"
+)
+
+// recorder records the failures of a test.
+type recorder struct {
+	testing.TB
+	failed, fatal bool
+	out           string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Logf(format string, args ...any) {
+	r.out = fmt.Sprintf(format, args...)
+}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.failed = true
+	r.out = fmt.Sprintf(format, args...)
+}
+
+func (r *recorder) Fatalf(format string, args ...any) {
+	r.failed, r.fatal = true, true
+	r.out = fmt.Sprintf(format, args...)
+}
+
+func TestCheck(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping test that downloads modules in short mode")
+	}
+	testdata, err := filepath.Abs(filepath.Join("..", "..", "cmd", "govulncheck", "testdata", "common"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := web.URLFromFilePath(filepath.Join(testdata, "vulndb-v1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A policy that reports findings without failing on them.
+	report := filepath.Join(t.TempDir(), "report.cel")
+	if err := os.WriteFile(report, []byte(`"report"`), 0666); err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		name, module string
+		policy       string
+		wantFailed   bool
+		wantOut      string
+	}{
+		{"vuln", "vuln", "", true, "GO-2021-0265"},
+		{"novuln", "novuln", "", false, "No vulnerabilities found."},
+		{"policy", "vuln", report, false, "GO-2021-0265"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			r := &recorder{}
+			Check(r, &Options{
+				Dir:    filepath.Join(testdata, "modules", test.module),
+				Policy: test.policy,
+				Flags:  []string{"-db", db.String()},
+			})
+			if r.fatal {
+				t.Fatalf("Check failed to scan: %s", r.out)
+			}
+			if r.failed != test.wantFailed {
+				t.Errorf("Check failed the test: %t, want %t\n%s", r.failed, test.wantFailed, r.out)
+			}
+			if !strings.Contains(r.out, test.wantOut) {
+				t.Errorf("Check output does not contain %q:\n%s", test.wantOut, r.out)
+			}
+		})
+	}
+}